@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptName     string
+	adoptDBType   string
+	adoptTTLHours int
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <container>",
+	Short: "Start tracking a Docker container that mkdb didn't create",
+	Long: `Track an existing Docker container, given by name or ID, so it gets a TTL and works
+with 'mkdb list', 'mkdb creds' and the other commands.
+
+The database type is detected from the image and confirmed before the container is
+tracked. Pass --db to skip the prompt, e.g. for an image mkdb doesn't recognize.
+The connection details are read from the container's published port and environment.
+
+Docker can't add labels to an existing container, so adopted containers aren't labeled
+as mkdb-managed and 'mkdb import' won't find them if mkdb's database is lost. The
+container is left as it is: 'mkdb stop' keeps it, and commands that recreate containers
+(upgrade, restart after removal, creds rotate and reset for Redis) refuse to replace it.
+'mkdb remove' and 'mkdb cleanup' warn before deleting it.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: requiresDocker,
+	RunE:        runAdopt,
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().StringVar(&adoptName, "name", "", "Name to track the container as (default: the Docker container's name)")
+	adoptCmd.Flags().StringVar(&adoptDBType, "db", "", "Database type (skips detection and confirmation)")
+	adoptCmd.Flags().IntVar(&adoptTTLHours, "ttl", 0, "Time to live in hours (prompts if not set)")
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	summary, err := docker.InspectContainer(args[0])
+	if err != nil {
+		return fmt.Errorf("container '%s' not found in Docker: %w", args[0], err)
+	}
+	if summary.Managed {
+		return fmt.Errorf("container '%s' is already labeled as mkdb-managed, use 'mkdb import' to track it", summary.Name)
+	}
+
+	tracked, err := database.ListContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range tracked {
+		if c.ContainerID == summary.ID {
+			return fmt.Errorf("container '%s' is already tracked as '%s'", summary.Name, c.DisplayName)
+		}
+	}
+
+	name := adoptName
+	if name == "" {
+		name = summary.Name
+	}
+	if _, err := database.GetContainerByDisplayName(name); err == nil {
+		return fmt.Errorf("container with name '%s' already exists, pick another with --name", name)
+	}
+
+	dbType, err := adoptType(summary)
+	if err != nil {
+		return err
+	}
+	if summary.Port == "" {
+		return fmt.Errorf("container '%s' has no published port, mkdb needs one for connection strings", summary.Name)
+	}
+
+	ttl := adoptTTLHours
+	if ttl <= 0 {
+		ttl, err = promptImportTTL()
+		if err != nil {
+			return err
+		}
+	}
+
+	container := &database.Container{
+		// Commands exec into the container by this name, so it has to be Docker's
+		Name:        summary.Name,
+		DisplayName: name,
+		Type:        dbType,
+		Version:     imageVersion(summary.Image),
+		ContainerID: summary.ID,
+		Port:        summary.Port,
+		Status:      summaryStatus(*summary),
+		CreatedAt:   summary.Created,
+		ExpiresAt:   time.Now().Add(time.Duration(ttl) * time.Hour),
+		VolumeType:  summary.VolumeType,
+		VolumePath:  summary.VolumePath,
+		Adopted:     true,
+	}
+	if err := trackContainer(container, recoverCredentials(container), "adopted", fmt.Sprintf("Adopted Docker container %s (%s)", summary.Name, summary.ID[:12])); err != nil {
+		return fmt.Errorf("failed to track container: %w", err)
+	}
+
+	ui.Success(fmt.Sprintf("Adopted %s as '%s' (%s) on port %s", summary.Name, name, dbType, summary.Port))
+	ui.Info(fmt.Sprintf("Expires at %s, cleanup offers to remove it after that unless it's extended", ui.FormatTime(container.ExpiresAt)))
+	return nil
+}
+
+// adoptType returns the database type from --db, or confirms the type detected from the container
+func adoptType(summary *docker.ContainerSummary) (string, error) {
+	if adoptDBType != "" {
+		return types.NormalizeDBType(adoptDBType)
+	}
+
+	if summary.Type == "" {
+		ui.Warning(fmt.Sprintf("Couldn't detect the database type from image %s", summary.Image))
+	} else {
+		ui.Info(fmt.Sprintf("Detected %s from image %s", summary.Type, summary.Image))
+		if ui.AssumeYes {
+			return summary.Type, nil
+		}
+	}
+
+	dbType, err := ui.SelectDBType(summary.Type)
+	if err != nil {
+		return "", fmt.Errorf("failed to select database type: %w", err)
+	}
+	return dbType, nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pbzona/mkdb/internal/cleanup"
 	"github.com/pbzona/mkdb/internal/database"
@@ -12,17 +13,31 @@ import (
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Clean up expired database containers",
-	Long:  `Interactively select and remove expired database containers and their volumes.`,
-	RunE:  runCleanup,
+	Long: `Interactively select and remove expired database containers and their volumes.
+
+With --yes, every matching container is removed without prompting.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runCleanup,
 }
 
+var (
+	includeStopped bool
+	cleanupGrace   time.Duration
+)
+
 func init() {
 	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.Flags().BoolVar(&includeStopped, "include-stopped", false, "Also include stopped containers that have expired")
+	cleanupCmd.Flags().DurationVar(&cleanupGrace, "grace", 0, "Only include containers that expired at least this long ago (e.g., 24h)")
 }
 
 func runCleanup(cmd *cobra.Command, args []string) error {
+	if cleanupGrace < 0 {
+		return fmt.Errorf("--grace must not be negative")
+	}
+
 	// Get expired containers
-	containers, err := database.GetExpiredContainers()
+	containers, err := database.GetContainersForCleanup(cleanupGrace, includeStopped)
 	if err != nil {
 		return fmt.Errorf("failed to get expired containers: %w", err)
 	}
@@ -34,6 +49,11 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 
 	ui.Info(fmt.Sprintf("Found %d expired container(s)", len(containers)))
 
+	// With --yes there's no one to choose, so everything that matched is removed
+	if ui.AssumeYes {
+		return cleanup.RemoveAll(containers)
+	}
+
 	// Force cleanup to run (it will prompt for selection)
 	return cleanup.RunInteractive(containers)
 }
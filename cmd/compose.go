@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pbzona/mkdb/internal/compose"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportContainerName string
+	exportOutput        string
+	exportForce         bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:     "export",
+	Aliases: []string{"compose"},
+	Short:   "Export a container as a docker-compose.yml",
+	Long: `Write a docker-compose.yml describing a container's image, port, environment, volumes,
+and command, matching what mkdb runs.
+
+The file contains the container's credentials, so share it with care.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportContainerName, "name", "", "Container name (skips interactive selection)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "docker-compose.yml", "File to write, or - for stdout")
+	exportCmd.Flags().BoolVar(&exportForce, "force", false, "Overwrite the output file if it exists")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	var container *database.Container
+	var err error
+
+	// If name is provided, look it up directly
+	if exportContainerName != "" {
+		container, err = database.GetContainerByDisplayName(exportContainerName)
+		if err != nil {
+			return fmt.Errorf("container '%s' not found", exportContainerName)
+		}
+	} else {
+		// Get all containers
+		containers, err := database.ListContainers()
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		if len(containers) == 0 {
+			ui.Warning("No containers found")
+			return nil
+		}
+
+		// Select container
+		container, err = ui.SelectContainer(containers, "Select container to export")
+		if err != nil {
+			return fmt.Errorf("failed to select container: %w", err)
+		}
+	}
+
+	service, err := compose.BuildComposeService(container)
+	if err != nil {
+		return err
+	}
+
+	data, err := compose.Marshal(container.DisplayName, service)
+	if err != nil {
+		return err
+	}
+
+	if exportOutput == "-" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if _, err := os.Stat(exportOutput); err == nil && !exportForce {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", exportOutput)
+	}
+
+	// The file holds credentials, so keep it private
+	if err := os.WriteFile(exportOutput, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+	}
+
+	ui.Success(fmt.Sprintf("Wrote %s for '%s'", exportOutput, container.DisplayName))
+	return nil
+}
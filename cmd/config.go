@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,18 +15,31 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var configApply bool
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Edit database configuration file",
-	Long:  `Open the database configuration file in your default editor ($EDITOR).`,
-	RunE:  runConfig,
+	Long: `Open the database configuration file in your default editor ($EDITOR).
+
+With --apply, a running container is restarted once the editor exits so the changes take
+effect. It's left alone if the file is unchanged or fails validation.`,
+	RunE: runConfig,
 }
 
 func init() {
 	rootCmd.AddCommand(configCmd)
+	configCmd.Flags().BoolVar(&configApply, "apply", false, "Restart the container after editing if the config changed")
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
+	// Editing works without Docker, so only --apply needs the daemon
+	if configApply {
+		if err := docker.Ping(); err != nil {
+			return fmt.Errorf("--apply needs Docker to restart the container (is Docker installed and running?): %w", err)
+		}
+	}
+
 	// Get all containers
 	containers, err := database.ListContainers()
 	if err != nil {
@@ -42,6 +57,10 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to select container: %w", err)
 	}
 
+	if container.NoConfig {
+		return fmt.Errorf("container '%s' was created with --no-config and uses the image's default configuration", container.DisplayName)
+	}
+
 	// Get config file path
 	configDir := filepath.Join(config.DataDir, "configs", container.DisplayName)
 	configFile := filepath.Join(configDir, docker.GetConfigFileName(container.Type))
@@ -57,6 +76,11 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		editor = "vi" // Default to vi
 	}
 
+	before, err := fileHash(configFile)
+	if err != nil {
+		return err
+	}
+
 	ui.Info(fmt.Sprintf("Opening %s in %s...", configFile, editor))
 
 	// Open editor
@@ -69,11 +93,50 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to open editor: %w", err)
 	}
 
+	// Validate the edited config so a broken file doesn't silently break the next restart
+	if err := docker.ValidateConfigFile(container.Type, configFile); err != nil {
+		ui.Newline()
+		ui.Warning(fmt.Sprintf("Config file may be invalid: %v", err))
+		ui.Warning("Restarting with this config may fail. Run 'mkdb config' again to fix it.")
+		if configApply {
+			ui.Warning("Not restarting the container")
+		}
+		return nil
+	}
+
+	if configApply {
+		after, err := fileHash(configFile)
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Equal(before, after):
+			ui.Info("Config file unchanged, not restarting")
+		case container.Status != "running":
+			ui.Info(fmt.Sprintf("Container '%s' is %s, the new config is used the next time it starts", container.DisplayName, container.Status))
+		default:
+			return restartContainer(container, docker.DefaultStopTimeout, false)
+		}
+		return nil
+	}
+
 	// Print restart command
-	fmt.Println()
-	ui.Info("To apply configuration changes, restart the container:")
-	fmt.Printf("  mkdb restart\n")
-	fmt.Println()
+	if !ui.Quiet {
+		fmt.Println()
+		ui.Info("To apply configuration changes, restart the container:")
+		fmt.Printf("  mkdb restart\n")
+		fmt.Println()
+	}
 
 	return nil
 }
+
+// fileHash returns the SHA-256 of a file's contents, to tell whether an editor changed it
+func fileHash(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(content)
+	return sum[:], nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	connectContainerName string
+	connectLang          string
+)
+
+var connectCmd = &cobra.Command{
+	Use:   "connect",
+	Short: "Print connection snippets for a container",
+	Long: `Print ready-to-use snippets for connecting to a container from its command line client,
+Go, Python, and Node.
+
+Snippets include the container's credentials.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runConnect,
+}
+
+func init() {
+	rootCmd.AddCommand(connectCmd)
+	connectCmd.Flags().StringVar(&connectContainerName, "name", "", "Container name (skips interactive selection)")
+	connectCmd.Flags().StringVar(&connectLang, "lang", "", "Only print the snippet for this language ("+strings.Join(credentials.SnippetLanguages, ", ")+")")
+}
+
+func runConnect(cmd *cobra.Command, args []string) error {
+	if connectLang != "" && !slices.Contains(credentials.SnippetLanguages, connectLang) {
+		return fmt.Errorf("invalid language '%s' (must be one of: %s)", connectLang, strings.Join(credentials.SnippetLanguages, ", "))
+	}
+
+	var container *database.Container
+	var err error
+
+	// If name is provided, look it up directly
+	if connectContainerName != "" {
+		container, err = database.GetContainerByDisplayName(connectContainerName)
+		if err != nil {
+			return fmt.Errorf("container '%s' not found", connectContainerName)
+		}
+	} else {
+		// Get all containers
+		containers, err := database.ListContainers()
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		if len(containers) == 0 {
+			ui.Warning("No containers found")
+			return nil
+		}
+
+		// Select container
+		container, err = ui.SelectContainer(containers, "Select container")
+		if err != nil {
+			return fmt.Errorf("failed to select container: %w", err)
+		}
+	}
+
+	connStr, err := defaultConnectionString(container)
+	if err != nil {
+		return err
+	}
+	snippets := credentials.GenerateSnippets(container.Type, connStr)
+	if len(snippets) == 0 {
+		return fmt.Errorf("no connection snippets available for %s", container.Type)
+	}
+
+	// A single snippet is printed bare so it can be piped
+	if connectLang != "" {
+		fmt.Println(snippets[connectLang])
+		return nil
+	}
+
+	for _, lang := range credentials.SnippetLanguages {
+		ui.Header(lang)
+		fmt.Println(snippets[lang])
+		ui.Newline()
+	}
+	return nil
+}
@@ -1,19 +1,31 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/atotto/clipboard"
 	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/credentials"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/probe"
 	"github.com/pbzona/mkdb/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	credsContainerName string
+	credsEnvKey        string
+	credsWriteEnv      string
+	credsAll           bool
+	credsFormat        string
+	credsRotateUser    string
+	credsRotateAll     bool
 )
 
 var credsCmd = &cobra.Command{
@@ -25,22 +37,48 @@ var credsCmd = &cobra.Command{
 var credsGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get connection string for the default user",
-	Long:  `Display the connection string for the default database user.`,
-	RunE:  runCredsGet,
+	Long: `Display the connection string for the default database user.
+
+With --all, print the connection strings of every running container as name=URL lines,
+or as JSON with --format json. Containers whose credentials can't be read are skipped
+with a warning.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runCredsGet,
 }
 
 var credsCopyCmd = &cobra.Command{
-	Use:   "copy",
-	Short: "Copy connection string to clipboard",
-	Long:  `Copy the connection string for the default database user to the clipboard.`,
-	RunE:  runCredsCopy,
+	Use:         "copy",
+	Short:       "Copy connection string to clipboard",
+	Long:        `Copy the connection string for the default database user to the clipboard.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runCredsCopy,
 }
 
 var credsRotateCmd = &cobra.Command{
 	Use:   "rotate",
 	Short: "Rotate credentials for the default user",
-	Long:  `Generate a new password for the default user and update it in the database.`,
-	RunE:  runCredsRotate,
+	Long: `Generate a new password for the default user and update it in the database.
+
+Use --user to rotate a user created with 'mkdb user create' instead, or --all-users to
+rotate every user on the container. With --all-users, each new connection string is
+printed as a username=URL line, and users that fail are reported without stopping the rest.`,
+	Annotations: requiresDocker,
+	RunE:        runCredsRotate,
+}
+
+var credsResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Set a new password when the stored one can't be decrypted",
+	Long: `Generate a new password for the default user of a container whose stored password can't
+be decrypted, such as after the encryption key was deleted, and apply it to the database.
+
+Use 'mkdb creds rotate' while the password can still be decrypted.
+
+MySQL containers can't be reset. Their root password is encrypted with the same key, so once
+the key is lost mkdb can't log in to change the password. Restore the previous key, or dump
+the data and recreate the database.`,
+	Annotations: requiresDocker,
+	RunE:        runCredsReset,
 }
 
 func init() {
@@ -48,29 +86,121 @@ func init() {
 	credsCmd.AddCommand(credsGetCmd)
 	credsCmd.AddCommand(credsCopyCmd)
 	credsCmd.AddCommand(credsRotateCmd)
+	credsCmd.AddCommand(credsResetCmd)
 
 	// Add --name flag to all creds subcommands
 	credsGetCmd.Flags().StringVar(&credsContainerName, "name", "", "Container name (skips interactive selection)")
 	credsCopyCmd.Flags().StringVar(&credsContainerName, "name", "", "Container name (skips interactive selection)")
 	credsRotateCmd.Flags().StringVar(&credsContainerName, "name", "", "Container name (skips interactive selection)")
+	credsResetCmd.Flags().StringVar(&credsContainerName, "name", "", "Container name (skips interactive selection)")
+	credsRotateCmd.Flags().StringVar(&credsRotateUser, "user", "", "Rotate this user's password instead of the default user's")
+	credsRotateCmd.Flags().BoolVar(&credsRotateAll, "all-users", false, "Rotate the passwords of all users on the container")
+
+	credsGetCmd.Flags().StringVar(&credsEnvKey, "env-key", "", "Environment variable name for the connection string (default: DB_URL)")
+	credsGetCmd.Flags().StringVar(&credsWriteEnv, "write-env", "", "Write the connection string into a .env file at this path instead of printing it")
+	credsGetCmd.Flags().BoolVar(&credsAll, "all", false, "Print the connection strings of all running containers")
+	credsGetCmd.Flags().StringVar(&credsFormat, "format", "env", "Output format for --all (env, json)")
+	credsCopyCmd.Flags().StringVar(&credsEnvKey, "env-key", "", "Environment variable name for the connection string (default: DB_URL)")
 }
 
 func runCredsGet(cmd *cobra.Command, args []string) error {
-	envVar, err := getConnectionString()
+	if credsAll {
+		return runCredsGetAll(cmd)
+	}
+
+	container, err := selectCredsContainer()
 	if err != nil {
 		return err
 	}
+	return printCredentials(container)
+}
+
+// printCredentials prints the default user's connection string for container, or writes it with --write-env
+func printCredentials(container *database.Container) error {
+	envKey, connStr, err := containerConnectionString(container)
+	if err != nil {
+		return err
+	}
+
+	// Write into a .env file, replacing any previous value for the key
+	if credsWriteEnv != "" {
+		if err := credentials.UpsertEnvVar(credsWriteEnv, envKey, connStr); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Wrote %s to %s", envKey, credsWriteEnv))
+		return nil
+	}
 
 	// Print the connection string
-	fmt.Println(envVar)
+	fmt.Println(credentials.FormatEnvVar(envKey, connStr))
+	return nil
+}
+
+// credsEntry is a container's connection string in 'mkdb creds get --all --format json'
+type credsEntry struct {
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	ConnectionString string `json:"connection_string"`
+}
+
+// runCredsGetAll prints the connection strings of all running containers
+func runCredsGetAll(cmd *cobra.Command) error {
+	if credsFormat != "env" && credsFormat != "json" {
+		return fmt.Errorf("invalid format %q (valid: env, json)", credsFormat)
+	}
+	for _, flag := range []string{"name", "write-env", "env-key"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s cannot be used with --all", flag)
+		}
+	}
+
+	containers, err := database.ListContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	entries := []credsEntry{}
+	for _, c := range containers {
+		if c.Status != "running" {
+			continue
+		}
+		connStr, err := defaultConnectionString(c)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Skipping '%s': %v", c.DisplayName, err))
+			continue
+		}
+		entries = append(entries, credsEntry{Name: c.DisplayName, Type: c.Type, ConnectionString: connStr})
+	}
+
+	if credsFormat == "json" {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode connection strings: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		ui.Info("No running containers")
+		return nil
+	}
+	for _, e := range entries {
+		fmt.Println(credentials.FormatEnvVar(e.Name, e.ConnectionString))
+	}
 	return nil
 }
 
 func runCredsCopy(cmd *cobra.Command, args []string) error {
-	envVar, err := getConnectionString()
+	container, err := selectCredsContainer()
+	if err != nil {
+		return err
+	}
+	envKey, connStr, err := containerConnectionString(container)
 	if err != nil {
 		return err
 	}
+	envVar := credentials.FormatEnvVar(envKey, connStr)
 
 	// Copy to clipboard
 	if err := clipboard.WriteAll(envVar); err != nil {
@@ -81,35 +211,53 @@ func runCredsCopy(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getConnectionString() (string, error) {
-	var container *database.Container
-	var err error
-
+// selectCredsContainer looks up the container given by --name, or prompts for one
+func selectCredsContainer() (*database.Container, error) {
 	// If name is provided, look it up directly
 	if credsContainerName != "" {
-		container, err = database.GetContainerByDisplayName(credsContainerName)
-		if err != nil {
-			return "", fmt.Errorf("container '%s' not found", credsContainerName)
-		}
-	} else {
-		// Get all containers
-		containers, err := database.ListContainers()
+		container, err := database.GetContainerByDisplayName(credsContainerName)
 		if err != nil {
-			return "", fmt.Errorf("failed to list containers: %w", err)
+			return nil, fmt.Errorf("container '%s' not found", credsContainerName)
 		}
+		return container, nil
+	}
 
-		if len(containers) == 0 {
-			ui.Warning("No containers found")
-			return "", fmt.Errorf("no containers found")
-		}
+	// Get all containers
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
 
-		// Select container
-		container, err = ui.SelectContainer(containers, "Select container")
-		if err != nil {
-			return "", fmt.Errorf("failed to select container: %w", err)
-		}
+	if len(containers) == 0 {
+		ui.Warning("No containers found")
+		return nil, fmt.Errorf("no containers found")
 	}
 
+	// Select container
+	container, err := ui.SelectContainer(containers, "Select container")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select container: %w", err)
+	}
+	return container, nil
+}
+
+// containerConnectionString returns the env var name and connection string for a container's default user
+func containerConnectionString(container *database.Container) (string, string, error) {
+	connStr, err := defaultConnectionString(container)
+	if err != nil {
+		return "", "", err
+	}
+
+	envKey, err := resolveEnvKey(credsEnvKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return envKey, connStr, nil
+}
+
+// defaultConnectionString returns the connection string for a container's default user
+func defaultConnectionString(container *database.Container) (string, error) {
 	// Get default user
 	user, err := database.GetDefaultUser(container.ID)
 	if err != nil {
@@ -131,19 +279,30 @@ func getConnectionString() (string, error) {
 	}
 
 	// Format connection string
-	connStr := credentials.FormatConnectionString(
+	return credentials.FormatConnectionString(
 		container.Type,
 		username,
 		password,
-		"localhost",
+		credentials.ConnectionHost(),
 		container.Port,
-		container.DisplayName,
-	)
+		connectionDBName(container),
+	), nil
+}
 
-	return credentials.FormatEnvVar(connStr), nil
+// connectionDBName returns the database to put in a container's connection strings
+// For Redis, use the database number chosen with 'mkdb start --redis-db' instead of the container name
+func connectionDBName(container *database.Container) string {
+	if container.Type == "redis" {
+		return strconv.Itoa(container.RedisDB)
+	}
+	return container.DisplayName
 }
 
 func runCredsRotate(cmd *cobra.Command, args []string) error {
+	if credsRotateUser != "" && credsRotateAll {
+		return fmt.Errorf("--user and --all-users cannot be used together")
+	}
+
 	var container *database.Container
 	var err error
 
@@ -183,56 +342,438 @@ func runCredsRotate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Get default user
+	users, err := rotationUsers(container)
+	if err != nil {
+		return err
+	}
+
+	// A password on the command line is only replaced by recreating the container, which loses
+	// data that isn't on a volume. Only the default user's password is passed that way
+	recreate := docker.PasswordInCommand(container.Type) && slices.ContainsFunc(users, func(u *database.User) bool { return u.IsDefault })
+	if recreate {
+		if err := checkRecreatable(container); err != nil {
+			return err
+		}
+		confirmed, err := confirmRecreate(container, "Rotate the password anyway?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			ui.Info("Rotation cancelled")
+			return nil
+		}
+	}
+
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
+	}
+
+	if !credsRotateAll {
+		user := users[0]
+		newPassword, err := rotateUserPassword(container, user, adminPassword)
+		if err != nil {
+			return err
+		}
+		if recreate {
+			if err := recreateWithNewPassword(container); err != nil {
+				return err
+			}
+		}
+
+		ui.Success("Password rotated successfully!")
+
+		envKey, err := resolveEnvKey("")
+		if err != nil {
+			return err
+		}
+
+		// Print the connection string
+		fmt.Println(credentials.FormatEnvVar(envKey, rotatedConnectionString(container, user, newPassword)))
+		return nil
+	}
+
+	rotated, failed := rotateAllUsers(container, users, adminPassword, rotateUserPassword)
+	if recreate && !slices.ContainsFunc(users, func(u *database.User) bool { return u.IsDefault && !slices.Contains(failed, rotationLabel(u)) }) {
+		ui.Warning("The default user's password wasn't rotated, so the container is left as it is")
+	} else if recreate {
+		// The new container loses in-memory users such as Redis ACL users, the recreate creates
+		// them again from the passwords just stored, so the printed credentials still work
+		if err := recreateWithNewPassword(container); err != nil {
+			return err
+		}
+	}
+
+	if len(rotated) > 0 {
+		ui.Success(fmt.Sprintf("Rotated %d of %d passwords", len(rotated), len(users)))
+	}
+	for _, user := range users {
+		if newPassword, ok := rotated[rotationLabel(user)]; ok {
+			fmt.Printf("%s=%s\n", rotationLabel(user), rotatedConnectionString(container, user, newPassword))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to rotate %d user(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func runCredsReset(cmd *cobra.Command, args []string) error {
+	container, err := selectCredsContainer()
+	if err != nil {
+		return err
+	}
+	if container.Status != "running" || container.ContainerID == "" {
+		return fmt.Errorf("container '%s' is not running", container.DisplayName)
+	}
+
 	user, err := database.GetDefaultUser(container.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get default user: %w", err)
 	}
+	if user.PasswordHash == "" {
+		return fmt.Errorf("'%s' was created with --no-auth and has no password to reset", container.DisplayName)
+	}
+	if _, err := config.Decrypt(user.PasswordHash); err == nil {
+		return fmt.Errorf("the password of '%s' can still be decrypted, use 'mkdb creds rotate' to change it", container.DisplayName)
+	}
 
-	// Check if database is unauthenticated
-	if user.Username == "" && user.PasswordHash == "" {
-		return fmt.Errorf("cannot rotate password for unauthenticated database")
+	var newPassword string
+	if docker.PasswordInCommand(container.Type) {
+		// The old password is needed to change it in the running server, recreating the container sets it instead
+		if err := checkRecreatable(container); err != nil {
+			return err
+		}
+		confirmed, err := confirmRecreate(container, "Reset the password anyway?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			ui.Info("Reset cancelled")
+			return nil
+		}
+
+		if newPassword, err = storeNewPassword(user); err != nil {
+			return err
+		}
+		if err := recreateWithNewPassword(container); err != nil {
+			return err
+		}
+	} else {
+		// Without a separate admin account the lost password isn't needed, the server is changed from inside the container
+		// The admin password is encrypted with the same key, so it has normally been lost as well
+		var adminPassword string
+		if docker.HasAdminPassword(container.Type) {
+			if adminPassword, err = credentials.AdminPassword(container); err != nil {
+				return fmt.Errorf("the %s root password of '%s' can't be decrypted either, so mkdb can't log in to reset the password. Restore the previous encryption key, or dump the data and recreate the database: %w", container.Type, container.DisplayName, err)
+			}
+		}
+		if newPassword, err = rotateUserPassword(container, user, adminPassword); err != nil {
+			return err
+		}
+	}
+
+	ui.Success("Password reset successfully!")
+
+	envKey, err := resolveEnvKey("")
+	if err != nil {
+		return err
+	}
+	fmt.Println(credentials.FormatEnvVar(envKey, rotatedConnectionString(container, user, newPassword)))
+	return nil
+}
+
+// confirmRecreate asks before a container without a volume is recreated for a new password, since its data is lost
+// Containers with a volume are recreated without asking
+func confirmRecreate(container *database.Container, question string) (bool, error) {
+	if container.VolumeType != "" && container.VolumeType != "none" {
+		return true, nil
+	}
+	ui.Warning(fmt.Sprintf("'%s' has to be recreated to keep the new password, and it has no volume, so its data will be lost", container.DisplayName))
+	confirmed, err := ui.PromptConfirm(question)
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	return confirmed, nil
+}
+
+// storeNewPassword generates a password for user and stores it encrypted, without changing it in the database server
+func storeNewPassword(user *database.User) (string, error) {
+	newPassword, err := credentials.GeneratePassword(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	encryptedPassword, err := config.Encrypt(newPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	user.PasswordHash = encryptedPassword
+	if err := database.UpdateUser(user); err != nil {
+		return "", fmt.Errorf("failed to update user: %w", err)
+	}
+	return newPassword, nil
+}
+
+// checkEncryptionKey warns when the encryption key changed and stored passwords or environments can't be
+// decrypted anymore
+// Once none of them are left, the current key is accepted and the warning stops
+func checkEncryptionKey() {
+	if !config.KeyMismatch() {
+		return
+	}
+
+	names, err := undecryptableContainers()
+	if err != nil {
+		config.Logger.Warn("Failed to check stored passwords", "error", err)
+		return
+	}
+	if len(names) == 0 {
+		if err := config.AcceptKey(); err != nil {
+			config.Logger.Warn("Failed to accept encryption key", "error", err)
+		}
+		return
+	}
+
+	key := filepath.Join(config.DataDir, config.KeyFileName)
+	if config.ActiveStore().Name() == config.CredStoreKeyring {
+		key = "the OS keyring"
+	}
+	// This runs before every command, so it stays out of output that's parsed, like --json or 'creds get'
+	ui.WarningStderr(fmt.Sprintf("The encryption key in %s doesn't decrypt the stored passwords or --env values of: %s", key, strings.Join(names, ", ")))
+	ui.WarningStderr("Restore the previous key, or set new passwords with 'mkdb creds reset --name <name>'")
+	ui.WarningStderr("Lost --env values can't be reset, remove the database and start it again with them")
+}
+
+// undecryptableContainers returns the names of containers with a stored password or environment that
+// doesn't decrypt
+func undecryptableContainers() ([]string, error) {
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var names []string
+	for _, c := range containers {
+		if c.EnvUnreadable {
+			names = append(names, c.DisplayName)
+			continue
+		}
+		hashes := []string{c.AdminPasswordHash}
+		users, err := database.ListUsers(c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			hashes = append(hashes, user.PasswordHash)
+		}
+
+		if slices.ContainsFunc(hashes, func(hash string) bool {
+			_, err := config.Decrypt(hash)
+			return hash != "" && err != nil
+		}) {
+			names = append(names, c.DisplayName)
+		}
+	}
+	return names, nil
+}
+
+// rotateAllUsers rotates each of users with rotate, returning the new passwords by rotation label
+// and the labels of the users that failed. It keeps going past failures, so one broken user doesn't
+// leave the rest on old passwords. The admin password is read again after each rotation, since on
+// databases like Redis it's the default user's and the rest have to authenticate with the new one
+func rotateAllUsers(container *database.Container, users []*database.User, adminPassword string, rotate func(*database.Container, *database.User, string) (string, error)) (map[string]string, []string) {
+	var failed []string
+	rotated := make(map[string]string)
+	for _, user := range users {
+		newPassword, err := rotate(container, user, adminPassword)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Failed to rotate '%s': %v", rotationLabel(user), err))
+			failed = append(failed, rotationLabel(user))
+			continue
+		}
+		rotated[rotationLabel(user)] = newPassword
+
+		if current, err := credentials.AdminPassword(container); err == nil {
+			adminPassword = current
+		} else {
+			ui.Warning(fmt.Sprintf("Failed to read the admin password after rotating '%s': %v", rotationLabel(user), err))
+		}
+	}
+	return rotated, failed
+}
+
+// rotationUsers returns the users selected by --user and --all-users, the default user without either
+func rotationUsers(container *database.Container) ([]*database.User, error) {
+	if !credsRotateAll && credsRotateUser == "" {
+		user, err := database.GetDefaultUser(container.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default user: %w", err)
+		}
+
+		// Check if database is unauthenticated
+		if user.Username == "" && user.PasswordHash == "" {
+			return nil, fmt.Errorf("'%s' was created with --no-auth and has no password to rotate", container.DisplayName)
+		}
+		return []*database.User{user}, nil
+	}
+
+	users, err := database.ListUsers(container.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
-	ui.Info("Generating new password...")
+	if credsRotateUser != "" {
+		for _, user := range users {
+			if user.Username == credsRotateUser {
+				if user.PasswordHash == "" {
+					return nil, fmt.Errorf("user '%s' has no password to rotate", user.Username)
+				}
+				return []*database.User{user}, nil
+			}
+		}
+		return nil, fmt.Errorf("user '%s' not found on '%s'", credsRotateUser, container.DisplayName)
+	}
+
+	// Users without a password, such as the entry for a --no-auth container, are skipped
+	users = slices.DeleteFunc(users, func(u *database.User) bool { return u.PasswordHash == "" })
+	if len(users) == 0 {
+		return nil, fmt.Errorf("'%s' has no users with a password to rotate", container.DisplayName)
+	}
+	return users, nil
+}
+
+// rotateUserPassword sets a new generated password for user in the database container, checks it works,
+// then stores it encrypted. It returns the new password
+func rotateUserPassword(container *database.Container, user *database.User, adminPassword string) (string, error) {
+	ui.Info(fmt.Sprintf("Generating new password for '%s'...", rotationLabel(user)))
 
 	// Generate new password
 	newPassword, err := credentials.GeneratePassword(32)
 	if err != nil {
-		return fmt.Errorf("failed to generate password: %w", err)
+		return "", fmt.Errorf("failed to generate password: %w", err)
 	}
 
 	// Update password in database container
-	if err := docker.RotatePassword(container.ContainerID, container.Type, user.Username, newPassword, container.DisplayName); err != nil {
-		return fmt.Errorf("failed to rotate password in database: %w", err)
+	if err := docker.RotatePassword(container.ContainerID, container.Type, user.Username, newPassword, container.DisplayName, adminPassword); err != nil {
+		return "", fmt.Errorf("failed to rotate password in database: %w", err)
+	}
+
+	// The database accepted the new password, so the old one no longer works and the new one is
+	// stored whether or not a test connection succeeds
+	pingCommand, err := probe.Command(container.Type, container.DisplayName, user.Username, newPassword)
+	if err == nil {
+		_, err = docker.ExecCommand(container.ContainerID, pingCommand)
+	}
+	if err != nil {
+		ui.Warning(fmt.Sprintf("The new password for '%s' is active, but a test connection with it failed: %v", rotationLabel(user), err))
 	}
 
 	// Encrypt and store new password
 	encryptedPassword, err := config.Encrypt(newPassword)
 	if err != nil {
-		return fmt.Errorf("failed to encrypt password: %w", err)
+		return "", fmt.Errorf("failed to encrypt password: %w", err)
 	}
 
 	user.PasswordHash = encryptedPassword
 	if err := database.UpdateUser(user); err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		return "", fmt.Errorf("failed to update user: %w", err)
 	}
+	return newPassword, nil
+}
 
-	ui.Success("Password rotated successfully!")
+// rotationLabel names a user in rotation output, databases without usernames have a single "default" user
+func rotationLabel(user *database.User) string {
+	if user.Username == "" {
+		return "default"
+	}
+	return user.Username
+}
 
-	// Display new connection string
-	connStr := credentials.FormatConnectionString(
+// rotatedConnectionString returns the connection string for user with its new password
+func rotatedConnectionString(container *database.Container, user *database.User, password string) string {
+	return credentials.FormatConnectionString(
 		container.Type,
 		user.Username,
-		newPassword,
-		"localhost",
+		password,
+		credentials.ConnectionHost(),
 		container.Port,
-		container.DisplayName,
+		connectionDBName(container),
 	)
+}
 
-	envVar := credentials.FormatEnvVar(connStr)
+// recreateWithNewPassword replaces a running container so its command line carries the
+// password just stored for the default user
+func recreateWithNewPassword(container *database.Container) error {
+	ui.Info(fmt.Sprintf("Recreating '%s' with the new password...", container.DisplayName))
 
-	// Print the connection string
-	fmt.Println(envVar)
+	if err := docker.StopContainer(container.ContainerID, docker.DefaultStopTimeout); err != nil {
+		ui.Warning("The new password is active, but it will revert if the container restarts before it's recreated")
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	if err := docker.RemoveContainer(container.ContainerID); err != nil {
+		ui.Warning("The new password is active, but it will revert if the container restarts before it's recreated")
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	if _, _, err := recreateContainer(container); err != nil {
+		// Record that the old container is gone so 'mkdb restart' recreates it with the new password
+		container.ContainerID = ""
+		container.Status = "stopped"
+		if updateErr := database.UpdateContainer(container); updateErr != nil {
+			ui.Warning(fmt.Sprintf("Failed to update container record: %v", updateErr))
+		}
+		return fmt.Errorf("%w (run 'mkdb restart --name %s' to try again)", err, container.DisplayName)
+	}
+
+	if err := database.UpdateContainer(container); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
 	return nil
 }
+
+// resolveEnvKey returns the environment variable name to emit connection strings under
+// The flag value takes precedence over the configured default, which takes precedence over DB_URL
+func resolveEnvKey(flagValue string) (string, error) {
+	key := flagValue
+	if key == "" {
+		defaults, err := config.LoadDefaults()
+		if err != nil {
+			return "", fmt.Errorf("failed to load defaults: %w", err)
+		}
+		key = defaults.EnvKey
+	}
+
+	if key == "" {
+		return credentials.DefaultEnvKey, nil
+	}
+
+	if err := credentials.ValidateEnvKey(key); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// resolveUsername returns the username to create the default user with
+// The flag value takes precedence over the configured default, which takes precedence over dbuser
+func resolveUsername(flagValue string) (string, error) {
+	username := flagValue
+	if username == "" {
+		defaults, err := config.LoadDefaults()
+		if err != nil {
+			return "", fmt.Errorf("failed to load defaults: %w", err)
+		}
+		username = defaults.Username
+	}
+
+	if username == "" {
+		return credentials.DefaultUsername, nil
+	}
+
+	if err := credentials.ValidateUsername(username); err != nil {
+		return "", err
+	}
+
+	return username, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func TestRotateAllUsers_RedisAdminPassword(t *testing.T) {
+	setupTestDB(t)
+
+	encrypt := func(password string) string {
+		hash, err := config.Encrypt(password)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		return hash
+	}
+
+	container := importedContainer("cache", "redis")
+	users := []*database.User{
+		{Username: "default", PasswordHash: encrypt("old-default"), IsDefault: true},
+		{Username: "app", PasswordHash: encrypt("old-app")},
+	}
+	if err := database.CreateContainerRecords(container, users, nil, nil); err != nil {
+		t.Fatalf("CreateContainerRecords() error = %v", err)
+	}
+
+	// Like rotateUserPassword, store the new password, recording what each rotation authenticated with
+	usedAdmin := make(map[string]string)
+	rotate := func(c *database.Container, user *database.User, adminPassword string) (string, error) {
+		usedAdmin[user.Username] = adminPassword
+		newPassword := "new-" + user.Username
+		user.PasswordHash = encrypt(newPassword)
+		return newPassword, database.UpdateUser(user)
+	}
+
+	rotated, failed := rotateAllUsers(container, users, "old-default", rotate)
+	if len(failed) != 0 || len(rotated) != 2 {
+		t.Fatalf("rotateAllUsers() = %v rotated, %v failed, want both rotated", rotated, failed)
+	}
+	if usedAdmin["default"] != "old-default" {
+		t.Errorf("default user rotated with admin password %q, want the old one", usedAdmin["default"])
+	}
+	// The default user's password is Redis's admin password, so the old one no longer works for app
+	if usedAdmin["app"] != "new-default" {
+		t.Errorf("app rotated with admin password %q, want the default user's new one", usedAdmin["app"])
+	}
+}
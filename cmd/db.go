@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbContainerName string
+)
+
+// databaseNamePattern restricts logical database names to plain identifiers,
+// since they are interpolated into CREATE/DROP DATABASE statements
+var databaseNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage databases inside a container",
+	Long:  `Create, list, or drop logical databases inside a running Postgres or MySQL container.`,
+}
+
+var dbCreateCmd = &cobra.Command{
+	Use:         "create [database]",
+	Short:       "Create a database in a container",
+	Long:        `Create a new logical database inside an existing container.`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: requiresDocker,
+	RunE:        runDBCreate,
+}
+
+var dbListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List databases in a container",
+	Long:        `List the databases that exist inside a container.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runDBList,
+}
+
+var dbDropCmd = &cobra.Command{
+	Use:         "drop [database]",
+	Short:       "Drop a database from a container",
+	Long:        `Drop a logical database that was created with 'mkdb db create'.`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: requiresDocker,
+	RunE:        runDBDrop,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbCreateCmd)
+	dbCmd.AddCommand(dbListCmd)
+	dbCmd.AddCommand(dbDropCmd)
+
+	// Add --name flag to db subcommands
+	dbCreateCmd.Flags().StringVar(&dbContainerName, "name", "", "Container name (skips interactive selection)")
+	dbListCmd.Flags().StringVar(&dbContainerName, "name", "", "Container name (skips interactive selection)")
+	dbDropCmd.Flags().StringVar(&dbContainerName, "name", "", "Container name (skips interactive selection)")
+}
+
+func runDBCreate(cmd *cobra.Command, args []string) error {
+	container, err := selectDatabaseContainer(dbContainerName)
+	if err != nil || container == nil {
+		return err
+	}
+
+	// Get database name from args or prompt
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		name, err = ui.PromptString("Enter database name", "")
+		if err != nil {
+			return fmt.Errorf("failed to get database name: %w", err)
+		}
+	}
+
+	if !databaseNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid database name %q: use letters, digits, and underscores, not starting with a digit", name)
+	}
+
+	ui.Info(fmt.Sprintf("Creating database '%s' in %s...", name, container.DisplayName))
+
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
+	}
+
+	if err := docker.CreateDatabase(container.ContainerID, container.Type, name, adminPassword); err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	logical := &database.LogicalDatabase{
+		ContainerID: container.ID,
+		Name:        name,
+		CreatedAt:   time.Now(),
+	}
+	if err := database.CreateLogicalDatabase(logical); err != nil {
+		return fmt.Errorf("failed to store database: %w", err)
+	}
+
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "database_created",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Database '%s' created", name),
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+
+	ui.Success(fmt.Sprintf("Database '%s' created successfully!", name))
+
+	// Display connection string for the new database using the default user
+	user, err := database.GetDefaultUser(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get default user: %w", err)
+	}
+
+	var password string
+	if user.PasswordHash != "" {
+		password, err = config.Decrypt(user.PasswordHash)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+
+	connStr := credentials.FormatConnectionString(
+		container.Type,
+		user.Username,
+		password,
+		credentials.ConnectionHost(),
+		container.Port,
+		name,
+	)
+
+	envKey, err := resolveEnvKey("")
+	if err != nil {
+		return err
+	}
+
+	ui.Box(credentials.FormatEnvVar(envKey, connStr))
+	return nil
+}
+
+func runDBList(cmd *cobra.Command, args []string) error {
+	container, err := selectDatabaseContainer(dbContainerName)
+	if err != nil || container == nil {
+		return err
+	}
+
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
+	}
+
+	names, err := docker.ListDatabases(container.ContainerID, container.Type, adminPassword)
+	if err != nil {
+		return fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	tracked, err := database.ListLogicalDatabases(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list tracked databases: %w", err)
+	}
+	isTracked := make(map[string]bool, len(tracked))
+	for _, d := range tracked {
+		isTracked[d.Name] = true
+	}
+
+	if len(names) == 0 {
+		ui.Warning(fmt.Sprintf("No databases found in %s", container.DisplayName))
+		return nil
+	}
+
+	fmt.Println()
+	ui.Header(fmt.Sprintf("Databases in %s", container.DisplayName))
+	for _, name := range names {
+		switch {
+		case name == container.DisplayName:
+			fmt.Printf("  %s (primary)\n", name)
+		case isTracked[name]:
+			fmt.Printf("  %s\n", name)
+		default:
+			fmt.Printf("  %s (not created by mkdb)\n", name)
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runDBDrop(cmd *cobra.Command, args []string) error {
+	container, err := selectDatabaseContainer(dbContainerName)
+	if err != nil || container == nil {
+		return err
+	}
+
+	tracked, err := database.ListLogicalDatabases(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	// Only databases created with 'mkdb db create' can be dropped, which
+	// keeps the container's primary database safe
+	var logical *database.LogicalDatabase
+	if len(args) > 0 {
+		for _, d := range tracked {
+			if d.Name == args[0] {
+				logical = d
+				break
+			}
+		}
+		if logical == nil {
+			return fmt.Errorf("database '%s' was not created with 'mkdb db create' in %s", args[0], container.DisplayName)
+		}
+	} else {
+		if len(tracked) == 0 {
+			ui.Warning("No databases created with 'mkdb db create' found")
+			return nil
+		}
+
+		logical, err = ui.SelectDatabase(tracked, "Select database to drop")
+		if err != nil {
+			return fmt.Errorf("failed to select database: %w", err)
+		}
+	}
+
+	confirmed, err := ui.PromptConfirm(fmt.Sprintf("Are you sure you want to drop database '%s'? All of its data will be lost", logical.Name))
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+
+	if !confirmed {
+		ui.Info("Drop cancelled")
+		return nil
+	}
+
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
+	}
+
+	if err := docker.DropDatabase(container.ContainerID, container.Type, logical.Name, adminPassword); err != nil {
+		return fmt.Errorf("failed to drop database: %w", err)
+	}
+
+	if err := database.DeleteLogicalDatabase(logical.ID); err != nil {
+		return fmt.Errorf("failed to delete database record: %w", err)
+	}
+
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "database_dropped",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Database '%s' dropped", logical.Name),
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+
+	config.Logger.Info("Database dropped", "container", container.DisplayName, "database", logical.Name)
+	ui.Success(fmt.Sprintf("Database '%s' dropped successfully!", logical.Name))
+	return nil
+}
+
+// selectDatabaseContainer looks up a running container that supports named databases by name,
+// or prompts for one if name is empty
+// Returns nil without an error if there are no such containers to choose from
+func selectDatabaseContainer(name string) (*database.Container, error) {
+	if name != "" {
+		container, err := database.GetContainerByDisplayName(name)
+		if err != nil {
+			return nil, fmt.Errorf("container '%s' not found", name)
+		}
+		if !docker.SupportsMultipleDatabases(container.Type) {
+			if container.Type == "redis" {
+				return nil, fmt.Errorf("container '%s' runs redis, which has numbered databases (0-15) instead of named ones, select one with the number at the end of the connection string", name)
+			}
+			return nil, fmt.Errorf("container '%s' runs %s, which doesn't support named databases", name, container.Type)
+		}
+		if container.Status != "running" {
+			return nil, fmt.Errorf("container '%s' is not running", name)
+		}
+		return container, nil
+	}
+
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var running []*database.Container
+	for _, c := range containers {
+		if c.Status == "running" && docker.SupportsMultipleDatabases(c.Type) {
+			running = append(running, c)
+		}
+	}
+
+	if len(running) == 0 {
+		ui.Warning("No running containers that support named databases found")
+		return nil, nil
+	}
+
+	container, err := ui.SelectContainer(running, "Select container")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select container: %w", err)
+	}
+	return container, nil
+}
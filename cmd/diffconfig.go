@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/diff"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var diffConfigContainerName string
+
+var diffConfigCmd = &cobra.Command{
+	Use:   "diff-config",
+	Short: "Show how a database's config file differs from the default",
+	Long: `Show the changes made to a database's config file, as a unified diff against the
+default config mkdb creates. Useful before 'mkdb restart' to review what will change.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runDiffConfig,
+}
+
+func init() {
+	rootCmd.AddCommand(diffConfigCmd)
+	diffConfigCmd.Flags().StringVar(&diffConfigContainerName, "name", "", "Container name (skips interactive selection)")
+}
+
+func runDiffConfig(cmd *cobra.Command, args []string) error {
+	container, err := selectInfoContainer(diffConfigContainerName, "Select container to compare")
+	if err != nil || container == nil {
+		return err
+	}
+
+	if container.NoConfig {
+		return fmt.Errorf("container '%s' was created with --no-config and uses the image's default configuration", container.DisplayName)
+	}
+
+	adapter, err := adapters.GetRegistry().Get(container.Type)
+	if err != nil {
+		return err
+	}
+
+	fileName := docker.GetConfigFileName(container.Type)
+	configFile := filepath.Join(docker.ConfigDir(container.DisplayName), fileName)
+	content, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("config file not found: %s", configFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	unified := diff.Unified(fileName+" (default)", configFile, adapter.GetDefaultConfig(), string(content), diff.DefaultContext)
+	if unified == "" {
+		ui.Info(fmt.Sprintf("%s matches the default config", configFile))
+		return nil
+	}
+	printDiff(unified)
+	return nil
+}
+
+// printDiff prints a unified diff, with added lines in green, removed lines in red and hunk headers in blue
+func printDiff(unified string) {
+	var (
+		added   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		removed = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		hunk    = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+		header  = lipgloss.NewStyle().Bold(true)
+	)
+
+	for _, line := range strings.Split(strings.TrimSuffix(unified, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			line = header.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunk.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = added.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removed.Render(line)
+		}
+		fmt.Println(line)
+	}
+}
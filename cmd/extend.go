@@ -2,32 +2,81 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/pbzona/mkdb/internal/cleanup"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	extendHours         int
-	extendContainerName string
+	extendHours          int
+	extendContainerName  string
+	extendAll            bool
+	extendExpiringWithin time.Duration
+	extendAt             string
+	extendNever          bool
 )
 
 var extendCmd = &cobra.Command{
 	Use:   "extend",
 	Short: "Extend the TTL of a container",
-	Long:  `Extend the time-to-live of a database container to prevent automatic cleanup.`,
-	RunE:  runExtend,
+	Long: `Extend the time-to-live of a database container to prevent automatic cleanup.
+
+Use --at to set an absolute expiration instead, or --never to pin the container so it
+never expires. Extending a pinned container or setting --at unpins it again.`,
+	RunE: runExtend,
 }
 
 func init() {
 	rootCmd.AddCommand(extendCmd)
 	extendCmd.Flags().IntVar(&extendHours, "hours", 1, "Number of hours to extend TTL")
 	extendCmd.Flags().StringVar(&extendContainerName, "name", "", "Container name (skips interactive selection)")
+	extendCmd.Flags().BoolVar(&extendAll, "all", false, "Extend every container")
+	extendCmd.Flags().DurationVar(&extendExpiringWithin, "expiring-within", 0, "Extend every container that expires within this long (e.g., 1h), including expired ones")
+	extendCmd.Flags().StringVar(&extendAt, "at", "", `Set the expiration to this time instead, e.g. "2026-01-02 15:00" (in the --tz time zone)`)
+	extendCmd.Flags().BoolVar(&extendNever, "never", false, "Pin the container so it never expires")
 }
 
 func runExtend(cmd *cobra.Command, args []string) error {
+	bulk := extendAll || cmd.Flags().Changed("expiring-within")
+	if bulk && extendContainerName != "" {
+		return fmt.Errorf("--name cannot be used with --all or --expiring-within")
+	}
+	if extendAll && cmd.Flags().Changed("expiring-within") {
+		return fmt.Errorf("--all and --expiring-within cannot be used together")
+	}
+	if extendExpiringWithin < 0 {
+		return fmt.Errorf("--expiring-within must not be negative")
+	}
+
+	var expiresAt time.Time
+	if extendAt != "" || extendNever {
+		if bulk {
+			return fmt.Errorf("--at and --never cannot be used with --all or --expiring-within")
+		}
+		if extendAt != "" && extendNever {
+			return fmt.Errorf("--at and --never cannot be used together")
+		}
+		if cmd.Flags().Changed("hours") {
+			return fmt.Errorf("--hours cannot be used with --at or --never")
+		}
+	}
+	if extendAt != "" {
+		var err error
+		expiresAt, err = parseExpiry(extendAt, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	if bulk {
+		return runExtendMany()
+	}
+
 	var container *database.Container
 	var err error
 
@@ -56,30 +105,116 @@ func runExtend(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Extend TTL - if container is already expired, extend from now instead of from old expiration time
-	if time.Now().After(container.ExpiresAt) {
-		ui.Info(fmt.Sprintf("Container is expired, extending from current time"))
-		container.ExpiresAt = time.Now().Add(time.Duration(extendHours) * time.Hour)
-	} else {
-		// Container is still valid, extend from current expiration
-		container.ExpiresAt = container.ExpiresAt.Add(time.Duration(extendHours) * time.Hour)
+	switch {
+	case extendNever:
+		if err := cleanup.Pin(container); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Container '%s' is pinned and won't expire", container.DisplayName))
+		ui.Info(fmt.Sprintf("Unpin it with 'mkdb extend --name %s --hours <n>' or --at", container.DisplayName))
+		return nil
+	case extendAt != "":
+		if err := cleanup.SetExpiry(container, expiresAt); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Container '%s' now expires at %s", container.DisplayName, ui.FormatTime(container.ExpiresAt)))
+		return nil
 	}
 
-	if err := database.UpdateContainer(container); err != nil {
-		return fmt.Errorf("failed to update container: %w", err)
+	if container.Pinned {
+		ui.Info("Container is pinned, unpinning it and extending from current time")
+	} else if time.Now().After(container.ExpiresAt) {
+		ui.Info("Container is expired, extending from current time")
 	}
-
-	// Log event
-	event := &database.Event{
-		ContainerID: container.ID,
-		EventType:   "ttl_extended",
-		Timestamp:   time.Now(),
-		Details:     fmt.Sprintf("TTL extended by %d hours", extendHours),
+	if err := cleanup.ExtendContainer(container, extendHours); err != nil {
+		return err
 	}
-	database.CreateEvent(event)
 
 	ui.Success(fmt.Sprintf("Container '%s' TTL extended by %d hours!", container.DisplayName, extendHours))
-	ui.Info(fmt.Sprintf("New expiration: %s", container.ExpiresAt.Format("2006-01-02 15:04:05")))
+	ui.Info(fmt.Sprintf("New expiration: %s", ui.FormatTime(container.ExpiresAt)))
 
 	return nil
 }
+
+// expiryLayouts are the formats accepted by --at, read in the display time zone unless they include an offset
+var expiryLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseExpiry parses an --at value, which must be after now
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		for _, layout := range expiryLayouts {
+			if t, err = time.ParseInLocation(layout, value, ui.Location()); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf(`invalid --at %q, use a time like "2026-01-02 15:00"`, value)
+	}
+
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("--at %s is in the past", ui.FormatTime(t))
+	}
+	return t, nil
+}
+
+// runExtendMany extends every container selected by --all or --expiring-within
+func runExtendMany() error {
+	containers, err := database.ListContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// Already expired containers are always within the window
+	if !extendAll {
+		containers = expiringWithin(containers, time.Now().Add(extendExpiringWithin))
+	}
+
+	// Pinned containers don't expire, extending them would unpin them
+	containers = slices.DeleteFunc(containers, func(c *database.Container) bool { return c.Pinned })
+
+	if len(containers) == 0 {
+		if extendAll {
+			ui.Warning("No containers found")
+		} else {
+			ui.Info(fmt.Sprintf("No containers expire within %s", extendExpiringWithin))
+		}
+		return nil
+	}
+
+	extended := 0
+	for _, c := range containers {
+		if err := cleanup.ExtendContainer(c, extendHours); err != nil {
+			ui.Error(fmt.Sprintf("Failed to extend '%s': %v", c.DisplayName, err))
+			continue
+		}
+		fmt.Printf("  %s (%s) now expires %s\n", c.DisplayName, c.Type, ui.FormatTime(c.ExpiresAt))
+		extended++
+	}
+
+	ui.Success(fmt.Sprintf("Extended %d of %d container(s) by %d hours", extended, len(containers), extendHours))
+	if extended < len(containers) {
+		return fmt.Errorf("failed to extend %d container(s)", len(containers)-extended)
+	}
+	return nil
+}
+
+// expiringWithin returns the containers that expire before the deadline
+func expiringWithin(containers []*database.Container, deadline time.Time) []*database.Container {
+	var selected []*database.Container
+	for _, c := range containers {
+		if c.ExpiresAt.Before(deadline) {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importTTLHours int
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import existing mkdb-managed Docker containers",
+	Long: `Find Docker containers carrying the mkdb labels and start tracking them again.
+Useful if the mkdb database was deleted while its containers kept running.
+Containers that are already tracked are skipped.`,
+	Annotations: requiresDocker,
+	RunE:        runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().IntVar(&importTTLHours, "ttl", 0, "Time to live in hours for imported containers (prompts if not set)")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	managed, err := docker.ListManagedContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list Docker containers: %w", err)
+	}
+
+	// Skip containers that are already tracked
+	var untracked []docker.ContainerSummary
+	for _, s := range managed {
+		if _, err := database.GetContainerByDisplayName(s.Name); err == nil {
+			config.Logger.Debug("Skipping tracked container", "name", s.Name)
+			continue
+		}
+		untracked = append(untracked, s)
+	}
+
+	if len(untracked) == 0 {
+		ui.Info(fmt.Sprintf("No untracked containers found (%d already tracked)", len(managed)))
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Found %d untracked container(s)", len(untracked)))
+
+	ttl := importTTLHours
+	if ttl <= 0 {
+		ttl, err = promptImportTTL()
+		if err != nil {
+			return err
+		}
+	}
+
+	imported := 0
+	for _, s := range untracked {
+		if _, err := importManagedContainer(s, ttl); err != nil {
+			ui.Error(fmt.Sprintf("Failed to import %s: %v", s.Name, err))
+			continue
+		}
+		ui.Success(fmt.Sprintf("Imported %s (%s) on port %s", s.Name, s.Type, s.Port))
+		imported++
+	}
+
+	ui.Newline()
+	ui.Success(fmt.Sprintf("Imported %d of %d container(s)", imported, len(untracked)))
+	return nil
+}
+
+// promptImportTTL asks how long imported containers should live, since Docker doesn't store a TTL
+func promptImportTTL() (int, error) {
+	value, err := ui.PromptString("TTL in hours for imported containers", strconv.Itoa(defaultTTLHours))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get TTL: %w", err)
+	}
+
+	ttl, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("TTL must be a positive number of hours")
+	}
+
+	return ttl, nil
+}
+
+// importManagedContainer inserts a record for a labeled Docker container that mkdb isn't tracking
+func importManagedContainer(s docker.ContainerSummary, ttlHours int) (*database.Container, error) {
+	dbType, err := types.NormalizeDBType(s.Type)
+	if err != nil {
+		return nil, err
+	}
+	if s.Port == "" {
+		return nil, fmt.Errorf("container has no published port")
+	}
+
+	container := &database.Container{
+		Name:        "mkdb-" + s.Name,
+		DisplayName: s.Name,
+		Type:        dbType,
+		Version:     imageVersion(s.Image),
+		ContainerID: s.ID,
+		Port:        s.Port,
+		Status:      summaryStatus(s),
+		CreatedAt:   s.Created,
+		ExpiresAt:   time.Now().Add(time.Duration(ttlHours) * time.Hour),
+		VolumeType:  s.VolumeType,
+		VolumePath:  s.VolumePath,
+	}
+
+	if err := trackContainer(container, recoverCredentials(container), "imported", fmt.Sprintf("Imported existing Docker container %s", s.ID[:12])); err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+// imageVersion takes the version from an image's tag (e.g., "postgres:16" -> "16")
+func imageVersion(image string) string {
+	if idx := strings.LastIndex(image, ":"); idx != -1 && idx > strings.LastIndex(image, "/") {
+		return image[idx+1:]
+	}
+	return "latest"
+}
+
+// summaryStatus maps a Docker container's state to the status mkdb records
+func summaryStatus(s docker.ContainerSummary) string {
+	if s.State == types.StatusRunning {
+		return types.StatusRunning
+	}
+	return types.StatusStopped
+}
+
+// recoverCredentials reads a container's credentials from its Docker configuration
+// A container that can't be inspected is tracked without credentials
+func recoverCredentials(container *database.Container) docker.ContainerCredentials {
+	creds, err := docker.GetContainerCredentials(container.ContainerID, container.Type)
+	if err != nil {
+		config.Logger.Warn("Failed to recover credentials", "name", container.DisplayName, "error", err)
+	}
+	return creds
+}
+
+// trackContainer inserts the record for an existing Docker container, along with its default user
+// creds are the credentials recovered from the container's environment, the admin password is
+// stored on the record like for containers mkdb creates
+func trackContainer(container *database.Container, creds docker.ContainerCredentials, eventType, details string) error {
+	now := time.Now()
+	username, password := creds.Username, creds.Password
+	if password != "" && username == "" {
+		// Password-only databases are stored under the user their password is set for, like a new container's
+		username = passwordUsername(container.Type, credentials.DefaultUsername)
+	}
+
+	var passwordHash string
+	var err error
+	if password != "" {
+		passwordHash, err = config.Encrypt(password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt password: %w", err)
+		}
+	} else {
+		username = ""
+	}
+
+	if creds.AdminPassword != "" {
+		container.AdminPasswordHash, err = config.Encrypt(creds.AdminPassword)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt admin password: %w", err)
+		}
+	}
+
+	// Written in one transaction, so a failure doesn't leave a record without its user
+	user := &database.User{
+		Username:     username,
+		PasswordHash: passwordHash,
+		IsDefault:    true,
+		CreatedAt:    now,
+	}
+	event := &database.Event{
+		EventType: eventType,
+		Timestamp: now,
+		Details:   details,
+	}
+	return database.CreateContainerRecords(container, []*database.User{user}, nil, []*database.Event{event})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+)
+
+// setupTestDB initializes config and an empty database in a fresh data directory
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	database.Close()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() error = %v", err)
+	}
+	if err := database.Initialize(); err != nil {
+		t.Fatalf("database.Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+}
+
+// importedContainer returns the record importManagedContainer builds for a labeled container
+func importedContainer(name, dbType string) *database.Container {
+	return &database.Container{
+		Name:        "mkdb-" + name,
+		DisplayName: name,
+		Type:        dbType,
+		Version:     "8.0",
+		ContainerID: "0123456789abcdef",
+		Port:        "3306",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestTrackContainer_MySQLAdminPassword(t *testing.T) {
+	setupTestDB(t)
+
+	container := importedContainer("orders", "mysql")
+	creds := docker.ContainerCredentials{Username: "dbuser", Password: "user-secret", AdminPassword: "root-secret"}
+	if err := trackContainer(container, creds, "imported", "test"); err != nil {
+		t.Fatalf("trackContainer() error = %v", err)
+	}
+
+	stored, err := database.GetContainerByDisplayName("orders")
+	if err != nil {
+		t.Fatalf("GetContainerByDisplayName() error = %v", err)
+	}
+	admin, err := credentials.AdminPassword(stored)
+	if err != nil {
+		t.Fatalf("AdminPassword() error = %v", err)
+	}
+	if admin != "root-secret" {
+		t.Errorf("AdminPassword() = %q, want the MYSQL_ROOT_PASSWORD value", admin)
+	}
+}
+
+func TestTrackContainer_BuiltInUser(t *testing.T) {
+	setupTestDB(t)
+
+	// ELASTIC_PASSWORD and --requirepass set the password of a built-in user, not of "dbuser"
+	tests := []struct {
+		name   string
+		dbType string
+		want   string
+	}{
+		{"search", "elasticsearch", "elastic"},
+		{"cache", "redis", "default"},
+	}
+	for _, tt := range tests {
+		container := importedContainer(tt.name, tt.dbType)
+		if err := trackContainer(container, docker.ContainerCredentials{Password: "secret"}, "imported", "test"); err != nil {
+			t.Fatalf("trackContainer(%s) error = %v", tt.dbType, err)
+		}
+
+		user, err := database.GetDefaultUser(container.ID)
+		if err != nil {
+			t.Fatalf("GetDefaultUser(%s) error = %v", tt.dbType, err)
+		}
+		if user.Username != tt.want {
+			t.Errorf("%s default user = %q, want %q", tt.dbType, user.Username, tt.want)
+		}
+	}
+}
@@ -2,70 +2,242 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
 	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/volumes"
 	"github.com/spf13/cobra"
 )
 
 var (
 	infoContainerName string
+	infoOutput        string
 )
 
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Display container information",
-	Long:  `Display detailed information about a database container including status, version, port, and TTL.`,
-	RunE:  runInfo,
+	Long: `Display detailed information about a database container including status, version, port, and TTL.
+
+Use --output json or --output yaml to print the information for scripts.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runInfo,
 }
 
 func init() {
 	rootCmd.AddCommand(infoCmd)
 	infoCmd.Flags().StringVar(&infoContainerName, "name", "", "Container name (skips interactive selection)")
+	infoCmd.Flags().StringVarP(&infoOutput, "output", "o", ui.OutputText, "Output format: text, json, or yaml")
 }
 
-func runInfo(cmd *cobra.Command, args []string) error {
-	var container *database.Container
-	var err error
+// containerReport is what 'mkdb info' and 'mkdb stat' show about a container, in the shape printed by --output
+type containerReport struct {
+	Name          string                     `json:"name" yaml:"name"`
+	Type          string                     `json:"type" yaml:"type"`
+	Version       string                     `json:"version" yaml:"version"`
+	ServerVersion string                     `json:"server_version,omitempty" yaml:"server_version,omitempty"`
+	ActualVersion string                     `json:"actual_version,omitempty" yaml:"actual_version,omitempty"`
+	Status        string                     `json:"status" yaml:"status"`
+	ContainerID   string                     `json:"container_id" yaml:"container_id"`
+	Port          string                     `json:"port" yaml:"port"`
+	BindIP        string                     `json:"bind_ip,omitempty" yaml:"bind_ip,omitempty"`
+	Network       string                     `json:"network,omitempty" yaml:"network,omitempty"`
+	VolumeType    string                     `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+	VolumePath    string                     `json:"volume_path,omitempty" yaml:"volume_path,omitempty"`
+	VolumeDriver  string                     `json:"volume_driver,omitempty" yaml:"volume_driver,omitempty"`
+	Labels        []string                   `json:"labels,omitempty" yaml:"labels,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at" yaml:"created_at"`
+	ExpiresAt     time.Time                  `json:"expires_at" yaml:"expires_at"`
+	TTLRemaining  string                     `json:"ttl_remaining" yaml:"ttl_remaining"`
+	TTLSeconds    int64                      `json:"ttl_seconds" yaml:"ttl_seconds"`
+	Pinned        bool                       `json:"pinned" yaml:"pinned"`
+	Uptime        string                     `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+	UptimeSeconds int64                      `json:"uptime_seconds,omitempty" yaml:"uptime_seconds,omitempty"`
+	StoppedAt     *time.Time                 `json:"stopped_at,omitempty" yaml:"stopped_at,omitempty"`
+	Resources     *docker.ContainerResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Stats         *docker.ContainerStats     `json:"stats,omitempty" yaml:"stats,omitempty"`
 
-	// If name is provided, look it up directly
-	if infoContainerName != "" {
-		container, err = database.GetContainerByDisplayName(infoContainerName)
-		if err != nil {
-			return fmt.Errorf("container '%s' not found", infoContainerName)
+	container *database.Container
+	statsErr  error // why Stats is missing for a running container
+}
+
+// buildContainerReport gathers a container's record along with what Docker reports about it
+// The actual version, uptime and stats are only looked up for running containers, and resources for
+// ones that still exist. When a stopped container's Docker container was removed, when it stopped
+// comes from the stop event instead.
+// Failing to sample stats isn't an error, the report is returned without them
+func buildContainerReport(container *database.Container, withResources bool) (*containerReport, error) {
+	ttl := time.Until(container.ExpiresAt)
+	report := &containerReport{
+		Name:          container.DisplayName,
+		Type:          container.Type,
+		Version:       container.Version,
+		ServerVersion: container.ServerVersion,
+		Status:        container.Status,
+		ContainerID:   container.ContainerID,
+		Port:          container.Port,
+		BindIP:        container.BindIP,
+		Network:       container.Network,
+		VolumeType:    container.VolumeType,
+		VolumePath:    container.VolumePath,
+		VolumeDriver:  container.VolumeDriver,
+		Labels:        container.Labels,
+		CreatedAt:     container.CreatedAt.In(ui.Location()),
+		ExpiresAt:     container.ExpiresAt.In(ui.Location()),
+		TTLRemaining:  ui.FormatDuration(ttl),
+		TTLSeconds:    int64(ttl.Seconds()),
+		Pinned:        container.Pinned,
+		container:     container,
+	}
+	if container.Pinned {
+		// Pinned containers have a placeholder expiration, there's no meaningful TTL
+		report.TTLRemaining = "never"
+		report.TTLSeconds = 0
+	}
+
+	running := container.Status == "running" && container.ContainerID != ""
+
+	// Try to get the actual version from the running container
+	if running {
+		actualVersion, err := docker.GetActualVersion(container.ContainerID, container.Type)
+		if err == nil && actualVersion != "" {
+			report.ActualVersion = actualVersion
 		}
-	} else {
-		// Get all containers
-		containers, err := database.ListContainers()
+		// If error, just use the stored version (tag like "latest")
+	}
+
+	// Containers that no longer exist in Docker have no resources to show
+	if withResources && container.ContainerID != "" && docker.ContainerExists(container.ContainerID) {
+		resources, err := docker.GetContainerResources(container.ContainerID)
 		if err != nil {
-			return fmt.Errorf("failed to list containers: %w", err)
+			return nil, err
 		}
+		report.Resources = resources
 
-		if len(containers) == 0 {
-			ui.Warning("No containers found")
-			return nil
+		// The record's status may be stale, so neither is an error
+		if running {
+			if uptime, err := docker.GetContainerUptime(container.ContainerID); err == nil {
+				report.Uptime = ui.FormatDuration(uptime)
+				report.UptimeSeconds = int64(uptime.Seconds())
+			}
+		} else if stoppedAt, err := docker.GetContainerStoppedAt(container.ContainerID); err == nil {
+			stoppedAt = stoppedAt.In(ui.Location())
+			report.StoppedAt = &stoppedAt
 		}
+	}
 
-		// Select container
-		container, err = ui.SelectContainer(containers, "Select container to view")
-		if err != nil {
-			return fmt.Errorf("failed to select container: %w", err)
+	// 'mkdb stop' removes the Docker container unless --keep is given, so only mkdb knows when it stopped
+	if withResources && container.Status == "stopped" && report.StoppedAt == nil {
+		if event, err := database.GetLatestEvent(container.ID, "stopped"); err == nil {
+			stoppedAt := event.Timestamp.In(ui.Location())
+			report.StoppedAt = &stoppedAt
 		}
 	}
 
-	// Try to get the actual version from the running container
-	if container.Status == "running" && container.ContainerID != "" {
-		actualVersion, err := docker.GetActualVersion(container.ContainerID, container.Type)
-		if err == nil && actualVersion != "" {
-			// Update the container version with the actual version
-			container.Version = actualVersion
+	if running {
+		report.Stats, report.statsErr = docker.GetContainerStats(container.ContainerID)
+		if report.statsErr != nil {
+			config.Logger.Warn("Failed to get container stats", "name", container.DisplayName, "error", report.statsErr)
 		}
-		// If error, just use the stored version (tag like "latest")
 	}
 
-	// Print container info
-	ui.PrintContainerInfo(container)
+	return report, nil
+}
+
+// displayContainer returns the container record as shown in text output, with the actual version if it's known
+func (r *containerReport) displayContainer() *database.Container {
+	c := *r.container
+	if r.ActualVersion != "" {
+		c.ServerVersion = r.ActualVersion
+	}
+	return &c
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	if err := ui.ValidateOutputFormat(infoOutput); err != nil {
+		return err
+	}
+
+	container, err := selectInfoContainer(infoContainerName, "Select container to view")
+	if err != nil || container == nil {
+		return err
+	}
+
+	report, err := buildContainerReport(container, true)
+	if err != nil {
+		return err
+	}
 
+	if infoOutput != ui.OutputText {
+		return ui.PrintStructured(infoOutput, report)
+	}
+
+	if report.Resources == nil && report.StoppedAt == nil {
+		ui.PrintContainerInfo(report.displayContainer())
+		return nil
+	}
+	var info ui.ResourceInfo
+	if report.Resources != nil {
+		info = formatResourceInfo(report.Resources, report.Stats)
+	}
+	info.Uptime = report.Uptime
+	if report.StoppedAt != nil {
+		info.StoppedSince = fmt.Sprintf("%s (%s ago)", ui.FormatTime(*report.StoppedAt), ui.FormatDuration(time.Since(*report.StoppedAt)))
+	}
+	ui.PrintContainerInfoDetailed(report.displayContainer(), info)
 	return nil
 }
+
+// selectInfoContainer looks up a container by name, or prompts for one if name is empty
+// Returns nil without an error if there are no containers to choose from
+func selectInfoContainer(name, label string) (*database.Container, error) {
+	if name != "" {
+		container, err := database.GetContainerByDisplayName(name)
+		if err != nil {
+			return nil, fmt.Errorf("container '%s' not found", name)
+		}
+		return container, nil
+	}
+
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	if len(containers) == 0 {
+		ui.Warning("No containers found")
+		return nil, nil
+	}
+
+	container, err := ui.SelectContainer(containers, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select container: %w", err)
+	}
+	return container, nil
+}
+
+// formatResourceInfo describes a container's limits, and its usage if stats is not nil
+func formatResourceInfo(resources *docker.ContainerResources, stats *docker.ContainerStats) ui.ResourceInfo {
+	info := ui.ResourceInfo{
+		CPULimit:    "unlimited",
+		MemoryLimit: "unlimited",
+	}
+	if resources.NanoCPUs > 0 {
+		info.CPULimit = fmt.Sprintf("%.2f CPUs", float64(resources.NanoCPUs)/1e9)
+	}
+	if resources.CPUShares > 0 {
+		info.CPULimit += fmt.Sprintf(" (%d shares)", resources.CPUShares)
+	}
+	if resources.Memory > 0 {
+		info.MemoryLimit = volumes.FormatSize(resources.Memory)
+	}
+
+	if stats != nil {
+		info.CPUUsage = fmt.Sprintf("%.1f%%", stats.CPUPercent)
+		info.MemoryUsage = volumes.FormatSize(int64(stats.MemoryUsage))
+	}
+	return info
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/filter"
 	"github.com/pbzona/mkdb/internal/types"
 	"github.com/pbzona/mkdb/internal/ui"
 	"github.com/pbzona/mkdb/internal/volumes"
@@ -14,27 +18,235 @@ import (
 )
 
 var (
-	filterType   string
-	filterStatus string
-	showAll      bool
+	filterType    string
+	filterStatus  string
+	filterExpr    string
+	showAll       bool
+	showDatabases bool
+	filterSince   string
+	filterUntil   string
+	listFieldSpec string
+	listJSON      bool
+	listQuiet     bool
+	tableStyle    string
+	listWatch     bool
+	listInterval  time.Duration
 )
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all database containers",
-	Long:    `List all database containers with optional filtering by type and status.`,
-	RunE:    runList,
+	Long: `List all database containers with optional filtering by type and status.
+
+Use --filter for more complex queries, combining comma-separated conditions:
+  mkdb list --filter 'type=postgres,status=running,expires<2h'
+
+Supported fields are type, status, name, port, and version (with = and !=),
+and expires (time remaining, with < and >).
+
+Use --since and --until to filter on creation time, given as a duration before now
+(24h, 7d) or a date (2006-01-02, or RFC3339 for a specific time):
+  mkdb list --since 7d --until 24h
+
+Use --fields to choose the columns to print, in order, and --json to print an array of
+objects keyed by field name for scripts. Without --fields, JSON output includes every field:
+  mkdb list --fields name,port --status running
+  mkdb list --json | jq -r '.[].name'
+
+Fields are name, type, version, status, port, ttl, created, expires, volume, and network.
+Field names are part of the JSON output and won't be renamed or removed.
+
+Use --quiet to print only names, one per line, e.g. to pass them to another command:
+  mkdb list -q --status running | xargs -n1 mkdb stop --name
+
+Use --watch to redraw the list every --interval until Ctrl-C, e.g. to watch TTLs count down.
+Leftover volumes are only scanned on each refresh with --all.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runList,
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().StringVar(&filterType, "type", "", "Filter by database type (postgres, mysql, redis)")
+	listCmd.Flags().StringVar(&filterType, "type", "", "Filter by database type ("+dbTypeList()+")")
 	listCmd.Flags().StringVar(&filterStatus, "status", "", "Filter by status (running, stopped, expired, removed)")
+	listCmd.Flags().StringVar(&filterExpr, "filter", "", "Filter expression (e.g. 'type=postgres,status=running,expires<2h')")
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "Show all databases including removed ones")
+	listCmd.Flags().StringVar(&filterSince, "since", "", "Only show containers created at or after this time (e.g. 24h, 7d, 2006-01-02)")
+	listCmd.Flags().StringVar(&filterUntil, "until", "", "Only show containers created before this time (e.g. 24h, 7d, 2006-01-02)")
+	listCmd.Flags().BoolVar(&showDatabases, "databases", false, "Show databases created with 'mkdb db create' under each container")
+	listCmd.Flags().StringVar(&listFieldSpec, "fields", "", "Comma-separated fields to print (e.g. name,type,port)")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print containers as a JSON array")
+	listCmd.Flags().BoolVarP(&listQuiet, "quiet", "q", false, "Print only container names, one per line")
+	listCmd.Flags().StringVar(&tableStyle, "table-style", tableStylePlain, "Table style: plain, rounded, or markdown")
+	listCmd.Flags().BoolVarP(&listWatch, "watch", "w", false, "Continuously refresh the list until interrupted")
+	listCmd.Flags().DurationVar(&listInterval, "interval", 2*time.Second, "Refresh interval for --watch")
+}
+
+// listField is a column that can be selected with --fields
+type listField struct {
+	name   string
+	header string
+	value  func(c *database.Container) string
+}
+
+// listFields are the selectable fields, in the order they're printed when all are shown
+// Their names are JSON keys in 'mkdb list --json', so existing names must not change
+var listFields = []listField{
+	{"name", "NAME", func(c *database.Container) string { return c.DisplayName }},
+	{"type", "TYPE", func(c *database.Container) string { return c.Type }},
+	{"version", "VERSION", func(c *database.Container) string { return c.DisplayVersion() }},
+	{"status", "STATUS", containerDisplayStatus},
+	{"port", "PORT", func(c *database.Container) string { return c.Port }},
+	{"ttl", "TTL REMAINING", formatTTL},
+	{"created", "CREATED", func(c *database.Container) string { return formatListTime(c.CreatedAt) }},
+	{"expires", "EXPIRES", formatListExpiry},
+	{"volume", "VOLUME", func(c *database.Container) string { return c.VolumePath }},
+	{"network", "NETWORK", func(c *database.Container) string { return c.Network }},
+}
+
+// listJSONValues replace a field's value in 'mkdb list --json', so times stay machine-readable
+var listJSONValues = map[string]func(c *database.Container) string{
+	"created": func(c *database.Container) string { return formatJSONTime(c.CreatedAt) },
+	"expires": func(c *database.Container) string { return formatJSONTime(c.ExpiresAt) },
+}
+
+// parseListFields returns the fields named in a comma-separated list, or all fields if spec is empty
+func parseListFields(spec string) ([]listField, error) {
+	if strings.TrimSpace(spec) == "" {
+		return listFields, nil
+	}
+
+	var fields []listField
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		field, ok := lookupListField(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (valid fields: %s)", name, listFieldNames())
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields given (valid fields: %s)", listFieldNames())
+	}
+	return fields, nil
+}
+
+func lookupListField(name string) (listField, bool) {
+	for _, f := range listFields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return listField{}, false
+}
+
+func listFieldNames() string {
+	names := make([]string, len(listFields))
+	for i, f := range listFields {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatListTime formats a timestamp for list output, leaving unset times empty
+func formatListTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return ui.FormatTime(t)
+}
+
+// formatListExpiry formats a container's expiration for list output, pinned containers never expire
+func formatListExpiry(c *database.Container) string {
+	if c.Pinned {
+		return "never"
+	}
+	return formatListTime(c.ExpiresAt)
+}
+
+// formatJSONTime formats a timestamp as RFC 3339 for JSON output, leaving unset times empty
+func formatJSONTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(ui.Location()).Format(time.RFC3339)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	// Parse the field list and filter expression before doing any work
+	fields, err := parseListFields(listFieldSpec)
+	if err != nil {
+		return fmt.Errorf("invalid --fields: %w", err)
+	}
+	if !slices.Contains(tableStyles, tableStyle) {
+		return fmt.Errorf("invalid --table-style %q (valid styles: %s)", tableStyle, strings.Join(tableStyles, ", "))
+	}
+	if listWatch && listJSON {
+		return fmt.Errorf("--watch cannot be used with --json")
+	}
+	if listInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if listQuiet && (listJSON || listWatch || listFieldSpec != "" || showDatabases) {
+		return fmt.Errorf("--quiet cannot be used with --json, --fields, --watch or --databases")
+	}
+
+	query := listQuery{fields: fields}
+	if filterExpr != "" {
+		query.predicate, err = filter.Parse(filterExpr)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+	}
+	if filterSince != "" {
+		query.since, err = filter.ParseTime(filterSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if filterUntil != "" {
+		query.until, err = filter.ParseTime(filterUntil)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	if listWatch {
+		// Scanning volumes on every refresh is slow, so only do it when asked for everything
+		query.scanOrphaned = showAll
+		return watchList(query)
+	}
+	query.scanOrphaned = showAll || filterStatus == "removed" || filter.HasStatus(filterExpr, "removed")
+	return renderList(query)
+}
+
+// listQuery is what 'mkdb list' shows, parsed from its flags
+type listQuery struct {
+	fields       []listField
+	predicate    filter.Predicate
+	since        time.Time
+	until        time.Time
+	scanOrphaned bool // Include leftover volumes as removed containers
+}
+
+// watchList redraws the list every interval until interrupted
+func watchList(query listQuery) error {
+	sample := func() (listQuery, error) { return query, nil }
+	return watchLoop(listInterval, sample, func(query listQuery) error {
+		if err := renderList(query); err != nil {
+			return err
+		}
+		fmt.Println()
+		return nil
+	})
+}
+
+// renderList prints the containers matching query
+func renderList(query listQuery) error {
 	// Get all containers
 	containers, err := database.ListContainers()
 	if err != nil {
@@ -42,7 +254,7 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check for orphaned volumes and add them as "removed" containers
-	if showAll || filterStatus == "removed" {
+	if query.scanOrphaned {
 		orphaned, err := volumes.ScanOrphaned()
 		if err != nil {
 			return fmt.Errorf("failed to scan volumes: %w", err)
@@ -63,6 +275,7 @@ func runList(cmd *cobra.Command, args []string) error {
 			if vol.Container != nil {
 				removedContainer.Type = vol.Container.Type
 				removedContainer.Version = vol.Container.Version
+				removedContainer.ServerVersion = vol.Container.ServerVersion
 				removedContainer.CreatedAt = vol.Container.CreatedAt
 				removedContainer.ExpiresAt = vol.Container.ExpiresAt
 				removedContainer.Port = vol.Container.Port
@@ -73,27 +286,89 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(containers) == 0 {
+		if listJSON {
+			return printContainerJSON(nil, query.fields, nil)
+		}
+		if listQuiet {
+			return nil
+		}
 		ui.Warning("No containers found")
 		return nil
 	}
 
 	// Apply filters
-	filtered := filterContainers(containers, filterType, filterStatus)
+	filtered := filterContainers(containers, filterType, filterStatus, query.since, query.until)
+	if query.predicate != nil {
+		var matched []*database.Container
+		for _, c := range filtered {
+			if query.predicate(c) {
+				matched = append(matched, c)
+			}
+		}
+		filtered = matched
+	}
 
+	if len(filtered) == 0 && listJSON {
+		return printContainerJSON(nil, query.fields, nil)
+	}
+	if len(filtered) == 0 && listQuiet {
+		return nil
+	}
 	if len(filtered) == 0 {
-		ui.Warning(fmt.Sprintf("No containers found matching filters (type=%s, status=%s)",
-			valueOrAny(filterType), valueOrAny(filterStatus)))
+		filters := fmt.Sprintf("type=%s, status=%s", valueOrAny(filterType), valueOrAny(filterStatus))
+		if filterSince != "" {
+			filters += ", since=" + filterSince
+		}
+		if filterUntil != "" {
+			filters += ", until=" + filterUntil
+		}
+		if filterExpr != "" {
+			filters += ", filter=" + filterExpr
+		}
+		ui.Warning(fmt.Sprintf("No containers found matching filters (%s)", filters))
 		return nil
 	}
 
+	// Look up logical databases to nest under their containers
+	var logical map[int][]*database.LogicalDatabase
+	if showDatabases {
+		logical = make(map[int][]*database.LogicalDatabase)
+		for _, c := range filtered {
+			if c.ID == 0 {
+				continue // Removed containers have no record
+			}
+			dbs, err := database.ListLogicalDatabases(c.ID)
+			if err != nil {
+				return fmt.Errorf("failed to list databases: %w", err)
+			}
+			logical[c.ID] = dbs
+		}
+	}
+
 	// Display results
-	displayContainerList(filtered)
+	switch {
+	case listJSON:
+		return printContainerJSON(filtered, query.fields, logical)
+	case listQuiet:
+		printContainerNames(filtered)
+		return nil
+	case listFieldSpec != "":
+		return displayContainerFields(filtered, query.fields, logical, tableStyle)
+	default:
+		return displayContainerList(filtered, logical, tableStyle)
+	}
+}
 
-	return nil
+// printContainerNames prints one display name per line, with nothing else, for piping into other commands
+func printContainerNames(containers []*database.Container) {
+	for _, c := range containers {
+		fmt.Println(c.DisplayName)
+	}
 }
 
-func filterContainers(containers []*database.Container, typeFilter, statusFilter string) []*database.Container {
+func filterContainers(containers []*database.Container, typeFilter, statusFilter string, since, until time.Time) []*database.Container {
 	var filtered []*database.Container
+	created := filter.CreatedBetween(since, until)
 
 	for _, c := range containers {
 		// Filter by type
@@ -113,6 +388,11 @@ func filterContainers(containers []*database.Container, typeFilter, statusFilter
 			}
 		}
 
+		// Filter by creation time
+		if !created(c) {
+			continue
+		}
+
 		filtered = append(filtered, c)
 	}
 
@@ -156,110 +436,182 @@ func normalizeStatus(c *database.Container, statusFilter string) bool {
 	}
 }
 
-func displayContainerList(containers []*database.Container) {
-	// Define styles
-	headerStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("12"))
+// Table styles for --table-style
+const (
+	tableStylePlain    = "plain"
+	tableStyleRounded  = "rounded"
+	tableStyleMarkdown = "markdown"
+)
 
-	statusRunningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true) // Green
-	statusStoppedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true) // Yellow
-	statusExpiredStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)  // Red
-	statusRemovedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Bold(true)  // Gray
+var tableStyles = []string{tableStylePlain, tableStyleRounded, tableStyleMarkdown}
+
+// newListTable returns an empty table drawn in one of tableStyles
+// Column widths are measured by lipgloss, which ignores ANSI codes and counts wide characters
+// (such as CJK names) as two columns. Headers are highlighted when colored is set
+func newListTable(style string, colored bool) (*table.Table, error) {
+	t := table.New()
+	cell := lipgloss.NewStyle().Padding(0, 1)
+
+	switch style {
+	case tableStylePlain:
+		// Columns separated by spaces with a line under the header, for reading in a terminal
+		t.Border(lipgloss.NormalBorder()).
+			BorderTop(false).BorderBottom(false).BorderLeft(false).BorderRight(false).
+			BorderColumn(false)
+		cell = lipgloss.NewStyle().PaddingRight(2)
+	case tableStyleRounded:
+		t.Border(lipgloss.RoundedBorder())
+	case tableStyleMarkdown:
+		t.Border(lipgloss.MarkdownBorder()).BorderTop(false).BorderBottom(false)
+	default:
+		return nil, fmt.Errorf("invalid table style %q (valid styles: %s)", style, strings.Join(tableStyles, ", "))
+	}
 
-	// Calculate column widths
-	nameWidth := max(len("NAME"), maxLen(containers, func(c *database.Container) string { return c.DisplayName }))
-	typeWidth := max(len("TYPE"), maxLen(containers, func(c *database.Container) string { return c.Type }))
-	portWidth := max(len("PORT"), maxLen(containers, func(c *database.Container) string { return c.Port }))
+	header := cell
+	if colored {
+		header = header.Bold(true).Foreground(lipgloss.Color("12"))
+	}
+	t.StyleFunc(func(row, col int) lipgloss.Style {
+		if row == table.HeaderRow {
+			return header
+		}
+		return cell
+	})
+	return t, nil
+}
 
-	// Print header
-	fmt.Println()
-	// Build header with proper padding then style it
-	header := fmt.Sprintf("%-*s  %-*s  %-10s  %-*s  %s",
-		nameWidth, "NAME",
-		typeWidth, "TYPE",
-		"STATUS",
-		portWidth, "PORT",
-		"TTL REMAINING")
-	fmt.Println(headerStyle.Render(header))
-
-	// Print separator
-	totalWidth := nameWidth + typeWidth + 10 + portWidth + 15 + 8 // +8 for spacing
-	fmt.Println(strings.Repeat("─", totalWidth))
-
-	// Print rows
-	for _, c := range containers {
-		// Determine actual status - don't override "removed" status
-		displayStatus := c.Status
-		if c.Status != "removed" {
-			isExpired := time.Now().After(c.ExpiresAt)
-			if isExpired && c.Status != "stopped" {
-				displayStatus = "expired"
-			}
+// logicalDatabaseRows returns table rows for databases nested under the container row above them
+// The name goes in the first column, the other columns are left empty
+func logicalDatabaseRows(dbs []*database.LogicalDatabase, columns int) [][]string {
+	var rows [][]string
+	for i, d := range dbs {
+		branch := "├─"
+		if i == len(dbs)-1 {
+			branch = "└─"
 		}
+		row := make([]string, columns)
+		row[0] = "  " + branch + " " + d.Name
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func displayContainerList(containers []*database.Container, logical map[int][]*database.LogicalDatabase, style string) error {
+	// Markdown tables are meant to be pasted elsewhere, so they're left unstyled
+	colored := style != tableStyleMarkdown
+
+	t, err := newListTable(style, colored)
+	if err != nil {
+		return err
+	}
+	t.Headers("NAME", "TYPE", "STATUS", "PORT", "TTL REMAINING")
+
+	statusStyles := map[string]lipgloss.Style{
+		"running": lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true), // Green
+		"stopped": lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true), // Yellow
+		"expired": lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),  // Red
+		"removed": lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Bold(true),  // Gray
+	}
 
-		// Format TTL
-		ttlRemaining := formatTTL(c)
-
-		// Apply status style
-		var styledStatus string
-		switch displayStatus {
-		case "running":
-			styledStatus = statusRunningStyle.Render("● running")
-		case "stopped":
-			styledStatus = statusStoppedStyle.Render("● stopped")
-		case "expired":
-			styledStatus = statusExpiredStyle.Render("● expired")
-		case "removed":
-			styledStatus = statusRemovedStyle.Render("○ removed")
-		default:
-			styledStatus = displayStatus
+	for _, c := range containers {
+		status := containerDisplayStatus(c)
+		if statusStyle, ok := statusStyles[status]; ok && colored {
+			marker := "●"
+			if status == "removed" {
+				marker = "○"
+			}
+			status = statusStyle.Render(marker + " " + status)
 		}
 
-		// Print row - use plain printf with spacing
-		fmt.Printf("%-*s  %-*s  %s  %-*s  %s\n",
-			nameWidth, c.DisplayName,
-			typeWidth, c.Type,
-			padStatus(styledStatus, 10),
-			portWidth, c.Port,
-			ttlRemaining)
+		t.Row(c.DisplayName, c.Type, status, c.Port, formatTTL(c))
+		t.Rows(logicalDatabaseRows(logical[c.ID], 5)...)
 	}
 
+	fmt.Println()
+	fmt.Println(t.Render())
 	fmt.Println()
 	fmt.Printf("Total: %d container(s)\n", len(containers))
 	fmt.Println()
+	return nil
 }
 
-// padStatus adds padding to a styled status string while accounting for ANSI codes
-func padStatus(styledStatus string, width int) string {
-	visibleLen := len("● running") // All statuses are this length
-	padding := width - visibleLen
-	if padding < 0 {
-		padding = 0
+// displayContainerFields prints the selected fields without colors, for use in pipelines
+func displayContainerFields(containers []*database.Container, fields []listField, logical map[int][]*database.LogicalDatabase, style string) error {
+	t, err := newListTable(style, false)
+	if err != nil {
+		return err
+	}
+	// Keep plain output to a header and one line per container, so it's easy to parse
+	if style == tableStylePlain {
+		t.BorderHeader(false)
+	}
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.header
+	}
+	t.Headers(headers...)
+
+	for _, c := range containers {
+		values := make([]string, len(fields))
+		for i, f := range fields {
+			values[i] = f.value(c)
+		}
+		t.Row(values...)
+		t.Rows(logicalDatabaseRows(logical[c.ID], len(fields))...)
 	}
-	return styledStatus + strings.Repeat(" ", padding)
+
+	fmt.Println(t.Render())
+	return nil
 }
 
-// Helper function to find max length
-func maxLen(containers []*database.Container, fn func(*database.Container) string) int {
-	maxL := 0
+// printContainerJSON prints containers as an array of objects holding the selected fields
+// Logical databases are included under "databases" when they were looked up
+func printContainerJSON(containers []*database.Container, fields []listField, logical map[int][]*database.LogicalDatabase) error {
+	objects := make([]map[string]any, 0, len(containers))
 	for _, c := range containers {
-		if l := len(fn(c)); l > maxL {
-			maxL = l
+		object := make(map[string]any, len(fields)+1)
+		for _, f := range fields {
+			if value, ok := listJSONValues[f.name]; ok {
+				object[f.name] = value(c)
+			} else {
+				object[f.name] = f.value(c)
+			}
+		}
+		if logical != nil {
+			names := []string{}
+			for _, d := range logical[c.ID] {
+				names = append(names, d.Name)
+			}
+			object["databases"] = names
 		}
+		objects = append(objects, object)
 	}
-	return maxL
+
+	data, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode containers: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
 }
 
-// Helper function for max of two ints
-func max(a, b int) int {
-	if a > b {
-		return a
+// containerDisplayStatus returns a container's status, reporting "expired" once its TTL has passed
+func containerDisplayStatus(c *database.Container) string {
+	// Don't override "removed" status
+	if c.Status == "removed" {
+		return c.Status
+	}
+	if time.Now().After(c.ExpiresAt) && c.Status != "stopped" {
+		return "expired"
 	}
-	return b
+	return c.Status
 }
 
 func formatTTL(c *database.Container) string {
+	if c.Pinned {
+		return "pinned"
+	}
 	timeRemaining := time.Until(c.ExpiresAt)
 
 	if timeRemaining < 0 {
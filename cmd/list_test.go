@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/ui"
+)
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+// testListContainers returns two containers with fixed times, for output that doesn't depend on the clock
+func testListContainers(t *testing.T) []*database.Container {
+	t.Helper()
+	if err := ui.SetTimeZone("UTC"); err != nil {
+		t.Fatalf("SetTimeZone() error = %v", err)
+	}
+	t.Cleanup(func() { ui.SetTimeZone("") })
+
+	created := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	return []*database.Container{
+		{ID: 1, DisplayName: "orders", Type: "postgres", Version: "16", Port: "5432", Status: "stopped", CreatedAt: created, ExpiresAt: created.Add(24 * time.Hour)},
+		{ID: 2, DisplayName: "キャッシュ", Type: "redis", Version: "latest", ServerVersion: "7.2.4", Port: "6379", Status: "stopped", CreatedAt: created, Pinned: true},
+	}
+}
+
+func TestParseListFields(t *testing.T) {
+	fields, err := parseListFields("")
+	if err != nil || len(fields) != len(listFields) {
+		t.Errorf("parseListFields(\"\") = %d fields, %v, want all %d", len(fields), err, len(listFields))
+	}
+
+	fields, err = parseListFields(" name, PORT ,,")
+	if err != nil {
+		t.Fatalf("parseListFields() error = %v", err)
+	}
+	if len(fields) != 2 || fields[0].name != "name" || fields[1].name != "port" {
+		t.Errorf("parseListFields() = %v, want name and port", fields)
+	}
+
+	for _, spec := range []string{"name,owner", ",,"} {
+		if _, err := parseListFields(spec); err == nil {
+			t.Errorf("parseListFields(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+func TestPrintContainerJSON(t *testing.T) {
+	containers := testListContainers(t)
+	fields, err := parseListFields("name,version,created,expires")
+	if err != nil {
+		t.Fatalf("parseListFields() error = %v", err)
+	}
+	logical := map[int][]*database.LogicalDatabase{1: {{Name: "reports"}}}
+
+	out := captureStdout(t, func() {
+		if err := printContainerJSON(containers, fields, logical); err != nil {
+			t.Fatalf("printContainerJSON() error = %v", err)
+		}
+	})
+
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output isn't JSON: %v\n%s", err, out)
+	}
+	if len(got) != 2 {
+		t.Fatalf("printContainerJSON() wrote %d objects, want 2", len(got))
+	}
+	want := map[string]any{
+		"name":      "orders",
+		"version":   "16",
+		"created":   "2026-10-16T14:00:00Z",
+		"expires":   "2026-10-17T14:00:00Z",
+		"databases": []any{"reports"},
+	}
+	if len(got[0]) != len(want) {
+		t.Errorf("object keys = %v, want only the selected fields and databases", got[0])
+	}
+	for key, value := range want {
+		if gotJSON, _ := json.Marshal(got[0][key]); string(gotJSON) != mustJSON(t, value) {
+			t.Errorf("%s = %s, want %s", key, gotJSON, mustJSON(t, value))
+		}
+	}
+	if got[1]["version"] != "7.2.4" {
+		t.Errorf("version = %v, want the server version 7.2.4", got[1]["version"])
+	}
+	if dbs, ok := got[1]["databases"].([]any); !ok || len(dbs) != 0 {
+		t.Errorf("databases = %v, want an empty list", got[1]["databases"])
+	}
+
+	// Without looking up logical databases there's no databases key
+	out = captureStdout(t, func() {
+		printContainerJSON(containers, fields, nil)
+	})
+	if strings.Contains(out, `"databases"`) {
+		t.Errorf("printContainerJSON() without databases = %s, want no databases key", out)
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestNewListTable_InvalidStyle(t *testing.T) {
+	if _, err := newListTable("fancy", false); err == nil {
+		t.Error("newListTable(fancy) error = nil, want error")
+	}
+}
+
+func TestDisplayContainerFields(t *testing.T) {
+	containers := testListContainers(t)
+	fields, err := parseListFields("name,port")
+	if err != nil {
+		t.Fatalf("parseListFields() error = %v", err)
+	}
+	logical := map[int][]*database.LogicalDatabase{1: {{Name: "reports"}, {Name: "archive"}}}
+
+	tests := []struct {
+		style string
+		want  []string
+	}{
+		{
+			style: tableStylePlain,
+			// Wide characters count as two columns, so the ports line up
+			want: []string{
+				"NAME          PORT",
+				"orders        5432",
+				"  ├─ reports",
+				"  └─ archive",
+				"キャッシュ    6379",
+			},
+		},
+		{
+			style: tableStyleMarkdown,
+			want: []string{
+				"| NAME         | PORT |",
+				"|--------------|------|",
+				"| orders       | 5432 |",
+				"|   ├─ reports |      |",
+				"|   └─ archive |      |",
+				"| キャッシュ   | 6379 |",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			out := captureStdout(t, func() {
+				if err := displayContainerFields(containers, fields, logical, tt.style); err != nil {
+					t.Fatalf("displayContainerFields() error = %v", err)
+				}
+			})
+
+			var lines []string
+			for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+				lines = append(lines, strings.TrimRight(line, " "))
+			}
+			if strings.Join(lines, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("displayContainerFields() output:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLogicalDatabaseRows(t *testing.T) {
+	rows := logicalDatabaseRows([]*database.LogicalDatabase{{Name: "a"}, {Name: "b"}}, 3)
+	want := [][]string{{"  ├─ a", "", ""}, {"  └─ b", "", ""}}
+	if len(rows) != len(want) {
+		t.Fatalf("logicalDatabaseRows() = %q, want %q", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("logicalDatabaseRows()[%d] = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/filter"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsContainerName string
+	logsFollow        bool
+	logsTail          string
+	logsTimestamps    bool
+	logsGrep          string
+	logsGrepCI        bool
+	logsSince         string
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show container logs",
+	Long: `Show the logs of a database container.
+
+Use --follow to stream new log lines until Ctrl-C, and --grep to only show lines matching a regular expression.
+--grep ignores case unless --grep-ci=false is passed. Use --since to skip older lines, given as a
+duration before now (10m, 2h, 7d) or a date (2006-01-02, or RFC3339 for a specific time):
+  mkdb logs --name mydb --since 1h --grep 'error|fatal'`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runLogs,
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVar(&logsContainerName, "name", "", "Container name (skips interactive selection)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Stream new log lines until interrupted")
+	logsCmd.Flags().StringVar(&logsTail, "tail", "all", "Number of lines to show from the end of the logs")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "Prefix each line with its RFC3339 timestamp")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsGrepCI, "grep-ci", true, "Match --grep regardless of case")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show lines logged since this time (e.g. 10m, 2h, 2006-01-02)")
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	opts := docker.LogOptions{
+		Follow:     logsFollow,
+		Tail:       logsTail,
+		Timestamps: logsTimestamps,
+	}
+	if logsGrep != "" {
+		pattern, err := docker.CompileGrep(logsGrep, logsGrepCI)
+		if err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+		opts.Grep = pattern
+	}
+	if logsSince != "" {
+		since, err := filter.ParseTime(logsSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		opts.Since = since
+	}
+
+	var container *database.Container
+	var err error
+
+	// If name is provided, look it up directly
+	if logsContainerName != "" {
+		container, err = database.GetContainerByDisplayName(logsContainerName)
+		if err != nil {
+			return fmt.Errorf("container '%s' not found", logsContainerName)
+		}
+	} else {
+		containers, err := database.ListContainers()
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		if len(containers) == 0 {
+			ui.Warning("No containers found")
+			return nil
+		}
+
+		container, err = ui.SelectContainer(containers, "Select container to view logs")
+		if err != nil {
+			return fmt.Errorf("failed to select container: %w", err)
+		}
+	}
+
+	if container.ContainerID == "" || !docker.ContainerExists(container.ContainerID) {
+		return fmt.Errorf("container '%s' no longer exists in Docker", container.DisplayName)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return docker.StreamLogs(ctx, container.ContainerID, opts, os.Stdout)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openContainerName string
+	openPrintOnly     bool
+)
+
+// adminerPort is the host port suggested for an Adminer container
+const adminerPort = "8080"
+
+// adminerSystems maps database types to the login parameter Adminer uses for them
+var adminerSystems = map[string]string{
+	"postgres":    "pgsql",
+	"timescaledb": "pgsql",
+	"postgis":     "pgsql",
+	"mysql":       "server",
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Open a database's web interface",
+	Long: `Open the web interface a database serves on its published port in the browser, such as
+Elasticsearch's REST API. The URL is printed too, and only printed with --print or when not
+run from a terminal.
+
+Databases without a web interface of their own get instructions for running Adminer next
+to them instead, where one can be used.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().StringVar(&openContainerName, "name", "", "Container name (skips interactive selection)")
+	openCmd.Flags().BoolVar(&openPrintOnly, "print", false, "Only print the URL, don't open the browser")
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	container, err := selectInfoContainer(openContainerName, "Select container to open")
+	if err != nil || container == nil {
+		return err
+	}
+
+	adapter, err := adapters.GetRegistry().Get(container.Type)
+	if err != nil {
+		return err
+	}
+
+	webURL := adapter.WebUIURL(credentials.ConnectionHost(), container.Port)
+	if webURL == "" {
+		return printWebUIAlternative(container)
+	}
+
+	if container.Status != "running" {
+		ui.Warning(fmt.Sprintf("Container '%s' is %s, start it with 'mkdb restart --name %s'", container.DisplayName, container.Status, container.DisplayName))
+	}
+	fmt.Println(webURL)
+
+	if openPrintOnly || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return nil
+	}
+	if err := openBrowser(webURL); err != nil {
+		ui.Warning(fmt.Sprintf("Couldn't open the browser: %v", err))
+	}
+	return nil
+}
+
+// printWebUIAlternative explains that a database has no web interface, and how to run Adminer for it if Adminer supports it
+func printWebUIAlternative(container *database.Container) error {
+	system, ok := adminerSystems[container.Type]
+	if !ok {
+		ui.Info(fmt.Sprintf("%s doesn't have a web interface, use 'mkdb connect --name %s' to connect with a client", container.Type, container.DisplayName))
+		return nil
+	}
+
+	// Adminer reaches the database over its network by name, or through the host's published port
+	run := fmt.Sprintf("docker run --rm -p %s:8080", adminerPort)
+	server := net.JoinHostPort("host.docker.internal", container.Port)
+	if container.Network != "" {
+		run += " --network " + container.Network
+		server = container.DisplayName
+	} else {
+		run += " --add-host host.docker.internal:host-gateway"
+	}
+	run += " adminer"
+
+	query := url.Values{system: {server}}
+	if user, err := database.GetDefaultUser(container.ID); err == nil && user.Username != "" {
+		query.Set("username", user.Username)
+	}
+	query.Set("db", connectionDBName(container))
+	loginURL := fmt.Sprintf("http://%s/?%s", net.JoinHostPort(credentials.ConnectionHost(), adminerPort), query.Encode())
+
+	ui.Info(fmt.Sprintf("%s doesn't have a web interface, but Adminer can run next to it:", container.Type))
+	ui.Newline()
+	fmt.Printf("  %s\n", run)
+	ui.Newline()
+	ui.Info(fmt.Sprintf("Then log in at %s, 'mkdb creds get --name %s' prints the password", loginURL, container.DisplayName))
+	return nil
+}
+
+// openBrowser opens target with the desktop's default handler
+func openBrowser(target string) error {
+	var name string
+	switch runtime.GOOS {
+	case "darwin":
+		name = "open"
+	case "linux":
+		name = "xdg-open"
+	default:
+		return fmt.Errorf("opening a browser isn't supported on %s", runtime.GOOS)
+	}
+
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found", name)
+	}
+	return exec.Command(name, target).Start()
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "Reconcile tracked containers with Docker",
+	Long: `Compare the containers mkdb is tracking with the mkdb-labeled containers in Docker.
+Reports containers whose recorded status doesn't match Docker, tracked containers that
+no longer exist, and labeled containers that mkdb isn't tracking, then offers to repair them.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runPs,
+}
+
+func init() {
+	rootCmd.AddCommand(psCmd)
+}
+
+// containerDrift describes a mismatch between the mkdb database and Docker
+type containerDrift struct {
+	Container *database.Container      // nil if Docker has a container mkdb isn't tracking
+	Summary   *docker.ContainerSummary // nil if the Docker container no longer exists
+	Issue     string
+}
+
+func runPs(cmd *cobra.Command, args []string) error {
+	tracked, err := database.ListContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	managed, err := docker.ListManagedContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list Docker containers: %w", err)
+	}
+
+	// Adopted containers don't carry the mkdb labels, so look them up directly
+	for _, c := range tracked {
+		if !c.Adopted || c.ContainerID == "" {
+			continue
+		}
+		if summary, err := docker.InspectContainer(c.ContainerID); err == nil {
+			managed = append(managed, *summary)
+		}
+	}
+
+	drifts := detectDrift(tracked, managed)
+
+	if len(drifts) == 0 {
+		ui.Success(fmt.Sprintf("No drift detected (%d tracked, %d in Docker)", len(tracked), len(managed)))
+		return nil
+	}
+
+	displayDrift(drifts)
+
+	// Repairing requires prompts, so only report in non-interactive terminals
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		ui.Info("Run 'mkdb ps' in an interactive terminal to repair")
+		return nil
+	}
+
+	confirmed, err := ui.PromptConfirm(fmt.Sprintf("Repair %d issue(s)?", len(drifts)))
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	if !confirmed {
+		ui.Info("No changes made")
+		return nil
+	}
+
+	return repairDrift(drifts)
+}
+
+// detectDrift cross-references tracked containers with the labeled containers in Docker
+func detectDrift(tracked []*database.Container, managed []docker.ContainerSummary) []containerDrift {
+	byID := make(map[string]*docker.ContainerSummary, len(managed))
+	byName := make(map[string]*docker.ContainerSummary, len(managed))
+	for i := range managed {
+		byID[managed[i].ID] = &managed[i]
+		byName[managed[i].Name] = &managed[i]
+	}
+
+	matched := make(map[string]bool)
+	var drifts []containerDrift
+
+	for _, c := range tracked {
+		summary := byID[c.ContainerID]
+		if summary == nil {
+			summary = byName[c.DisplayName]
+		}
+		if summary != nil {
+			matched[summary.ID] = true
+		}
+
+		switch {
+		case summary == nil && c.Status == types.StatusRunning:
+			drifts = append(drifts, containerDrift{
+				Container: c,
+				Issue:     "recorded as running but the Docker container is gone",
+			})
+		case summary != nil && summary.State == types.StatusRunning && c.Status != types.StatusRunning:
+			drifts = append(drifts, containerDrift{
+				Container: c,
+				Summary:   summary,
+				Issue:     fmt.Sprintf("recorded as %s but Docker reports running", c.Status),
+			})
+		case summary != nil && summary.State != types.StatusRunning && c.Status == types.StatusRunning:
+			drifts = append(drifts, containerDrift{
+				Container: c,
+				Summary:   summary,
+				Issue:     fmt.Sprintf("recorded as running but Docker reports %s", summary.State),
+			})
+		}
+	}
+
+	for i := range managed {
+		if matched[managed[i].ID] {
+			continue
+		}
+		drifts = append(drifts, containerDrift{
+			Summary: &managed[i],
+			Issue:   "labeled as mkdb-managed but not tracked",
+		})
+	}
+
+	return drifts
+}
+
+func displayDrift(drifts []containerDrift) {
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12"))
+
+	rows := make([][3]string, len(drifts))
+	nameWidth := len("NAME")
+	typeWidth := len("TYPE")
+	for i, d := range drifts {
+		name, dbType := d.name(), d.dbType()
+		rows[i] = [3]string{name, dbType, d.Issue}
+		nameWidth = max(nameWidth, len(name))
+		typeWidth = max(typeWidth, len(dbType))
+	}
+
+	fmt.Println()
+	fmt.Println(headerStyle.Render(fmt.Sprintf("%-*s  %-*s  %s", nameWidth, "NAME", typeWidth, "TYPE", "ISSUE")))
+	fmt.Println(strings.Repeat("─", nameWidth+typeWidth+45))
+	for _, row := range rows {
+		fmt.Printf("%-*s  %-*s  %s\n", nameWidth, row[0], typeWidth, row[1], row[2])
+	}
+	fmt.Println()
+	fmt.Printf("Total: %d issue(s)\n", len(drifts))
+	fmt.Println()
+}
+
+func (d containerDrift) name() string {
+	if d.Container != nil {
+		return d.Container.DisplayName
+	}
+	return d.Summary.Name
+}
+
+func (d containerDrift) dbType() string {
+	if d.Container != nil {
+		return d.Container.Type
+	}
+	return d.Summary.Type
+}
+
+// repairDrift updates tracked records to match Docker and imports untracked containers
+func repairDrift(drifts []containerDrift) error {
+	// Only ask for a TTL if there's something to import
+	importTTL := 0
+	for _, d := range drifts {
+		if d.Container == nil {
+			ttl, err := promptImportTTL()
+			if err != nil {
+				return err
+			}
+			importTTL = ttl
+			break
+		}
+	}
+
+	repaired := 0
+	for _, d := range drifts {
+		if d.Container == nil {
+			if _, err := importManagedContainer(*d.Summary, importTTL); err != nil {
+				ui.Error(fmt.Sprintf("Failed to import %s: %v", d.Summary.Name, err))
+				continue
+			}
+			ui.Success(fmt.Sprintf("Imported %s (%s)", d.Summary.Name, d.Summary.Type))
+			repaired++
+			continue
+		}
+
+		c := d.Container
+		previousStatus := c.Status
+		if d.Summary != nil && d.Summary.State == types.StatusRunning {
+			c.Status = types.StatusRunning
+		} else {
+			c.Status = types.StatusStopped
+		}
+		if d.Summary != nil {
+			c.ContainerID = d.Summary.ID
+		}
+
+		if err := database.UpdateContainer(c); err != nil {
+			ui.Error(fmt.Sprintf("Failed to update %s: %v", c.DisplayName, err))
+			continue
+		}
+
+		event := &database.Event{
+			ContainerID: c.ID,
+			EventType:   "repaired",
+			Timestamp:   time.Now(),
+			Details:     fmt.Sprintf("Status changed from %s to %s to match Docker", previousStatus, c.Status),
+		}
+		if err := database.CreateEvent(event); err != nil {
+			config.Logger.Warn("Failed to log event", "error", err)
+		}
+
+		ui.Success(fmt.Sprintf("Marked %s as %s", c.DisplayName, c.Status))
+		repaired++
+	}
+
+	ui.Newline()
+	ui.Success(fmt.Sprintf("Repaired %d of %d issue(s)", repaired, len(drifts)))
+	return nil
+}
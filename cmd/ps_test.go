@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+)
+
+func TestDetectDrift(t *testing.T) {
+	record := func(name, containerID, status string) *database.Container {
+		return &database.Container{DisplayName: name, ContainerID: containerID, Status: status}
+	}
+
+	// wantDrift is a drift by name, issue and whether the Docker container was matched
+	type wantDrift struct {
+		name       string
+		issue      string
+		hasSummary bool
+	}
+
+	tests := []struct {
+		name    string
+		tracked []*database.Container
+		managed []docker.ContainerSummary
+		want    []wantDrift
+	}{
+		{
+			name:    "in sync",
+			tracked: []*database.Container{record("orders", "abc", types.StatusRunning), record("cache", "def", types.StatusStopped)},
+			managed: []docker.ContainerSummary{{ID: "abc", Name: "orders", State: types.StatusRunning}, {ID: "def", Name: "cache", State: "exited"}},
+		},
+		{
+			name:    "running record without a Docker container",
+			tracked: []*database.Container{record("orders", "abc", types.StatusRunning)},
+			want:    []wantDrift{{"orders", "recorded as running but the Docker container is gone", false}},
+		},
+		{
+			// Only a running record is drift, a stopped one may have been removed with 'mkdb stop'
+			name:    "stopped record without a Docker container",
+			tracked: []*database.Container{record("orders", "abc", types.StatusStopped)},
+		},
+		{
+			name:    "Docker running while the record says stopped",
+			tracked: []*database.Container{record("orders", "abc", types.StatusStopped)},
+			managed: []docker.ContainerSummary{{ID: "abc", Name: "orders", State: types.StatusRunning}},
+			want:    []wantDrift{{"orders", "recorded as stopped but Docker reports running", true}},
+		},
+		{
+			name:    "record says running while Docker reports exited",
+			tracked: []*database.Container{record("orders", "abc", types.StatusRunning)},
+			managed: []docker.ContainerSummary{{ID: "abc", Name: "orders", State: "exited"}},
+			want:    []wantDrift{{"orders", "recorded as running but Docker reports exited", true}},
+		},
+		{
+			// The container was recreated outside mkdb, so only its name still matches
+			name:    "match falls back from ID to name",
+			tracked: []*database.Container{record("orders", "old", types.StatusStopped)},
+			managed: []docker.ContainerSummary{{ID: "new", Name: "orders", State: types.StatusRunning}},
+			want:    []wantDrift{{"orders", "recorded as stopped but Docker reports running", true}},
+		},
+		{
+			name:    "name match isn't reported as untracked",
+			tracked: []*database.Container{record("orders", "old", types.StatusRunning)},
+			managed: []docker.ContainerSummary{{ID: "new", Name: "orders", State: types.StatusRunning}},
+		},
+		{
+			name:    "labeled container that isn't tracked",
+			tracked: []*database.Container{record("orders", "abc", types.StatusRunning)},
+			managed: []docker.ContainerSummary{{ID: "abc", Name: "orders", State: types.StatusRunning}, {ID: "xyz", Name: "stray", State: types.StatusRunning}},
+			want:    []wantDrift{{"stray", "labeled as mkdb-managed but not tracked", true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drifts := detectDrift(tt.tracked, tt.managed)
+			if len(drifts) != len(tt.want) {
+				t.Fatalf("detectDrift() = %d drifts %+v, want %d", len(drifts), drifts, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := drifts[i]
+				if got.name() != want.name || got.Issue != want.issue || (got.Summary != nil) != want.hasSummary {
+					t.Errorf("drift[%d] = %s: %q (summary %v), want %s: %q (summary %v)",
+						i, got.name(), got.Issue, got.Summary != nil, want.name, want.issue, want.hasSummary)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeForce bool
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete the records of removed containers",
+	Long: `Permanently delete the records mkdb keeps for removed and cleaned up containers.
+
+These records let mkdb identify the type and version of leftover volumes. Volumes
+on disk are not touched.`,
+	RunE: runPurge,
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+	purgeCmd.Flags().BoolVar(&purgeForce, "force", false, "Skip the confirmation prompt")
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	removed, err := database.ListRemovedContainers()
+	if err != nil {
+		return fmt.Errorf("failed to list removed containers: %w", err)
+	}
+
+	if len(removed) == 0 {
+		ui.Info("No removed containers to purge")
+		return nil
+	}
+
+	fmt.Println()
+	ui.Header("Removed containers")
+	for _, c := range removed {
+		fmt.Printf("  %s (%s %s, created %s)\n", c.DisplayName, c.Type, c.DisplayVersion(), c.CreatedAt.Format("2006-01-02"))
+	}
+	fmt.Println()
+
+	if !purgeForce {
+		confirmed, err := ui.PromptConfirm(fmt.Sprintf("Permanently delete %d record(s)?", len(removed)))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+
+		if !confirmed {
+			ui.Info("Purge cancelled")
+			return nil
+		}
+	}
+
+	count, err := database.PurgeRemovedContainers()
+	if err != nil {
+		return fmt.Errorf("failed to purge removed containers: %w", err)
+	}
+
+	config.Logger.Info("Purged removed container records", "count", count)
+	ui.Success(fmt.Sprintf("Purged %d removed container record(s)", count))
+	return nil
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
 	"github.com/pbzona/mkdb/internal/ui"
@@ -13,18 +16,23 @@ import (
 
 var (
 	restartContainerName string
+	restartTimeout       time.Duration
+	restartReassignPort  bool
 )
 
 var restartCmd = &cobra.Command{
-	Use:   "restart",
-	Short: "Restart a database container",
-	Long:  `Restart a stopped database container with its existing data.`,
-	RunE:  runRestart,
+	Use:         "restart",
+	Short:       "Restart a database container",
+	Long:        `Restart a stopped database container with its existing data.`,
+	Annotations: requiresDocker,
+	RunE:        runRestart,
 }
 
 func init() {
 	rootCmd.AddCommand(restartCmd)
 	restartCmd.Flags().StringVar(&restartContainerName, "name", "", "Container name (skips interactive selection)")
+	restartCmd.Flags().DurationVar(&restartTimeout, "timeout", docker.DefaultStopTimeout, "Time to wait for a clean shutdown before killing the database (0 waits indefinitely)")
+	restartCmd.Flags().BoolVar(&restartReassignPort, "reassign-port", false, "Pick a new port if the container's port was taken while it was gone")
 }
 
 func runRestart(cmd *cobra.Command, args []string) error {
@@ -56,53 +64,66 @@ func runRestart(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	return restartContainer(container, restartTimeout, restartReassignPort)
+}
+
+// restartContainer restarts a container's Docker container, or recreates it from the record if it was removed
+// If the port had to be reassigned, the new connection string is printed
+func restartContainer(container *database.Container, timeout time.Duration, reassignPort bool) error {
 	ui.Info(fmt.Sprintf("Restarting container '%s'...", container.DisplayName))
 
+	var newConnStr string
+
 	// Check if container exists
 	if container.ContainerID != "" && docker.ContainerExists(container.ContainerID) {
 		// Container exists, just restart it
-		if err := docker.RestartContainer(container.ContainerID); err != nil {
+		if err := docker.RestartContainer(container.ContainerID, timeout); err != nil {
 			return fmt.Errorf("failed to restart container: %w", err)
 		}
+		username, password, err := defaultCredentials(container)
+		if err != nil {
+			return err
+		}
+		restoreUsers(container, username, password)
 	} else {
 		// Container doesn't exist, recreate it
+		if err := checkRecreatable(container); err != nil {
+			return err
+		}
 		ui.Info("Container not found, recreating...")
 
-		// Get default user credentials
-		user, err := database.GetDefaultUser(container.ID)
+		// Something else may have claimed the port since the container was removed
+		// A leftover container of this database's own doesn't count, it's replaced
+		port, err := docker.ReclaimPort(container.Port, reassignPort, container.Name, container.ContainerID)
 		if err != nil {
-			return fmt.Errorf("failed to get default user: %w", err)
+			return err
+		}
+		originalPort := container.Port
+		if port != originalPort {
+			ui.Warning(fmt.Sprintf("Port %s is in use, using port %s instead", container.Port, port))
+			container.Port = port
 		}
 
-		// Handle unauthenticated databases
-		var username, password string
-		if user.Username != "" && user.PasswordHash != "" {
-			username = user.Username
-			password, err = config.Decrypt(user.PasswordHash)
-			if err != nil {
-				return fmt.Errorf("failed to decrypt password: %w", err)
-			}
-		} else {
-			// Unauthenticated database
-			username = ""
-			password = ""
+		// Warn rather than refuse, since the container is being recreated with the version it had
+		if err := checkDataDirVersion(container, container.Version); err != nil {
+			ui.Warning(err.Error())
 		}
 
-		containerID, err := docker.CreateContainer(
-			container.Type,
-			container.DisplayName,
-			username,
-			password,
-			container.Port,
-			container.VolumeType,
-			container.VolumePath,
-			container.Version,
-		)
+		username, password, err := recreateContainer(container)
 		if err != nil {
-			return fmt.Errorf("failed to create container: %w", err)
+			return err
 		}
 
-		container.ContainerID = containerID
+		if port != originalPort {
+			newConnStr = credentials.FormatConnectionString(
+				container.Type,
+				username,
+				password,
+				credentials.ConnectionHost(),
+				container.Port,
+				connectionDBName(container),
+			)
+		}
 	}
 
 	// Update status
@@ -121,5 +142,141 @@ func runRestart(cmd *cobra.Command, args []string) error {
 	database.CreateEvent(event)
 
 	ui.Success(fmt.Sprintf("Container '%s' restarted successfully!", container.DisplayName))
+
+	// The old connection string no longer works, so show the new one
+	if newConnStr != "" {
+		envKey, err := resolveEnvKey("")
+		if err != nil {
+			return err
+		}
+		ui.Newline()
+		fmt.Println(credentials.FormatEnvVar(envKey, newConnStr))
+		ui.Newline()
+	}
+
+	return nil
+}
+
+// recreateContainer creates a new Docker container for an existing record from its stored
+// settings and default user, reattaching its volume. The new container ID is set on the record
+// Returns the default user's credentials so callers can print a connection string
+func recreateContainer(container *database.Container) (string, string, error) {
+	if err := checkRecreatable(container); err != nil {
+		return "", "", err
+	}
+
+	username, password, err := defaultCredentials(container)
+	if err != nil {
+		return "", "", err
+	}
+
+	// The data directory was initialized with this password, so it has to be reused
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return "", "", err
+	}
+
+	containerID, err := docker.CreateContainer(context.Background(), docker.ContainerOptions{
+		DBType:         container.Type,
+		DisplayName:    container.DisplayName,
+		Username:       username,
+		Password:       password,
+		Port:           container.Port,
+		VolumeType:     container.VolumeType,
+		VolumePath:     container.VolumePath,
+		VolumeDriver:   container.VolumeDriver,
+		Version:        container.Version,
+		Persistence:    container.Persistence,
+		CPUShares:      container.CPUShares,
+		VolumeReadOnly: container.VolumeReadOnly,
+		DataTarget:     container.DataTarget,
+		RestartPolicy:  container.RestartPolicy,
+		ExtraEnv:       container.ExtraEnv,
+		AdminPassword:  adminPassword,
+		BindIP:         container.BindIP,
+		ExtraArgs:      container.ExtraArgs,
+		Network:        container.Network,
+		NoConfig:       container.NoConfig,
+		Labels:         container.Labels,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	container.ContainerID = containerID
+	restoreUsers(container, username, password)
+	return username, password, nil
+}
+
+// defaultCredentials returns a container's default username and decrypted password
+// Both are empty for unauthenticated databases
+func defaultCredentials(container *database.Container) (string, string, error) {
+	user, err := database.GetDefaultUser(container.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get default user: %w", err)
+	}
+	if user.Username == "" || user.PasswordHash == "" {
+		return "", "", nil
+	}
+
+	password, err := config.Decrypt(user.PasswordHash)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	return user.Username, password, nil
+}
+
+// restoreUsers creates a container's non-default users again from their stored passwords when
+// its database keeps them only in memory, like Redis ACL users, after its server was restarted
+// username and password are the default user's, used to wait for the database to accept connections
+// Failures are warnings, since the container itself is running
+func restoreUsers(container *database.Container, username, password string) {
+	if !docker.UsersInMemory(container.Type) {
+		return
+	}
+
+	users, err := database.ListUsers(container.ID)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Failed to list users to restore: %v", err))
+		return
+	}
+	users = slices.DeleteFunc(users, func(u *database.User) bool { return u.IsDefault })
+	if len(users) == 0 {
+		return
+	}
+
+	ui.Info(fmt.Sprintf("Restoring %d user(s)...", len(users)))
+	if err := docker.WaitForReady(container.ContainerID, container.Type, username, password, container.DisplayName, docker.DefaultReadyTimeout); err != nil {
+		ui.Warning(fmt.Sprintf("Users weren't restored, the database didn't become ready: %v", err))
+		return
+	}
+
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Users weren't restored: %v", err))
+		return
+	}
+
+	for _, user := range users {
+		userPassword, err := config.Decrypt(user.PasswordHash)
+		if err == nil {
+			err = docker.CreateUser(container.ContainerID, container.Type, user.Username, userPassword, container.DisplayName, adminPassword)
+		}
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Failed to restore user '%s', delete and create it again with 'mkdb user': %v", user.Username, err))
+		}
+	}
+}
+
+// checkRecreatable refuses to recreate an adopted container. mkdb didn't create it, so a container
+// built from the record would replace the user's with one mkdb names and configures. It also refuses
+// when the stored --env values can't be decrypted, since the new container would silently lack them
+func checkRecreatable(container *database.Container) error {
+	if container.Adopted {
+		return fmt.Errorf("'%s' was adopted and mkdb can't recreate it, manage the Docker container '%s' directly", container.DisplayName, container.Name)
+	}
+	if container.EnvUnreadable {
+		return fmt.Errorf("the --env values stored for '%s' can't be decrypted with the current encryption key, so mkdb can't recreate it without losing them", container.DisplayName)
+	}
 	return nil
 }
@@ -4,27 +4,38 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
 	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/volumes"
 	"github.com/spf13/cobra"
 )
 
 var (
 	rmContainerName string
+	rmKeepVolume    bool
 )
 
 var rmCmd = &cobra.Command{
 	Use:     "remove",
 	Aliases: []string{"rm"},
 	Short:   "Delete an existing container and its volume",
-	Long:    `Delete an existing database container and its associated volume.`,
-	RunE:    runRm,
+	Long: `Delete an existing database container and its associated volume.
+
+With --keep-volume, only the container is deleted, and its volume and snapshots are left in
+place. A named volume shows up in 'mkdb ls --all' like other leftover volumes. Starting a new
+container with the same name and volume picks up the data and reuses the removed container's
+credentials, since the database was initialized with them.`,
+	Annotations: requiresDocker,
+	RunE:        runRm,
 }
 
 func init() {
 	rootCmd.AddCommand(rmCmd)
 	rmCmd.Flags().StringVar(&rmContainerName, "name", "", "Container name (skips interactive selection)")
+	rmCmd.Flags().BoolVar(&rmKeepVolume, "keep-volume", false, "Keep the volume so its data can be used by a new container")
 }
 
 func runRm(cmd *cobra.Command, args []string) error {
@@ -56,8 +67,17 @@ func runRm(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Adopted containers hold data mkdb didn't create
+	if container.Adopted {
+		ui.Warning(fmt.Sprintf("'%s' was adopted, mkdb didn't create it. Removing it deletes the Docker container '%s' and any data stored in it", container.DisplayName, container.Name))
+	}
+
 	// Confirm deletion
-	confirmed, err := ui.PromptConfirm(fmt.Sprintf("Are you sure you want to delete '%s'? This will remove the container and its volume", container.DisplayName))
+	prompt := fmt.Sprintf("Are you sure you want to delete '%s'? This will remove the container and its volume", container.DisplayName)
+	if rmKeepVolume {
+		prompt = fmt.Sprintf("Are you sure you want to delete '%s'? The volume is kept", container.DisplayName)
+	}
+	confirmed, err := ui.PromptConfirm(prompt)
 	if err != nil {
 		return fmt.Errorf("failed to get confirmation: %w", err)
 	}
@@ -71,7 +91,7 @@ func runRm(cmd *cobra.Command, args []string) error {
 
 	// Stop and remove container
 	if container.ContainerID != "" && docker.ContainerExists(container.ContainerID) {
-		if err := docker.StopContainer(container.ContainerID); err != nil {
+		if err := docker.StopContainer(container.ContainerID, docker.DefaultStopTimeout); err != nil {
 			ui.Warning(fmt.Sprintf("Failed to stop container: %v", err))
 		}
 
@@ -80,27 +100,61 @@ func runRm(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Remove volume if it exists
-	if container.VolumePath != "" {
-		if err := docker.RemoveVolume(container.VolumePath); err != nil {
-			ui.Warning(fmt.Sprintf("Failed to remove volume: %v", err))
+	// Remove the volume and snapshots, unless they're kept for a new container with the same name
+	if !rmKeepVolume {
+		if container.VolumePath != "" {
+			if err := docker.RemoveVolume(container.VolumePath); err != nil {
+				ui.Warning(fmt.Sprintf("Failed to remove volume: %v", err))
+			}
+		}
+
+		// Snapshots are found by name, so they'd otherwise show up for a new container with the same name
+		if err := volumes.RemoveSnapshots(container.DisplayName); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to remove snapshots: %v", err))
 		}
 	}
 
 	// Log event
+	details := "Container deleted by user"
+	if rmKeepVolume {
+		details += ", volume kept"
+	}
 	event := &database.Event{
 		ContainerID: container.ID,
 		EventType:   "deleted",
 		Timestamp:   time.Now(),
-		Details:     "Container deleted by user",
+		Details:     details,
 	}
 	database.CreateEvent(event)
 
-	// Delete from database
-	if err := database.DeleteContainer(container.ID); err != nil {
-		return fmt.Errorf("failed to delete container from database: %w", err)
+	// Keep the record so leftover volumes can still be identified, 'mkdb purge' deletes it
+	if err := database.MarkContainerRemoved(container.ID); err != nil {
+		return fmt.Errorf("failed to update container in database: %w", err)
 	}
 
+	config.Logger.Info("Container removed", "name", container.DisplayName, "volume", container.VolumePath)
 	ui.Success(fmt.Sprintf("Container '%s' removed successfully!", container.DisplayName))
+	if rmKeepVolume {
+		if flag := keptVolumeFlag(container); flag != "" {
+			ui.Info(fmt.Sprintf("Volume and snapshots kept, reuse them with 'mkdb start --name %s --db %s --volume %s', which keeps the current credentials", container.DisplayName, container.Type, flag))
+		} else {
+			ui.Info(fmt.Sprintf("'%s' had no volume, there was nothing to keep", container.DisplayName))
+		}
+	}
 	return nil
 }
+
+// keptVolumeFlag returns the 'mkdb start --volume' value that mounts a removed container's volume
+// again, or "" if it had none. Named volumes are found by the container's name
+func keptVolumeFlag(c *database.Container) string {
+	switch c.VolumeType {
+	case types.VolumeTypeNamed:
+		return types.VolumeTypeNamed
+	case types.VolumeTypeDocker:
+		return types.VolumeTypeDocker + ":" + c.VolumePath
+	case types.VolumeTypeBind:
+		return c.VolumePath
+	default:
+		return ""
+	}
+}
@@ -3,11 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/pbzona/mkdb/internal/adapters"
 	"github.com/pbzona/mkdb/internal/cleanup"
 	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -15,7 +19,9 @@ var rootCmd = &cobra.Command{
 	Use:   "mkdb",
 	Short: "mkdb - Easily manage local database containers",
 	Long: `mkdb is a CLI tool to create and manage local Docker database containers
-for development environments. It supports PostgreSQL, MySQL, and Redis.
+for development environments.
+
+Supported databases: ` + dbTypeList() + `
 
 Container lifecycle:
   start   - Create a new database container
@@ -30,18 +36,40 @@ Container lifecycle:
 			return fmt.Errorf("failed to initialize config: %w", err)
 		}
 
+		if err := ui.SetTimeZone(timeZone); err != nil {
+			return err
+		}
+
+		// Offer the setup wizard before anything else is written to the data directory
+		if err := maybeRunFirstRunWizard(cmd); err != nil {
+			config.Logger.Warn("Setup wizard failed", "error", err)
+		}
+
+		// Apply the color preference from the defaults file
+		if defaults, err := config.LoadDefaults(); err == nil {
+			ui.SetColorMode(defaults.Color)
+		}
+
 		// Initialize database
 		if err := database.Initialize(); err != nil {
 			return fmt.Errorf("failed to initialize database: %w", err)
 		}
+		checkEncryptionKey()
 
-		// Initialize Docker client
+		// Initialize Docker client, commands that only read mkdb's records can run without it
 		if err := docker.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize Docker client: %w", err)
+			if requiresDockerDaemon(cmd) {
+				return fmt.Errorf("failed to initialize Docker client (is Docker installed and running?): %w", err)
+			}
+			// Cleanup may remove containers, so it's skipped along with the prompt
+			config.Logger.Warn("Docker is unavailable, skipping cleanup", "error", err)
+			return nil
 		}
+		credentials.DockerHost = docker.ActiveEndpoint().Host
 
-		// Run cleanup to check for expired containers
-		if err := cleanup.Run(); err != nil {
+		// Run cleanup to check for expired containers, only prompting before commands that change things
+		// --yes confirms the command being run, it shouldn't also remove unrelated expired containers
+		if err := cleanup.Run(!noCleanup && !ui.AssumeYes && !skipsCleanupPrompt(cmd)); err != nil {
 			config.Logger.Warn("Cleanup failed", "error", err)
 		}
 
@@ -62,6 +90,60 @@ Container lifecycle:
 	},
 }
 
+// annotationNoCleanupPrompt marks read-only commands that shouldn't stop for the expired container prompt
+const annotationNoCleanupPrompt = "mkdb/no-cleanup-prompt"
+
+// noCleanupPrompt is the Annotations value for commands that skip the expired container prompt
+var noCleanupPrompt = map[string]string{annotationNoCleanupPrompt: "true"}
+
+// annotationRequiresDocker marks commands that fail if the Docker daemon isn't reachable
+const annotationRequiresDocker = "mkdb/requires-docker"
+
+// requiresDocker is the Annotations value for commands that need the Docker daemon
+var requiresDocker = map[string]string{annotationRequiresDocker: "true"}
+
+var (
+	noCleanup bool
+	timeZone  string
+)
+
+// mergeAnnotations combines Annotations values, e.g. for a read-only command that needs Docker
+func mergeAnnotations(sets ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, set := range sets {
+		for key, value := range set {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// dbTypeList returns the registered database types for help text, so new adapters show up in it
+func dbTypeList() string {
+	return strings.Join(adapters.GetRegistry().List(), ", ")
+}
+
+// requiresDockerDaemon reports whether cmd is annotated as needing the Docker daemon
+func requiresDockerDaemon(cmd *cobra.Command) bool {
+	return cmd.Annotations[annotationRequiresDocker] == "true"
+}
+
+// skipsCleanupPrompt reports whether cmd is annotated to skip the expired container prompt
+func skipsCleanupPrompt(cmd *cobra.Command) bool {
+	return cmd.Annotations[annotationNoCleanupPrompt] == "true"
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCleanup, "no-cleanup", false, "Don't prompt to extend or remove expired containers before running the command")
+	rootCmd.PersistentFlags().BoolVarP(&ui.Quiet, "quiet", "q", false, "Only print essential output, such as connection strings")
+	rootCmd.PersistentFlags().StringVar(&credentials.Host, "host", "", "Host to use in connection strings (default: $MKDB_HOST, the DOCKER_HOST host, or localhost)")
+	rootCmd.PersistentFlags().StringVar(&docker.Host, "docker-host", "", "Docker daemon to connect to, e.g. tcp://devbox:2376 (default: $DOCKER_HOST or the local socket)")
+	rootCmd.PersistentFlags().StringVar(&docker.ContextName, "docker-context", "", "Docker context to connect to, as listed by 'docker context ls' (default: $MKDB_DOCKER_CONTEXT)")
+	rootCmd.PersistentFlags().StringVar(&timeZone, "tz", "", "Time zone to display times in, e.g. Europe/Berlin (default: $MKDB_TZ or the local time zone)")
+	rootCmd.PersistentFlags().BoolVar(&config.Verbose, "verbose", false, "Print log messages to stderr as well as the log file")
+	rootCmd.PersistentFlags().BoolVarP(&ui.AssumeYes, "yes", "y", false, "Answer yes to confirmation prompts")
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/volumes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotContainerName string
+	snapshotStop          bool
+	rollbackContainerName string
+	rollbackSnapshotID    string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Copy a database's volume so it can be rolled back",
+	Long: `Copy a container's volume to XDG_DATA_HOME/mkdb/snapshots/<name>/<timestamp>.
+
+The copy is taken at the filesystem level, so the database must not be writing while
+it runs. Stop the container first, or pass --stop to stop it for the copy and start it
+again afterwards. Snapshots of a running database may not be usable.
+
+Restore a snapshot with 'mkdb rollback'.`,
+	Annotations: requiresDocker,
+	RunE:        runSnapshot,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:         "list",
+	Aliases:     []string{"ls"},
+	Short:       "List a database's snapshots",
+	Long:        `List the snapshots of a container's volume, newest first.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runSnapshotList,
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore a database's volume from a snapshot",
+	Long: `Replace a container's volume with a snapshot taken by 'mkdb snapshot'.
+
+A running container is stopped while its volume is replaced and started again afterwards.
+Everything written since the snapshot was taken is lost. The snapshot is kept, so it can
+be restored again.`,
+	Annotations: requiresDocker,
+	RunE:        runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+
+	snapshotCmd.Flags().StringVar(&snapshotContainerName, "name", "", "Container name (skips interactive selection)")
+	snapshotCmd.Flags().BoolVar(&snapshotStop, "stop", false, "Stop a running container while its volume is copied")
+	snapshotListCmd.Flags().StringVar(&snapshotContainerName, "name", "", "Container name (skips interactive selection)")
+	rollbackCmd.Flags().StringVar(&rollbackContainerName, "name", "", "Container name (skips interactive selection)")
+	rollbackCmd.Flags().StringVar(&rollbackSnapshotID, "snapshot", "", "Snapshot to restore (skips interactive selection)")
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) error {
+	container, err := selectVolumeContainer(snapshotContainerName, "Select container to snapshot")
+	if err != nil || container == nil {
+		return err
+	}
+
+	running := isDockerContainerRunning(container)
+	if running && !snapshotStop {
+		ui.Warning("The database is running, a filesystem-level snapshot of it may be inconsistent")
+		ui.Info("Stop it first or pass --stop to stop it while the volume is copied")
+
+		confirmed, err := ui.PromptConfirm(fmt.Sprintf("Snapshot '%s' while it is running?", container.DisplayName))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			ui.Info("Snapshot cancelled")
+			return nil
+		}
+	}
+
+	if running && snapshotStop {
+		ui.Info(fmt.Sprintf("Stopping '%s' for the snapshot...", container.DisplayName))
+		if err := docker.StopContainer(container.ContainerID, docker.DefaultStopTimeout); err != nil {
+			return fmt.Errorf("failed to stop container: %w", err)
+		}
+	}
+
+	ui.Info(fmt.Sprintf("Copying volume of '%s'...", container.DisplayName))
+	snapshot, err := volumes.CreateSnapshot(container.DisplayName, docker.HostVolumePath(container.VolumeType, container.VolumePath))
+
+	// Start the container again even if the copy failed
+	if running && snapshotStop {
+		if startErr := docker.StartContainer(container.ContainerID); startErr != nil {
+			ui.Warning(fmt.Sprintf("Failed to start container, run 'mkdb restart --name %s': %v", container.DisplayName, startErr))
+		} else {
+			restoreUsersAfterStart(container)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "snapshot_created",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Snapshot %s created (%s)", snapshot.ID, volumes.FormatSize(snapshot.Size)),
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+
+	ui.Success(fmt.Sprintf("Snapshot %s of '%s' created (%s)", snapshot.ID, container.DisplayName, volumes.FormatSize(snapshot.Size)))
+	return nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	container, err := selectVolumeContainer(snapshotContainerName, "Select container")
+	if err != nil || container == nil {
+		return err
+	}
+
+	snapshots, err := volumes.ListSnapshots(container.DisplayName)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		ui.Info(fmt.Sprintf("No snapshots of '%s', create one with 'mkdb snapshot'", container.DisplayName))
+		return nil
+	}
+
+	fmt.Printf("Snapshots of %s:\n", container.DisplayName)
+	for _, s := range snapshots {
+		fmt.Printf("  %s  %s  %s\n", s.ID, ui.FormatTime(s.CreatedAt), volumes.FormatSize(s.Size))
+	}
+	return nil
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	container, err := selectVolumeContainer(rollbackContainerName, "Select container to roll back")
+	if err != nil || container == nil {
+		return err
+	}
+
+	snapshots, err := volumes.ListSnapshots(container.DisplayName)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("container '%s' has no snapshots, create one with 'mkdb snapshot'", container.DisplayName)
+	}
+
+	var snapshot *volumes.Snapshot
+	if rollbackSnapshotID != "" {
+		for _, s := range snapshots {
+			if s.ID == rollbackSnapshotID {
+				snapshot = s
+				break
+			}
+		}
+		if snapshot == nil {
+			return fmt.Errorf("snapshot '%s' not found, see 'mkdb snapshot list --name %s'", rollbackSnapshotID, container.DisplayName)
+		}
+	} else {
+		snapshot, err = ui.SelectSnapshot(snapshots, "Select snapshot to restore")
+		if err != nil {
+			return fmt.Errorf("failed to select snapshot: %w", err)
+		}
+	}
+
+	ui.Warning("Everything written since the snapshot was taken will be lost")
+	confirmed, err := ui.PromptConfirm(fmt.Sprintf("Restore '%s' to snapshot %s?", container.DisplayName, snapshot.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	if !confirmed {
+		ui.Info("Rollback cancelled")
+		return nil
+	}
+
+	running := isDockerContainerRunning(container)
+	if running {
+		ui.Info(fmt.Sprintf("Stopping '%s'...", container.DisplayName))
+		if err := docker.StopContainer(container.ContainerID, docker.DefaultStopTimeout); err != nil {
+			return fmt.Errorf("failed to stop container: %w", err)
+		}
+	}
+
+	ui.Info(fmt.Sprintf("Restoring snapshot %s...", snapshot.ID))
+	restoreErr := volumes.RestoreSnapshot(snapshot, docker.HostVolumePath(container.VolumeType, container.VolumePath))
+
+	// Start the container again even if the restore failed, the volume is left untouched in that case
+	if running {
+		if err := docker.StartContainer(container.ContainerID); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to start container, run 'mkdb restart --name %s': %v", container.DisplayName, err))
+		} else {
+			restoreUsersAfterStart(container)
+		}
+	}
+	if restoreErr != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", restoreErr)
+	}
+
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "rolled_back",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Volume restored from snapshot %s", snapshot.ID),
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+
+	ui.Success(fmt.Sprintf("Container '%s' restored to snapshot %s", container.DisplayName, snapshot.ID))
+	if !running {
+		ui.Info(fmt.Sprintf("Start it with 'mkdb restart --name %s'", container.DisplayName))
+	}
+	return nil
+}
+
+// selectVolumeContainer looks up a container with a named or bind volume by name, or prompts for one if name is empty
+// Returns nil without an error if there are no such containers to choose from
+func selectVolumeContainer(name, label string) (*database.Container, error) {
+	if name != "" {
+		container, err := database.GetContainerByDisplayName(name)
+		if err != nil {
+			return nil, fmt.Errorf("container '%s' not found", name)
+		}
+		if container.VolumeType == types.VolumeTypeDocker {
+			return nil, fmt.Errorf("container '%s' uses a Docker volume, snapshots need a named volume or a host path", name)
+		}
+		if !hasVolume(container) {
+			return nil, fmt.Errorf("container '%s' has no volume to snapshot", name)
+		}
+		return container, nil
+	}
+
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var withVolume []*database.Container
+	for _, c := range containers {
+		if hasVolume(c) {
+			withVolume = append(withVolume, c)
+		}
+	}
+
+	if len(withVolume) == 0 {
+		ui.Warning("No containers with a volume found")
+		return nil, nil
+	}
+
+	container, err := ui.SelectContainer(withVolume, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select container: %w", err)
+	}
+	return container, nil
+}
+
+// hasVolume reports whether a container's data is stored in a volume on the host
+// Docker volumes are only reachable through Docker, so they don't count
+func hasVolume(c *database.Container) bool {
+	return c.VolumeType != "" && c.VolumeType != "none" && c.VolumeType != types.VolumeTypeDocker && c.VolumePath != ""
+}
+
+// isDockerContainerRunning reports whether a container's Docker container exists and is running
+func isDockerContainerRunning(c *database.Container) bool {
+	if c.ContainerID == "" {
+		return false
+	}
+	status, err := docker.GetContainerStatus(c.ContainerID)
+	return err == nil && status == "running"
+}
+
+// restoreUsersAfterStart restores in-memory users once a container stopped for a snapshot is running again
+func restoreUsersAfterStart(container *database.Container) {
+	username, password, err := defaultCredentials(container)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Users weren't restored: %v", err))
+		return
+	}
+	restoreUsers(container, username, password)
+}
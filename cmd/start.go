@@ -1,114 +1,372 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/pbzona/mkdb/internal/adapters"
 	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/credentials"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
 	"github.com/pbzona/mkdb/internal/types"
 	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/volumes"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	dbType     string
-	dbName     string
-	version    string
-	port       string
-	volumeFlag string
-	ttlHours   int
-	useRepeat  bool
-	noAuth     bool
+	dbType         string
+	dbName         string
+	version        string
+	port           string
+	volumeFlag     string
+	volumeDriver   string
+	ttlHours       int
+	useRepeat      bool
+	noAuth         bool
+	envKey         string
+	persistMode    string
+	startUser      string
+	portRange      string
+	cpuShares      int64
+	volumeReadOnly bool
+	dataTarget     string
+	restartPolicy  string
+	extraEnv       []string
+	envForce       bool
+	startDryRun    bool
+	bindIP         string
+	seedFiles      []string
+	seedStrict     bool
+	extraArgs      []string
+	networkName    string
+	createNetwork  bool
+	fromStopped    bool
+	credsFile      string
+	credsForce     bool
+	redisDB        int
+	noConfig       bool
+	maxConns       int
+	labels         []string
 )
 
+// defaultTTLHours is how long a database lives when neither --ttl nor the defaults file sets it
+const defaultTTLHours = 2
+
+// startRequest is what a database should be created with, taken from the start flags or a profile
+// Empty settings are filled in from the defaults file, last settings, and prompts
+type startRequest struct {
+	settings      config.LastSettings
+	envKey        string
+	username      string
+	portRange     string
+	ttlSet        bool   // Use settings.TTLHours instead of the per-type default
+	cpuSharesSet  bool   // Validate settings.CPUShares, which is otherwise Docker's default
+	noAuth        bool   // Create the database without authentication, if noAuthSet
+	noAuthSet     bool   // Skip the authentication prompt
+	defaultVolume string // Volume used when none is given or configured, empty to prompt
+	repeat        bool
+	envForce      bool
+	seedFiles     []string
+	seedStrict    bool
+	createNetwork bool // Create settings.Network if it doesn't exist
+}
+
+// startPlan is everything 'mkdb start' resolves from flags, defaults and prompts before creating anything
+type startPlan struct {
+	settings          *config.LastSettings
+	containerName     string
+	connEnvKey        string
+	nextPort          docker.PortPicker
+	opts              docker.ContainerOptions
+	adminPasswordHash string
+	seedFiles         []string
+	seedStrict        bool
+	createNetwork     bool // opts.Network doesn't exist yet and is created with the container
+}
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Create a new database container",
-	Long:  `Create and start a new database container with persistent volume storage.`,
-	RunE:  runStart,
+	Long: `Create and start a new database container with persistent volume storage.
+
+With --from-stopped, bring back a container removed by 'mkdb stop' using its stored type,
+version, volume, port and credentials instead of creating a new one. --ttl sets how long it
+lives from now, and the stored expiration is kept otherwise.`,
+	Annotations: requiresDocker,
+	RunE:        runStart,
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
-	startCmd.Flags().StringVar(&dbType, "db", "", "Database type (postgres, redis, mysql)")
+	startCmd.Flags().StringVar(&dbType, "db", "", "Database type ("+dbTypeList()+")")
 	startCmd.Flags().StringVar(&dbName, "name", "", "Database name")
 	startCmd.Flags().StringVar(&version, "version", "", "Database version (default: latest)")
 	startCmd.Flags().StringVar(&port, "port", "", "Host port to bind to")
-	startCmd.Flags().StringVar(&volumeFlag, "volume", "", "Volume path (optional)")
-	startCmd.Flags().IntVar(&ttlHours, "ttl", 2, "Time to live in hours")
+	startCmd.Flags().StringVar(&portRange, "port-range", "", "Range to pick a free host port from, e.g. 15432-15499")
+	startCmd.Flags().StringVar(&volumeFlag, "volume", "", "Volume: none, named, docker, docker:<volume>, or a host path (optional)")
+	startCmd.Flags().StringVar(&volumeDriver, "volume-driver", "", "Driver for a Docker volume, implies --volume docker (default: Docker's local driver)")
+	startCmd.Flags().IntVar(&ttlHours, "ttl", defaultTTLHours, "Time to live in hours")
 	startCmd.Flags().BoolVar(&useRepeat, "repeat", false, "Use settings from last database created")
 	startCmd.Flags().BoolVar(&noAuth, "no-auth", false, "Create database without authentication")
+	startCmd.Flags().StringVar(&envKey, "env-key", "", "Environment variable name for the connection string (default: DB_URL)")
+	startCmd.Flags().StringVar(&startUser, "username", "", "Username for the default user (default: dbuser)")
+	startCmd.Flags().Int64Var(&cpuShares, "cpu-shares", 0, "Relative CPU weight when the host is busy (2-262144, Docker default 1024)")
+	startCmd.Flags().StringVar(&persistMode, "persistence", "", "Redis persistence mode (none, rdb, aof)")
+	startCmd.Flags().IntVar(&maxConns, "max-connections", 0, "Connection limit written to the config file (postgres, mysql)")
+	startCmd.Flags().IntVar(&redisDB, "redis-db", 0, "Redis database number for the connection string and --seed files (0-15)")
+	startCmd.Flags().BoolVar(&volumeReadOnly, "volume-readonly", false, "Mount the volume read-only, e.g. for seed data with --data-target")
+	startCmd.Flags().StringVar(&restartPolicy, "restart", "", "Container restart policy (no, on-failure, always, unless-stopped; default: unless-stopped)")
+	startCmd.Flags().StringVar(&bindIP, "bind", "", "Host interface to publish the port on, e.g. 127.0.0.1 (default: 0.0.0.0)")
+	startCmd.Flags().StringArrayVar(&extraEnv, "env", nil, "Extra environment variable for the container as KEY=VALUE (repeatable)")
+	startCmd.Flags().StringArrayVar(&extraArgs, "arg", nil, "Extra server flag appended to the container's command, e.g. --arg=-c --arg=shared_buffers=256MB (repeatable)")
+	startCmd.Flags().StringArrayVar(&labels, "label", nil, "Docker label for the container as KEY=VALUE, e.g. for Traefik (repeatable)")
+	startCmd.Flags().BoolVar(&envForce, "env-force", false, "Allow --env to override variables mkdb manages, such as credentials")
+	startCmd.Flags().StringArrayVar(&seedFiles, "seed", nil, "SQL file (or Redis commands file) to run once the database is ready (repeatable, applied in order)")
+	startCmd.Flags().BoolVar(&seedStrict, "seed-strict", false, "Remove the new container if a seed file fails")
+	startCmd.Flags().StringVar(&networkName, "network", "", "Docker network to attach the container to, e.g. your app's compose network")
+	startCmd.Flags().BoolVar(&createNetwork, "create-network", false, "Create the --network network if it doesn't exist")
+	startCmd.Flags().BoolVar(&startDryRun, "dry-run", false, "Show the container that would be created without creating it")
+	startCmd.Flags().BoolVar(&noConfig, "no-config", false, "Don't mount a config file, keep the image's default configuration ('mkdb config' won't work)")
+	startCmd.Flags().StringVar(&dataTarget, "data-target", "", "Path to mount the volume at inside the container (default: the database's data directory)")
+	startCmd.Flags().BoolVar(&fromStopped, "from-stopped", false, "Recreate the stopped container given by --name from its stored settings")
+	startCmd.Flags().StringVar(&credsFile, "creds-file", "", "Write the credentials to this file (mode 0600) instead of printing them")
+	startCmd.Flags().BoolVar(&credsForce, "force", false, "Overwrite the --creds-file file if it exists")
 }
 
-func runStart(cmd *cobra.Command, args []string) error {
+// startRequestFromFlags collects the start flags into a startRequest
+func startRequestFromFlags(cmd *cobra.Command) startRequest {
+	return startRequest{
+		settings: config.LastSettings{
+			DBType:         dbType,
+			Name:           dbName,
+			Version:        version,
+			Port:           port,
+			VolumePath:     volumeFlag,
+			VolumeDriver:   volumeDriver,
+			TTLHours:       ttlHours,
+			Persistence:    persistMode,
+			CPUShares:      cpuShares,
+			VolumeReadOnly: volumeReadOnly,
+			DataTarget:     dataTarget,
+			RestartPolicy:  restartPolicy,
+			ExtraEnv:       extraEnv,
+			BindIP:         bindIP,
+			ExtraArgs:      extraArgs,
+			Network:        networkName,
+			RedisDB:        redisDB,
+			NoConfig:       noConfig,
+			MaxConnections: maxConns,
+			Labels:         labels,
+		},
+		envKey:        envKey,
+		username:      startUser,
+		portRange:     portRange,
+		ttlSet:        cmd.Flags().Changed("ttl"),
+		cpuSharesSet:  cmd.Flags().Changed("cpu-shares"),
+		noAuth:        noAuth,
+		noAuthSet:     cmd.Flags().Changed("no-auth"),
+		repeat:        useRepeat,
+		envForce:      envForce,
+		seedFiles:     seedFiles,
+		seedStrict:    seedStrict,
+		createNetwork: createNetwork,
+	}
+}
+
+// buildStartPlan validates a start request and resolves settings, the port, volume and credentials
+// Returns a nil plan if the user cancels
+func buildStartPlan(req startRequest) (*startPlan, error) {
 	var settings *config.LastSettings
+	var typeDefaults *config.Defaults
+
+	// Resolve the env var name and username up front so invalid values fail before creating anything
+	connEnvKey, err := resolveEnvKey(req.envKey)
+	if err != nil {
+		return nil, err
+	}
+	defaultUsername, err := resolveUsername(req.username)
+	if err != nil {
+		return nil, err
+	}
+	if req.settings.Name != "" {
+		if err := types.ValidateDBName(req.settings.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.settings.VolumeDriver != "" {
+		if req.settings.VolumePath == "" {
+			req.settings.VolumePath = types.VolumeTypeDocker
+		} else if _, ok := dockerVolumeFlag(req.settings.VolumePath); !ok {
+			return nil, fmt.Errorf("--volume-driver requires --volume docker or docker:<volume>")
+		}
+	}
+	if req.cpuSharesSet {
+		if err := docker.ValidateCPUShares(req.settings.CPUShares); err != nil {
+			return nil, err
+		}
+	}
+	for _, pair := range req.settings.ExtraEnv {
+		if _, _, err := docker.ParseEnvVar(pair); err != nil {
+			return nil, err
+		}
+	}
+	if err := docker.ValidateLabels(req.settings.Labels); err != nil {
+		return nil, err
+	}
+	var rangeStart, rangeEnd int
+	if req.portRange != "" {
+		if req.settings.Port != "" {
+			return nil, fmt.Errorf("--port and --port-range cannot be used together")
+		}
+		rangeStart, rangeEnd, err = docker.ParsePortRange(req.portRange)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Check if using repeat mode
-	if useRepeat {
+	if req.repeat {
 		lastSettings, err := config.LoadLastSettings()
 		if err != nil {
-			return fmt.Errorf("failed to load last settings: %w", err)
+			return nil, fmt.Errorf("failed to load last settings: %w", err)
 		}
 		if lastSettings == nil {
-			return fmt.Errorf("no previous settings found, create a database first")
+			return nil, fmt.Errorf("no previous settings found, create a database first")
 		}
 
 		// Confirm with user
 		ui.Info(fmt.Sprintf("Using previous settings: %s database '%s'", lastSettings.DBType, lastSettings.Name))
 		confirmed, err := ui.PromptConfirm("Continue with these settings?")
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if !confirmed {
 			ui.Info("Cancelled")
-			return nil
+			return nil, nil
 		}
 
 		settings = lastSettings
 	} else {
-		// Build settings from flags and prompts
-		settings = &config.LastSettings{
-			DBType:     dbType,
-			Name:       dbName,
-			Version:    version,
-			Port:       port,
-			VolumePath: volumeFlag,
-			TTLHours:   ttlHours,
+		// Build settings from the request and prompts
+		requested := req.settings
+		settings = &requested
+
+		// Fill in anything not set by flags from the defaults file
+		defaults, err := config.LoadDefaults()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load defaults: %w", err)
+		}
+		// The type may only be known after prompting, so per-type TTLs are applied below
+		if !req.ttlSet {
+			settings.TTLHours = 0
+			typeDefaults = defaults
+		}
+		if settings.VolumePath == "" && defaults.Volume != "" {
+			settings.VolumePath = defaults.Volume
+		}
+		if settings.VolumePath == "" {
+			settings.VolumePath = req.defaultVolume
+		}
+		if settings.RestartPolicy == "" {
+			settings.RestartPolicy = defaults.RestartPolicy
 		}
 
 		// Prompt for missing required fields
 		if err := promptForMissingFields(settings); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	// Use TTL from settings, or default if not set
-	if settings.TTLHours == 0 {
-		settings.TTLHours = 2
-	}
-
 	// Validate database type
 	normalizedType, err := types.NormalizeDBType(settings.DBType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	settings.DBType = normalizedType
 
+	// Use TTL from settings, then the defaults file for this type, then the global default
+	if typeDefaults != nil {
+		settings.TTLHours = typeDefaults.TTLHoursFor(settings.DBType)
+	}
+	if settings.TTLHours == 0 {
+		settings.TTLHours = defaultTTLHours
+	}
+
+	// Validate the restart policy, which may come from the defaults file or last settings
+	if settings.RestartPolicy != "" {
+		if err := docker.ValidateRestartPolicy(settings.RestartPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.BindIP != "" {
+		if err := docker.ValidateBindIP(settings.BindIP); err != nil {
+			return nil, err
+		}
+	}
+
+	// The network is only created along with the container, so a dry run leaves it alone
+	var networkMissing bool
+	if settings.Network != "" {
+		if err := docker.ValidateNetworkName(settings.Network); err != nil {
+			return nil, err
+		}
+		exists, err := docker.NetworkExists(settings.Network)
+		if err != nil {
+			return nil, err
+		}
+		if !exists && !req.createNetwork {
+			return nil, fmt.Errorf("network '%s' does not exist (use --create-network to create it)", settings.Network)
+		}
+		networkMissing = !exists
+	}
+
+	// Seed files are only taken from flags, even with --repeat
+	if err := docker.ValidateSeedFiles(settings.DBType, req.seedFiles); err != nil {
+		return nil, err
+	}
+
+	// Settings from --repeat were already checked when they were first used, including any forced overrides
+	if err := docker.ValidateExtraEnv(settings.DBType, settings.ExtraEnv, req.envForce || req.repeat); err != nil {
+		return nil, err
+	}
+
+	// Validate persistence mode before creating anything
+	if settings.Persistence != "" {
+		if err := docker.ValidatePersistence(settings.DBType, settings.Persistence); err != nil {
+			return nil, err
+		}
+	}
+	if settings.MaxConnections != 0 {
+		if err := docker.ValidateMaxConnections(settings.DBType, settings.MaxConnections, settings.NoConfig); err != nil {
+			return nil, err
+		}
+	}
+	if settings.RedisDB != 0 {
+		if err := docker.ValidateRedisDB(settings.DBType, settings.RedisDB); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get database configuration
 	dbConfig := docker.GetDBConfig(settings.DBType, settings.Version)
 
 	// Store the actual version that will be used (adapter provides default if empty)
 	if settings.Version == "" {
-		// Get the actual version from the image string (e.g., "postgres:18" -> "18")
-		imageParts := strings.Split(dbConfig.Image, ":")
-		if len(imageParts) == 2 {
-			settings.Version = imageParts[1]
+		// Get the actual version from the image tag (e.g., "postgres:18" -> "18"), the image
+		// may be overridden with a registry that includes a port
+		if i := strings.LastIndex(dbConfig.Image, ":"); i > strings.LastIndex(dbConfig.Image, "/") {
+			settings.Version = dbConfig.Image[i+1:]
 		}
 	}
 
@@ -117,35 +375,46 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	// Check if container already exists
 	if _, err := database.GetContainer(containerName); err == nil {
-		return fmt.Errorf("container with name '%s' already exists", settings.Name)
+		return nil, fmt.Errorf("container with name '%s' already exists", settings.Name)
 	}
 
 	// Determine port
+	// Automatically chosen ports can be retried if another process takes them before the container starts
 	hostPort := settings.Port
-	if hostPort == "" {
+	var nextPort docker.PortPicker
+	if req.portRange != "" {
+		// Pick the first free port in the requested range
+		hostPort, err = docker.FindAvailablePortInRange(rangeStart, rangeEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find available port: %w", err)
+		}
+		ui.Info(fmt.Sprintf("Using port %s", hostPort))
+		nextPort = docker.NextPortInRange(rangeEnd)
+	} else if hostPort == "" {
 		// No port specified, use default and find next available if needed
 		hostPort = dbConfig.DefaultPort
 		available, err := docker.IsPortAvailable(hostPort)
 		if err != nil {
-			return fmt.Errorf("failed to check port availability: %w", err)
+			return nil, fmt.Errorf("failed to check port availability: %w", err)
 		}
 		if !available {
 			// Default port is taken, find next available
 			ui.Warning(fmt.Sprintf("Default port %s is in use, finding next available port...", hostPort))
 			hostPort, err = docker.FindAvailablePort(hostPort)
 			if err != nil {
-				return fmt.Errorf("failed to find available port: %w", err)
+				return nil, fmt.Errorf("failed to find available port: %w", err)
 			}
 			ui.Info(fmt.Sprintf("Using port %s", hostPort))
 		}
+		nextPort = docker.NextPort
 	} else {
 		// User specified a port, check if it's available
 		available, err := docker.IsPortAvailable(hostPort)
 		if err != nil {
-			return fmt.Errorf("failed to check port availability: %w", err)
+			return nil, fmt.Errorf("failed to check port availability: %w", err)
 		}
 		if !available {
-			return fmt.Errorf("port %s is already in use (use default port for automatic selection)", hostPort)
+			return nil, fmt.Errorf("port %s is already in use (use default port for automatic selection)", hostPort)
 		}
 	}
 
@@ -166,22 +435,29 @@ func runStart(cmd *cobra.Command, args []string) error {
 			volumeType = "named"
 			volumePath = settings.Name
 			settings.VolumeType = volumeType
-			// Create the volume directory
-			volumeDir := filepath.Join(config.VolumesDir, settings.Name)
-			if err := os.MkdirAll(volumeDir, 0755); err != nil {
-				return fmt.Errorf("failed to create volume directory: %w", err)
-			}
 		default:
+			if name, ok := dockerVolumeFlag(settings.VolumePath); ok {
+				volumeType = types.VolumeTypeDocker
+				volumePath = name
+				if name == "" {
+					// mkdb creates a volume named like the container, the name is picked again on --repeat
+					volumePath = "mkdb-" + settings.Name
+				} else if err := docker.ValidateVolumeName(name); err != nil {
+					return nil, err
+				}
+				settings.VolumeType = volumeType
+				settings.VolumePath = name
+				break
+			}
+
 			// Custom path
 			volumeType = "bind"
-			volumePath = settings.VolumePath
-			settings.VolumeType = volumeType
-			// Validate path
-			if _, err := os.Stat(volumePath); os.IsNotExist(err) {
-				if err := os.MkdirAll(volumePath, 0755); err != nil {
-					return fmt.Errorf("failed to create volume directory: %w", err)
-				}
+			volumePath, err = volumes.ResolveBindPath(settings.VolumePath)
+			if err != nil {
+				return nil, err
 			}
+			settings.VolumeType = volumeType
+			settings.VolumePath = volumePath
 		}
 	} else if settings.VolumeType != "" {
 		// Volume type from repeat settings
@@ -190,16 +466,15 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 		if volumeType == "named" && volumePath == "" {
 			volumePath = settings.Name
-			volumeDir := filepath.Join(config.VolumesDir, settings.Name)
-			if err := os.MkdirAll(volumeDir, 0755); err != nil {
-				return fmt.Errorf("failed to create volume directory: %w", err)
-			}
+		}
+		if volumeType == types.VolumeTypeDocker && volumePath == "" {
+			volumePath = "mkdb-" + settings.Name
 		}
 	} else {
 		// Prompt for volume configuration
 		volumeOption, err := ui.SelectVolumeOption()
 		if err != nil {
-			return fmt.Errorf("failed to select volume option: %w", err)
+			return nil, fmt.Errorf("failed to select volume option: %w", err)
 		}
 
 		switch volumeOption {
@@ -208,53 +483,67 @@ func runStart(cmd *cobra.Command, args []string) error {
 			volumePath = settings.Name
 			settings.VolumeType = volumeType
 			settings.VolumePath = volumePath
-			// Create the volume directory
-			volumeDir := filepath.Join(config.VolumesDir, settings.Name)
-			if err := os.MkdirAll(volumeDir, 0755); err != nil {
-				return fmt.Errorf("failed to create volume directory: %w", err)
-			}
 		case "custom path":
 			volumeType = "bind"
 			volumePath, err = ui.PromptString("Enter volume path", "")
 			if err != nil {
-				return fmt.Errorf("failed to get volume path: %w", err)
+				return nil, fmt.Errorf("failed to get volume path: %w", err)
+			}
+			volumePath, err = volumes.ResolveBindPath(volumePath)
+			if err != nil {
+				return nil, err
 			}
 			settings.VolumeType = volumeType
 			settings.VolumePath = volumePath
-			// Validate path
-			if _, err := os.Stat(volumePath); os.IsNotExist(err) {
-				if err := os.MkdirAll(volumePath, 0755); err != nil {
-					return fmt.Errorf("failed to create volume directory: %w", err)
-				}
-			}
 		default:
 			settings.VolumeType = "none"
 			settings.VolumePath = ""
 		}
 	}
 
+	if settings.VolumeReadOnly || settings.DataTarget != "" {
+		if volumeType == "" || volumeType == "none" {
+			return nil, fmt.Errorf("--volume-readonly and --data-target require a volume")
+		}
+		if settings.DataTarget != "" {
+			if err := docker.ValidateDataTarget(settings.DBType, settings.DataTarget); err != nil {
+				return nil, err
+			}
+		}
+		if settings.VolumeReadOnly && docker.DataTargetIsWritable(settings.DBType, settings.DataTarget) {
+			ui.Warning(fmt.Sprintf("%s writes to its data directory and will likely fail to start with a read-only volume, use --data-target to mount it elsewhere", settings.DBType))
+		}
+	}
+
 	// Determine credentials based on --no-auth flag or prompt
 	var username, password string
 
-	// Check if --no-auth flag was explicitly set
-	noAuthFlagSet := cmd.Flags().Changed("no-auth")
+	// Some images can only run without authentication, so there's nothing to ask
+	if reason := docker.UnauthenticatedReason(settings.DBType); reason != "" {
+		if req.noAuthSet && !req.noAuth {
+			ui.Warning(reason)
+		} else if !req.noAuthSet {
+			ui.Info(reason)
+		}
+		req.noAuth, req.noAuthSet = true, true
+	}
 
-	if noAuthFlagSet && noAuth {
+	if req.noAuthSet && req.noAuth {
 		// Flag explicitly set to true - no authentication
 		username = ""
 		password = ""
-	} else if !noAuthFlagSet {
+	} else if !req.noAuthSet {
 		// Flag not set, prompt user
 		useAuth, err := ui.PromptConfirm("Enable authentication? (recommended)")
 		if err != nil {
-			return fmt.Errorf("failed to get authentication preference: %w", err)
+			return nil, fmt.Errorf("failed to get authentication preference: %w", err)
 		}
 		if useAuth {
 			// Generate random password
-			username = credentials.DefaultUsername
+			username = defaultUsername
 			password, err = credentials.GeneratePassword(12)
 			if err != nil {
-				return fmt.Errorf("failed to generate password: %w", err)
+				return nil, fmt.Errorf("failed to generate password: %w", err)
 			}
 		} else {
 			username = ""
@@ -262,10 +551,205 @@ func runStart(cmd *cobra.Command, args []string) error {
 		}
 	} else {
 		// Flag explicitly set to false - use authentication with random password
-		username = credentials.DefaultUsername
+		username = defaultUsername
 		password, err = credentials.GeneratePassword(12)
 		if err != nil {
-			return fmt.Errorf("failed to generate password: %w", err)
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+	}
+
+	// A volume kept by 'mkdb rm --keep-volume' was initialized with the removed container's credentials,
+	// new ones would be ignored by the database, so the stored ones are used again
+	kept, err := credentials.KeptCredentials(containerName, settings.DBType, volumeType, volumePath)
+	if err != nil {
+		return nil, fmt.Errorf("the volume belongs to the removed '%s', whose credentials can't be read (%w). Use a different name or volume to start with new credentials", settings.Name, err)
+	}
+	if kept != nil {
+		if kept.Username != username && kept.Username != "" && username != "" {
+			ui.Warning(fmt.Sprintf("Using the username '%s' the volume was created with instead of '%s'", kept.Username, username))
+		}
+		ui.Info(fmt.Sprintf("Reusing the credentials of the removed '%s', whose volume this is", settings.Name))
+		username, password = kept.Username, kept.Password
+	}
+
+	if username != "" {
+		username = passwordUsername(settings.DBType, username)
+	}
+
+	// Databases with a separate administrative account get their own random password
+	var adminPassword, adminPasswordHash string
+	if kept != nil {
+		// Containers from before admin passwords were generated keep using the legacy one
+		adminPassword, adminPasswordHash = kept.AdminPassword, kept.AdminPasswordHash
+	} else if password != "" && docker.HasAdminPassword(settings.DBType) {
+		adminPassword, err = credentials.GeneratePassword(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate admin password: %w", err)
+		}
+		adminPasswordHash, err = config.Encrypt(adminPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt admin password: %w", err)
+		}
+	}
+
+	return &startPlan{
+		settings:          settings,
+		containerName:     containerName,
+		connEnvKey:        connEnvKey,
+		nextPort:          nextPort,
+		adminPasswordHash: adminPasswordHash,
+		seedFiles:         req.seedFiles,
+		seedStrict:        req.seedStrict,
+		createNetwork:     networkMissing,
+		opts: docker.ContainerOptions{
+			DBType:         settings.DBType,
+			DisplayName:    settings.Name,
+			Username:       username,
+			Password:       password,
+			Port:           hostPort,
+			VolumeType:     volumeType,
+			VolumePath:     volumePath,
+			VolumeDriver:   settings.VolumeDriver,
+			Version:        settings.Version,
+			Persistence:    settings.Persistence,
+			CPUShares:      settings.CPUShares,
+			VolumeReadOnly: settings.VolumeReadOnly,
+			DataTarget:     settings.DataTarget,
+			RestartPolicy:  settings.RestartPolicy,
+			ExtraEnv:       settings.ExtraEnv,
+			AdminPassword:  adminPassword,
+			BindIP:         settings.BindIP,
+			ExtraArgs:      settings.ExtraArgs,
+			Network:        settings.Network,
+			NoConfig:       settings.NoConfig,
+			MaxConnections: settings.MaxConnections,
+			Labels:         settings.Labels,
+		},
+	}, nil
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	if fromStopped {
+		return runStartFromStopped(cmd)
+	}
+
+	// Check the credentials file before anything is created, so its contents can't be lost
+	if credsFile != "" {
+		if _, err := os.Stat(credsFile); err == nil && !credsForce {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", credsFile)
+		}
+	}
+
+	plan, err := buildStartPlan(startRequestFromFlags(cmd))
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return nil
+	}
+
+	if startDryRun {
+		return printStartPlan(plan)
+	}
+
+	container, err := createFromPlan(plan)
+	if err != nil {
+		return err
+	}
+	settings := plan.settings
+
+	// Save settings for next time
+	if err := config.SaveLastSettings(settings); err != nil {
+		config.Logger.Warn("Failed to save last settings", "error", err)
+	}
+
+	ui.Success(fmt.Sprintf("Database '%s' created successfully!", settings.Name))
+
+	connStr := planConnectionString(plan, container)
+	if credsFile != "" {
+		err := credentials.WriteCredsFile(credsFile, credentials.Creds{
+			Name:             container.DisplayName,
+			Type:             container.Type,
+			Host:             credentials.ConnectionHost(),
+			Port:             container.Port,
+			Username:         plan.opts.Username,
+			Password:         plan.opts.Password,
+			ConnectionString: connStr,
+			EnvKey:           plan.connEnvKey,
+		})
+		if err == nil {
+			ui.Info(fmt.Sprintf("Credentials written to %s", credsFile))
+		} else {
+			// Fall back to printing them
+			ui.Warning(fmt.Sprintf("Failed to write credentials file: %v", err))
+			credsFile = ""
+		}
+	}
+	if credsFile == "" {
+		ui.Newline()
+		fmt.Println(credentials.FormatEnvVar(plan.connEnvKey, connStr))
+		ui.Newline()
+	}
+
+	ttlMsg := fmt.Sprintf("Database will expire in %d hours (at %s)", settings.TTLHours, ui.FormatTime(container.ExpiresAt))
+	if settings.TTLHours == 1 {
+		ttlMsg = fmt.Sprintf("Database will expire in 1 hour (at %s)", ui.FormatTime(container.ExpiresAt))
+	}
+	ui.Info(ttlMsg)
+	ui.Info("Use 'mkdb start --repeat' to quickly create another database with the same settings")
+
+	return nil
+}
+
+// errStartInterrupted is returned when Ctrl-C aborts a start before the container was recorded
+var errStartInterrupted = errors.New("interrupted")
+
+// createFromPlan creates the volume directory, container and records for a plan, then seeds the database
+// The plan's settings are updated if the container ends up on a different port
+func createFromPlan(plan *startPlan) (*database.Container, error) {
+	var err error
+	settings := plan.settings
+	containerOpts := plan.opts
+	username, password, hostPort := containerOpts.Username, containerOpts.Password, containerOpts.Port
+	volumeType, volumePath := containerOpts.VolumeType, containerOpts.VolumePath
+
+	// Until the container is recorded, Ctrl-C removes what was created instead of leaving it untracked
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Create the volume directory, remembering whether it's new so a failed start can remove it
+	var createdVolumeDir string
+	if volumeType == "named" || volumeType == "bind" {
+		dir := docker.HostVolumePath(volumeType, volumePath)
+		if _, statErr := os.Stat(dir); os.IsNotExist(statErr) {
+			createdVolumeDir = dir
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create volume directory: %w", err)
+		}
+	}
+	// Until the container is recorded, any error or Ctrl-C removes the new volume directory again
+	recorded := false
+	defer func() {
+		if recorded || createdVolumeDir == "" {
+			return
+		}
+		if err := os.RemoveAll(createdVolumeDir); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to remove volume directory %s: %v", createdVolumeDir, err))
+		}
+	}()
+	// interrupted describes a start aborted by Ctrl-C, cause is set if the container couldn't be removed
+	interrupted := func(cause error) error {
+		if cause != nil {
+			return fmt.Errorf("%w: %v", errStartInterrupted, cause)
+		}
+		return fmt.Errorf("%w, the container for '%s' was not created", errStartInterrupted, settings.Name)
+	}
+
+	if plan.createNetwork {
+		ui.Info(fmt.Sprintf("Creating network '%s'...", containerOpts.Network))
+		if err := docker.EnsureNetwork(containerOpts.Network); err != nil {
+			return nil, err
 		}
 	}
 
@@ -275,19 +759,36 @@ func runStart(cmd *cobra.Command, args []string) error {
 		ui.Info("Creating database without authentication")
 	}
 
+	// Encrypt first so the container, its default user and the created event are recorded together
+	var passwordHash string
+	if password != "" {
+		passwordHash, err = config.Encrypt(password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt password: %w", err)
+		}
+	}
+
 	// Create container
-	containerID, err := docker.CreateContainer(
-		settings.DBType,
-		settings.Name,
-		username,
-		password,
-		hostPort,
-		volumeType,
-		volumePath,
-		settings.Version,
-	)
+	var containerID string
+	if plan.nextPort != nil {
+		var boundPort string
+		containerID, boundPort, err = docker.CreateContainerRetryPort(ctx, containerOpts, docker.DefaultPortRetries, plan.nextPort)
+		if err == nil && boundPort != hostPort {
+			ui.Warning(fmt.Sprintf("Port %s was taken while creating the container, using port %s instead", hostPort, boundPort))
+			hostPort = boundPort
+			settings.Port = boundPort
+		}
+	} else {
+		containerID, err = docker.CreateContainer(ctx, containerOpts)
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, interrupted(nil)
+	}
+	if errors.Is(err, docker.ErrPortInUse) && plan.nextPort == nil {
+		return nil, fmt.Errorf("port %s was taken while creating the container, choose another with --port: %w", hostPort, err)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create container: %w", err)
+		return nil, fmt.Errorf("failed to create container: %w", err)
 	}
 
 	// Store in database
@@ -295,102 +796,390 @@ func runStart(cmd *cobra.Command, args []string) error {
 	expiresAt := now.Add(time.Duration(settings.TTLHours) * time.Hour)
 
 	container := &database.Container{
-		Name:        containerName,
-		DisplayName: settings.Name,
-		Type:        settings.DBType,
-		Version:     settings.Version,
-		ContainerID: containerID,
-		Port:        hostPort,
-		Status:      "running",
-		CreatedAt:   now,
-		ExpiresAt:   expiresAt,
-		VolumeType:  volumeType,
-		VolumePath:  volumePath,
-	}
-
-	if err := database.CreateContainer(container); err != nil {
-		// Try to clean up the Docker container
-		docker.RemoveContainer(containerID)
-		return fmt.Errorf("failed to store container in database: %w", err)
+		Name:              plan.containerName,
+		DisplayName:       settings.Name,
+		Type:              settings.DBType,
+		Version:           settings.Version,
+		ContainerID:       containerID,
+		Port:              hostPort,
+		Status:            "running",
+		CreatedAt:         now,
+		ExpiresAt:         expiresAt,
+		VolumeType:        volumeType,
+		VolumePath:        volumePath,
+		VolumeDriver:      containerOpts.VolumeDriver,
+		Persistence:       settings.Persistence,
+		CPUShares:         settings.CPUShares,
+		VolumeReadOnly:    settings.VolumeReadOnly,
+		DataTarget:        settings.DataTarget,
+		RestartPolicy:     settings.RestartPolicy,
+		ExtraEnv:          settings.ExtraEnv,
+		AdminPasswordHash: plan.adminPasswordHash,
+		BindIP:            settings.BindIP,
+		ExtraArgs:         settings.ExtraArgs,
+		Network:           settings.Network,
+		RedisDB:           settings.RedisDB,
+		NoConfig:          settings.NoConfig,
+		Labels:            settings.Labels,
 	}
 
 	// Create default user (or unauthenticated entry if no auth)
-	var passwordHash string
-	if !noAuth {
-		passwordHash, err = config.Encrypt(password)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt password: %w", err)
-		}
-	}
-
 	user := &database.User{
-		ContainerID:  container.ID,
 		Username:     username,
 		PasswordHash: passwordHash,
 		IsDefault:    true,
 		CreatedAt:    now,
 	}
+	event := &database.Event{
+		EventType: "created",
+		Timestamp: now,
+		Details:   fmt.Sprintf("Container created with %s:%s", settings.DBType, settings.Version),
+	}
+
+	// The container is removed again if this fails or Ctrl-C was pressed after it was created
+	if err := docker.RecordCreated(ctx, containerID, func() error {
+		return database.CreateContainerRecords(container, []*database.User{user}, nil, []*database.Event{event})
+	}); err != nil {
+		if ctx.Err() != nil {
+			if err == ctx.Err() {
+				err = nil // Removed cleanly
+			}
+			return nil, interrupted(err)
+		}
+		return nil, fmt.Errorf("failed to store container in database: %w", err)
+	}
+	// The container is tracked from here on, so Ctrl-C exits as usual and 'mkdb rm' cleans up
+	recorded = true
+	stop()
+
+	if len(plan.seedFiles) > 0 {
+		if err := seedContainer(container, username, password, plan.seedFiles); err != nil {
+			if plan.seedStrict {
+				// Roll back like 'mkdb rm', leaving the volume for inspection
+				// If the container can't be removed, it stays tracked so 'mkdb rm' can remove it
+				if rmErr := docker.RemoveContainer(containerID); rmErr != nil {
+					return nil, fmt.Errorf("%w (container '%s' is still running and couldn't be removed, remove it with 'mkdb rm --keep-volume --name %s': %v)", err, settings.Name, settings.Name, rmErr)
+				}
+				if markErr := database.MarkContainerRemoved(container.ID); markErr != nil {
+					config.Logger.Warn("Failed to mark container removed", "error", markErr)
+				}
+				return nil, fmt.Errorf("%w (container '%s' was removed)", err, settings.Name)
+			}
+			ui.Warning(fmt.Sprintf("Seeding failed: %v", err))
+		}
+	}
+
+	if container.Version == latestTag {
+		pinLatestVersion(container, username, password)
+	}
+
+	return container, nil
+}
+
+// passwordUsername returns the user a database's password is set for, which is username unless
+// the database sets it for a built-in user whatever username was requested
+func passwordUsername(dbType, username string) string {
+	switch {
+	case dbType == "redis":
+		// The Redis password belongs to its built-in ACL user
+		return adapters.RedisDefaultUser
+	case adapters.IsElasticsearch(dbType):
+		// Elasticsearch's password is likewise set for its built-in superuser
+		return adapters.ElasticsearchDefaultUser
+	}
+	return username
+}
+
+// latestTag is the floating image tag that pinLatestVersion resolves
+const latestTag = "latest"
+
+// pinLatestVersion records the version a container started from the latest tag actually runs,
+// so list and info show it without asking the container. The tag itself is kept as the version,
+// since the server's version isn't necessarily an image tag
+func pinLatestVersion(container *database.Container, username, password string) {
+	ui.Info("Resolving the version behind 'latest'...")
+	if err := docker.WaitForReady(container.ContainerID, container.Type, username, password, container.DisplayName, docker.DefaultReadyTimeout); err != nil {
+		ui.Warning(fmt.Sprintf("Couldn't resolve the version, showing '%s': %v", latestTag, err))
+		return
+	}
+	version, err := docker.GetActualVersion(container.ContainerID, container.Type)
+	if err != nil || version == "" {
+		config.Logger.Warn("Failed to detect version", "container", container.DisplayName, "error", err)
+		return
+	}
+
+	container.ServerVersion = version
+	if err := database.UpdateContainer(container); err != nil {
+		config.Logger.Warn("Failed to save resolved version", "container", container.DisplayName, "error", err)
+		container.ServerVersion = ""
+	}
+}
+
+// planConnectionString returns the connection string for the default user of a container created from plan
+func planConnectionString(plan *startPlan, container *database.Container) string {
+	return credentials.FormatConnectionString(
+		container.Type,
+		plan.opts.Username,
+		plan.opts.Password,
+		credentials.ConnectionHost(),
+		container.Port,
+		connectionDBName(container),
+	)
+}
+
+// fromStoppedFlags are the start flags that apply to --from-stopped, the rest are stored with the container
+var fromStoppedFlags = []string{"from-stopped", "name", "ttl", "env-key"}
+
+// runStartFromStopped recreates a stopped container from its record, like 'mkdb restart'
+func runStartFromStopped(cmd *cobra.Command) error {
+	var ignored []string
+	cmd.LocalNonPersistentFlags().Visit(func(f *pflag.Flag) {
+		if !slices.Contains(fromStoppedFlags, f.Name) {
+			ignored = append(ignored, "--"+f.Name)
+		}
+	})
+	if len(ignored) > 0 {
+		return fmt.Errorf("%s can't be used with --from-stopped, the container's stored settings are used", strings.Join(ignored, ", "))
+	}
+	if dbName == "" {
+		return fmt.Errorf("--from-stopped requires --name")
+	}
+	connEnvKey, err := resolveEnvKey(envKey)
+	if err != nil {
+		return err
+	}
+
+	container, err := database.GetContainerByDisplayName(dbName)
+	if err != nil {
+		return fmt.Errorf("container '%s' not found", dbName)
+	}
+	switch container.Status {
+	case "stopped":
+	case "running":
+		return fmt.Errorf("container '%s' is already running", dbName)
+	default:
+		return fmt.Errorf("container '%s' is %s and can't be started, create a new one instead", dbName, container.Status)
+	}
+
+	ui.Info(fmt.Sprintf("Starting %s database '%s' from its stored settings...", container.Type, container.DisplayName))
+
+	var username, password string
+	if container.ContainerID != "" && docker.ContainerExists(container.ContainerID) {
+		if err := docker.StartContainer(container.ContainerID); err != nil {
+			return fmt.Errorf("failed to start container: %w", err)
+		}
+		username, password, err = defaultCredentials(container)
+		if err != nil {
+			return err
+		}
+		restoreUsers(container, username, password)
+	} else {
+		if err := checkRecreatable(container); err != nil {
+			return err
+		}
+
+		// Like a new database, take the next free port if the stored one was claimed while it was stopped
+		port, err := docker.ReclaimPort(container.Port, true, container.Name, container.ContainerID)
+		if err != nil {
+			return err
+		}
+		if port != container.Port {
+			ui.Warning(fmt.Sprintf("Port %s is in use, using port %s instead", container.Port, port))
+			container.Port = port
+		}
+
+		// Warn rather than refuse, since the container is being recreated with the version it had
+		if err := checkDataDirVersion(container, container.Version); err != nil {
+			ui.Warning(err.Error())
+		}
+
+		username, password, err = recreateContainer(container)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	if err := setFromStoppedExpiry(container, cmd.Flags().Changed("ttl"), ttlHours, now); err != nil {
+		return err
+	}
 
-	if err := database.CreateUser(user); err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+	container.Status = "running"
+	if err := database.UpdateContainer(container); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
 	}
 
-	// Log event
 	event := &database.Event{
 		ContainerID: container.ID,
-		EventType:   "created",
+		EventType:   "restarted",
 		Timestamp:   now,
-		Details:     fmt.Sprintf("Container created with %s:%s", settings.DBType, settings.Version),
+		Details:     "Container recreated from stored settings with 'mkdb start --from-stopped'",
 	}
 	database.CreateEvent(event)
 
-	// Save settings for next time
-	if err := config.SaveLastSettings(settings); err != nil {
-		config.Logger.Warn("Failed to save last settings", "error", err)
+	connStr := credentials.FormatConnectionString(container.Type, username, password, credentials.ConnectionHost(), container.Port, connectionDBName(container))
+
+	ui.Success(fmt.Sprintf("Database '%s' started successfully!", container.DisplayName))
+
+	ui.Newline()
+	fmt.Println(credentials.FormatEnvVar(connEnvKey, connStr))
+	ui.Newline()
+
+	ui.Info(fmt.Sprintf("Database will expire at %s", ui.FormatTime(container.ExpiresAt)))
+	return nil
+}
+
+// setFromStoppedExpiry sets when a container started with --from-stopped expires
+// An explicit --ttl unpins a pinned container, like 'mkdb extend'. Without it a pinned container
+// stays pinned, and an expired one gets the default TTL so it isn't cleaned up as soon as it's running
+func setFromStoppedExpiry(container *database.Container, ttlSet bool, hours int, now time.Time) error {
+	switch {
+	case ttlSet:
+		if container.Pinned {
+			ui.Info("Container is pinned, unpinning it so it expires after --ttl")
+			container.Pinned = false
+		}
+		container.ExpiresAt = now.Add(time.Duration(hours) * time.Hour)
+	case container.Pinned:
+	case now.After(container.ExpiresAt):
+		defaults, err := config.LoadDefaults()
+		if err != nil {
+			return fmt.Errorf("failed to load defaults: %w", err)
+		}
+		hours := defaults.TTLHoursFor(container.Type)
+		if hours == 0 {
+			hours = defaultTTLHours
+		}
+		ui.Info(fmt.Sprintf("Container had expired, it will now expire in %d hour(s)", hours))
+		container.ExpiresAt = now.Add(time.Duration(hours) * time.Hour)
 	}
+	return nil
+}
 
-	ui.Success(fmt.Sprintf("Database '%s' created successfully!", settings.Name))
+// seedContainer waits for a new database to accept connections, then runs each seed file against it in order
+func seedContainer(container *database.Container, username, password string, files []string) error {
+	ui.Info("Waiting for the database to accept connections...")
+	if err := docker.WaitForReady(container.ContainerID, container.Type, username, password, container.DisplayName, docker.DefaultReadyTimeout); err != nil {
+		return err
+	}
 
-	// Display connection string
-	// For Redis, use database number "0" instead of container name
-	dbIdentifier := settings.Name
-	if settings.DBType == "redis" {
-		dbIdentifier = "0"
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
 	}
 
-	connStr := credentials.FormatConnectionString(
-		settings.DBType,
-		username,
-		password,
-		"localhost",
-		hostPort,
-		dbIdentifier,
-	)
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open seed file: %w", err)
+		}
+		err = docker.SeedDatabase(container.ContainerID, container.Type, connectionDBName(container), adminPassword, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to seed from %s: %w", path, err)
+		}
+		ui.Info(fmt.Sprintf("Seeded from %s", path))
+	}
 
-	fmt.Println()
-	fmt.Println(credentials.FormatEnvVar(connStr))
-	fmt.Println()
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "seeded",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Seeded from %s", strings.Join(files, ", ")),
+	}
+	database.CreateEvent(event)
+	return nil
+}
 
-	ttlMsg := fmt.Sprintf("Database will expire in %d hours (at %s)", settings.TTLHours, expiresAt.Format("2006-01-02 15:04:05"))
-	if settings.TTLHours == 1 {
-		ttlMsg = fmt.Sprintf("Database will expire in 1 hour (at %s)", expiresAt.Format("2006-01-02 15:04:05"))
+// printStartPlan shows what 'mkdb start' would create, with credentials masked
+func printStartPlan(plan *startPlan) error {
+	containerPlan, err := docker.PlanContainer(plan.opts)
+	if err != nil {
+		return err
 	}
-	ui.Info(ttlMsg)
-	ui.Info("Use 'mkdb start --repeat' to quickly create another database with the same settings")
 
+	ui.Header(fmt.Sprintf("Dry run: %s database '%s'", plan.settings.DBType, plan.settings.Name))
+	fmt.Printf("  Container: %s\n", plan.containerName)
+	fmt.Printf("  Image:     %s\n", containerPlan.Image)
+	fmt.Printf("  Port:      %s\n", containerPlan.PortBinding)
+	fmt.Printf("  Restart:   %s\n", containerPlan.RestartPolicy)
+	if containerPlan.Network != "" {
+		network := containerPlan.Network
+		if plan.createNetwork {
+			network += " (will be created)"
+		}
+		fmt.Printf("  Network:   %s\n", network)
+	}
+	fmt.Printf("  TTL:       %d hour(s)\n", plan.settings.TTLHours)
+	printPlanList("Environment", containerPlan.Env)
+	printPlanList("Labels", containerPlan.Labels)
+	printPlanList("Mounts", containerPlan.Mounts)
+	printPlanList("Command", containerPlan.Command)
+	printPlanList("Seed files", plan.seedFiles)
+	ui.Newline()
+
+	ui.Info("Dry run, no container was created")
 	return nil
 }
 
+func printPlanList(label string, values []string) {
+	if len(values) == 0 {
+		fmt.Printf("  %s: none\n", label)
+		return
+	}
+	fmt.Printf("  %s:\n", label)
+	for _, value := range values {
+		fmt.Printf("    %s\n", value)
+	}
+}
+
+// leftoverVolumeType guesses the database type from data already in the volume the container will use
+// Only a named volume for a name given on the command line, or a --volume path, can be checked before prompting
+func leftoverVolumeType(settings *config.LastSettings) (string, float64) {
+	if _, ok := dockerVolumeFlag(settings.VolumePath); ok {
+		return "", 0
+	}
+	switch settings.VolumePath {
+	case "none":
+		return "", 0
+	case "", "named":
+		if settings.Name == "" {
+			return "", 0
+		}
+		return volumes.DetectType(docker.HostVolumePath("named", settings.Name))
+	default:
+		return volumes.DetectType(settings.VolumePath)
+	}
+}
+
+// dockerVolumeFlag parses a --volume value for a Docker volume, "docker" for one mkdb names after
+// the container or "docker:<volume>" for a given volume. The name is empty for "docker"
+func dockerVolumeFlag(value string) (string, bool) {
+	if value == types.VolumeTypeDocker {
+		return "", true
+	}
+	return strings.CutPrefix(value, types.VolumeTypeDocker+":")
+}
+
 func promptForMissingFields(settings *config.LastSettings) error {
 	// Prompt for database type if not provided
 	if settings.DBType == "" {
-		// Check if we have last settings to offer as default
-		lastSettings, _ := config.LoadLastSettings()
-		if lastSettings != nil && config.HasLastSettings() {
+		// Start on the preferred type from the defaults file, falling back to the last used type
+		defaultType := ""
+		if defaults, err := config.LoadDefaults(); err == nil && defaults.DBType != "" {
+			defaultType = defaults.DBType
+		} else if lastSettings, _ := config.LoadLastSettings(); lastSettings != nil {
+			defaultType = lastSettings.DBType
 			ui.Info(fmt.Sprintf("Last used: %s (press Enter to use, or select different type)", lastSettings.DBType))
 		}
 
-		dbType, err := ui.SelectDBType()
+		// The container would reattach a leftover volume, so start on the type that wrote it
+		if detected, confidence := leftoverVolumeType(settings); detected != "" {
+			defaultType = detected
+			ui.Info(fmt.Sprintf("The volume already has data that looks like %s (%.0f%% confident)", detected, confidence*100))
+		}
+
+		dbType, err := ui.SelectDBType(defaultType)
 		if err != nil {
 			return fmt.Errorf("failed to select database type: %w", err)
 		}
@@ -403,8 +1192,8 @@ func promptForMissingFields(settings *config.LastSettings) error {
 		if err != nil {
 			return fmt.Errorf("failed to get database name: %w", err)
 		}
-		if name == "" {
-			return fmt.Errorf("database name cannot be empty")
+		if err := types.ValidateDBName(name); err != nil {
+			return err
 		}
 		settings.Name = name
 	}
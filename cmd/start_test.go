@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func TestSetFromStoppedExpiry(t *testing.T) {
+	setupTestDB(t)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	placeholder := now.Add(-time.Hour)
+
+	// --ttl unpins a pinned container, so the new TTL isn't ignored
+	pinned := &database.Container{Type: "postgres", Pinned: true, ExpiresAt: placeholder}
+	if err := setFromStoppedExpiry(pinned, true, 8, now); err != nil {
+		t.Fatalf("setFromStoppedExpiry() error = %v", err)
+	}
+	if pinned.Pinned || !pinned.ExpiresAt.Equal(now.Add(8*time.Hour)) {
+		t.Errorf("pinned with --ttl 8 = pinned %v, expires %s, want unpinned expiring in 8h", pinned.Pinned, pinned.ExpiresAt)
+	}
+
+	// Without --ttl a pinned container stays pinned, even though its placeholder expiration has passed
+	pinned = &database.Container{Type: "postgres", Pinned: true, ExpiresAt: placeholder}
+	if err := setFromStoppedExpiry(pinned, false, 0, now); err != nil {
+		t.Fatalf("setFromStoppedExpiry() error = %v", err)
+	}
+	if !pinned.Pinned || !pinned.ExpiresAt.Equal(placeholder) {
+		t.Errorf("pinned without --ttl = pinned %v, expires %s, want it left as it was", pinned.Pinned, pinned.ExpiresAt)
+	}
+
+	expired := &database.Container{Type: "postgres", ExpiresAt: placeholder}
+	if err := setFromStoppedExpiry(expired, false, 0, now); err != nil {
+		t.Fatalf("setFromStoppedExpiry() error = %v", err)
+	}
+	if !expired.ExpiresAt.Equal(now.Add(defaultTTLHours * time.Hour)) {
+		t.Errorf("expired without --ttl expires %s, want the default TTL from now", expired.ExpiresAt)
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/volumes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statContainerName string
+	statWatch         bool
+	statInterval      time.Duration
+	statOutput        string
+)
+
+// ANSI escape sequences used by --watch
+const (
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+var statCmd = &cobra.Command{
+	Use:   "stat",
+	Short: "Display container resource usage",
+	Long: `Display container information along with live CPU, memory, network, and disk usage.
+
+Use --watch to keep refreshing the output until Ctrl-C, or --output json or --output yaml
+to print a single sample for scripts.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runStat,
+}
+
+func init() {
+	rootCmd.AddCommand(statCmd)
+	statCmd.Flags().StringVar(&statContainerName, "name", "", "Container name (skips interactive selection)")
+	statCmd.Flags().BoolVarP(&statWatch, "watch", "w", false, "Continuously refresh stats until interrupted")
+	statCmd.Flags().DurationVar(&statInterval, "interval", 2*time.Second, "Refresh interval for --watch")
+	statCmd.Flags().StringVarP(&statOutput, "output", "o", ui.OutputText, "Output format: text, json, or yaml")
+}
+
+func runStat(cmd *cobra.Command, args []string) error {
+	if statInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if err := ui.ValidateOutputFormat(statOutput); err != nil {
+		return err
+	}
+	if statWatch && statOutput != ui.OutputText {
+		return fmt.Errorf("--watch cannot be used with --output %s", statOutput)
+	}
+
+	container, err := selectInfoContainer(statContainerName, "Select container to view")
+	if err != nil || container == nil {
+		return err
+	}
+
+	running := container.Status == "running" && container.ContainerID != ""
+
+	if !statWatch {
+		report, err := buildContainerReport(container, false)
+		if err != nil {
+			return err
+		}
+		if statOutput != ui.OutputText {
+			return ui.PrintStructured(statOutput, report)
+		}
+
+		ui.PrintContainerInfo(report.displayContainer())
+		if !running {
+			ui.Info("Stats are only available for running containers")
+			return nil
+		}
+		if report.statsErr != nil {
+			return report.statsErr
+		}
+		printContainerStats(report.Stats)
+		return nil
+	}
+
+	if !running {
+		return fmt.Errorf("container '%s' is %s, --watch requires a running container (start it with 'mkdb restart')", container.DisplayName, container.Status)
+	}
+
+	return watchStats(container)
+}
+
+// watchStats redraws container info and stats every interval until interrupted
+func watchStats(container *database.Container) error {
+	sample := func() (*docker.ContainerStats, error) {
+		return docker.GetContainerStats(container.ContainerID)
+	}
+	return watchLoop(statInterval, sample, func(stats *docker.ContainerStats) error {
+		ui.PrintContainerInfo(container)
+		printContainerStats(stats)
+		return nil
+	})
+}
+
+// watchLoop redraws the screen every interval until interrupted. sample runs before the screen is
+// cleared, so slow work like fetching stats doesn't leave it blank, then draw prints the result
+func watchLoop[T any](interval time.Duration, sample func() (T, error), draw func(T) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Only take over the screen when writing to a terminal
+	interactive := isatty.IsTerminal(os.Stdout.Fd())
+	if interactive {
+		fmt.Print(hideCursor)
+		defer fmt.Print(showCursor)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := sample()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if interactive {
+			fmt.Print(clearScreen)
+		}
+		if err := draw(result); err != nil {
+			return err
+		}
+		fmt.Printf("Refreshing every %s, press Ctrl-C to exit (updated %s)\n", interval, time.Now().Format("15:04:05"))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func printContainerStats(s *docker.ContainerStats) {
+	memory := volumes.FormatSize(int64(s.MemoryUsage))
+	if s.MemoryLimit > 0 {
+		memory = fmt.Sprintf("%s / %s (%.1f%%)", memory, volumes.FormatSize(int64(s.MemoryLimit)), s.MemoryPercent)
+	}
+
+	stats := fmt.Sprintf(`CPU:         %.1f%%
+Memory:      %s
+Network I/O: %s / %s
+Block I/O:   %s / %s
+PIDs:        %d`,
+		s.CPUPercent,
+		memory,
+		volumes.FormatSize(int64(s.NetworkRx)),
+		volumes.FormatSize(int64(s.NetworkTx)),
+		volumes.FormatSize(int64(s.BlockRead)),
+		volumes.FormatSize(int64(s.BlockWrite)),
+		s.PIDs,
+	)
+
+	ui.Box(stats)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pbzona/mkdb/internal/state"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// statePassphraseEnvVar supplies the archive passphrase without prompting, e.g. in scripts
+const statePassphraseEnvVar = "MKDB_STATE_PASSPHRASE"
+
+var (
+	stateWithVolumes bool
+	stateOverwrite   bool
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Move mkdb's records to another machine",
+	Long: `Export mkdb's records to a single archive and import them on another machine.
+
+The archive holds the containers mkdb tracks, their users, databases and events, and the
+last used settings. Passwords are encrypted with a passphrase instead of this machine's key,
+so the archive can be imported wherever the passphrase is known. Set MKDB_STATE_PASSPHRASE
+to skip the prompt.
+
+Docker containers and images are not included. Volume data is only included with
+--with-volumes, and only for named volumes, bind mounts stay where they are.`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Write mkdb's records to an archive",
+	Long: `Write the containers mkdb tracks, their users, databases and events, and the last used
+settings to a gzipped tar archive. Adopted containers are left out, since mkdb can't
+recreate them. Removed containers are left out too.
+
+With --with-volumes, the directories of named volumes are added. Stop the containers first,
+since files are copied as they are. Files written by the database may belong to its user in
+the container and need to be made readable first.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: noCleanupPrompt,
+	RunE:        runStateExport,
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Restore mkdb's records from an archive",
+	Long: `Record the containers in an archive from 'mkdb state export' on this machine.
+
+Containers are recorded as stopped, 'mkdb restart' or 'mkdb start --from-stopped' creates
+them in Docker. A container whose name is already taken is skipped, as is a volume whose
+directory already exists. The last used settings are only restored if there are none yet.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: noCleanupPrompt,
+	RunE:        runStateImport,
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+
+	stateExportCmd.Flags().BoolVar(&stateWithVolumes, "with-volumes", false, "Include the data of named volumes")
+	stateExportCmd.Flags().BoolVar(&stateOverwrite, "overwrite", false, "Overwrite the archive if it exists")
+}
+
+func runStateExport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if _, err := os.Stat(path); err == nil && !stateOverwrite {
+		return fmt.Errorf("%s already exists, use --overwrite to replace it", path)
+	}
+
+	passphrase, err := statePassphrase(true)
+	if err != nil {
+		return err
+	}
+
+	ui.Info(fmt.Sprintf("Exporting to %s...", path))
+	summary, err := state.Export(path, passphrase, stateWithVolumes)
+	if err != nil {
+		return err
+	}
+
+	for _, skipped := range summary.Skipped {
+		ui.Warning(fmt.Sprintf("Not exported: %s", skipped))
+	}
+	ui.Success(fmt.Sprintf("Exported %d container(s) to %s", len(summary.Containers), path))
+	if len(summary.Volumes) > 0 {
+		ui.Info(fmt.Sprintf("Included volumes: %s", strings.Join(summary.Volumes, ", ")))
+	} else if !stateWithVolumes {
+		ui.Info("Volume data isn't included, pass --with-volumes to add named volumes")
+	}
+	return nil
+}
+
+func runStateImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	passphrase, err := statePassphrase(false)
+	if err != nil {
+		return err
+	}
+
+	summary, err := state.Import(path, passphrase)
+	if summary != nil {
+		for _, skipped := range summary.Skipped {
+			ui.Warning(fmt.Sprintf("Skipped: %s", skipped))
+		}
+		if len(summary.Containers) > 0 {
+			ui.Success(fmt.Sprintf("Imported %d container(s): %s", len(summary.Containers), strings.Join(summary.Containers, ", ")))
+		}
+		if len(summary.Volumes) > 0 {
+			ui.Info(fmt.Sprintf("Restored volumes: %s", strings.Join(summary.Volumes, ", ")))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(summary.Containers) == 0 {
+		ui.Info("Nothing to import")
+		return nil
+	}
+
+	ui.Info("Containers are stopped, start one with 'mkdb restart --name <name>'")
+	return nil
+}
+
+// statePassphrase reads the archive passphrase from MKDB_STATE_PASSPHRASE or prompts for it
+// A new passphrase is asked for twice, so a typo doesn't make the archive unreadable
+func statePassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(statePassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	passphrase, err := ui.PromptSecret("Archive passphrase")
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	if confirm {
+		again, err := ui.PromptSecret("Repeat passphrase")
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
@@ -12,18 +12,27 @@ import (
 
 var (
 	stopContainerName string
+	stopTimeout       time.Duration
+	stopKeep          bool
 )
 
 var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop a database container",
-	Long:  `Stop a running database container while preserving its data. Use 'restart' to start it again.`,
-	RunE:  runStop,
+	Long: `Stop a running database container while preserving its data. Use 'restart' to start it again.
+
+The Docker container is removed and recreated by 'restart'. With --keep it's only stopped,
+so 'restart' starts the same container again, which is faster. Adopted containers are
+always kept, since mkdb didn't create them and can't recreate them.`,
+	Annotations: requiresDocker,
+	RunE:        runStop,
 }
 
 func init() {
 	rootCmd.AddCommand(stopCmd)
 	stopCmd.Flags().StringVar(&stopContainerName, "name", "", "Container name (skips interactive selection)")
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", docker.DefaultStopTimeout, "Time to wait for a clean shutdown before killing the database (0 waits indefinitely)")
+	stopCmd.Flags().BoolVar(&stopKeep, "keep", false, "Keep the stopped Docker container so 'restart' doesn't have to recreate it")
 }
 
 func runStop(cmd *cobra.Command, args []string) error {
@@ -70,13 +79,18 @@ func runStop(cmd *cobra.Command, args []string) error {
 
 	// Stop container
 	if container.ContainerID != "" && docker.ContainerExists(container.ContainerID) {
-		if err := docker.StopContainer(container.ContainerID); err != nil {
+		if err := docker.StopContainer(container.ContainerID, stopTimeout); err != nil {
 			return fmt.Errorf("failed to stop container: %w", err)
 		}
 
-		// Remove container
-		if err := docker.RemoveContainer(container.ContainerID); err != nil {
-			return fmt.Errorf("failed to remove container: %w", err)
+		// Remove container, unless it's kept for a fast restart. Adopted containers are always kept,
+		// since mkdb can't recreate them
+		if !keepStopped(container) {
+			if err := docker.RemoveContainer(container.ContainerID); err != nil {
+				return fmt.Errorf("failed to remove container: %w", err)
+			}
+		} else if docker.ResolveRestartPolicy(container.RestartPolicy) == "always" {
+			ui.Warning("The container's restart policy is 'always', so Docker will start it again when the daemon restarts")
 		}
 	}
 
@@ -93,8 +107,16 @@ func runStop(cmd *cobra.Command, args []string) error {
 		Timestamp:   time.Now(),
 		Details:     "Container stopped by user",
 	}
+	if keepStopped(container) {
+		event.Details = "Container stopped by user, Docker container kept"
+	}
 	database.CreateEvent(event)
 
 	ui.Success(fmt.Sprintf("Container '%s' stopped successfully!", container.DisplayName))
 	return nil
 }
+
+// keepStopped reports whether stopping leaves the Docker container in place
+func keepStopped(container *database.Container) bool {
+	return stopKeep || container.Adopted
+}
@@ -1,29 +1,40 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/probe"
 	"github.com/pbzona/mkdb/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	testContainerName string
+	testJSON          bool
+	testConnections   bool
 )
 
 var testCmd = &cobra.Command{
-	Use:     "test",
-	Aliases: []string{"ping"},
-	Short:   "Test database connectivity",
-	Long:    `Test connectivity to a database container by running a simple query.`,
-	RunE:    runTest,
+	Use:         "test",
+	Aliases:     []string{"ping"},
+	Short:       "Test database connectivity",
+	Long:        `Test connectivity to a database container by running a simple query.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runTest,
 }
 
 func init() {
 	rootCmd.AddCommand(testCmd)
 	testCmd.Flags().StringVar(&testContainerName, "name", "", "Container name (skips interactive selection)")
+	testCmd.Flags().BoolVar(&testJSON, "json", false, "Print the result as JSON")
+	testCmd.Flags().BoolVar(&testConnections, "connections", false, "Also report current, idle and max client connections")
 }
 
 func runTest(cmd *cobra.Command, args []string) error {
@@ -55,46 +66,81 @@ func runTest(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Test connectivity based on database type
-	ui.Info(fmt.Sprintf("Testing connectivity to %s (%s)...", container.DisplayName, container.Type))
-
-	var testCommand []string
-	switch container.Type {
-	case "postgres":
-		testCommand = []string{
-			"psql",
-			"-U", "dbuser",
-			"-d", container.DisplayName,
-			"-c", "SELECT 1 as status, current_user, current_database();",
+	// Connect as the container's default user, whose name may be configured
+	user, err := database.GetDefaultUser(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get default user: %w", err)
+	}
+	var password string
+	if user.PasswordHash != "" {
+		password, err = config.Decrypt(user.PasswordHash)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+
+	testCommand, err := probe.Command(container.Type, container.DisplayName, user.Username, password)
+	if err != nil {
+		return err
+	}
+
+	if !testJSON {
+		ui.Info(fmt.Sprintf("Testing connectivity to %s (%s)...", container.DisplayName, container.Type))
+	}
+
+	// Execute and time the test command
+	started := time.Now()
+	output, err := docker.ExecCommand(container.Name, testCommand)
+	result := probe.NewResult(container.DisplayName, container.Type, time.Since(started), output, err)
+
+	// Connection counts are extra detail, so failing to get them doesn't fail the test
+	if testConnections && result.OK {
+		if info, err := connectionInfo(container); err != nil {
+			config.Logger.Warn("Failed to get connection info", "container", container.DisplayName, "error", err)
+			if !testJSON {
+				ui.Warning(fmt.Sprintf("Failed to get connection info: %v", err))
+			}
+		} else {
+			result.Connections = &info
 		}
-	case "mysql":
-		testCommand = []string{
-			"mysql",
-			"-u", "dbuser",
-			"-p$uper$ecret",
-			container.DisplayName,
-			"-e", "SELECT 1 as status, USER() as user, DATABASE() as db;",
+	}
+
+	if testJSON {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
 		}
-	case "redis":
-		testCommand = []string{
-			"redis-cli",
-			"PING",
+		fmt.Println(string(data))
+		if !result.OK {
+			return fmt.Errorf("connectivity test failed")
 		}
-	default:
-		return fmt.Errorf("unsupported database type: %s", container.Type)
+		return nil
 	}
 
-	// Execute the test command
-	output, err := docker.ExecCommand(container.Name, testCommand)
-	if err != nil {
+	if !result.OK {
 		ui.Error(fmt.Sprintf("Connection failed: %v", err))
 		return fmt.Errorf("connectivity test failed: %w", err)
 	}
 
-	ui.Success("Connection successful!")
+	ui.Success(fmt.Sprintf("Connection successful! (%dms)", result.LatencyMS))
 	fmt.Println()
 	fmt.Println("Response:")
 	fmt.Println(output)
 
+	if result.Connections != nil {
+		fmt.Printf("Connections: %s\n", result.Connections)
+	}
+
 	return nil
 }
+
+// connectionInfo reports a container's client connections using its administrative credentials
+func connectionInfo(container *database.Container) (adapters.ConnInfo, error) {
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return adapters.ConnInfo{}, err
+	}
+	return probe.ConnectionInfo(container.Type, adminPassword, func(cmd []string) (string, error) {
+		return docker.ExecCommand(container.Name, cmd)
+	})
+}
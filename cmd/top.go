@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/volumes"
+	"github.com/spf13/cobra"
+)
+
+// Columns 'mkdb top' can sort by
+const (
+	topSortCPU    = "cpu"
+	topSortMemory = "memory"
+	topSortName   = "name"
+)
+
+var topSorts = []string{topSortCPU, topSortMemory, topSortName}
+
+var (
+	topSort     string
+	topAll      bool
+	topWatch    bool
+	topInterval time.Duration
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show resource usage of all databases",
+	Long: `Show CPU, memory, network and disk usage of every running database in one table,
+sorted by CPU by default. Use --sort memory to find the database using the most memory.
+
+Use --watch to keep refreshing the table until Ctrl-C. Use 'mkdb stat' for the details
+of a single database.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runTop,
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().StringVar(&topSort, "sort", topSortCPU, "Sort by cpu, memory, or name")
+	topCmd.Flags().BoolVar(&topAll, "all", false, "Include stopped and expired containers, without stats")
+	topCmd.Flags().BoolVarP(&topWatch, "watch", "w", false, "Continuously refresh the table until interrupted")
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "Refresh interval for --watch")
+}
+
+// topRow is a container with its stats, Stats is nil if the container isn't running or couldn't be sampled
+type topRow struct {
+	Container *database.Container
+	Stats     *docker.ContainerStats
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(topSorts, topSort) {
+		return fmt.Errorf("invalid --sort %q (valid values: %s)", topSort, strings.Join(topSorts, ", "))
+	}
+	if topInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	if !topWatch {
+		return renderTop()
+	}
+
+	return watchLoop(topInterval, sampleTop, func(rows []topRow) error {
+		displayTop(rows)
+		return nil
+	})
+}
+
+// renderTop samples and prints the table once
+func renderTop() error {
+	rows, err := sampleTop()
+	if err != nil {
+		return err
+	}
+	displayTop(rows)
+	return nil
+}
+
+// sampleTop collects stats for the running containers, and the others with --all, sorted by --sort
+// Each sample blocks for about a second, so the containers are sampled in parallel
+func sampleTop() ([]topRow, error) {
+	containers, err := database.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var rows []topRow
+	for _, c := range containers {
+		running := c.Status == types.StatusRunning && c.ContainerID != ""
+		if running || topAll {
+			rows = append(rows, topRow{Container: c})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range rows {
+		c := rows[i].Container
+		if c.Status != types.StatusRunning || c.ContainerID == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats, err := docker.GetContainerStats(c.ContainerID)
+			if err != nil {
+				// The container may have stopped since it was recorded, it's shown without stats
+				config.Logger.Warn("Failed to get container stats", "container", c.DisplayName, "error", err)
+				return
+			}
+			rows[i].Stats = stats
+		}()
+	}
+	wg.Wait()
+
+	sortTopRows(rows, topSort)
+	return rows, nil
+}
+
+// sortTopRows orders rows by the given column, busiest first, with unsampled containers last
+// Ties are broken by name so the order doesn't jump between refreshes
+func sortTopRows(rows []topRow, by string) {
+	slices.SortStableFunc(rows, func(a, b topRow) int {
+		byName := cmp.Compare(a.Container.DisplayName, b.Container.DisplayName)
+		if by == topSortName {
+			return byName
+		}
+		if (a.Stats == nil) != (b.Stats == nil) {
+			if a.Stats == nil {
+				return 1
+			}
+			return -1
+		}
+		if a.Stats == nil {
+			return byName
+		}
+
+		var order int
+		switch by {
+		case topSortMemory:
+			order = cmp.Compare(b.Stats.MemoryUsage, a.Stats.MemoryUsage)
+		default:
+			order = cmp.Compare(b.Stats.CPUPercent, a.Stats.CPUPercent)
+		}
+		if order != 0 {
+			return order
+		}
+		return byName
+	})
+}
+
+func displayTop(rows []topRow) {
+	t, _ := newListTable(tableStylePlain, true)
+	t.Headers("NAME", "TYPE", "STATUS", "CPU %", "MEMORY", "MEM %", "NET I/O", "BLOCK I/O", "PIDS")
+
+	for _, r := range rows {
+		s := r.Stats
+		status := containerDisplayStatus(r.Container)
+		if s == nil {
+			t.Row(r.Container.DisplayName, r.Container.Type, status, "-", "-", "-", "-", "-", "-")
+			continue
+		}
+		memPercent := "-"
+		if s.MemoryLimit > 0 {
+			memPercent = fmt.Sprintf("%.1f%%", s.MemoryPercent)
+		}
+		t.Row(
+			r.Container.DisplayName,
+			r.Container.Type,
+			status,
+			fmt.Sprintf("%.1f%%", s.CPUPercent),
+			volumes.FormatSize(int64(s.MemoryUsage)),
+			memPercent,
+			volumes.FormatSize(int64(s.NetworkRx))+" / "+volumes.FormatSize(int64(s.NetworkTx)),
+			volumes.FormatSize(int64(s.BlockRead))+" / "+volumes.FormatSize(int64(s.BlockWrite)),
+			fmt.Sprintf("%d", s.PIDs),
+		)
+	}
+
+	fmt.Println()
+	if len(rows) == 0 {
+		fmt.Println("No running databases")
+	} else {
+		fmt.Println(t.Render())
+	}
+	fmt.Println()
+}
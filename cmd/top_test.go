@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+)
+
+func TestSortTopRows(t *testing.T) {
+	row := func(name string, cpu float64, memory uint64) topRow {
+		return topRow{
+			Container: &database.Container{DisplayName: name},
+			Stats:     &docker.ContainerStats{CPUPercent: cpu, MemoryUsage: memory},
+		}
+	}
+	stopped := func(name string) topRow {
+		return topRow{Container: &database.Container{DisplayName: name}}
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		// Busiest first, ties by name, unsampled containers last
+		{by: topSortCPU, want: []string{"cache", "api", "orders", "archive", "legacy"}},
+		{by: topSortMemory, want: []string{"orders", "api", "cache", "archive", "legacy"}},
+		{by: topSortName, want: []string{"api", "archive", "cache", "legacy", "orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			rows := []topRow{
+				stopped("legacy"),
+				row("orders", 5, 512),
+				row("cache", 40, 64),
+				stopped("archive"),
+				row("api", 5, 256),
+			}
+			sortTopRows(rows, tt.by)
+
+			var got []string
+			for _, r := range rows {
+				got = append(got, r.Container.DisplayName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("sortTopRows(%s) = %v, want %v", tt.by, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/profile"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upFile   string
+	upStrict bool
+)
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create the databases listed in a profile",
+	Long: `Create every database described in a YAML or JSON profile, skipping any that already exist.
+
+Example profile:
+
+  databases:
+    - type: postgres
+      name: app
+      version: "16"
+      ttl: 8
+    - type: redis
+      name: cache
+      no_auth: true
+      volume: none
+
+    - type: postgres
+      name: reports
+      depends_on: [app]
+
+Each entry accepts type, name, version, port, ttl (hours), volume, env, args, username,
+no_auth and seed, which behave like the matching 'mkdb start' flags. Databases get a
+named volume unless one is given or set in the defaults file, and authentication unless no_auth is set.
+
+depends_on lists databases in the profile that have to accept connections before the entry is
+created, so its seed files can rely on them. Databases are created in dependency order, and
+one whose dependency failed is skipped.`,
+	Annotations: requiresDocker,
+	RunE:        runUp,
+}
+
+func init() {
+	rootCmd.AddCommand(upCmd)
+	upCmd.Flags().StringVarP(&upFile, "file", "f", "mkdb.yaml", "Profile to create databases from")
+	upCmd.Flags().BoolVar(&upStrict, "strict", false, "Stop at the first database that fails to be created")
+}
+
+// upResult is the outcome of creating one database from a profile
+type upResult struct {
+	name    string
+	status  string // "created", "skipped" or "failed"
+	detail  string // Connection string, or the reason it was skipped or failed
+	envName string
+}
+
+func runUp(cmd *cobra.Command, args []string) error {
+	specs, err := profile.Load(upFile)
+	if err != nil {
+		return err
+	}
+
+	var results []upResult
+	failed := 0
+	blocked := make(map[string]bool) // Databases that failed, or were skipped because a dependency did
+	ready := make(map[string]bool)
+	for _, spec := range specs {
+		if dep := blockedDependency(spec, blocked); dep != "" {
+			blocked[spec.Name] = true
+			results = append(results, upResult{name: spec.Name, status: "skipped", detail: fmt.Sprintf("dependency '%s' wasn't created", dep)})
+			continue
+		}
+
+		result := upResult{name: spec.Name, status: "failed"}
+		if err := waitForDependencies(spec, ready); err != nil {
+			result.detail = err.Error()
+		} else {
+			result = createFromSpec(spec)
+		}
+		results = append(results, result)
+		if result.status == "failed" {
+			blocked[spec.Name] = true
+			failed++
+			ui.Error(fmt.Sprintf("Failed to create '%s': %s", spec.Name, result.detail))
+			if upStrict {
+				break
+			}
+		}
+	}
+
+	printUpSummary(results)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d database(s) failed to be created", failed, len(specs))
+	}
+	return nil
+}
+
+// blockedDependency returns the first dependency of spec that wasn't created, or "" if there is none
+func blockedDependency(spec profile.StartSpec, blocked map[string]bool) string {
+	for _, dep := range spec.DependsOn {
+		if blocked[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// waitForDependencies waits until each database spec depends on accepts connections
+// ready records the ones already waited for, so shared dependencies are only checked once
+func waitForDependencies(spec profile.StartSpec, ready map[string]bool) error {
+	for _, dep := range spec.DependsOn {
+		if ready[dep] {
+			continue
+		}
+		container, err := database.GetContainerByDisplayName(dep)
+		if err != nil {
+			return fmt.Errorf("dependency '%s' not found", dep)
+		}
+		if container.Status != types.StatusRunning || container.ContainerID == "" {
+			return fmt.Errorf("dependency '%s' is %s, start it with 'mkdb restart --name %s'", dep, container.Status, dep)
+		}
+
+		ui.Info(fmt.Sprintf("Waiting for '%s' to accept connections before creating '%s'...", dep, spec.Name))
+		if err := waitForContainer(container, docker.DefaultReadyTimeout); err != nil {
+			return fmt.Errorf("dependency '%s' is not ready: %w", dep, err)
+		}
+		ready[dep] = true
+	}
+	return nil
+}
+
+// createFromSpec creates a single database from a profile entry using the same path as 'mkdb start'
+func createFromSpec(spec profile.StartSpec) upResult {
+	if existing, err := database.GetContainerByDisplayName(spec.Name); err == nil {
+		return upResult{name: spec.Name, status: "skipped", detail: fmt.Sprintf("already exists (%s)", existing.Status)}
+	}
+
+	ui.Info(fmt.Sprintf("Creating %s database '%s'...", spec.Type, spec.Name))
+
+	req := startRequest{
+		settings: config.LastSettings{
+			DBType:     spec.Type,
+			Name:       spec.Name,
+			Version:    spec.Version,
+			Port:       spec.Port,
+			VolumePath: spec.Volume,
+			TTLHours:   spec.TTLHours,
+			ExtraEnv:   spec.Env,
+			ExtraArgs:  spec.Args,
+		},
+		username:      spec.Username,
+		ttlSet:        spec.TTLHours > 0,
+		noAuth:        spec.NoAuth,
+		noAuthSet:     true,
+		defaultVolume: "named",
+		seedFiles:     spec.Seed,
+	}
+
+	plan, err := buildStartPlan(req)
+	if err != nil {
+		return upResult{name: spec.Name, status: "failed", detail: err.Error()}
+	}
+
+	container, err := createFromPlan(plan)
+	if err != nil {
+		return upResult{name: spec.Name, status: "failed", detail: err.Error()}
+	}
+
+	return upResult{
+		name:    spec.Name,
+		status:  "created",
+		detail:  planConnectionString(plan, container),
+		envName: plan.connEnvKey,
+	}
+}
+
+// printUpSummary lists what happened to each database in the profile
+func printUpSummary(results []upResult) {
+	counts := make(map[string]int)
+	ui.Newline()
+	ui.Header("Summary")
+	for _, r := range results {
+		counts[r.status]++
+		switch r.status {
+		case "created":
+			fmt.Printf("  %s: created\n", r.name)
+			fmt.Printf("    %s\n", credentials.FormatEnvVar(r.envName, r.detail))
+		default:
+			fmt.Printf("  %s: %s, %s\n", r.name, r.status, r.detail)
+		}
+	}
+	ui.Newline()
+	ui.Info(fmt.Sprintf("%d created, %d skipped, %d failed", counts["created"], counts["skipped"], counts["failed"]))
+}
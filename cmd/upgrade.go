@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeContainerName string
+	upgradeVersion       string
+	upgradeForce         bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:     "upgrade",
+	Aliases: []string{"update-version"},
+	Short:   "Change a container's database version",
+	Long: `Recreate a database container with a different image version, keeping its volume.
+
+Major version changes and downgrades can leave the data directory unreadable by the
+new version (Postgres in particular requires pg_upgrade or a dump and restore), so
+back up your data first.`,
+	Annotations: requiresDocker,
+	RunE:        runUpgrade,
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().StringVar(&upgradeContainerName, "name", "", "Container name (skips interactive selection)")
+	upgradeCmd.Flags().StringVar(&upgradeVersion, "version", "", "Database version to switch to (e.g., 16, 8.4)")
+	upgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Recreate even if the data directory was written by an incompatible version")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	var container *database.Container
+	var err error
+
+	// If name is provided, look it up directly
+	if upgradeContainerName != "" {
+		container, err = database.GetContainerByDisplayName(upgradeContainerName)
+		if err != nil {
+			return fmt.Errorf("container '%s' not found", upgradeContainerName)
+		}
+	} else {
+		// Get all containers
+		containers, err := database.ListContainers()
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		if len(containers) == 0 {
+			ui.Warning("No containers found")
+			return nil
+		}
+
+		// Select container
+		container, err = ui.SelectContainer(containers, "Select container to upgrade")
+		if err != nil {
+			return fmt.Errorf("failed to select container: %w", err)
+		}
+	}
+
+	// Upgrading replaces the container, which mkdb can only do for containers it created
+	if err := checkRecreatable(container); err != nil {
+		return err
+	}
+
+	// The stored version may be a tag like "latest", so prefer what's actually running
+	oldVersion := container.Version
+	exists := container.ContainerID != "" && docker.ContainerExists(container.ContainerID)
+	if exists && container.Status == "running" {
+		if actual, err := docker.GetActualVersion(container.ContainerID, container.Type); err == nil && actual != "" {
+			oldVersion = actual
+		}
+	}
+
+	newVersion := upgradeVersion
+	if newVersion == "" {
+		newVersion, err = ui.PromptString(fmt.Sprintf("Enter new version (current: %s)", oldVersion), "")
+		if err != nil {
+			return fmt.Errorf("failed to get version: %w", err)
+		}
+	}
+	if newVersion == "" {
+		return fmt.Errorf("a version is required")
+	}
+	if newVersion == container.Version || newVersion == oldVersion {
+		ui.Info(fmt.Sprintf("Container '%s' is already on version %s", container.DisplayName, newVersion))
+		return nil
+	}
+
+	// Without a volume the data lives in the container and is lost when it's replaced
+	if container.VolumeType == "" || container.VolumeType == "none" {
+		ui.Warning("This container has no volume, all of its data will be lost")
+	}
+
+	// The data directory records the version that wrote it, which catches mismatches the image tag hides
+	if err := checkDataDirVersion(container, newVersion); err != nil {
+		if !upgradeForce {
+			return fmt.Errorf("%w (use --force to upgrade anyway)", err)
+		}
+		ui.Warning(err.Error())
+	}
+
+	change := docker.CompareVersions(oldVersion, newVersion)
+	switch {
+	case !change.Comparable:
+		ui.Warning(fmt.Sprintf("Unable to compare versions %s and %s, the existing data may not be compatible", oldVersion, newVersion))
+	case change.Downgrade:
+		ui.Warning(fmt.Sprintf("Downgrading from %s to %s is not supported by most databases and may corrupt your data", oldVersion, newVersion))
+	case change.MajorChange:
+		ui.Warning(fmt.Sprintf("Changing major version from %s to %s may leave the data directory unreadable", oldVersion, newVersion))
+	}
+	if adapters.IsPostgres(container.Type) && (change.MajorChange || !change.Comparable) {
+		ui.Warning("Postgres cannot start on a data directory from another major version, dump your data with pg_dumpall first")
+	}
+
+	// Fail before the old container is removed if another container has taken its port
+	if _, err := docker.ReclaimPort(container.Port, false, container.Name, container.ContainerID); err != nil {
+		return err
+	}
+
+	confirmed, err := ui.PromptConfirm(fmt.Sprintf("Recreate '%s' with %s:%s?", container.DisplayName, container.Type, newVersion))
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+
+	if !confirmed {
+		ui.Info("Upgrade cancelled")
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Upgrading container '%s' to version %s...", container.DisplayName, newVersion))
+
+	// Remove the old container, the volume is left in place and reattached below
+	if exists {
+		if err := docker.StopContainer(container.ContainerID, docker.DefaultStopTimeout); err != nil {
+			return fmt.Errorf("failed to stop container: %w", err)
+		}
+		if err := docker.RemoveContainer(container.ContainerID); err != nil {
+			return fmt.Errorf("failed to remove container: %w", err)
+		}
+	}
+
+	// The server version resolved from the old tag no longer applies
+	storedVersion, storedServerVersion := container.Version, container.ServerVersion
+	container.Version, container.ServerVersion = newVersion, ""
+	if _, _, err := recreateContainer(container); err != nil {
+		// Record that the old container is gone so 'mkdb restart' can recreate it
+		container.Version, container.ServerVersion = storedVersion, storedServerVersion
+		container.ContainerID = ""
+		container.Status = "stopped"
+		if updateErr := database.UpdateContainer(container); updateErr != nil {
+			ui.Warning(fmt.Sprintf("Failed to update container record: %v", updateErr))
+		}
+		return err
+	}
+
+	container.Status = "running"
+	if err := database.UpdateContainer(container); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
+
+	// Log event
+	event := &database.Event{
+		ContainerID: container.ID,
+		EventType:   "upgraded",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Version changed from %s to %s", oldVersion, newVersion),
+	}
+	database.CreateEvent(event)
+
+	ui.Success(fmt.Sprintf("Container '%s' is now running %s:%s", container.DisplayName, container.Type, newVersion))
+	return nil
+}
+
+// checkDataDirVersion compares the version that wrote a container's volume with the version about to run on it
+// Returns an error if the database is not expected to start on the existing data
+func checkDataDirVersion(container *database.Container, version string) error {
+	// A volume mounted somewhere other than the data directory doesn't hold database files
+	if !docker.DataTargetIsWritable(container.Type, container.DataTarget) {
+		return nil
+	}
+
+	dataVersion, err := docker.DataDirVersion(container.Type, container.VolumeType, container.VolumePath)
+	if err != nil {
+		config.Logger.Warn("Failed to read data directory version", "error", err)
+		return nil
+	}
+	if dataVersion == adapters.UnknownVersion {
+		return nil
+	}
+
+	change := docker.CompareVersions(dataVersion, version)
+	switch {
+	case !change.Comparable:
+		return nil
+	case change.Downgrade:
+		return fmt.Errorf("data directory was written by %s %s and can't be opened by the older version %s", container.Type, dataVersion, version)
+	case change.MajorChange && adapters.IsPostgres(container.Type):
+		return fmt.Errorf("data directory was written by postgres %s, which postgres %s can't start on without pg_upgrade", dataVersion, version)
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 
 var (
 	userContainerName string
+	userPassword      string
 )
 
 var userCmd = &cobra.Command{
@@ -25,15 +26,21 @@ var userCmd = &cobra.Command{
 var userCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new database user",
-	Long:  `Create a new user in the database with a generated password.`,
-	RunE:  runUserCreate,
+	Long: `Create a new user in the database with a generated password, or the one passed with --password.
+
+A supplied password must be at least 12 characters and use at least three of lowercase
+letters, uppercase letters, digits, and symbols. Quotes, backslashes, whitespace, and
+characters that need escaping in connection strings (@ / : ? # %) aren't allowed.`,
+	Annotations: requiresDocker,
+	RunE:        runUserCreate,
 }
 
 var userDeleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Delete an existing database user",
-	Long:  `Delete a user from the database.`,
-	RunE:  runUserDelete,
+	Use:         "delete",
+	Short:       "Delete an existing database user",
+	Long:        `Delete a user from the database.`,
+	Annotations: requiresDocker,
+	RunE:        runUserDelete,
 }
 
 func init() {
@@ -44,12 +51,20 @@ func init() {
 	// Add --name flag to user subcommands
 	userCreateCmd.Flags().StringVar(&userContainerName, "name", "", "Container name (skips interactive selection)")
 	userDeleteCmd.Flags().StringVar(&userContainerName, "name", "", "Container name (skips interactive selection)")
+	userCreateCmd.Flags().StringVar(&userPassword, "password", "", "Password for the new user (default: generated)")
 }
 
 func runUserCreate(cmd *cobra.Command, args []string) error {
 	var container *database.Container
 	var err error
 
+	// Check a supplied password before prompting for anything else
+	if userPassword != "" {
+		if err := credentials.ValidatePassword(userPassword, credentials.DefaultPasswordOptions); err != nil {
+			return err
+		}
+	}
+
 	// If name is provided, look it up directly
 	if userContainerName != "" {
 		container, err = database.GetContainerByDisplayName(userContainerName)
@@ -86,6 +101,10 @@ func runUserCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if !docker.SupportsUsername(container.Type, container.DisplayVersion()) {
+		return fmt.Errorf("%s %s doesn't support additional users", container.Type, container.DisplayVersion())
+	}
+
 	// Prompt for username
 	username, err := ui.PromptString("Enter username", "")
 	if err != nil {
@@ -96,23 +115,41 @@ func runUserCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("username cannot be empty")
 	}
 
-	ui.Info("Generating password...")
+	// Databases that only run without authentication don't check passwords, so the user gets none
+	reason := docker.UnauthenticatedReason(container.Type)
+	if reason != "" && userPassword != "" {
+		return fmt.Errorf("--password can't be used with %s: %s", container.Type, reason)
+	}
+
+	password := userPassword
+	if reason != "" {
+		ui.Info(fmt.Sprintf("The user gets no password: %s", reason))
+	} else if password == "" {
+		ui.Info("Generating password...")
 
-	// Generate password
-	password, err := credentials.GeneratePassword(32)
+		password, err = credentials.GeneratePassword(32)
+		if err != nil {
+			return fmt.Errorf("failed to generate password: %w", err)
+		}
+	}
+
+	adminPassword, err := credentials.AdminPassword(container)
 	if err != nil {
-		return fmt.Errorf("failed to generate password: %w", err)
+		return err
 	}
 
 	// Create user in database container
-	if err := docker.CreateUser(container.ContainerID, container.Type, username, password, container.DisplayName); err != nil {
+	if err := docker.CreateUser(container.ContainerID, container.Type, username, password, container.DisplayName, adminPassword); err != nil {
 		return fmt.Errorf("failed to create user in database: %w", err)
 	}
 
 	// Encrypt and store password
-	encryptedPassword, err := config.Encrypt(password)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt password: %w", err)
+	var encryptedPassword string
+	if password != "" {
+		encryptedPassword, err = config.Encrypt(password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt password: %w", err)
+		}
 	}
 
 	user := &database.User{
@@ -134,12 +171,17 @@ func runUserCreate(cmd *cobra.Command, args []string) error {
 		container.Type,
 		username,
 		password,
-		"localhost",
+		credentials.ConnectionHost(),
 		container.Port,
-		container.DisplayName,
+		connectionDBName(container),
 	)
 
-	ui.Box(credentials.FormatEnvVar(connStr))
+	envKey, err := resolveEnvKey("")
+	if err != nil {
+		return err
+	}
+
+	ui.Box(credentials.FormatEnvVar(envKey, connStr))
 	return nil
 }
 
@@ -219,8 +261,13 @@ func runUserDelete(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	adminPassword, err := credentials.AdminPassword(container)
+	if err != nil {
+		return err
+	}
+
 	// Delete user from database container
-	if err := docker.DeleteUser(container.ContainerID, container.Type, user.Username, container.DisplayName); err != nil {
+	if err := docker.DeleteUser(container.ContainerID, container.Type, user.Username, container.DisplayName, adminPassword); err != nil {
 		return fmt.Errorf("failed to delete user from database: %w", err)
 	}
 
@@ -229,6 +276,7 @@ func runUserDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to delete user from database: %w", err)
 	}
 
+	config.Logger.Info("User deleted", "container", container.DisplayName, "username", user.Username)
 	ui.Success(fmt.Sprintf("User '%s' deleted successfully!", user.Username))
 	return nil
 }
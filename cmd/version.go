@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/pbzona/mkdb/internal/update"
 	"github.com/spf13/cobra"
 )
 
@@ -10,17 +13,52 @@ var (
 	// Version is the current version of mkdb
 	// This can be overridden at build time with -ldflags
 	Version = "dev"
+
+	versionCheck bool
 )
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of mkdb",
-	Long:  `Display the current version of mkdb.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("mkdb %s\n", Version)
-	},
+	Long: `Display the current version of mkdb.
+
+With --check, also look up the latest release on GitHub and report whether an update is
+available. Set MKDB_NO_UPDATE_CHECK to turn the check off.`,
+	Annotations: noCleanupPrompt,
+	RunE:        runVersion,
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	fmt.Printf("mkdb %s\n", Version)
+
+	if !versionCheck {
+		return nil
+	}
+	if update.Disabled() {
+		ui.Info(fmt.Sprintf("Update check disabled by %s", update.DisableEnvVar))
+		return nil
+	}
+
+	result, err := update.NewChecker().Check(context.Background(), Version)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	switch {
+	case !update.IsRelease(Version):
+		ui.Info(fmt.Sprintf("Development build, the latest release is %s", result.Latest))
+	case result.UpdateAvailable:
+		ui.Warning(fmt.Sprintf("Update available: %s (installed %s)", result.Latest, Version))
+		if result.URL != "" {
+			ui.Info(result.URL)
+		}
+	default:
+		ui.Success("mkdb is up to date")
+	}
+	return nil
 }
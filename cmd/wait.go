@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitContainerName string
+	waitTimeout       time.Duration
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait until a database accepts connections",
+	Long: `Block until a database container answers a test query, for use in scripts and CI.
+
+Exits 0 once the database is ready, or non-zero if it isn't ready before --timeout or there's
+no database to wait for. --name is required when stdin isn't a terminal.`,
+	Annotations: mergeAnnotations(noCleanupPrompt, requiresDocker),
+	RunE:        runWait,
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+	waitCmd.Flags().StringVar(&waitContainerName, "name", "", "Container name (skips interactive selection)")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", time.Minute, "How long to wait before giving up")
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	if waitTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	var container *database.Container
+	var err error
+
+	// If name is provided, look it up directly
+	if waitContainerName != "" {
+		container, err = database.GetContainerByDisplayName(waitContainerName)
+		if err != nil {
+			return fmt.Errorf("container '%s' not found", waitContainerName)
+		}
+	} else {
+		// Scripts can't answer the selection prompt
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("--name is required when stdin isn't a terminal")
+		}
+
+		// Get all containers
+		containers, err := database.ListContainers()
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		// Exiting 0 would tell a script the database is ready
+		if len(containers) == 0 {
+			return fmt.Errorf("no containers found")
+		}
+
+		// Select container
+		container, err = ui.SelectContainer(containers, "Select container to wait for")
+		if err != nil {
+			return fmt.Errorf("failed to select container: %w", err)
+		}
+	}
+
+	if container.ContainerID == "" {
+		return fmt.Errorf("container '%s' has no Docker container, run 'mkdb restart' to recreate it", container.DisplayName)
+	}
+
+	ui.Info(fmt.Sprintf("Waiting for '%s' to accept connections...", container.DisplayName))
+
+	started := time.Now()
+	if err := waitForContainer(container, waitTimeout); err != nil {
+		return fmt.Errorf("'%s' is not ready: %w", container.DisplayName, err)
+	}
+
+	ui.Success(fmt.Sprintf("Database '%s' is ready (%s)", container.DisplayName, time.Since(started).Round(100*time.Millisecond)))
+	return nil
+}
+
+// waitForContainer blocks until the database answers a test query, or only its port opens for types without one
+func waitForContainer(container *database.Container, timeout time.Duration) error {
+	// Connect as the container's default user, whose name may be configured
+	user, err := database.GetDefaultUser(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get default user: %w", err)
+	}
+	var password string
+	if user.PasswordHash != "" {
+		password, err = config.Decrypt(user.PasswordHash)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+
+	err = docker.WaitForReady(container.ContainerID, container.Type, user.Username, password, container.DisplayName, timeout)
+	if errors.Is(err, docker.ErrNoReadyCheck) {
+		err = docker.WaitForPort(container.ContainerID, credentials.ConnectionHost(), container.Port, timeout)
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// wizardSkipCommands don't trigger the first-run wizard
+var wizardSkipCommands = map[string]bool{
+	"version":    true,
+	"help":       true,
+	"completion": true,
+}
+
+// maybeRunFirstRunWizard offers the setup wizard on the first interactive run of mkdb
+func maybeRunFirstRunWizard(cmd *cobra.Command) error {
+	if wizardSkipCommands[cmd.Name()] || !config.IsFirstRun() {
+		return nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return nil
+	}
+	return runFirstRunWizard()
+}
+
+// runFirstRunWizard prompts for defaults and writes them to the defaults file
+// The file is written even if the user skips, so the wizard is only offered once
+func runFirstRunWizard() error {
+	defaults, err := config.LoadDefaults()
+	if err != nil {
+		return fmt.Errorf("failed to load defaults: %w", err)
+	}
+
+	setup := true
+	confirmForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Welcome to mkdb!").
+				Description("Set up your defaults now? You can change them later in " + config.DefaultsFileName + ".").
+				Affirmative("Yes").
+				Negative("Skip").
+				Value(&setup),
+		),
+	)
+	if err := confirmForm.Run(); err != nil {
+		return fmt.Errorf("failed to run setup wizard: %w", err)
+	}
+
+	if setup {
+		dbTypeOptions := []huh.Option[string]{huh.NewOption("Ask each time", "")}
+		for _, t := range types.ValidDBTypes() {
+			dbTypeOptions = append(dbTypeOptions, huh.NewOption(t, t))
+		}
+
+		ttlStr := "2"
+		if defaults.TTLHours > 0 {
+			ttlStr = strconv.Itoa(defaults.TTLHours)
+		}
+		if defaults.Color == "" {
+			defaults.Color = config.ColorAuto
+		}
+
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Preferred database type").
+					Options(dbTypeOptions...).
+					Value(&defaults.DBType),
+				huh.NewInput().
+					Title("Default TTL (hours)").
+					Value(&ttlStr).
+					Validate(func(s string) error {
+						hours, err := strconv.Atoi(s)
+						if err != nil || hours <= 0 {
+							return fmt.Errorf("TTL must be a positive number of hours")
+						}
+						return nil
+					}),
+				huh.NewSelect[string]().
+					Title("Default volume strategy").
+					Options(
+						huh.NewOption("Ask each time", ""),
+						huh.NewOption("Named volume (persists data)", "named"),
+						huh.NewOption("No volume (data lost on removal)", "none"),
+					).
+					Value(&defaults.Volume),
+				huh.NewSelect[string]().
+					Title("Color output").
+					Options(
+						huh.NewOption("Auto-detect", config.ColorAuto),
+						huh.NewOption("Always", config.ColorAlways),
+						huh.NewOption("Never", config.ColorNever),
+					).
+					Value(&defaults.Color),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return fmt.Errorf("failed to run setup wizard: %w", err)
+		}
+
+		// Validated above
+		defaults.TTLHours, _ = strconv.Atoi(ttlStr)
+	}
+
+	if err := config.SaveDefaults(defaults); err != nil {
+		return err
+	}
+
+	if setup {
+		ui.SetColorMode(defaults.Color)
+		ui.Success("Defaults saved")
+	}
+	return nil
+}
@@ -33,29 +33,69 @@ type DatabaseAdapter interface {
 	// GetDefaultConfig returns the default configuration file content
 	GetDefaultConfig() string
 
+	// ValidateConfig checks configuration file content for obvious syntax errors
+	// Returns nil if the content is valid or validation is not supported
+	ValidateConfig(content string) error
+
 	// CreateUserCommand returns the command to create a new user in the database
+	// adminPassword is the container's administrative password (see AdminPasswordAdapter), or the
+	// default user's password for databases without one. It is empty for unauthenticated containers
 	// Returns nil if user creation is not supported
-	CreateUserCommand(username, password, dbName string) []string
+	CreateUserCommand(username, password, dbName, adminPassword string) []string
 
 	// DeleteUserCommand returns the command to delete a user from the database
 	// Returns nil if user deletion is not supported
-	DeleteUserCommand(username, dbName string) []string
+	DeleteUserCommand(username, dbName, adminPassword string) []string
 
 	// RotatePasswordCommand returns the command to rotate a user's password
 	// Returns nil if password rotation is not supported
-	RotatePasswordCommand(username, newPassword, dbName string) []string
+	RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string
+
+	// CreateDatabaseCommand returns the command to create a logical database in the container
+	// Returns nil if multiple databases are not supported
+	CreateDatabaseCommand(dbName, adminPassword string) []string
+
+	// ListDatabasesCommand returns the command to list user databases, one name per line
+	// Returns nil if multiple databases are not supported
+	ListDatabasesCommand(adminPassword string) []string
+
+	// DropDatabaseCommand returns the command to drop a logical database from the container
+	// Returns nil if multiple databases are not supported
+	DropDatabaseCommand(dbName, adminPassword string) []string
+
+	// DataDirVersion returns the database version that wrote the data directory at dataDir,
+	// a host path mounted at GetDataPath. Returns UnknownVersion if it can't be determined
+	DataDirVersion(dataDir string) (string, error)
+
+	// GetPingCommand returns a command that runs a trivial query as the given user to check connectivity
+	// Pass empty strings for username and password for unauthenticated databases
+	GetPingCommand(username, password, dbName string) []string
+
+	// GetReadinessCommand returns a command that succeeds once a new container is ready for use,
+	// run by 'mkdb wait' and after creating a container. Returns nil if there's no check, then only
+	// the port is waited for
+	GetReadinessCommand(username, password, dbName string) []string
 
 	// FormatConnectionString returns the connection string for this database
 	FormatConnectionString(username, password, host, port, dbName string) string
 
-	// SupportsUsername returns whether this database supports username authentication
-	SupportsUsername() bool
+	// SupportsUsername returns whether users can be created in a container running version
+	// version may be a tag like "latest" when the server version isn't known
+	SupportsUsername(version string) bool
+
+	// SupportsMultipleDatabases returns whether named logical databases can be created in a container,
+	// which is required for the database commands to return anything but nil
+	SupportsMultipleDatabases() bool
 
 	// GetCommandArgs returns custom command line arguments for starting the container
 	// Returns empty slice if no custom command is needed
 	// Pass empty string for password to run in unauthenticated mode
 	GetCommandArgs(password string) []string
 
+	// BuildCommand returns the command for starting the container with extraArgs passed to the server
+	// after the arguments from GetCommandArgs. Returns empty slice if there are no arguments at all
+	BuildCommand(password string, extraArgs []string) []string
+
 	// GetVersionCommand returns the command to get the database version
 	// Returns nil if version detection is not supported
 	GetVersionCommand() []string
@@ -63,4 +103,74 @@ type DatabaseAdapter interface {
 	// ParseVersion parses the version output from GetVersionCommand
 	// Returns a clean version string (e.g., "16.1" instead of full output)
 	ParseVersion(output string) string
+
+	// GetConnectionInfoCommand returns the command that reports the server's client connections
+	// Returns nil if connection reporting is not supported
+	GetConnectionInfoCommand(adminPassword string) []string
+
+	// ParseConnectionInfo parses the output of GetConnectionInfoCommand
+	// Counts that can't be found in the output are left as UnknownCount
+	ParseConnectionInfo(output string) ConnInfo
+
+	// WebUIURL returns the address of a web interface served on the published port
+	// Returns an empty string if the database doesn't serve one
+	WebUIURL(host, port string) string
+
+	// SetMaxConnections returns config, the content of the config file, with the server's connection
+	// limit set to n. Returns config unchanged if the limit isn't set in the config file
+	SetMaxConnections(config string, n int) string
+}
+
+// UnknownVersion is returned by DataDirVersion when the version can't be determined,
+// such as for an empty data directory or a database that doesn't record it
+const UnknownVersion = "unknown"
+
+// Persistence modes for adapters that implement PersistenceAdapter
+const (
+	PersistenceNone = "none"
+	PersistenceRDB  = "rdb"
+	PersistenceAOF  = "aof"
+)
+
+// AdminPasswordAdapter is implemented by adapters that manage users through a separate
+// administrative account, such as MySQL's root user
+type AdminPasswordAdapter interface {
+	// GetAdminEnvVars returns the environment variables that set the administrative password
+	GetAdminEnvVars(adminPassword string) []string
+
+	// LegacyAdminPassword returns the administrative password used by containers created
+	// before mkdb generated one per container
+	LegacyAdminPassword() string
+}
+
+// SeedAdapter is implemented by adapters that can load a file of statements piped to a client
+// in the container, as done by mkdb start --seed
+type SeedAdapter interface {
+	// SeedCommand returns a command that runs the statements read from stdin against dbName,
+	// exiting with an error if any of them fail
+	SeedCommand(dbName, adminPassword string) []string
+}
+
+// UnauthenticatedAdapter is implemented by adapters for images that mkdb can only run without
+// authentication
+type UnauthenticatedAdapter interface {
+	// UnauthenticatedReason explains to the user why authentication isn't available
+	UnauthenticatedReason() string
+}
+
+// InMemoryUsersAdapter is implemented by adapters whose server keeps the users created with
+// CreateUserCommand only in memory, so they're gone whenever the server restarts
+type InMemoryUsersAdapter interface {
+	// UsersInMemory reports whether created users are lost when the server restarts
+	UsersInMemory() bool
+}
+
+// PersistenceAdapter is implemented by adapters whose persistence mode can be chosen at creation
+type PersistenceAdapter interface {
+	// GetPersistenceModes returns the supported persistence modes
+	GetPersistenceModes() []string
+
+	// BuildCommandArgs returns the command line arguments for the password and persistence mode
+	// Pass empty string for persistence to use the mode from the config file
+	BuildCommandArgs(password, persistence string) []string
 }
@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CockroachAdapter implements the DatabaseAdapter interface for CockroachDB
+// It runs a single insecure node, since secure mode needs TLS certificates that mkdb doesn't manage
+type CockroachAdapter struct{}
+
+func NewCockroachAdapter() *CockroachAdapter {
+	return &CockroachAdapter{}
+}
+
+// CockroachDefaultUser is the superuser that insecure clusters accept connections for
+const CockroachDefaultUser = "root"
+
+func (c *CockroachAdapter) GetName() string {
+	return "cockroachdb"
+}
+
+func (c *CockroachAdapter) GetAliases() []string {
+	return []string{"cockroachdb", "cockroach", "crdb"}
+}
+
+func (c *CockroachAdapter) GetImage(version string) string {
+	return resolveImage(c.GetName(), "cockroachdb/cockroach", "latest", version)
+}
+
+func (c *CockroachAdapter) GetDefaultPort() string {
+	return "26257"
+}
+
+func (c *CockroachAdapter) GetEnvVars(dbName, username, password string) []string {
+	// The image creates the database on first start, users can't have passwords in insecure mode
+	return []string{fmt.Sprintf("COCKROACH_DATABASE=%s", dbName)}
+}
+
+func (c *CockroachAdapter) GetDataPath() string {
+	return "/cockroach/cockroach-data"
+}
+
+func (c *CockroachAdapter) GetConfigPath() string {
+	// The server has no config file, the image runs these scripts once when the node is initialized
+	return "/docker-entrypoint-initdb.d"
+}
+
+func (c *CockroachAdapter) GetConfigFileName() string {
+	return "mkdb.sql"
+}
+
+func (c *CockroachAdapter) GetDefaultConfig() string {
+	return `-- CockroachDB initialization script
+-- Managed by mkdb
+-- Edit with: mkdb config
+--
+-- Runs once, when the container is first created. Later changes only apply to new containers,
+-- run them against an existing one with 'cockroach sql'
+
+-- Don't send usage reports from local databases
+SET CLUSTER SETTING diagnostics.reporting.enabled = false;
+`
+}
+
+func (c *CockroachAdapter) ValidateConfig(content string) error {
+	// SQL is checked by the server when the script runs
+	return nil
+}
+
+// cockroachSQL returns a command that runs sql with the built-in client as root
+func cockroachSQL(sql string, flags ...string) []string {
+	cmd := []string{"cockroach", "sql", "--insecure"}
+	cmd = append(cmd, flags...)
+	return append(cmd, "-e", sql)
+}
+
+func (c *CockroachAdapter) CreateUserCommand(username, password, dbName, adminPassword string) []string {
+	// Setting a password is rejected in insecure mode, any client can connect as any user
+	return cockroachSQL(fmt.Sprintf("CREATE USER IF NOT EXISTS %s; GRANT ALL ON DATABASE %s TO %s;", username, quoteIdent(dbName), username))
+}
+
+func (c *CockroachAdapter) DeleteUserCommand(username, dbName, adminPassword string) []string {
+	// Privileges have to be revoked before the user can be dropped
+	return cockroachSQL(fmt.Sprintf("REVOKE ALL ON DATABASE %s FROM %s; DROP USER IF EXISTS %s;", quoteIdent(dbName), username, username))
+}
+
+func (c *CockroachAdapter) RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string {
+	// Passwords aren't supported in insecure mode
+	return nil
+}
+
+func (c *CockroachAdapter) CreateDatabaseCommand(dbName, adminPassword string) []string {
+	return cockroachSQL(fmt.Sprintf("CREATE DATABASE %s;", quoteIdent(dbName)))
+}
+
+func (c *CockroachAdapter) ListDatabasesCommand(adminPassword string) []string {
+	// CSV output starts with a header line
+	sql := "SELECT database_name FROM [SHOW DATABASES] WHERE database_name NOT IN ('defaultdb', 'postgres', 'system') ORDER BY 1;"
+	return []string{"sh", "-c", `cockroach sql --insecure --format=csv -e "$0" | tail -n +2`, sql}
+}
+
+func (c *CockroachAdapter) DropDatabaseCommand(dbName, adminPassword string) []string {
+	return cockroachSQL(fmt.Sprintf("DROP DATABASE IF EXISTS %s CASCADE;", quoteIdent(dbName)))
+}
+
+func (c *CockroachAdapter) SeedCommand(dbName, adminPassword string) []string {
+	// Statements are read from stdin, stopping at the first error
+	return []string{"cockroach", "sql", "--insecure", "--set=errexit=true", "-d", dbName}
+}
+
+func (c *CockroachAdapter) GetConnectionInfoCommand(adminPassword string) []string {
+	// Sessions are reported per node, with no fixed connection limit to compare against
+	return nil
+}
+
+func (c *CockroachAdapter) ParseConnectionInfo(output string) ConnInfo {
+	return unknownConnInfo()
+}
+
+func (c *CockroachAdapter) WebUIURL(host, port string) string {
+	// The DB Console listens on 8080, which mkdb doesn't publish
+	return ""
+}
+
+func (c *CockroachAdapter) SetMaxConnections(config string, n int) string {
+	// The config file is an init script, the server is tuned with flags
+	return config
+}
+
+func (c *CockroachAdapter) DataDirVersion(dataDir string) (string, error) {
+	// The store only records a minimum binary version in an encoded file
+	return UnknownVersion, nil
+}
+
+func (c *CockroachAdapter) GetPingCommand(username, password, dbName string) []string {
+	if username == "" {
+		username = CockroachDefaultUser
+	}
+	return cockroachSQL("SELECT 1 AS status, current_user, current_database();", "-u", username, "-d", dbName)
+}
+
+func (c *CockroachAdapter) GetReadinessCommand(username, password, dbName string) []string {
+	// Node status answers once the node has joined its single-node cluster and serves SQL
+	return []string{"cockroach", "node", "status", "--insecure"}
+}
+
+func (c *CockroachAdapter) FormatConnectionString(username, password, host, port, dbName string) string {
+	// CockroachDB speaks the Postgres wire protocol. Insecure nodes don't serve TLS or check
+	// passwords, so the password is left out and sslmode is disabled
+	if username == "" {
+		username = CockroachDefaultUser
+	}
+	return fmt.Sprintf("postgresql://%s@%s:%s/%s?sslmode=disable", username, host, port, dbName)
+}
+
+func (c *CockroachAdapter) SupportsUsername(version string) bool {
+	return true
+}
+
+func (c *CockroachAdapter) SupportsMultipleDatabases() bool {
+	return true
+}
+
+func (c *CockroachAdapter) SupportsUnauthenticated() bool {
+	return true
+}
+
+func (c *CockroachAdapter) UnauthenticatedReason() string {
+	return "CockroachDB runs in insecure mode without passwords, secure mode needs TLS certificates that mkdb doesn't manage"
+}
+
+func (c *CockroachAdapter) GetCommandArgs(password string) []string {
+	// The image's entrypoint passes its arguments to the cockroach binary
+	return []string{"start-single-node", "--insecure"}
+}
+
+func (c *CockroachAdapter) BuildCommand(password string, extraArgs []string) []string {
+	return append(c.GetCommandArgs(password), extraArgs...)
+}
+
+func (c *CockroachAdapter) GetVersionCommand() []string {
+	return []string{"cockroach", "version", "--build-tag"}
+}
+
+func (c *CockroachAdapter) ParseVersion(output string) string {
+	// Input: "v24.3.0", which is also the image tag
+	return strings.TrimSpace(output)
+}
@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCockroachAdapter_FormatConnectionString(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     string
+	}{
+		{"default user", "", "", "postgresql://root@localhost:26257/orders?sslmode=disable"},
+		{"named user", "app", "", "postgresql://app@localhost:26257/orders?sslmode=disable"},
+		{"password is left out", "app", "secret", "postgresql://app@localhost:26257/orders?sslmode=disable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adapter.FormatConnectionString(tt.username, tt.password, "localhost", "26257", "orders")
+			if got != tt.want {
+				t.Errorf("FormatConnectionString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCockroachAdapter_Command(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	want := []string{"start-single-node", "--insecure"}
+	if got := adapter.BuildCommand("", nil); !slices.Equal(got, want) {
+		t.Errorf("BuildCommand() = %v, want %v", got, want)
+	}
+
+	want = []string{"start-single-node", "--insecure", "--cache=.25"}
+	if got := adapter.BuildCommand("", []string{"--cache=.25"}); !slices.Equal(got, want) {
+		t.Errorf("BuildCommand() with args = %v, want %v", got, want)
+	}
+}
+
+func TestCockroachAdapter_UserCommands(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	create := adapter.CreateUserCommand("app", "secret", "orders", "")
+	want := []string{"cockroach", "sql", "--insecure", "-e", "CREATE USER IF NOT EXISTS app; GRANT ALL ON DATABASE \"orders\" TO app;"}
+	if !slices.Equal(create, want) {
+		t.Errorf("CreateUserCommand() = %v, want %v", create, want)
+	}
+
+	// Insecure mode has no passwords to rotate
+	if cmd := adapter.RotatePasswordCommand("app", "new", "orders", ""); cmd != nil {
+		t.Errorf("RotatePasswordCommand() = %v, want nil", cmd)
+	}
+}
+
+func TestCockroachAdapter_PingCommand(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	// Without a user the query runs as root, it's still a SQL query like with a user
+	got := adapter.GetPingCommand("", "", "orders")
+	if !slices.Contains(got, "sql") || !slices.Contains(got, CockroachDefaultUser) || !slices.Contains(got, "orders") {
+		t.Errorf("GetPingCommand() without a user = %v, want a query as root on orders", got)
+	}
+
+	got = adapter.GetPingCommand("app", "secret", "orders")
+	if !slices.Contains(got, "-u") || !slices.Contains(got, "app") || !slices.Contains(got, "orders") {
+		t.Errorf("GetPingCommand() as app = %v, want a query as app on orders", got)
+	}
+}
+
+func TestCockroachAdapter_ReadinessCommand(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	// Readiness doesn't depend on the user
+	want := []string{"cockroach", "node", "status", "--insecure"}
+	for _, username := range []string{"", "app"} {
+		if got := adapter.GetReadinessCommand(username, "", "orders"); !slices.Equal(got, want) {
+			t.Errorf("GetReadinessCommand(%q) = %v, want %v", username, got, want)
+		}
+	}
+}
+
+func TestCockroachAdapter_Unauthenticated(t *testing.T) {
+	var adapter DatabaseAdapter = NewCockroachAdapter()
+
+	unauthenticated, ok := adapter.(UnauthenticatedAdapter)
+	if !ok || unauthenticated.UnauthenticatedReason() == "" {
+		t.Error("CockroachAdapter should only run without authentication")
+	}
+	if _, ok := adapter.(SeedAdapter); !ok {
+		t.Error("CockroachAdapter should support seed files")
+	}
+}
+
+func TestCockroachAdapter_ParseVersion(t *testing.T) {
+	adapter := NewCockroachAdapter()
+
+	if got := adapter.ParseVersion("v24.3.0\n"); got != "v24.3.0" {
+		t.Errorf("ParseVersion() = %q, want v24.3.0", got)
+	}
+}
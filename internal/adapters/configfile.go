@@ -0,0 +1,60 @@
+package adapters
+
+import "strings"
+
+// setConfigValue sets key to value in a "key = value" config file, replacing every line that sets it
+// For INI-style files, pass the section the key belongs in, it's created if missing. Pass an empty
+// section for files without sections. Dashes and underscores in key names are treated alike
+func setConfigValue(content, section, key, value string) string {
+	setting := key + " = " + value
+	lines := strings.Split(content, "\n")
+	normalize := func(k string) string { return strings.ReplaceAll(strings.TrimSpace(k), "-", "_") }
+
+	inSection := section == ""
+	sectionEnd := -1 // Line after the last setting of section, where a missing key is added
+	replaced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			inSection = section == "" || trimmed == "["+section+"]"
+			if inSection {
+				sectionEnd = i + 1
+			}
+			continue
+		}
+		if !inSection || trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+		sectionEnd = i + 1
+
+		name, _, found := strings.Cut(trimmed, "=")
+		if !found {
+			name, _, _ = strings.Cut(trimmed, " ")
+		}
+		if normalize(name) == normalize(key) {
+			lines[i] = setting
+			replaced = true
+		}
+	}
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+
+	switch {
+	case section != "" && sectionEnd == -1:
+		return appendLines(content, "["+section+"]", setting)
+	case section != "":
+		lines = append(lines[:sectionEnd], append([]string{setting}, lines[sectionEnd:]...)...)
+		return strings.Join(lines, "\n")
+	default:
+		return appendLines(content, setting)
+	}
+}
+
+// appendLines adds lines to the end of content, each ending in a newline
+func appendLines(content string, lines ...string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + strings.Join(lines, "\n") + "\n"
+}
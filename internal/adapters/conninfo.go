@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnknownCount marks a ConnInfo count the database didn't report
+const UnknownCount = -1
+
+// ConnInfo is a summary of a database server's client connections
+type ConnInfo struct {
+	Current int `json:"current"`
+	Idle    int `json:"idle"`
+	Max     int `json:"max"`
+}
+
+// unknownConnInfo returns a ConnInfo with every count unknown
+func unknownConnInfo() ConnInfo {
+	return ConnInfo{Current: UnknownCount, Idle: UnknownCount, Max: UnknownCount}
+}
+
+// String formats the counts, e.g. "3 of 100 (1 idle)", leaving out the ones that are unknown
+func (c ConnInfo) String() string {
+	if c.Current == UnknownCount {
+		return "unknown"
+	}
+
+	s := strconv.Itoa(c.Current)
+	if c.Max != UnknownCount {
+		s += fmt.Sprintf(" of %d", c.Max)
+	}
+	if c.Idle != UnknownCount {
+		s += fmt.Sprintf(" (%d idle)", c.Idle)
+	}
+	return s
+}
+
+// parseCounts parses a single row of separated counts into ConnInfo, in current, idle, max order
+// Fields that aren't numbers are left unknown
+func parseCounts(output, sep string) ConnInfo {
+	info := unknownConnInfo()
+	fields := strings.Split(strings.TrimSpace(output), sep)
+	counts := []*int{&info.Current, &info.Idle, &info.Max}
+	for i, field := range fields {
+		if i >= len(counts) {
+			break
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(field)); err == nil {
+			*counts[i] = n
+		}
+	}
+	return info
+}
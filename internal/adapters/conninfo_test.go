@@ -0,0 +1,69 @@
+package adapters
+
+import "testing"
+
+func TestParseConnectionInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter DatabaseAdapter
+		output  string
+		want    ConnInfo
+	}{
+		{
+			name:    "Postgres",
+			adapter: NewPostgresAdapter(),
+			output:  "3|1|100\n",
+			want:    ConnInfo{Current: 3, Idle: 1, Max: 100},
+		},
+		{
+			name:    "MySQL",
+			adapter: NewMySQLAdapter(),
+			output:  "2\t0\t151\n",
+			want:    ConnInfo{Current: 2, Idle: 0, Max: 151},
+		},
+		{
+			name:    "Redis",
+			adapter: NewRedisAdapter(),
+			output:  "# Clients\r\nconnected_clients:4\r\ncluster_connections:0\r\nmaxclients:10000\r\nblocked_clients:0\r\n",
+			want:    ConnInfo{Current: 4, Idle: UnknownCount, Max: 10000},
+		},
+		{
+			name:    "Redis before 7.0",
+			adapter: NewRedisAdapter(),
+			output:  "# Clients\r\nconnected_clients:1\r\nblocked_clients:0\r\n",
+			want:    ConnInfo{Current: 1, Idle: UnknownCount, Max: UnknownCount},
+		},
+		{
+			name:    "Unexpected output",
+			adapter: NewPostgresAdapter(),
+			output:  "ERROR: permission denied",
+			want:    ConnInfo{Current: UnknownCount, Idle: UnknownCount, Max: UnknownCount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.adapter.ParseConnectionInfo(tt.output); got != tt.want {
+				t.Errorf("ParseConnectionInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnInfoString(t *testing.T) {
+	tests := []struct {
+		info ConnInfo
+		want string
+	}{
+		{ConnInfo{Current: 3, Idle: 1, Max: 100}, "3 of 100 (1 idle)"},
+		{ConnInfo{Current: 4, Idle: UnknownCount, Max: 10000}, "4 of 10000"},
+		{ConnInfo{Current: 1, Idle: UnknownCount, Max: UnknownCount}, "1"},
+		{ConnInfo{Current: UnknownCount, Idle: UnknownCount, Max: UnknownCount}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataDirVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter DatabaseAdapter
+		files   map[string]string
+		want    string
+	}{
+		{
+			name:    "Postgres before 18",
+			adapter: NewPostgresAdapter(),
+			files:   map[string]string{"data/PG_VERSION": "16\n"},
+			want:    "16",
+		},
+		{
+			name:    "Postgres 18 layout",
+			adapter: NewPostgresAdapter(),
+			files:   map[string]string{"18/docker/PG_VERSION": "18\n"},
+			want:    "18",
+		},
+		{
+			name:    "Postgres empty volume",
+			adapter: NewPostgresAdapter(),
+			want:    UnknownVersion,
+		},
+		{
+			name:    "MySQL",
+			adapter: NewMySQLAdapter(),
+			files:   map[string]string{"mysql_upgrade_info": "8.0.35\x00"},
+			want:    "8.0.35",
+		},
+		{
+			name:    "MySQL empty volume",
+			adapter: NewMySQLAdapter(),
+			want:    UnknownVersion,
+		},
+		{
+			name:    "Redis",
+			adapter: NewRedisAdapter(),
+			files:   map[string]string{"dump.rdb": "REDIS0011"},
+			want:    UnknownVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			got, err := tt.adapter.DataDirVersion(dir)
+			if err != nil {
+				t.Fatalf("DataDirVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DataDirVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ElasticsearchAdapter implements the DatabaseAdapter interface for Elasticsearch
+// OpenSearch, a fork that keeps the same layout under a different home directory, is built on it
+type ElasticsearchAdapter struct {
+	name           string
+	aliases        []string
+	image          string
+	defaultVersion string
+	home           string // Install directory inside the image
+	server         string // Image command that starts the server with extra arguments
+}
+
+func NewElasticsearchAdapter() *ElasticsearchAdapter {
+	return &ElasticsearchAdapter{
+		name:    "elasticsearch",
+		aliases: []string{"elasticsearch", "es", "elastic"},
+		// The official image has no "latest" tag
+		image:          "elasticsearch",
+		defaultVersion: "8.17.0",
+		home:           "/usr/share/elasticsearch",
+		server:         "eswrapper",
+	}
+}
+
+// ElasticsearchDefaultUser is the built-in superuser that ELASTIC_PASSWORD sets the password for
+const ElasticsearchDefaultUser = "elastic"
+
+// IsElasticsearch reports whether a database type runs Elasticsearch with its security features
+func IsElasticsearch(dbType string) bool {
+	adapter, err := GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	_, ok := adapter.(*ElasticsearchAdapter)
+	return ok
+}
+
+func (e *ElasticsearchAdapter) GetName() string {
+	return e.name
+}
+
+func (e *ElasticsearchAdapter) GetAliases() []string {
+	return e.aliases
+}
+
+func (e *ElasticsearchAdapter) GetImage(version string) string {
+	return resolveImage(e.GetName(), e.image, e.defaultVersion, version)
+}
+
+func (e *ElasticsearchAdapter) GetDefaultPort() string {
+	return "9200"
+}
+
+func (e *ElasticsearchAdapter) GetEnvVars(dbName, username, password string) []string {
+	// A single node skips the production bootstrap checks, such as vm.max_map_count
+	envVars := []string{"discovery.type=single-node"}
+
+	// If password is empty, run with security disabled
+	// Otherwise security is on for authentication only, over plain HTTP since mkdb doesn't manage certificates
+	if password == "" {
+		return append(envVars, "xpack.security.enabled=false")
+	}
+	return append(envVars,
+		"xpack.security.enabled=true",
+		"xpack.security.http.ssl.enabled=false",
+		"xpack.security.transport.ssl.enabled=false",
+		fmt.Sprintf("ELASTIC_PASSWORD=%s", password),
+	)
+}
+
+func (e *ElasticsearchAdapter) GetDataPath() string {
+	return e.home + "/data"
+}
+
+func (e *ElasticsearchAdapter) GetConfigPath() string {
+	// Mounting over the whole config directory would hide the files the image ships, but
+	// JVM options are also read from this directory
+	return e.home + "/config/jvm.options.d"
+}
+
+func (e *ElasticsearchAdapter) GetConfigFileName() string {
+	return "mkdb.options"
+}
+
+func (e *ElasticsearchAdapter) GetDefaultConfig() string {
+	return `# JVM options
+# Managed by mkdb
+# Edit with: mkdb config
+#
+# Server settings can't be set here, pass them to mkdb start with --env, e.g.
+# --env indices.query.bool.max_clause_count=4096
+
+# Heap size, the server otherwise takes half of the memory Docker gives it
+-Xms512m
+-Xmx512m
+`
+}
+
+func (e *ElasticsearchAdapter) ValidateConfig(content string) error {
+	return validateJVMOptions(content)
+}
+
+// curlCommand returns a curl request to the server from inside the container, authenticated
+// as the built-in superuser when adminPassword is set. -f makes HTTP errors fail the command
+func (e *ElasticsearchAdapter) curlCommand(adminPassword, method, path string, args ...string) []string {
+	cmd := []string{"curl", "-sSf", "-X", method}
+	if adminPassword != "" {
+		cmd = append(cmd, "-u", ElasticsearchDefaultUser+":"+adminPassword)
+	}
+	cmd = append(cmd, args...)
+	return append(cmd, "http://localhost:9200"+path)
+}
+
+// jsonBody returns curl arguments that send v as a JSON request body
+func jsonBody(v any) []string {
+	// Marshaling maps of strings can't fail
+	body, _ := json.Marshal(v)
+	return []string{"-H", "Content-Type: application/json", "-d", string(body)}
+}
+
+func (e *ElasticsearchAdapter) CreateUserCommand(username, password, dbName, adminPassword string) []string {
+	// Users get full access through the built-in superuser role, like users of the other databases
+	body := jsonBody(map[string]any{"password": password, "roles": []string{"superuser"}})
+	return e.curlCommand(adminPassword, "POST", "/_security/user/"+url.PathEscape(username), body...)
+}
+
+func (e *ElasticsearchAdapter) DeleteUserCommand(username, dbName, adminPassword string) []string {
+	return e.curlCommand(adminPassword, "DELETE", "/_security/user/"+url.PathEscape(username))
+}
+
+func (e *ElasticsearchAdapter) RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string {
+	if username == "" {
+		username = ElasticsearchDefaultUser
+	}
+	body := jsonBody(map[string]string{"password": newPassword})
+	return e.curlCommand(adminPassword, "POST", "/_security/user/"+url.PathEscape(username)+"/_password", body...)
+}
+
+func (e *ElasticsearchAdapter) CreateDatabaseCommand(dbName, adminPassword string) []string {
+	// Indices are created on first write, there are no separate databases to manage
+	return nil
+}
+
+func (e *ElasticsearchAdapter) ListDatabasesCommand(adminPassword string) []string {
+	// Indices are created on first write, there are no separate databases to manage
+	return nil
+}
+
+func (e *ElasticsearchAdapter) DropDatabaseCommand(dbName, adminPassword string) []string {
+	// Indices are created on first write, there are no separate databases to manage
+	return nil
+}
+
+func (e *ElasticsearchAdapter) SeedCommand(dbName, adminPassword string) []string {
+	// Seed files are _bulk request bodies (NDJSON). The bulk API responds 200 even when some of
+	// the actions fail, so the response is checked for errors
+	curl := e.curlCommand(adminPassword, "POST", "/_bulk?refresh=true&filter_path=errors,items.*.error",
+		"-H", "Content-Type: application/x-ndjson", "--data-binary", "@-")
+	script := `out=$("$@") || exit 1; case "$out" in *'"errors":false'*) ;; *) echo "$out" >&2; exit 1 ;; esac`
+	return append([]string{"sh", "-c", script, "sh"}, curl...)
+}
+
+func (e *ElasticsearchAdapter) GetConnectionInfoCommand(adminPassword string) []string {
+	return e.curlCommand(adminPassword, "GET", "/_nodes/stats/http?filter_path=nodes.*.http.current_open")
+}
+
+func (e *ElasticsearchAdapter) ParseConnectionInfo(output string) ConnInfo {
+	// Input: {"nodes":{"<id>":{"http":{"current_open":3}}}}
+	// Open HTTP connections are reported, there's no idle count or limit
+	info := unknownConnInfo()
+	var stats struct {
+		Nodes map[string]struct {
+			HTTP struct {
+				CurrentOpen *int `json:"current_open"`
+			} `json:"http"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		return info
+	}
+	for _, node := range stats.Nodes {
+		if node.HTTP.CurrentOpen == nil {
+			continue
+		}
+		if info.Current == UnknownCount {
+			info.Current = 0
+		}
+		info.Current += *node.HTTP.CurrentOpen
+	}
+	return info
+}
+
+func (e *ElasticsearchAdapter) WebUIURL(host, port string) string {
+	// The REST API answers in the browser over plain HTTP, which asks for the user's credentials if security is on
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+func (e *ElasticsearchAdapter) SetMaxConnections(config string, n int) string {
+	// Elasticsearch has no connection limit, the config file holds JVM options
+	return config
+}
+
+func (e *ElasticsearchAdapter) DataDirVersion(dataDir string) (string, error) {
+	// The node metadata is binary, so the version isn't tracked
+	return UnknownVersion, nil
+}
+
+func (e *ElasticsearchAdapter) GetPingCommand(username, password, dbName string) []string {
+	// The root endpoint returns the cluster info, or 401 for a wrong password
+	return e.curlAsUser(username, password, "/")
+}
+
+func (e *ElasticsearchAdapter) GetReadinessCommand(username, password, dbName string) []string {
+	// The cluster health endpoint answers before shards are allocated, so wait for at least yellow
+	return e.curlAsUser(username, password, "/_cluster/health?wait_for_status=yellow&timeout=1s")
+}
+
+// curlAsUser is a GET request like curlCommand, authenticated as username, the superuser if it's empty
+func (e *ElasticsearchAdapter) curlAsUser(username, password, path string) []string {
+	cmd := []string{"curl", "-sSf"}
+	if password != "" {
+		if username == "" {
+			username = ElasticsearchDefaultUser
+		}
+		cmd = append(cmd, "-u", username+":"+password)
+	}
+	return append(cmd, "http://localhost:9200"+path)
+}
+
+func (e *ElasticsearchAdapter) FormatConnectionString(username, password, host, port, dbName string) string {
+	// Connection string format: http://[user:password@]host:port
+	// Indices are named per request, so dbName isn't part of it
+	u := url.URL{Scheme: "http", Host: host + ":" + port}
+	if password != "" {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String()
+}
+
+func (e *ElasticsearchAdapter) SupportsUsername(version string) bool {
+	return true
+}
+
+func (e *ElasticsearchAdapter) SupportsMultipleDatabases() bool {
+	// Indices are created on first write, there are no separate databases to manage
+	return false
+}
+
+func (e *ElasticsearchAdapter) SupportsUnauthenticated() bool {
+	return true
+}
+
+func (e *ElasticsearchAdapter) GetCommandArgs(password string) []string {
+	// Settings are passed as environment variables, the image's default command is used
+	return []string{}
+}
+
+func (e *ElasticsearchAdapter) BuildCommand(password string, extraArgs []string) []string {
+	if len(extraArgs) == 0 {
+		return e.GetCommandArgs(password)
+	}
+	// The image's entrypoint passes arguments after its default command to the server, e.g. -Ename=value
+	return append([]string{e.server}, extraArgs...)
+}
+
+func (e *ElasticsearchAdapter) GetVersionCommand() []string {
+	return []string{e.home + "/bin/" + e.name, "--version"}
+}
+
+func (e *ElasticsearchAdapter) ParseVersion(output string) string {
+	// Input: "Version: 8.17.0, Build: docker/2b6a7fed44faa321997703718f07ee0420804b41/2024-12-11T12:08:05.663969764Z, JVM: 23"
+	// Output: "8.17.0"
+	for _, part := range strings.Split(output, ",") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(part), "Version: "); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return strings.TrimSpace(output)
+}
+
+// OpenSearchAdapter implements the DatabaseAdapter interface for OpenSearch
+// Its security plugin needs TLS certificates that mkdb doesn't manage, so it always runs without authentication
+type OpenSearchAdapter struct {
+	*ElasticsearchAdapter
+}
+
+func NewOpenSearchAdapter() *OpenSearchAdapter {
+	return &OpenSearchAdapter{&ElasticsearchAdapter{
+		name:           "opensearch",
+		aliases:        []string{"opensearch"},
+		image:          "opensearchproject/opensearch",
+		defaultVersion: "2.18.0",
+		home:           "/usr/share/opensearch",
+		server:         "opensearch",
+	}}
+}
+
+func (o *OpenSearchAdapter) GetEnvVars(dbName, username, password string) []string {
+	// Without the demo configuration no initial admin password is required
+	return []string{
+		"discovery.type=single-node",
+		"DISABLE_SECURITY_PLUGIN=true",
+		"DISABLE_INSTALL_DEMO_CONFIG=true",
+	}
+}
+
+func (o *OpenSearchAdapter) CreateUserCommand(username, password, dbName, adminPassword string) []string {
+	// Users are managed by the security plugin, which is disabled
+	return nil
+}
+
+func (o *OpenSearchAdapter) DeleteUserCommand(username, dbName, adminPassword string) []string {
+	// Users are managed by the security plugin, which is disabled
+	return nil
+}
+
+func (o *OpenSearchAdapter) RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string {
+	// Users are managed by the security plugin, which is disabled
+	return nil
+}
+
+func (o *OpenSearchAdapter) SupportsUsername(version string) bool {
+	return false
+}
+
+func (o *OpenSearchAdapter) UnauthenticatedReason() string {
+	return "OpenSearch runs without authentication, its security plugin needs TLS certificates that mkdb doesn't manage"
+}
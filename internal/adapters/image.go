@@ -0,0 +1,36 @@
+package adapters
+
+import (
+	"os"
+	"strings"
+)
+
+// ImageEnvVar returns the environment variable that overrides an adapter's default image repository
+// (e.g., MKDB_POSTGRES_IMAGE)
+func ImageEnvVar(name string) string {
+	return "MKDB_" + strings.ToUpper(name) + "_IMAGE"
+}
+
+// VersionEnvVar returns the environment variable that overrides an adapter's default version
+// (e.g., MKDB_POSTGRES_VERSION)
+func VersionEnvVar(name string) string {
+	return "MKDB_" + strings.ToUpper(name) + "_VERSION"
+}
+
+// resolveImage builds an image reference, letting MKDB_<NAME>_IMAGE replace the repository
+// and MKDB_<NAME>_VERSION replace the default version. An explicit version always wins.
+func resolveImage(name, defaultImage, defaultVersion, version string) string {
+	image := defaultImage
+	if override := strings.TrimSpace(os.Getenv(ImageEnvVar(name))); override != "" {
+		image = override
+	}
+
+	if version == "" {
+		version = defaultVersion
+		if override := strings.TrimSpace(os.Getenv(VersionEnvVar(name))); override != "" {
+			version = override
+		}
+	}
+
+	return image + ":" + version
+}
@@ -0,0 +1,73 @@
+package adapters
+
+import "testing"
+
+func TestGetImageOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter DatabaseAdapter
+		env     map[string]string
+		version string
+		want    string
+	}{
+		{
+			name:    "Postgres default",
+			adapter: NewPostgresAdapter(),
+			want:    "postgres:18",
+		},
+		{
+			name:    "Postgres image override",
+			adapter: NewPostgresAdapter(),
+			env:     map[string]string{"MKDB_POSTGRES_IMAGE": "registry.local:5000/postgres"},
+			want:    "registry.local:5000/postgres:18",
+		},
+		{
+			name:    "Postgres version override",
+			adapter: NewPostgresAdapter(),
+			env:     map[string]string{"MKDB_POSTGRES_VERSION": "16"},
+			want:    "postgres:16",
+		},
+		{
+			name:    "Explicit version wins over version override",
+			adapter: NewPostgresAdapter(),
+			env:     map[string]string{"MKDB_POSTGRES_VERSION": "16"},
+			version: "15",
+			want:    "postgres:15",
+		},
+		{
+			name:    "MySQL image and version override",
+			adapter: NewMySQLAdapter(),
+			env:     map[string]string{"MKDB_MYSQL_IMAGE": "mariadb", "MKDB_MYSQL_VERSION": "11"},
+			want:    "mariadb:11",
+		},
+		{
+			name:    "Redis image override keeps explicit version",
+			adapter: NewRedisAdapter(),
+			env:     map[string]string{"MKDB_REDIS_IMAGE": "valkey/valkey"},
+			version: "7.2",
+			want:    "valkey/valkey:7.2",
+		},
+		{
+			name:    "Blank override is ignored",
+			adapter: NewRedisAdapter(),
+			env:     map[string]string{"MKDB_REDIS_IMAGE": " "},
+			want:    "redis:8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"POSTGRES", "MYSQL", "REDIS"} {
+				t.Setenv("MKDB_"+name+"_IMAGE", "")
+				t.Setenv("MKDB_"+name+"_VERSION", "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if got := tt.adapter.GetImage(tt.version); got != tt.want {
+				t.Errorf("GetImage(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
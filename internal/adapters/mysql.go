@@ -2,6 +2,9 @@ package adapters
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -21,10 +24,7 @@ func (m *MySQLAdapter) GetAliases() []string {
 }
 
 func (m *MySQLAdapter) GetImage(version string) string {
-	if version == "" {
-		version = "latest"
-	}
-	return fmt.Sprintf("mysql:%s", version)
+	return resolveImage(m.GetName(), "mysql", "latest", version)
 }
 
 func (m *MySQLAdapter) GetDefaultPort() string {
@@ -37,11 +37,11 @@ func (m *MySQLAdapter) GetEnvVars(dbName, username, password string) []string {
 	}
 
 	// If username and password are empty, allow unauthenticated root login
+	// The root password is added separately by GetAdminEnvVars
 	if username != "" && password != "" {
 		envVars = append(envVars,
 			fmt.Sprintf("MYSQL_USER=%s", username),
 			fmt.Sprintf("MYSQL_PASSWORD=%s", password),
-			"MYSQL_ROOT_PASSWORD=rootpassword",
 		)
 	} else {
 		// Allow empty root password for unauthenticated access
@@ -78,26 +78,117 @@ general_log_file = /var/log/mysql/general.log
 `
 }
 
-func (m *MySQLAdapter) CreateUserCommand(username, password, dbName string) []string {
-	return []string{
-		"mysql", "-u", "root", "-prootpassword", "-e",
-		fmt.Sprintf("CREATE USER '%s'@'%%' IDENTIFIED BY '%s'; GRANT ALL PRIVILEGES ON %s.* TO '%s'@'%%'; FLUSH PRIVILEGES;",
-			username, password, dbName, username),
+func (m *MySQLAdapter) ValidateConfig(content string) error {
+	return validateMySQLConfig(content)
+}
+
+func (m *MySQLAdapter) GetAdminEnvVars(adminPassword string) []string {
+	return []string{fmt.Sprintf("MYSQL_ROOT_PASSWORD=%s", adminPassword)}
+}
+
+func (m *MySQLAdapter) LegacyAdminPassword() string {
+	return "rootpassword"
+}
+
+// rootCommand returns a mysql client invocation as root, without a password for unauthenticated containers
+func rootCommand(adminPassword string, args ...string) []string {
+	cmd := []string{"mysql", "-u", "root"}
+	if adminPassword != "" {
+		cmd = append(cmd, "-p"+adminPassword)
 	}
+	return append(cmd, args...)
+}
+
+func (m *MySQLAdapter) CreateUserCommand(username, password, dbName, adminPassword string) []string {
+	return rootCommand(adminPassword, "-e",
+		fmt.Sprintf("CREATE USER '%s'@'%%' IDENTIFIED BY '%s'; GRANT ALL PRIVILEGES ON `%s`.* TO '%s'@'%%'; FLUSH PRIVILEGES;",
+			username, password, dbName, username))
+}
+
+func (m *MySQLAdapter) DeleteUserCommand(username, dbName, adminPassword string) []string {
+	return rootCommand(adminPassword, "-e",
+		fmt.Sprintf("DROP USER IF EXISTS '%s'@'%%'; FLUSH PRIVILEGES;", username))
+}
+
+func (m *MySQLAdapter) RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string {
+	return rootCommand(adminPassword, "-e",
+		fmt.Sprintf("ALTER USER '%s'@'%%' IDENTIFIED BY '%s'; FLUSH PRIVILEGES;", username, newPassword))
+}
+
+func (m *MySQLAdapter) CreateDatabaseCommand(dbName, adminPassword string) []string {
+	return rootCommand(adminPassword, "-e",
+		fmt.Sprintf("CREATE DATABASE `%s`;", dbName))
+}
+
+func (m *MySQLAdapter) ListDatabasesCommand(adminPassword string) []string {
+	return rootCommand(adminPassword, "-N", "-B", "-e",
+		"SELECT schema_name FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys') ORDER BY schema_name;")
+}
+
+func (m *MySQLAdapter) DropDatabaseCommand(dbName, adminPassword string) []string {
+	return rootCommand(adminPassword, "-e",
+		fmt.Sprintf("DROP DATABASE IF EXISTS `%s`;", dbName))
+}
+
+func (m *MySQLAdapter) SeedCommand(dbName, adminPassword string) []string {
+	// In batch mode mysql stops at the first failed statement
+	return rootCommand(adminPassword, dbName)
 }
 
-func (m *MySQLAdapter) DeleteUserCommand(username, dbName string) []string {
-	return []string{
-		"mysql", "-u", "root", "-prootpassword", "-e",
-		fmt.Sprintf("DROP USER IF EXISTS '%s'@'%%'; FLUSH PRIVILEGES;", username),
+func (m *MySQLAdapter) GetConnectionInfoCommand(adminPassword string) []string {
+	return rootCommand(adminPassword, "-N", "-B", "-e",
+		"SELECT (SELECT VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Threads_connected'), (SELECT COUNT(*) FROM information_schema.processlist WHERE command = 'Sleep'), @@max_connections;")
+}
+
+func (m *MySQLAdapter) ParseConnectionInfo(output string) ConnInfo {
+	// Input: "3\t1\t151"
+	return parseCounts(output, "\t")
+}
+
+func (m *MySQLAdapter) WebUIURL(host, port string) string {
+	// mkdb doesn't bundle a web client, see 'mkdb open' for running one next to the database
+	return ""
+}
+
+func (m *MySQLAdapter) SetMaxConnections(config string, n int) string {
+	return setConfigValue(config, "mysqld", "max_connections", strconv.Itoa(n))
+}
+
+func (m *MySQLAdapter) DataDirVersion(dataDir string) (string, error) {
+	// Written by the server after initializing or upgrading the data directory
+	path := filepath.Join(dataDir, "mysql_upgrade_info")
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return UnknownVersion, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
 	}
+
+	// Content is the full server version, possibly NUL terminated (e.g., "8.0.35")
+	version := strings.TrimSpace(strings.TrimRight(string(content), "\x00"))
+	if version == "" {
+		return UnknownVersion, nil
+	}
+	return version, nil
 }
 
-func (m *MySQLAdapter) RotatePasswordCommand(username, newPassword, dbName string) []string {
-	return []string{
-		"mysql", "-u", "root", "-prootpassword", "-e",
-		fmt.Sprintf("ALTER USER '%s'@'%%' IDENTIFIED BY '%s'; FLUSH PRIVILEGES;", username, newPassword),
+func (m *MySQLAdapter) GetPingCommand(username, password, dbName string) []string {
+	cmd := []string{"mysql"}
+	if username != "" {
+		cmd = append(cmd, "-u", username, "-p"+password)
+	} else {
+		cmd = append(cmd, "-u", "root")
 	}
+	return append(cmd,
+		dbName,
+		"-e", "SELECT 1 as status, USER() as user, DATABASE() as db;",
+	)
+}
+
+func (m *MySQLAdapter) GetReadinessCommand(username, password, dbName string) []string {
+	// The test query only succeeds once the server accepts connections
+	return m.GetPingCommand(username, password, dbName)
 }
 
 func (m *MySQLAdapter) FormatConnectionString(username, password, host, port, dbName string) string {
@@ -108,7 +199,11 @@ func (m *MySQLAdapter) FormatConnectionString(username, password, host, port, db
 	return fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s", username, password, host, port, dbName)
 }
 
-func (m *MySQLAdapter) SupportsUsername() bool {
+func (m *MySQLAdapter) SupportsUsername(version string) bool {
+	return true
+}
+
+func (m *MySQLAdapter) SupportsMultipleDatabases() bool {
 	return true
 }
 
@@ -121,6 +216,14 @@ func (m *MySQLAdapter) GetCommandArgs(password string) []string {
 	return []string{}
 }
 
+func (m *MySQLAdapter) BuildCommand(password string, extraArgs []string) []string {
+	if len(extraArgs) == 0 {
+		return m.GetCommandArgs(password)
+	}
+	// Replacing the image's command also replaces its default "mysqld"
+	return append([]string{"mysqld"}, extraArgs...)
+}
+
 func (m *MySQLAdapter) GetVersionCommand() []string {
 	return []string{"mysqld", "--version"}
 }
@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMySQLAdapter_AdminPassword(t *testing.T) {
+	adapter := NewMySQLAdapter()
+
+	env := adapter.GetEnvVars("shop", "dbuser", "secret")
+	for _, pair := range env {
+		if pair == "MYSQL_ROOT_PASSWORD=rootpassword" {
+			t.Errorf("GetEnvVars() = %q, should not hardcode the root password", env)
+		}
+	}
+
+	if got := adapter.GetAdminEnvVars("rootsecret"); !slices.Equal(got, []string{"MYSQL_ROOT_PASSWORD=rootsecret"}) {
+		t.Errorf("GetAdminEnvVars() = %q, want [MYSQL_ROOT_PASSWORD=rootsecret]", got)
+	}
+}
+
+func TestMySQLAdapter_RootCommands(t *testing.T) {
+	adapter := NewMySQLAdapter()
+
+	tests := []struct {
+		name          string
+		adminPassword string
+		want          []string
+	}{
+		{"With admin password", "rootsecret", []string{"mysql", "-u", "root", "-prootsecret", "-e"}},
+		{"Unauthenticated", "", []string{"mysql", "-u", "root", "-e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := map[string][]string{
+				"CreateUserCommand":     adapter.CreateUserCommand("app", "pass", "shop", tt.adminPassword),
+				"DeleteUserCommand":     adapter.DeleteUserCommand("app", "shop", tt.adminPassword),
+				"RotatePasswordCommand": adapter.RotatePasswordCommand("app", "pass", "shop", tt.adminPassword),
+				"CreateDatabaseCommand": adapter.CreateDatabaseCommand("orders", tt.adminPassword),
+				"DropDatabaseCommand":   adapter.DropDatabaseCommand("orders", tt.adminPassword),
+			}
+			for name, cmd := range commands {
+				if len(cmd) < len(tt.want) || !slices.Equal(cmd[:len(tt.want)], tt.want) {
+					t.Errorf("%s() = %q, want prefix %q", name, cmd, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMySQLAdapter_SeedCommand(t *testing.T) {
+	want := []string{"mysql", "-u", "root", "-prootsecret", "shop"}
+	if got := NewMySQLAdapter().SeedCommand("shop", "rootsecret"); !slices.Equal(got, want) {
+		t.Errorf("SeedCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestMySQLAdapter_BuildCommand(t *testing.T) {
+	adapter := NewMySQLAdapter()
+
+	if got := adapter.BuildCommand("secret", nil); len(got) != 0 {
+		t.Errorf("BuildCommand() = %q, want empty", got)
+	}
+
+	want := []string{"mysqld", "--max-connections=500"}
+	if got := adapter.BuildCommand("secret", []string{"--max-connections=500"}); !slices.Equal(got, want) {
+		t.Errorf("BuildCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestMySQLSetMaxConnections(t *testing.T) {
+	adapter := NewMySQLAdapter()
+
+	config := adapter.SetMaxConnections(adapter.GetDefaultConfig(), 500)
+	if !strings.Contains(config, "\nmax_connections = 500\n") || strings.Contains(config, "max_connections = 100") {
+		t.Errorf("SetMaxConnections() didn't replace the default limit:\n%s", config)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"Dashed option", "[mysqld]\nmax-connections=10\n", "[mysqld]\nmax_connections = 50\n"},
+		{"Added to the section", "[client]\nport = 3306\n[mysqld]\ngeneral_log = 1\n", "[client]\nport = 3306\n[mysqld]\ngeneral_log = 1\nmax_connections = 50\n"},
+		{"Other section only", "[client]\nmax_connections = 10\n", "[client]\nmax_connections = 10\n[mysqld]\nmax_connections = 50\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adapter.SetMaxConnections(tt.config, 50); got != tt.want {
+				t.Errorf("SetMaxConnections() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,29 +2,91 @@ package adapters
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 // PostgresAdapter implements the DatabaseAdapter interface for PostgreSQL
-type PostgresAdapter struct{}
+// Images built on the official Postgres image, such as TimescaleDB, use it with a different name and image
+type PostgresAdapter struct {
+	name           string
+	aliases        []string
+	image          string
+	defaultVersion string
+}
+
+// PostgresOption configures a PostgresAdapter
+type PostgresOption func(*PostgresAdapter)
+
+// WithPostgresName sets the adapter's database type name and aliases
+func WithPostgresName(name string, aliases ...string) PostgresOption {
+	return func(p *PostgresAdapter) {
+		p.name = name
+		p.aliases = aliases
+	}
+}
+
+// WithPostgresImage sets the image repository and the version used when none is requested
+func WithPostgresImage(image, defaultVersion string) PostgresOption {
+	return func(p *PostgresAdapter) {
+		p.image = image
+		p.defaultVersion = defaultVersion
+	}
+}
 
-func NewPostgresAdapter() *PostgresAdapter {
-	return &PostgresAdapter{}
+func NewPostgresAdapter(opts ...PostgresOption) *PostgresAdapter {
+	p := &PostgresAdapter{
+		name:           "postgres",
+		aliases:        []string{"pg", "postgres", "postgresql"},
+		image:          "postgres",
+		defaultVersion: "18",
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewTimescaleAdapter returns a Postgres adapter for the TimescaleDB image
+// Its tags pair a TimescaleDB release with a Postgres major version (e.g., 2.17.2-pg17)
+func NewTimescaleAdapter() *PostgresAdapter {
+	return NewPostgresAdapter(
+		WithPostgresName("timescaledb", "timescaledb", "timescale", "tsdb"),
+		WithPostgresImage("timescale/timescaledb", "latest-pg17"),
+	)
+}
+
+// NewPostGISAdapter returns a Postgres adapter for the PostGIS image
+// Its tags pair a Postgres major version with a PostGIS release (e.g., 17-3.5)
+func NewPostGISAdapter() *PostgresAdapter {
+	return NewPostgresAdapter(
+		WithPostgresName("postgis", "postgis"),
+		WithPostgresImage("postgis/postgis", "17-3.5"),
+	)
+}
+
+// IsPostgres reports whether a database type runs PostgreSQL, including variants like TimescaleDB
+func IsPostgres(dbType string) bool {
+	adapter, err := GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	_, ok := adapter.(*PostgresAdapter)
+	return ok
 }
 
 func (p *PostgresAdapter) GetName() string {
-	return "postgres"
+	return p.name
 }
 
 func (p *PostgresAdapter) GetAliases() []string {
-	return []string{"pg", "postgres", "postgresql"}
+	return p.aliases
 }
 
 func (p *PostgresAdapter) GetImage(version string) string {
-	if version == "" {
-		version = "18"
-	}
-	return fmt.Sprintf("postgres:%s", version)
+	return resolveImage(p.GetName(), p.image, p.defaultVersion, version)
 }
 
 func (p *PostgresAdapter) GetDefaultPort() string {
@@ -80,26 +142,133 @@ log_statement = 'all'
 `
 }
 
-func (p *PostgresAdapter) CreateUserCommand(username, password, dbName string) []string {
-	return []string{
-		"psql", "-U", "dbuser", "-d", dbName, "-c",
+func (p *PostgresAdapter) ValidateConfig(content string) error {
+	return validatePostgresConfig(content)
+}
+
+// psqlCommand builds a psql command that runs as the container's superuser
+// The superuser is POSTGRES_USER, or postgres when running without authentication
+func psqlCommand(dbName, sql string, flags ...string) []string {
+	script := `exec psql -U "${POSTGRES_USER:-postgres}" -d "$0"`
+	for _, flag := range flags {
+		script += " " + flag
+	}
+	script += ` -c "$1"`
+	return []string{"sh", "-c", script, dbName, sql}
+}
+
+// quoteIdent quotes a database name for Postgres-compatible SQL. Names may contain hyphens and
+// uppercase letters, which unquoted identifiers reject or fold to lowercase
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (p *PostgresAdapter) CreateUserCommand(username, password, dbName, adminPassword string) []string {
+	return psqlCommand(dbName,
 		fmt.Sprintf("CREATE USER %s WITH PASSWORD '%s'; GRANT ALL PRIVILEGES ON DATABASE %s TO %s;",
-			username, password, dbName, username),
+			username, password, quoteIdent(dbName), username))
+}
+
+func (p *PostgresAdapter) DeleteUserCommand(username, dbName, adminPassword string) []string {
+	return psqlCommand(dbName, fmt.Sprintf("DROP USER IF EXISTS %s;", username))
+}
+
+func (p *PostgresAdapter) RotatePasswordCommand(username, newPassword, dbName, adminPassword string) []string {
+	return psqlCommand(dbName, fmt.Sprintf("ALTER USER %s WITH PASSWORD '%s';", username, newPassword))
+}
+
+func (p *PostgresAdapter) CreateDatabaseCommand(dbName, adminPassword string) []string {
+	return psqlCommand("postgres", fmt.Sprintf("CREATE DATABASE %s;", quoteIdent(dbName)))
+}
+
+func (p *PostgresAdapter) ListDatabasesCommand(adminPassword string) []string {
+	return psqlCommand("postgres",
+		"SELECT datname FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres' ORDER BY datname;",
+		"-A", "-t")
+}
+
+func (p *PostgresAdapter) DropDatabaseCommand(dbName, adminPassword string) []string {
+	return psqlCommand("postgres", fmt.Sprintf("DROP DATABASE IF EXISTS %s;", quoteIdent(dbName)))
+}
+
+func (p *PostgresAdapter) SeedCommand(dbName, adminPassword string) []string {
+	// Without ON_ERROR_STOP psql carries on past failed statements and exits 0
+	script := `exec psql -v ON_ERROR_STOP=1 -q -U "${POSTGRES_USER:-postgres}" -d "$0"`
+	return []string{"sh", "-c", script, dbName}
+}
+
+func (p *PostgresAdapter) GetConnectionInfoCommand(adminPassword string) []string {
+	return psqlCommand("postgres",
+		"SELECT count(*), count(*) FILTER (WHERE state = 'idle'), current_setting('max_connections') FROM pg_stat_activity WHERE backend_type = 'client backend';",
+		"-A", "-t")
+}
+
+func (p *PostgresAdapter) ParseConnectionInfo(output string) ConnInfo {
+	// Input: "3|1|100"
+	return parseCounts(output, "|")
+}
+
+func (p *PostgresAdapter) WebUIURL(host, port string) string {
+	// mkdb doesn't bundle a web client, see 'mkdb open' for running one next to the database
+	return ""
+}
+
+func (p *PostgresAdapter) SetMaxConnections(config string, n int) string {
+	return setConfigValue(config, "", "max_connections", strconv.Itoa(n))
+}
+
+func (p *PostgresAdapter) DataDirVersion(dataDir string) (string, error) {
+	path, err := PostgresVersionFile(dataDir)
+	if err != nil || path == "" {
+		return UnknownVersion, err
 	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(content)), nil
 }
 
-func (p *PostgresAdapter) DeleteUserCommand(username, dbName string) []string {
-	return []string{
-		"psql", "-U", "dbuser", "-d", dbName, "-c",
-		fmt.Sprintf("DROP USER IF EXISTS %s;", username),
+// PostgresVersionFile returns the path of the PG_VERSION file in a Postgres data directory, or ""
+// if there isn't one. Images before 18 keep PGDATA in data/, 18 and later use <major>/docker/
+func PostgresVersionFile(dataDir string) (string, error) {
+	candidates := []string{filepath.Join(dataDir, "data", "PG_VERSION")}
+	matches, err := filepath.Glob(filepath.Join(dataDir, "*", "docker", "PG_VERSION"))
+	if err != nil {
+		return "", err
+	}
+	candidates = append(candidates, matches...)
+
+	for _, path := range candidates {
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return path, nil
 	}
+	return "", nil
 }
 
-func (p *PostgresAdapter) RotatePasswordCommand(username, newPassword, dbName string) []string {
-	return []string{
-		"psql", "-U", "dbuser", "-d", dbName, "-c",
-		fmt.Sprintf("ALTER USER %s WITH PASSWORD '%s';", username, newPassword),
+func (p *PostgresAdapter) GetPingCommand(username, password, dbName string) []string {
+	query := "SELECT 1 as status, current_user, current_database();"
+	if username == "" || password == "" {
+		// Unauthenticated containers only have the postgres superuser
+		return []string{"psql", "-U", "postgres", "-d", dbName, "-c", query}
 	}
+
+	// The image trusts socket and loopback connections, so connect to the container's own
+	// address to make the server check the password
+	script := `PGPASSWORD="$1" exec psql -h "$(hostname -i | cut -d ' ' -f 1)" -U "$0" -d "$2" -c "$3"`
+	return []string{"sh", "-c", script, username, password, dbName, query}
+}
+
+func (p *PostgresAdapter) GetReadinessCommand(username, password, dbName string) []string {
+	// The test query only succeeds once the server accepts connections
+	return p.GetPingCommand(username, password, dbName)
 }
 
 func (p *PostgresAdapter) FormatConnectionString(username, password, host, port, dbName string) string {
@@ -110,7 +279,11 @@ func (p *PostgresAdapter) FormatConnectionString(username, password, host, port,
 	return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", username, password, host, port, dbName)
 }
 
-func (p *PostgresAdapter) SupportsUsername() bool {
+func (p *PostgresAdapter) SupportsUsername(version string) bool {
+	return true
+}
+
+func (p *PostgresAdapter) SupportsMultipleDatabases() bool {
 	return true
 }
 
@@ -123,6 +296,14 @@ func (p *PostgresAdapter) GetCommandArgs(password string) []string {
 	return []string{}
 }
 
+func (p *PostgresAdapter) BuildCommand(password string, extraArgs []string) []string {
+	if len(extraArgs) == 0 {
+		return p.GetCommandArgs(password)
+	}
+	// Replacing the image's command also replaces its default "postgres"
+	return append([]string{"postgres"}, extraArgs...)
+}
+
 func (p *PostgresAdapter) GetVersionCommand() []string {
 	return []string{"postgres", "--version"}
 }
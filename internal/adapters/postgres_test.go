@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPostgresAdapter_GetPingCommand(t *testing.T) {
+	adapter := NewPostgresAdapter()
+
+	// Unauthenticated containers connect over the socket as the superuser
+	got := adapter.GetPingCommand("", "", "mydb")
+	if got[0] != "psql" || !slices.Contains(got, "postgres") {
+		t.Errorf("GetPingCommand() = %q, want psql as postgres", got)
+	}
+
+	// Authenticated pings go over TCP so the password is checked, and pass values as arguments
+	got = adapter.GetPingCommand("dbuser", "secret", "mydb")
+	if len(got) < 6 || got[0] != "sh" || !strings.Contains(got[2], "-h") {
+		t.Fatalf("GetPingCommand() = %q, want a TCP psql connection", got)
+	}
+	if !slices.Equal(got[3:6], []string{"dbuser", "secret", "mydb"}) {
+		t.Errorf("GetPingCommand() args = %q, want [dbuser secret mydb]", got[3:6])
+	}
+	if strings.Contains(got[2], "secret") {
+		t.Errorf("GetPingCommand() script = %q, should not embed the password", got[2])
+	}
+}
+
+func TestPostgresVariants(t *testing.T) {
+	tests := []struct {
+		adapter *PostgresAdapter
+		name    string
+		want    string
+	}{
+		{NewPostgresAdapter(), "postgres", "postgres:18"},
+		{NewTimescaleAdapter(), "timescaledb", "timescale/timescaledb:latest-pg17"},
+		{NewPostGISAdapter(), "postgis", "postgis/postgis:17-3.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.adapter.GetName(); got != tt.name {
+				t.Errorf("GetName() = %q, want %q", got, tt.name)
+			}
+			if got := tt.adapter.GetImage(""); got != tt.want {
+				t.Errorf("GetImage() = %q, want %q", got, tt.want)
+			}
+			if !IsPostgres(tt.name) {
+				t.Errorf("IsPostgres(%q) = false, want true", tt.name)
+			}
+		})
+	}
+
+	if IsPostgres("mysql") {
+		t.Error("IsPostgres(mysql) = true, want false")
+	}
+}
+
+func TestPostgresAdapter_SeedCommand(t *testing.T) {
+	got := NewPostgresAdapter().SeedCommand("my db", "")
+	if len(got) != 4 || got[0] != "sh" || got[3] != "my db" {
+		t.Fatalf("SeedCommand() = %q, want a psql script with the database as an argument", got)
+	}
+	if !strings.Contains(got[2], "ON_ERROR_STOP=1") {
+		t.Errorf("SeedCommand() script = %q, want it to stop on the first error", got[2])
+	}
+}
+
+func TestPostgresAdapter_DatabaseCommandsQuoteName(t *testing.T) {
+	adapter := NewPostgresAdapter()
+
+	tests := []struct {
+		name string
+		got  []string
+		want string
+	}{
+		{"CreateDatabaseCommand", adapter.CreateDatabaseCommand("My-App", ""), `CREATE DATABASE "My-App";`},
+		{"DropDatabaseCommand", adapter.DropDatabaseCommand("My-App", ""), `DROP DATABASE IF EXISTS "My-App";`},
+		{"CreateUserCommand", adapter.CreateUserCommand("app", "secret", "My-App", ""),
+			`CREATE USER app WITH PASSWORD 'secret'; GRANT ALL PRIVILEGES ON DATABASE "My-App" TO app;`},
+	}
+	for _, tt := range tests {
+		if sql := tt.got[len(tt.got)-1]; sql != tt.want {
+			t.Errorf("%s() SQL = %q, want %q", tt.name, sql, tt.want)
+		}
+	}
+
+	if got := quoteIdent(`a"b`); got != `"a""b"` {
+		t.Errorf(`quoteIdent(a"b) = %s, want "a""b"`, got)
+	}
+}
+
+func TestPostgresAdapter_BuildCommand(t *testing.T) {
+	adapter := NewPostgresAdapter()
+
+	// Without extra args the image's default command is kept
+	if got := adapter.BuildCommand("secret", nil); len(got) != 0 {
+		t.Errorf("BuildCommand() = %q, want empty", got)
+	}
+
+	want := []string{"postgres", "-c", "shared_buffers=256MB"}
+	if got := adapter.BuildCommand("secret", []string{"-c", "shared_buffers=256MB"}); !slices.Equal(got, want) {
+		t.Errorf("BuildCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestPostgresSetMaxConnections(t *testing.T) {
+	adapter := NewPostgresAdapter()
+
+	config := adapter.SetMaxConnections(adapter.GetDefaultConfig(), 500)
+	if !strings.Contains(config, "\nmax_connections = 500\n") || strings.Contains(config, "max_connections = 100") {
+		t.Errorf("SetMaxConnections() didn't replace the default limit:\n%s", config)
+	}
+	if err := adapter.ValidateConfig(config); err != nil {
+		t.Errorf("ValidateConfig() error = %v", err)
+	}
+
+	if got, want := adapter.SetMaxConnections("shared_buffers = 128MB", 50), "shared_buffers = 128MB\nmax_connections = 50\n"; got != want {
+		t.Errorf("SetMaxConnections() without a limit = %q, want %q", got, want)
+	}
+}
@@ -30,6 +30,11 @@ func GetRegistry() *Registry {
 		defaultRegistry.Register(NewPostgresAdapter())
 		defaultRegistry.Register(NewMySQLAdapter())
 		defaultRegistry.Register(NewRedisAdapter())
+		defaultRegistry.Register(NewTimescaleAdapter())
+		defaultRegistry.Register(NewPostGISAdapter())
+		defaultRegistry.Register(NewElasticsearchAdapter())
+		defaultRegistry.Register(NewOpenSearchAdapter())
+		defaultRegistry.Register(NewCockroachAdapter())
 	})
 	return defaultRegistry
 }
@@ -75,8 +80,8 @@ func (r *Registry) List() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Define explicit order: postgres, redis, mysql
-	order := []string{"postgres", "redis", "mysql"}
+	// Define explicit order: postgres, redis, mysql, then the Postgres variants, search engines and CockroachDB
+	order := []string{"postgres", "redis", "mysql", "timescaledb", "postgis", "elasticsearch", "opensearch", "cockroachdb"}
 	names := make([]string, 0, len(r.adapters))
 
 	// Add adapters in the defined order if they exist
@@ -118,6 +123,34 @@ func (r *Registry) NormalizeType(dbType string) (string, error) {
 	return adapter.GetName(), nil
 }
 
+// DetectType returns the database type whose image repository matches image, e.g. "postgres" for
+// "postgres:16" or "docker.io/library/postgres:16". Returns false if no adapter uses the image
+func (r *Registry) DetectType(image string) (string, bool) {
+	repo := imageRepository(image)
+	for _, name := range r.List() {
+		adapter, err := r.Get(name)
+		if err != nil {
+			continue
+		}
+		// Adapters only expose full image references, so take the repository from a placeholder version
+		adapterRepo := imageRepository(adapter.GetImage("latest"))
+		if repo == adapterRepo || strings.HasSuffix(repo, "/"+adapterRepo) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// imageRepository strips the tag and digest from an image reference
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(strings.TrimSpace(image), "@")
+	// A colon before the last slash belongs to a registry port, not a tag
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
 // GetAllAliases returns a map of all aliases to their canonical names
 func (r *Registry) GetAllAliases() map[string]string {
 	r.mu.RLock()
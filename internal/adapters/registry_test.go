@@ -43,6 +43,36 @@ func TestRegistry_Get(t *testing.T) {
 			wantName:  "redis",
 			wantError: false,
 		},
+		{
+			name:      "timescaledb by alias",
+			dbType:    "timescale",
+			wantName:  "timescaledb",
+			wantError: false,
+		},
+		{
+			name:      "postgis by name",
+			dbType:    "postgis",
+			wantName:  "postgis",
+			wantError: false,
+		},
+		{
+			name:      "elasticsearch by alias es",
+			dbType:    "es",
+			wantName:  "elasticsearch",
+			wantError: false,
+		},
+		{
+			name:      "opensearch by name",
+			dbType:    "opensearch",
+			wantName:  "opensearch",
+			wantError: false,
+		},
+		{
+			name:      "cockroachdb by alias crdb",
+			dbType:    "crdb",
+			wantName:  "cockroachdb",
+			wantError: false,
+		},
 		{
 			name:      "invalid database type",
 			dbType:    "mongodb",
@@ -130,14 +160,19 @@ func TestRegistry_List(t *testing.T) {
 	registry := GetRegistry()
 	types := registry.List()
 
-	if len(types) != 3 {
-		t.Errorf("List() returned %d types, want 3", len(types))
+	if len(types) != 8 {
+		t.Errorf("List() returned %d types, want 8", len(types))
 	}
 
 	expectedTypes := map[string]bool{
-		"postgres": true,
-		"mysql":    true,
-		"redis":    true,
+		"postgres":      true,
+		"mysql":         true,
+		"redis":         true,
+		"timescaledb":   true,
+		"postgis":       true,
+		"elasticsearch": true,
+		"opensearch":    true,
+		"cockroachdb":   true,
 	}
 
 	for _, dbType := range types {
@@ -170,8 +205,8 @@ func TestRegistry_ListOrder(t *testing.T) {
 		}
 	}
 
-	// Verify the expected order: postgres, redis, mysql
-	expectedOrder := []string{"postgres", "redis", "mysql"}
+	// Verify the expected order: postgres, redis, mysql, then the Postgres variants, search engines and CockroachDB
+	expectedOrder := []string{"postgres", "redis", "mysql", "timescaledb", "postgis", "elasticsearch", "opensearch", "cockroachdb"}
 	types := registry.List()
 
 	if len(types) != len(expectedOrder) {
@@ -273,3 +308,86 @@ func TestAdapters_Interface(t *testing.T) {
 		})
 	}
 }
+
+func TestSupportsMultipleDatabases(t *testing.T) {
+	registry := GetRegistry()
+
+	tests := []struct {
+		dbType string
+		want   bool
+	}{
+		{"postgres", true},
+		{"timescaledb", true},
+		{"postgis", true},
+		{"mysql", true},
+		{"redis", false},
+		{"elasticsearch", false},
+		{"opensearch", false},
+		{"cockroachdb", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			adapter, err := registry.Get(tt.dbType)
+			if err != nil {
+				t.Fatalf("Get() error: %v", err)
+			}
+			if got := adapter.SupportsMultipleDatabases(); got != tt.want {
+				t.Errorf("SupportsMultipleDatabases() = %v, want %v", got, tt.want)
+			}
+
+			// The database commands must agree with SupportsMultipleDatabases
+			if got := adapter.CreateDatabaseCommand("app", "secret") != nil; got != tt.want {
+				t.Errorf("CreateDatabaseCommand() returned a command = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_DetectType(t *testing.T) {
+	registry := GetRegistry()
+
+	tests := []struct {
+		image  string
+		want   string
+		wantOK bool
+	}{
+		{"postgres:16", "postgres", true},
+		{"postgres", "postgres", true},
+		{"docker.io/library/postgres:16-alpine", "postgres", true},
+		{"localhost:5000/postgres:16", "postgres", true},
+		{"postgres@sha256:0123456789abcdef", "postgres", true},
+		{"redis:7", "redis", true},
+		{"mysql:8.4", "mysql", true},
+		{"timescale/timescaledb:latest-pg17", "timescaledb", true},
+		{"postgis/postgis:17-3.5", "postgis", true},
+		{"cockroachdb/cockroach:v24.3.0", "cockroachdb", true},
+		{"mongo:7", "", false},
+		{"mypostgres:16", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, ok := registry.DetectType(tt.image)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("DetectType(%q) = %q, %v, want %q, %v", tt.image, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSupportsUsernameMatchesUserCommands(t *testing.T) {
+	registry := GetRegistry()
+
+	// An adapter that reports usernames must be able to create users, and the other way around
+	for _, dbType := range registry.List() {
+		adapter, err := registry.Get(dbType)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", dbType, err)
+		}
+		canCreate := adapter.CreateUserCommand("app", "secret", "app", "admin") != nil
+		if got := adapter.SupportsUsername("latest"); got != canCreate {
+			t.Errorf("%s: SupportsUsername(latest) = %v, but CreateUserCommand() != nil is %v", dbType, got, canCreate)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package adapters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// postgresKeyPattern matches PostgreSQL parameter names, including custom "ext.param" names
+	postgresKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+	// mysqlKeyPattern matches MySQL option names, which may use dashes or underscores
+	mysqlKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// redisDirectivePattern matches Redis config directive names
+	redisDirectivePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+	// jvmOptionPattern matches a JVM option, optionally limited to a range of Java versions ("17-:-Xmx1g")
+	jvmOptionPattern = regexp.MustCompile(`^([0-9]+(-[0-9]*)?:)?-\S`)
+)
+
+// configLine is a non-empty, non-comment line of a config file
+type configLine struct {
+	num  int
+	text string
+}
+
+// configLines returns the non-empty, non-comment lines of a config file in order
+func configLines(content string, commentPrefixes ...string) []configLine {
+	var lines []configLine
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		comment := false
+		for _, prefix := range commentPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				comment = true
+				break
+			}
+		}
+		if !comment {
+			lines = append(lines, configLine{num: i + 1, text: line})
+		}
+	}
+	return lines
+}
+
+// checkQuotes returns an error if value contains an unterminated quoted string
+func checkQuotes(value string) error {
+	for _, quote := range []string{"'", `"`} {
+		if strings.Count(value, quote)%2 != 0 {
+			return fmt.Errorf("unterminated %s quote", quote)
+		}
+	}
+	return nil
+}
+
+// validatePostgresConfig checks postgresql.conf syntax: "name = value" or "name value"
+func validatePostgresConfig(content string) error {
+	for _, l := range configLines(content, "#") {
+		lineNum, line := l.num, l.text
+
+		// Strip trailing comments outside of quotes
+		if idx := strings.Index(line, "#"); idx != -1 && strings.Count(line[:idx], "'")%2 == 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			key, value, found = strings.Cut(line, " ")
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if !postgresKeyPattern.MatchString(key) {
+			return fmt.Errorf("line %d: invalid parameter name %q", lineNum, key)
+		}
+		if !found || value == "" {
+			return fmt.Errorf("line %d: missing value for %s", lineNum, key)
+		}
+		if err := checkQuotes(value); err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	return nil
+}
+
+// validateMySQLConfig checks my.cnf syntax: "[section]" headers followed by "name = value" or "name" options
+func validateMySQLConfig(content string) error {
+	inSection := false
+	for _, l := range configLines(content, "#", ";") {
+		lineNum, line := l.num, l.text
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return fmt.Errorf("line %d: malformed section header %q", lineNum, line)
+			}
+			inSection = true
+			continue
+		}
+
+		// Include directives may appear anywhere
+		if strings.HasPrefix(line, "!include") {
+			continue
+		}
+
+		if !inSection {
+			return fmt.Errorf("line %d: option %q appears before any [section] header", lineNum, line)
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !mysqlKeyPattern.MatchString(key) {
+			return fmt.Errorf("line %d: invalid option name %q", lineNum, key)
+		}
+		if found {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return fmt.Errorf("line %d: missing value for %s", lineNum, key)
+			}
+			if err := checkQuotes(value); err != nil {
+				return fmt.Errorf("line %d: %w", lineNum, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRedisConfig checks redis.conf syntax: "directive arg [arg ...]"
+func validateRedisConfig(content string) error {
+	for _, l := range configLines(content, "#") {
+		lineNum, fields := l.num, strings.Fields(l.text)
+		directive := fields[0]
+
+		if strings.Contains(directive, "=") {
+			return fmt.Errorf("line %d: redis.conf uses \"directive value\", not \"key=value\" (%q)", lineNum, directive)
+		}
+		if !redisDirectivePattern.MatchString(directive) {
+			return fmt.Errorf("line %d: invalid directive %q", lineNum, directive)
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("line %d: missing value for %s", lineNum, directive)
+		}
+		if err := checkQuotes(strings.Join(fields[1:], " ")); err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	return nil
+}
+
+// validateJVMOptions checks a jvm.options file: one option per line, such as "-Xmx1g"
+func validateJVMOptions(content string) error {
+	for _, l := range configLines(content, "#") {
+		if !jvmOptionPattern.MatchString(l.text) {
+			return fmt.Errorf("line %d: JVM options start with \"-\" (%q)", l.num, l.text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig_Defaults(t *testing.T) {
+	registry := GetRegistry()
+
+	for _, name := range registry.List() {
+		t.Run(name, func(t *testing.T) {
+			adapter, err := registry.Get(name)
+			if err != nil {
+				t.Fatalf("Get() error: %v", err)
+			}
+			if err := adapter.ValidateConfig(adapter.GetDefaultConfig()); err != nil {
+				t.Errorf("ValidateConfig(GetDefaultConfig()) error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter DatabaseAdapter
+		content string
+		wantErr string
+	}{
+		{
+			name:    "postgres valid with spaces and comments",
+			adapter: NewPostgresAdapter(),
+			content: "# comment\nmax_connections = 200 # trailing\nshared_buffers 256MB\nlog_line_prefix = '%m # [%p] '\n",
+		},
+		{
+			name:    "postgres missing value",
+			adapter: NewPostgresAdapter(),
+			content: "max_connections = 100\nshared_buffers =\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "postgres invalid parameter name",
+			adapter: NewPostgresAdapter(),
+			content: "max-connections = 100\n",
+			wantErr: "invalid parameter name",
+		},
+		{
+			name:    "postgres unterminated quote",
+			adapter: NewPostgresAdapter(),
+			content: "log_directory = 'log\n",
+			wantErr: "unterminated",
+		},
+		{
+			name:    "mysql valid with flags",
+			adapter: NewMySQLAdapter(),
+			content: "[mysqld]\nmax_connections = 100\nskip-name-resolve\n; comment\n[client]\nport=3306\n",
+		},
+		{
+			name:    "mysql option before section",
+			adapter: NewMySQLAdapter(),
+			content: "max_connections = 100\n[mysqld]\n",
+			wantErr: "before any [section]",
+		},
+		{
+			name:    "mysql malformed section",
+			adapter: NewMySQLAdapter(),
+			content: "[mysqld\nmax_connections = 100\n",
+			wantErr: "malformed section",
+		},
+		{
+			name:    "mysql missing value",
+			adapter: NewMySQLAdapter(),
+			content: "[mysqld]\nmax_connections =\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "redis valid",
+			adapter: NewRedisAdapter(),
+			content: "bind 0.0.0.0 ::1\nsave 900 1\nrequirepass \"secret\"\n",
+		},
+		{
+			name:    "redis key=value syntax",
+			adapter: NewRedisAdapter(),
+			content: "port 6379\nmaxmemory=100mb\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "redis missing value",
+			adapter: NewRedisAdapter(),
+			content: "appendonly\n",
+			wantErr: "missing value",
+		},
+		{
+			name:    "elasticsearch valid with version ranges",
+			adapter: NewElasticsearchAdapter(),
+			content: "# heap\n-Xmx1g\n17-:-XX:+UseG1GC\n11-16:-Xss1m\n",
+		},
+		{
+			name:    "elasticsearch setting instead of option",
+			adapter: NewElasticsearchAdapter(),
+			content: "-Xmx1g\ncluster.name: dev\n",
+			wantErr: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.adapter.ValidateConfig(tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ValidateConfig() expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateConfig() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
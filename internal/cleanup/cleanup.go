@@ -3,6 +3,7 @@ package cleanup
 import (
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -11,10 +12,12 @@ import (
 	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/database"
 	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/volumes"
 )
 
 // Run checks for and cleans up expired containers
-func Run() error {
+// If prompt is false, or the terminal isn't interactive, expired containers are only logged
+func Run(prompt bool) error {
 	containers, err := database.GetExpiredContainers()
 	if err != nil {
 		return fmt.Errorf("failed to get expired containers: %w", err)
@@ -26,9 +29,16 @@ func Run() error {
 
 	config.Logger.Info("Found expired containers", "count", len(containers))
 
+	if !prompt {
+		return nil
+	}
+
 	// Check if we're in an interactive terminal
 	if !isatty.IsTerminal(os.Stdin.Fd()) {
 		config.Logger.Info("Non-interactive terminal detected, skipping cleanup prompt")
+		for _, c := range containers {
+			recordSkipped(c, "terminal is not interactive")
+		}
 		return nil
 	}
 
@@ -47,7 +57,7 @@ func RunInteractive(containers []*database.Container) error {
 	extendedCount := 0
 	if len(toExtend) > 0 {
 		for _, c := range toExtend {
-			if err := extendContainer(c, extendHours); err != nil {
+			if err := ExtendContainer(c, extendHours); err != nil {
 				config.Logger.Error("Failed to extend container", "name", c.DisplayName, "error", err)
 				fmt.Printf("✗ Failed to extend %s: %v\n", c.DisplayName, err)
 				continue
@@ -82,16 +92,7 @@ func RunInteractive(containers []*database.Container) error {
 	}
 
 	// Clean up selected containers
-	removedCount := 0
-	for _, c := range toRemove {
-		if err := cleanupContainer(c); err != nil {
-			config.Logger.Error("Failed to cleanup container", "name", c.DisplayName, "error", err)
-			fmt.Printf("✗ Failed to remove %s: %v\n", c.DisplayName, err)
-			continue
-		}
-		fmt.Printf("✓ Removed %s (%s)\n", c.DisplayName, c.Type)
-		removedCount++
-	}
+	removedCount := printRemovals(removeContainers(toRemove))
 
 	// Print summary
 	if extendedCount > 0 || removedCount > 0 {
@@ -109,6 +110,18 @@ func RunInteractive(containers []*database.Container) error {
 	return nil
 }
 
+// RemoveAll removes every given container without prompting
+func RemoveAll(containers []*database.Container) error {
+	removedCount := printRemovals(removeContainers(containers))
+
+	fmt.Println()
+	fmt.Printf("✓ Removed %d container(s)\n", removedCount)
+	if removedCount < len(containers) {
+		return fmt.Errorf("failed to remove %d container(s)", len(containers)-removedCount)
+	}
+	return nil
+}
+
 // promptForExtend shows an interactive prompt to select expired containers to extend
 func promptForExtend(containers []*database.Container) ([]*database.Container, int, error) {
 	// Build options for multiselect
@@ -199,6 +212,9 @@ func promptForRemoval(containers []*database.Container) ([]*database.Container,
 		expiredStr := formatExpiredDuration(expired)
 
 		label := fmt.Sprintf("%s (%s) - expired %s ago", c.DisplayName, c.Type, expiredStr)
+		if c.Adopted {
+			label += " - adopted, removing deletes its Docker container"
+		}
 		options[i] = huh.NewOption(label, c)
 	}
 
@@ -249,12 +265,16 @@ func formatExpiredDuration(d time.Duration) string {
 	return fmt.Sprintf("%d days", days)
 }
 
-// extendContainer extends the TTL of a container, handling expired containers correctly
-func extendContainer(c *database.Container, hours int) error {
+// ExtendContainer extends the TTL of a container, handling expired containers correctly
+func ExtendContainer(c *database.Container, hours int) error {
 	config.Logger.Info("Extending container TTL", "name", c.DisplayName, "hours", hours)
 
-	// If container is already expired, extend from now instead of from old expiration time
-	if time.Now().After(c.ExpiresAt) {
+	// A pinned container's expiry is a placeholder, so extending unpins it and counts from now
+	if c.Pinned {
+		c.Pinned = false
+		c.ExpiresAt = time.Now().Add(time.Duration(hours) * time.Hour)
+	} else if time.Now().After(c.ExpiresAt) {
+		// If container is already expired, extend from now instead of from old expiration time
 		config.Logger.Info("Container is expired, extending from current time", "name", c.DisplayName)
 		c.ExpiresAt = time.Now().Add(time.Duration(hours) * time.Hour)
 	} else {
@@ -282,29 +302,195 @@ func extendContainer(c *database.Container, hours int) error {
 	return nil
 }
 
-func cleanupContainer(c *database.Container) error {
+// PinnedExpiry is the expiration recorded for pinned containers, far enough out that they never expire
+var PinnedExpiry = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// SetExpiry sets a container's expiration to an absolute time, unpinning it
+func SetExpiry(c *database.Container, expiresAt time.Time) error {
+	c.ExpiresAt = expiresAt
+	c.Pinned = false
+	return updateExpiry(c, "ttl_set", fmt.Sprintf("Expiration set to %s", expiresAt.UTC().Format(time.RFC3339)))
+}
+
+// Pin keeps a container from expiring, cleanup ignores it until its expiration is set again
+func Pin(c *database.Container) error {
+	c.ExpiresAt = PinnedExpiry
+	c.Pinned = true
+	return updateExpiry(c, "ttl_pinned", "Pinned, the container no longer expires")
+}
+
+// updateExpiry saves a container's new expiration and records why it changed
+func updateExpiry(c *database.Container, eventType, details string) error {
+	if err := database.UpdateContainer(c); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
+
+	event := &database.Event{
+		ContainerID: c.ID,
+		EventType:   eventType,
+		Timestamp:   time.Now(),
+		Details:     details,
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+
+	config.Logger.Info("Container expiration changed", "name", c.DisplayName, "new_expiration", c.ExpiresAt, "pinned", c.Pinned)
+	return nil
+}
+
+// recordSkipped records that an expired container was left in place, once per expiration,
+// since the cleanup check runs before every command
+func recordSkipped(c *database.Container, reason string) {
+	recorded, err := database.HasEventSince(c.ID, "cleanup_skipped", c.ExpiresAt)
+	if err != nil {
+		config.Logger.Warn("Failed to check events", "name", c.DisplayName, "error", err)
+		return
+	}
+	if recorded {
+		return
+	}
+
+	event := &database.Event{
+		ContainerID: c.ID,
+		EventType:   "cleanup_skipped",
+		Timestamp:   time.Now(),
+		Details:     "Expired container was not cleaned up: " + reason,
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+}
+
+// recordFailure logs a cleanup step that failed and records it as an event, so a leftover
+// container or volume can be explained later
+func recordFailure(c *database.Container, step string, err error) {
+	config.Logger.Warn("Failed to "+step, "name", c.DisplayName, "error", err)
+
+	event := &database.Event{
+		ContainerID: c.ID,
+		EventType:   "cleanup_failed",
+		Timestamp:   time.Now(),
+		Details:     fmt.Sprintf("Failed to %s: %v", step, err),
+	}
+	if err := database.CreateEvent(event); err != nil {
+		config.Logger.Warn("Failed to log event", "error", err)
+	}
+}
+
+// maxParallelRemovals bounds how many containers are stopped and removed at once,
+// so a long list of expired containers doesn't flood the Docker daemon with requests
+const maxParallelRemovals = 4
+
+// stepFailure is a cleanup step that failed in Docker, recorded as an event afterwards
+type stepFailure struct {
+	step string
+	err  error
+}
+
+// removalResult is the outcome of cleaning up one container
+type removalResult struct {
+	container *database.Container
+	failures  []stepFailure // Docker steps that failed, which don't stop the cleanup
+	err       error         // Set if the container couldn't be marked removed
+}
+
+// removeContainers cleans up containers, returning a result for each in the same order
+// The Docker work runs in parallel, the database writes afterwards one at a time, since SQLite
+// allows a single writer
+func removeContainers(containers []*database.Container) []removalResult {
+	if len(containers) > 1 {
+		fmt.Printf("Removing %d containers...\n", len(containers))
+	}
+	// Like 'mkdb remove', warn before deleting a container mkdb didn't create
+	for _, c := range containers {
+		if c.Adopted {
+			fmt.Printf("⚠ '%s' was adopted, mkdb didn't create it. Removing it deletes the Docker container '%s' and any data stored in it\n", c.DisplayName, c.Name)
+		}
+	}
+	results := removeParallel(containers, maxParallelRemovals, removeFromDocker)
+	for i := range results {
+		results[i].err = recordRemoval(results[i].container, results[i].failures)
+	}
+	return results
+}
+
+// removeParallel runs remove for each container on up to workers goroutines
+func removeParallel(containers []*database.Container, workers int, remove func(*database.Container) []stepFailure) []removalResult {
+	results := make([]removalResult, len(containers))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(containers)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = removalResult{container: containers[i], failures: remove(containers[i])}
+			}
+		}()
+	}
+	for i := range containers {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// printRemovals prints a line for each result and returns how many containers were removed
+func printRemovals(results []removalResult) int {
+	removed := 0
+	for _, r := range results {
+		c := r.container
+		if r.err != nil {
+			config.Logger.Error("Failed to cleanup container", "name", c.DisplayName, "error", r.err)
+			fmt.Printf("✗ Failed to remove %s: %v\n", c.DisplayName, r.err)
+			continue
+		}
+		fmt.Printf("✓ Removed %s (%s)\n", c.DisplayName, c.Type)
+		removed++
+	}
+	return removed
+}
+
+// removeFromDocker stops and removes a container and its Docker volume, and deletes its snapshots
+// It doesn't write to mkdb's database, so it can run for several containers at once
+func removeFromDocker(c *database.Container) []stepFailure {
 	config.Logger.Info("Cleaning up expired container", "name", c.DisplayName)
 
+	var failures []stepFailure
 	// Stop the container if it exists
 	if c.ContainerID != "" && docker.ContainerExists(c.ContainerID) {
-		if err := docker.StopContainer(c.ContainerID); err != nil {
-			config.Logger.Warn("Failed to stop container", "name", c.DisplayName, "error", err)
+		if err := docker.StopContainer(c.ContainerID, docker.DefaultStopTimeout); err != nil {
+			failures = append(failures, stepFailure{"stop container", err})
 		}
 
 		// Remove the container
 		if err := docker.RemoveContainer(c.ContainerID); err != nil {
-			config.Logger.Warn("Failed to remove container", "name", c.DisplayName, "error", err)
+			failures = append(failures, stepFailure{"remove container", err})
 		}
 	}
 
 	// Remove volume if it exists
 	if c.VolumePath != "" {
 		if err := docker.RemoveVolume(c.VolumePath); err != nil {
-			config.Logger.Warn("Failed to remove volume", "name", c.DisplayName, "error", err)
+			failures = append(failures, stepFailure{"remove volume", err})
 		}
 	}
+	if err := volumes.RemoveSnapshots(c.DisplayName); err != nil {
+		failures = append(failures, stepFailure{"remove snapshots", err})
+	}
+	return failures
+}
+
+// recordRemoval records the failed steps and the expiration of a cleaned up container, and marks it removed
+func recordRemoval(c *database.Container, failures []stepFailure) error {
+	for _, f := range failures {
+		recordFailure(c, f.step, f.err)
+	}
 
-	// Log the event before deleting from database
+	// Log the event before marking the container removed
 	event := &database.Event{
 		ContainerID: c.ID,
 		EventType:   "expired",
@@ -315,9 +501,9 @@ func cleanupContainer(c *database.Container) error {
 		config.Logger.Warn("Failed to log event", "error", err)
 	}
 
-	// Delete from database entirely instead of just marking as expired
-	if err := database.DeleteContainer(c.ID); err != nil {
-		return fmt.Errorf("failed to delete container from database: %w", err)
+	// Keep the record so leftover volumes can still be identified, 'mkdb purge' deletes it
+	if err := database.MarkContainerRemoved(c.ID); err != nil {
+		return fmt.Errorf("failed to update container in database: %w", err)
 	}
 
 	config.Logger.Info("Container cleanup complete", "name", c.DisplayName)
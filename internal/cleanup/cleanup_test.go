@@ -0,0 +1,66 @@
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func TestRemoveParallel(t *testing.T) {
+	var containers []*database.Container
+	for i := range 10 {
+		containers = append(containers, &database.Container{ID: i, DisplayName: fmt.Sprintf("db%d", i)})
+	}
+
+	var running, peak atomic.Int32
+	results := removeParallel(containers, 3, func(c *database.Container) []stepFailure {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		// Every third container fails to stop
+		if c.ID%3 == 0 {
+			return []stepFailure{{"stop container", errors.New("daemon busy")}}
+		}
+		return nil
+	})
+
+	if len(results) != len(containers) {
+		t.Fatalf("removeParallel() returned %d results, want %d", len(results), len(containers))
+	}
+	for i, r := range results {
+		if r.container != containers[i] {
+			t.Errorf("result %d is for %s, want %s", i, r.container.DisplayName, containers[i].DisplayName)
+		}
+		wantFailures := 0
+		if i%3 == 0 {
+			wantFailures = 1
+		}
+		if len(r.failures) != wantFailures {
+			t.Errorf("result %d has %d failures, want %d", i, len(r.failures), wantFailures)
+		}
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("removeParallel() ran %d removals at once, want at most 3", p)
+	}
+}
+
+func TestRemoveParallel_Empty(t *testing.T) {
+	results := removeParallel(nil, maxParallelRemovals, func(*database.Container) []stepFailure {
+		t.Error("remove called with no containers")
+		return nil
+	})
+	if len(results) != 0 {
+		t.Errorf("removeParallel() = %v, want no results", results)
+	}
+}
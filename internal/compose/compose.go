@@ -0,0 +1,176 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/credentials"
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
+	"github.com/pbzona/mkdb/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a docker-compose.yml document
+type File struct {
+	Services map[string]ComposeService `yaml:"services"`
+	Networks map[string]ComposeNetwork `yaml:"networks,omitempty"`
+	Volumes  map[string]ComposeVolume  `yaml:"volumes,omitempty"`
+}
+
+// ComposeVolume is a top-level volume in a compose file
+type ComposeVolume struct {
+	External bool `yaml:"external,omitempty"`
+}
+
+// ComposeNetwork is a top-level network in a compose file
+type ComposeNetwork struct {
+	External bool `yaml:"external,omitempty"`
+}
+
+// ComposeService is a single service in a compose file
+type ComposeService struct {
+	Image         string   `yaml:"image"`
+	ContainerName string   `yaml:"container_name,omitempty"`
+	Command       []string `yaml:"command,omitempty"`
+	Environment   []string `yaml:"environment,omitempty"`
+	Ports         []string `yaml:"ports,omitempty"`
+	Volumes       []string `yaml:"volumes,omitempty"`
+	Restart       string   `yaml:"restart,omitempty"`
+	CPUShares     int64    `yaml:"cpu_shares,omitempty"`
+	Networks      []string `yaml:"networks,omitempty"`
+	Labels        []string `yaml:"labels,omitempty"`
+}
+
+// BuildComposeService describes a container as a compose service, using the same adapter
+// settings and default user credentials that mkdb uses when creating the container
+func BuildComposeService(c *database.Container) (ComposeService, error) {
+	user, err := database.GetDefaultUser(c.ID)
+	if err != nil {
+		return ComposeService{}, fmt.Errorf("failed to get default user: %w", err)
+	}
+
+	// Handle unauthenticated databases
+	var username, password string
+	if user.Username != "" && user.PasswordHash != "" {
+		username = user.Username
+		password, err = config.Decrypt(user.PasswordHash)
+		if err != nil {
+			return ComposeService{}, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+
+	adminPassword, err := credentials.AdminPassword(c)
+	if err != nil {
+		return ComposeService{}, err
+	}
+
+	return buildService(c, username, password, adminPassword)
+}
+
+func buildService(c *database.Container, username, password, adminPassword string) (ComposeService, error) {
+	adapter, err := adapters.GetRegistry().Get(c.Type)
+	if err != nil {
+		return ComposeService{}, err
+	}
+
+	command, err := docker.ContainerCommand(adapter, password, c.Persistence, c.ExtraArgs, c.NoConfig)
+	if err != nil {
+		return ComposeService{}, err
+	}
+
+	env, err := docker.ContainerEnv(adapter, c.DisplayName, username, password, adminPassword)
+	if err != nil {
+		return ComposeService{}, err
+	}
+
+	service := ComposeService{
+		Image:         adapter.GetImage(c.Version),
+		ContainerName: c.Name,
+		Command:       command,
+		Environment:   docker.MergeEnv(env, c.ExtraEnv),
+		Ports:         []string{composePort(c.BindIP, c.Port, adapter.GetDefaultPort())},
+		Restart:       docker.ResolveRestartPolicy(c.RestartPolicy),
+		CPUShares:     c.CPUShares,
+		Labels:        c.Labels,
+	}
+
+	if c.VolumeType != "" && c.VolumeType != "none" && c.VolumePath != "" {
+		target := c.DataTarget
+		if target == "" {
+			target = adapter.GetDataPath()
+		}
+		source := c.VolumePath
+		if c.VolumeType != types.VolumeTypeDocker {
+			source = docker.HostVolumePath(c.VolumeType, c.VolumePath)
+		}
+		volume := source + ":" + target
+		if c.VolumeReadOnly {
+			volume += ":ro"
+		}
+		service.Volumes = append(service.Volumes, volume)
+	}
+
+	// mkdb mounts the config directory unless the container was created with --no-config,
+	// and command args may reference the file in it
+	if !c.NoConfig {
+		service.Volumes = append(service.Volumes, docker.ConfigDir(c.DisplayName)+":"+adapter.GetConfigPath())
+	}
+
+	if c.Network != "" {
+		service.Networks = []string{c.Network}
+	}
+
+	return service, nil
+}
+
+// composePort formats a port mapping, adding the host interface only when one was chosen
+func composePort(bindIP, hostPort, containerPort string) string {
+	if bindIP == "" {
+		return hostPort + ":" + containerPort
+	}
+	return net.JoinHostPort(bindIP, hostPort) + ":" + containerPort
+}
+
+// Marshal renders a compose file with a single service named after the container
+// The service's networks and Docker volumes are declared as external, since mkdb created them outside the file
+func Marshal(name string, service ComposeService) ([]byte, error) {
+	file := File{Services: map[string]ComposeService{name: service}}
+	for _, network := range service.Networks {
+		if file.Networks == nil {
+			file.Networks = make(map[string]ComposeNetwork)
+		}
+		file.Networks[network] = ComposeNetwork{External: true}
+	}
+	for _, volume := range service.Volumes {
+		source, _, _ := strings.Cut(volume, ":")
+		if !isVolumeName(source) {
+			continue
+		}
+		if file.Volumes == nil {
+			file.Volumes = make(map[string]ComposeVolume)
+		}
+		file.Volumes[source] = ComposeVolume{External: true}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// isVolumeName reports whether the source of a service volume is a Docker volume rather than a host path
+// mkdb only writes absolute host paths, which always contain a separator
+func isVolumeName(source string) bool {
+	return source != "" && !strings.ContainsAny(source, `/\`)
+}
@@ -0,0 +1,261 @@
+package compose
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func TestBuildService(t *testing.T) {
+	dataDir, volumesDir := config.DataDir, config.VolumesDir
+	t.Cleanup(func() { config.DataDir, config.VolumesDir = dataDir, volumesDir })
+	config.DataDir = "/home/me/.local/share/mkdb"
+	config.VolumesDir = "/home/me/.local/share/mkdb/volumes"
+
+	tests := []struct {
+		name      string
+		container *database.Container
+		username  string
+		password  string
+		adminPass string
+		want      ComposeService
+	}{
+		{
+			name: "Postgres with named volume",
+			container: &database.Container{
+				Name:        "mkdb-mydb",
+				DisplayName: "mydb",
+				Type:        "postgres",
+				Version:     "16",
+				Port:        "5433",
+				VolumeType:  "named",
+				VolumePath:  "mydb",
+				CPUShares:   512,
+				Labels:      []string{"team=payments"},
+			},
+			username: "dbuser",
+			password: "secret",
+			want: ComposeService{
+				Image:         "postgres:16",
+				ContainerName: "mkdb-mydb",
+				Environment:   []string{"POSTGRES_DB=mydb", "PGDATA=/var/lib/postgresql/data", "POSTGRES_USER=dbuser", "POSTGRES_PASSWORD=secret"},
+				Ports:         []string{"5433:5432"},
+				Volumes: []string{
+					"/home/me/.local/share/mkdb/volumes/mydb:/var/lib/postgresql",
+					"/home/me/.local/share/mkdb/configs/mydb:/etc/postgresql",
+				},
+				Restart:   "unless-stopped",
+				CPUShares: 512,
+				Labels:    []string{"team=payments"},
+			},
+		},
+		{
+			name: "Redis with persistence and no volume",
+			container: &database.Container{
+				Name:        "mkdb-cache",
+				DisplayName: "cache",
+				Type:        "redis",
+				Version:     "7",
+				Port:        "6379",
+				VolumeType:  "none",
+				Persistence: "aof",
+				BindIP:      "127.0.0.1",
+				ExtraArgs:   []string{"--maxmemory", "64mb"},
+			},
+			password: "secret",
+			want: ComposeService{
+				Image:         "redis:7",
+				ContainerName: "mkdb-cache",
+				Command:       []string{"redis-server", "/usr/local/etc/redis/redis.conf", "--save", "", "--appendonly", "yes", "--requirepass", "secret", "--maxmemory", "64mb"},
+				Ports:         []string{"127.0.0.1:6379:6379"},
+				Volumes:       []string{"/home/me/.local/share/mkdb/configs/cache:/usr/local/etc/redis"},
+				Restart:       "unless-stopped",
+			},
+		},
+		{
+			name: "Read-only bind mount with custom target",
+			container: &database.Container{
+				Name:           "mkdb-seeded",
+				DisplayName:    "seeded",
+				Type:           "postgres",
+				Version:        "16",
+				Port:           "5432",
+				VolumeType:     "bind",
+				VolumePath:     "/srv/seed",
+				VolumeReadOnly: true,
+				DataTarget:     "/docker-entrypoint-initdb.d",
+			},
+			want: ComposeService{
+				Image:         "postgres:16",
+				ContainerName: "mkdb-seeded",
+				Environment:   []string{"POSTGRES_DB=seeded", "PGDATA=/var/lib/postgresql/data", "POSTGRES_HOST_AUTH_METHOD=trust"},
+				Ports:         []string{"5432:5432"},
+				Volumes: []string{
+					"/srv/seed:/docker-entrypoint-initdb.d:ro",
+					"/home/me/.local/share/mkdb/configs/seeded:/etc/postgresql",
+				},
+				Restart: "unless-stopped",
+			},
+		},
+		{
+			name: "MySQL with admin password",
+			container: &database.Container{
+				Name:        "mkdb-shop",
+				DisplayName: "shop",
+				Type:        "mysql",
+				Version:     "8.4",
+				Port:        "3306",
+				VolumeType:  "none",
+			},
+			username:  "dbuser",
+			password:  "secret",
+			adminPass: "rootsecret",
+			want: ComposeService{
+				Image:         "mysql:8.4",
+				ContainerName: "mkdb-shop",
+				Environment:   []string{"MYSQL_DATABASE=shop", "MYSQL_USER=dbuser", "MYSQL_PASSWORD=secret", "MYSQL_ROOT_PASSWORD=rootsecret"},
+				Ports:         []string{"3306:3306"},
+				Volumes:       []string{"/home/me/.local/share/mkdb/configs/shop:/etc/mysql/conf.d"},
+				Restart:       "unless-stopped",
+			},
+		},
+		{
+			name: "Redis without a config mount",
+			container: &database.Container{
+				Name:        "mkdb-plain",
+				DisplayName: "plain",
+				Type:        "redis",
+				Version:     "7",
+				Port:        "6380",
+				VolumeType:  "none",
+				NoConfig:    true,
+			},
+			password: "secret",
+			want: ComposeService{
+				Image:         "redis:7",
+				ContainerName: "mkdb-plain",
+				Command:       []string{"redis-server", "--requirepass", "secret"},
+				Ports:         []string{"6380:6379"},
+				Restart:       "unless-stopped",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildService(tt.container, tt.username, tt.password, tt.adminPass)
+			if err != nil {
+				t.Fatalf("buildService() error = %v", err)
+			}
+			if got.Image != tt.want.Image {
+				t.Errorf("Image = %q, want %q", got.Image, tt.want.Image)
+			}
+			if got.ContainerName != tt.want.ContainerName {
+				t.Errorf("ContainerName = %q, want %q", got.ContainerName, tt.want.ContainerName)
+			}
+			if !slices.Equal(got.Command, tt.want.Command) {
+				t.Errorf("Command = %q, want %q", got.Command, tt.want.Command)
+			}
+			if !slices.Equal(got.Environment, tt.want.Environment) {
+				t.Errorf("Environment = %q, want %q", got.Environment, tt.want.Environment)
+			}
+			if !slices.Equal(got.Ports, tt.want.Ports) {
+				t.Errorf("Ports = %q, want %q", got.Ports, tt.want.Ports)
+			}
+			if !slices.Equal(got.Volumes, tt.want.Volumes) {
+				t.Errorf("Volumes = %q, want %q", got.Volumes, tt.want.Volumes)
+			}
+			if got.Restart != tt.want.Restart {
+				t.Errorf("Restart = %q, want %q", got.Restart, tt.want.Restart)
+			}
+			if got.CPUShares != tt.want.CPUShares {
+				t.Errorf("CPUShares = %d, want %d", got.CPUShares, tt.want.CPUShares)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	service := ComposeService{
+		Image:       "redis:7",
+		Command:     []string{"redis-server", "--save", ""},
+		Ports:       []string{"6379:6379"},
+		Environment: []string{"A=b"},
+		Restart:     "unless-stopped",
+	}
+
+	got, err := Marshal("cache", service)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `services:
+  cache:
+    image: redis:7
+    command:
+      - redis-server
+      - --save
+      - ""
+    environment:
+      - A=b
+    ports:
+      - 6379:6379
+    restart: unless-stopped
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarshal_DockerVolume(t *testing.T) {
+	service := ComposeService{
+		Image:   "postgres:16",
+		Volumes: []string{"mkdb-mydb:/var/lib/postgresql", "/home/me/.local/share/mkdb/config/mydb:/etc/postgresql"},
+	}
+
+	got, err := Marshal("mydb", service)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `services:
+  mydb:
+    image: postgres:16
+    volumes:
+      - mkdb-mydb:/var/lib/postgresql
+      - /home/me/.local/share/mkdb/config/mydb:/etc/postgresql
+volumes:
+  mkdb-mydb:
+    external: true
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarshal_Network(t *testing.T) {
+	service := ComposeService{
+		Image:    "redis:7",
+		Networks: []string{"app"},
+	}
+
+	got, err := Marshal("cache", service)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `services:
+  cache:
+    image: redis:7
+    networks:
+      - app
+networks:
+  app:
+    external: true
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
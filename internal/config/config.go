@@ -1,10 +1,6 @@
 package config
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -21,12 +17,14 @@ const (
 )
 
 var (
-	DataDir       string
-	DBPath        string
-	LogPath       string
-	VolumesDir    string
-	Logger        *log.Logger
-	encryptionKey []byte
+	DataDir    string
+	DBPath     string
+	LogPath    string
+	VolumesDir string
+	Logger     *log.Logger
+
+	// Verbose also sends log output to stderr, it is always written to the log file
+	Verbose bool
 )
 
 // Initialize sets up the configuration directories and logger
@@ -62,105 +60,55 @@ func Initialize() error {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
 
-	Logger = log.NewWithOptions(io.MultiWriter(os.Stdout, logFile), log.Options{
+	// Stdout is reserved for command output, such as connection strings
+	var logOutput io.Writer = logFile
+	if Verbose {
+		logOutput = io.MultiWriter(os.Stderr, logFile)
+	}
+
+	Logger = log.NewWithOptions(logOutput, log.Options{
 		ReportTimestamp: true,
 		TimeFormat:      "2006-01-02 15:04:05",
 		Prefix:          "mkdb",
 	})
 	Logger.SetLevel(log.InfoLevel)
 
-	// Initialize or load encryption key
-	if err := initEncryptionKey(); err != nil {
+	// Set up the credential store, which creates or loads the encryption key
+	store, err = newStore(DataDir)
+	if err != nil {
 		return fmt.Errorf("failed to initialize encryption key: %w", err)
 	}
+	Logger.Debug("Using credential store", "store", store.Name())
 
-	return nil
-}
-
-// initEncryptionKey creates or loads the encryption key for password storage
-func initEncryptionKey() error {
-	keyPath := filepath.Join(DataDir, KeyFileName)
-
-	// Check if key exists
-	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
-		// Generate new key
-		key := make([]byte, 32) // AES-256
-		if _, err := rand.Read(key); err != nil {
-			return fmt.Errorf("failed to generate encryption key: %w", err)
-		}
-
-		// Save key to file with restricted permissions
-		if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(key)), 0600); err != nil {
-			return fmt.Errorf("failed to save encryption key: %w", err)
-		}
-
-		encryptionKey = key
-	} else {
-		// Load existing key
-		keyHex, err := os.ReadFile(keyPath)
-		if err != nil {
-			return fmt.Errorf("failed to read encryption key: %w", err)
-		}
-
-		key, err := hex.DecodeString(string(keyHex))
-		if err != nil {
-			return fmt.Errorf("failed to decode encryption key: %w", err)
-		}
-
-		encryptionKey = key
+	// Commands that don't read passwords keep working with a replaced key, the rest get guidance from ErrKeyMismatch
+	valid, err := checkCanary(DataDir, store)
+	if err != nil {
+		Logger.Warn("Failed to check encryption key", "error", err)
+	}
+	keyMismatch = err == nil && !valid
+	if keyMismatch {
+		Logger.Warn("Encryption key doesn't match the one stored passwords were encrypted with", "store", store.Name())
 	}
 
 	return nil
 }
 
-// Encrypt encrypts plaintext using AES-GCM
+// Encrypt encrypts plaintext with the active credential store
 func Encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(encryptionKey)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+	if store == nil {
+		return "", errNoStore
 	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return hex.EncodeToString(ciphertext), nil
+	return store.Encrypt(plaintext)
 }
 
-// Decrypt decrypts ciphertext using AES-GCM
+// Decrypt decrypts ciphertext with the active credential store
 func Decrypt(ciphertext string) (string, error) {
-	data, err := hex.DecodeString(ciphertext)
-	if err != nil {
-		return "", err
+	if store == nil {
+		return "", errNoStore
 	}
-
-	block, err := aes.NewCipher(encryptionKey)
-	if err != nil {
-		return "", err
+	plaintext, err := store.Decrypt(ciphertext)
+	if err != nil && keyMismatch {
+		return "", fmt.Errorf("%w: %v", ErrKeyMismatch, err)
 	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
-	}
-
-	nonce, encryptedData := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, encryptedData, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
+	return plaintext, err
 }
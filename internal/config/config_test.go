@@ -1,10 +1,14 @@
 package config
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/zalando/go-keyring"
 )
 
 func TestEncryptDecrypt(t *testing.T) {
@@ -163,6 +167,41 @@ func TestInitializeCreatesDirectories(t *testing.T) {
 	}
 }
 
+func TestInitializeLogsToFileOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("XDG_DATA_HOME", tempDir)
+	defer os.Unsetenv("XDG_DATA_HOME")
+
+	// Log output must not end up in stdout, which is reserved for command results
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	Logger.Info("Container created", "name", "mydb")
+
+	w.Close()
+	os.Stdout = stdout
+	output, _ := io.ReadAll(r)
+	if len(output) != 0 {
+		t.Errorf("Logger wrote to stdout: %q", output)
+	}
+
+	content, err := os.ReadFile(LogPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(content), "Container created") {
+		t.Errorf("log file = %q, want the log message", content)
+	}
+}
+
 func TestEncryptionKeyPersistence(t *testing.T) {
 	tempDir := t.TempDir()
 	os.Setenv("XDG_DATA_HOME", tempDir)
@@ -181,8 +220,8 @@ func TestEncryptionKeyPersistence(t *testing.T) {
 		t.Fatalf("Encrypt() error = %v", err)
 	}
 
-	// Reset the encryption key variable to simulate a restart
-	encryptionKey = nil
+	// Reset the credential store to simulate a restart
+	store = nil
 
 	// Initialize again (should load existing key)
 	err = Initialize()
@@ -201,6 +240,246 @@ func TestEncryptionKeyPersistence(t *testing.T) {
 	}
 }
 
+func TestKeyMismatch(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if KeyMismatch() {
+		t.Fatal("KeyMismatch() = true for a new key, want false")
+	}
+	encrypted, err := Encrypt("testpassword")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Losing the key file generates a new key, which the canary tells apart from the old one
+	if err := os.Remove(filepath.Join(DataDir, KeyFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() with a new key error = %v", err)
+	}
+	if !KeyMismatch() {
+		t.Fatal("KeyMismatch() = false after the key was replaced, want true")
+	}
+	if _, err := Decrypt(encrypted); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Decrypt() error = %v, want ErrKeyMismatch", err)
+	}
+
+	if err := AcceptKey(); err != nil {
+		t.Fatalf("AcceptKey() error = %v", err)
+	}
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() after AcceptKey() error = %v", err)
+	}
+	if KeyMismatch() {
+		t.Error("KeyMismatch() = true after AcceptKey(), want false")
+	}
+}
+
+func TestCorruptKeyFile(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	for _, content := range []string{"not hex", "abcd"} {
+		if err := os.WriteFile(filepath.Join(DataDir, KeyFileName), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := Initialize()
+		if err == nil || !strings.Contains(err.Error(), "mkdb creds reset") {
+			t.Errorf("Initialize() with key %q error = %v, want guidance", content, err)
+		}
+	}
+}
+
+func TestKeyringStore(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(CredStoreEnvVar, "")
+	defer cleanupTestConfig(t)
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if got := ActiveStore().Name(); got != CredStoreFile {
+		t.Errorf("ActiveStore().Name() = %q, want %q", got, CredStoreFile)
+	}
+	encrypted, err := Encrypt("testpassword")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Switching to the keyring moves the key, passwords encrypted with the file key still decrypt
+	t.Setenv(CredStoreEnvVar, "keyring")
+	store = nil
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() with keyring error = %v", err)
+	}
+	if got := ActiveStore().Name(); got != CredStoreKeyring {
+		t.Errorf("ActiveStore().Name() = %q, want %q", got, CredStoreKeyring)
+	}
+	keyPath := filepath.Join(DataDir, KeyFileName)
+	if _, err := os.Stat(keyPath); !os.IsNotExist(err) {
+		t.Errorf("key file still exists after moving to the keyring, Stat() error = %v", err)
+	}
+	if decrypted, err := Decrypt(encrypted); err != nil || decrypted != "testpassword" {
+		t.Errorf("Decrypt() with keyring = %q, %v, want testpassword", decrypted, err)
+	}
+
+	// Switching back writes the key file again
+	t.Setenv(CredStoreEnvVar, "file")
+	store = nil
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() with file error = %v", err)
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		t.Errorf("key file not restored, Stat() error = %v", err)
+	}
+	if decrypted, err := Decrypt(encrypted); err != nil || decrypted != "testpassword" {
+		t.Errorf("Decrypt() after switching back = %q, %v, want testpassword", decrypted, err)
+	}
+	if _, err := os.Stat(filepath.Join(DataDir, KeyringMarkerFileName)); !os.IsNotExist(err) {
+		t.Errorf("keyring marker still exists after switching back, Stat() error = %v", err)
+	}
+}
+
+func TestFileStore_IgnoresKeyringWithoutMarker(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(CredStoreEnvVar, "")
+	defer cleanupTestConfig(t)
+
+	// A key left in the keyring by another setup isn't picked up unless this data directory moved its key there
+	dataDir := filepath.Join(os.Getenv("XDG_DATA_HOME"), AppName)
+	stale := strings.Repeat("ab", keySize)
+	if err := keyring.Set(keyringService, dataDir, stale); err != nil {
+		t.Fatalf("keyring.Set() error = %v", err)
+	}
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if DataDir != dataDir {
+		t.Fatalf("DataDir = %q, want %q", DataDir, dataDir)
+	}
+	keyHex, err := os.ReadFile(filepath.Join(DataDir, KeyFileName))
+	if err != nil {
+		t.Fatalf("failed to read key file: %v", err)
+	}
+	if string(keyHex) == stale {
+		t.Error("file store read the key from the OS keyring without a keyring marker")
+	}
+}
+
+func TestKeyringStore_Unavailable(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(CredStoreEnvVar, "keyring")
+	defer cleanupTestConfig(t)
+
+	if err := Initialize(); err == nil {
+		t.Error("Initialize() error = nil, want error when the keyring is unavailable")
+	}
+}
+
+func TestUnknownCredStore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(CredStoreEnvVar, "vault")
+	defer cleanupTestConfig(t)
+
+	if err := Initialize(); err == nil {
+		t.Error("Initialize() error = nil, want error for an unknown store")
+	}
+}
+
+func TestIsFirstRun(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("XDG_DATA_HOME", tempDir)
+	defer os.Unsetenv("XDG_DATA_HOME")
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	if !IsFirstRun() {
+		t.Error("IsFirstRun() = false with no defaults or settings files, want true")
+	}
+
+	// Either file marks mkdb as configured
+	files := []string{DefaultsFileName, SettingsFileName}
+	for _, name := range files {
+		path := filepath.Join(DataDir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if IsFirstRun() {
+			t.Errorf("IsFirstRun() = true with %s present, want false", name)
+		}
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+	}
+
+	if !IsFirstRun() {
+		t.Error("IsFirstRun() = false after removing files, want true")
+	}
+}
+
+func TestLoadDefaultsTTLHoursByType(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("XDG_DATA_HOME", tempDir)
+	defer os.Unsetenv("XDG_DATA_HOME")
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		want    map[string]int
+	}{
+		{
+			name:    "Aliases are normalized",
+			content: `{"ttl_hours": 8, "ttl_hours_by_type": {"redis": 1, "pg": 24}}`,
+			want:    map[string]int{"redis": 1, "postgres": 24, "mysql": 8},
+		},
+		{
+			name:    "Unknown type",
+			content: `{"ttl_hours_by_type": {"oracle": 4}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Non-positive TTL",
+			content: `{"ttl_hours_by_type": {"redis": 0}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(DataDir, DefaultsFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			defaults, err := LoadDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for dbType, want := range tt.want {
+				if got := defaults.TTLHoursFor(dbType); got != want {
+					t.Errorf("TTLHoursFor(%q) = %d, want %d", dbType, got, want)
+				}
+			}
+		})
+	}
+}
+
 func TestConstants(t *testing.T) {
 	if AppName != "mkdb" {
 		t.Errorf("AppName = %v, want mkdb", AppName)
@@ -233,7 +512,7 @@ func setupTestConfig(t *testing.T) {
 
 func cleanupTestConfig(t *testing.T) {
 	os.Unsetenv("XDG_DATA_HOME")
-	encryptionKey = nil
+	store = nil
 	DataDir = ""
 	DBPath = ""
 	LogPath = ""
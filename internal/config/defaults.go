@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+)
+
+const DefaultsFileName = "defaults.json"
+
+// Color preferences for Defaults.Color
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Defaults stores user-configured defaults applied when flags are not provided
+type Defaults struct {
+	EnvKey         string         `json:"env_key,omitempty"`
+	Username       string         `json:"username,omitempty"`
+	DBType         string         `json:"db_type,omitempty"`
+	TTLHours       int            `json:"ttl_hours,omitempty"`
+	TTLHoursByType map[string]int `json:"ttl_hours_by_type,omitempty"`
+	Volume         string         `json:"volume,omitempty"`
+	Color          string         `json:"color,omitempty"`
+	RestartPolicy  string         `json:"restart_policy,omitempty"`
+}
+
+// SaveDefaults saves defaults to disk
+func SaveDefaults(defaults *Defaults) error {
+	defaultsPath := filepath.Join(DataDir, DefaultsFileName)
+
+	data, err := json.MarshalIndent(defaults, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal defaults: %w", err)
+	}
+
+	if err := os.WriteFile(defaultsPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write defaults: %w", err)
+	}
+
+	return nil
+}
+
+// LoadDefaults loads defaults from disk
+// Returns an empty Defaults if no defaults file exists
+func LoadDefaults() (*Defaults, error) {
+	defaultsPath := filepath.Join(DataDir, DefaultsFileName)
+
+	// Check if file exists
+	if _, err := os.Stat(defaultsPath); os.IsNotExist(err) {
+		return &Defaults{}, nil // No defaults configured, not an error
+	}
+
+	data, err := os.ReadFile(defaultsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read defaults: %w", err)
+	}
+
+	var defaults Defaults
+	if err := json.Unmarshal(data, &defaults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal defaults: %w", err)
+	}
+
+	if err := defaults.normalizeTTLHoursByType(); err != nil {
+		return nil, err
+	}
+
+	return &defaults, nil
+}
+
+// normalizeTTLHoursByType resolves aliases in TTLHoursByType to canonical database names
+// Returns an error for unknown database types or non-positive TTLs
+func (d *Defaults) normalizeTTLHoursByType() error {
+	if len(d.TTLHoursByType) == 0 {
+		return nil
+	}
+
+	normalized := make(map[string]int, len(d.TTLHoursByType))
+	for dbType, hours := range d.TTLHoursByType {
+		adapter, err := adapters.GetRegistry().Get(dbType)
+		if err != nil {
+			return fmt.Errorf("invalid ttl_hours_by_type in defaults: %w", err)
+		}
+		if hours <= 0 {
+			return fmt.Errorf("invalid ttl_hours_by_type in defaults: TTL for %s must be at least 1 hour", dbType)
+		}
+		normalized[adapter.GetName()] = hours
+	}
+	d.TTLHoursByType = normalized
+	return nil
+}
+
+// TTLHoursFor returns the default TTL for a database type, or 0 if none is configured
+func (d *Defaults) TTLHoursFor(dbType string) int {
+	if hours, ok := d.TTLHoursByType[dbType]; ok {
+		return hours
+	}
+	return d.TTLHours
+}
+
+// IsFirstRun reports whether mkdb has never been configured or used to create a database
+// This is true when neither the defaults file nor the last settings file exists
+func IsFirstRun() bool {
+	for _, name := range []string{DefaultsFileName, SettingsFileName} {
+		if _, err := os.Stat(filepath.Join(DataDir, name)); err == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -11,13 +11,27 @@ const SettingsFileName = "last_settings.json"
 
 // LastSettings stores the last used settings for quick repeat
 type LastSettings struct {
-	DBType     string `json:"db_type"`
-	Name       string `json:"name"`
-	Version    string `json:"version"`
-	Port       string `json:"port"`
-	VolumeType string `json:"volume_type"`
-	VolumePath string `json:"volume_path"`
-	TTLHours   int    `json:"ttl_hours"`
+	DBType         string   `json:"db_type"`
+	Name           string   `json:"name"`
+	Version        string   `json:"version"`
+	Port           string   `json:"port"`
+	VolumeType     string   `json:"volume_type"`
+	VolumePath     string   `json:"volume_path"`
+	VolumeDriver   string   `json:"volume_driver,omitempty"`
+	TTLHours       int      `json:"ttl_hours"`
+	Persistence    string   `json:"persistence,omitempty"`
+	CPUShares      int64    `json:"cpu_shares,omitempty"`
+	VolumeReadOnly bool     `json:"volume_readonly,omitempty"`
+	DataTarget     string   `json:"data_target,omitempty"`
+	RestartPolicy  string   `json:"restart_policy,omitempty"`
+	ExtraEnv       []string `json:"env,omitempty"`
+	BindIP         string   `json:"bind,omitempty"`
+	ExtraArgs      []string `json:"args,omitempty"`
+	Network        string   `json:"network,omitempty"`
+	RedisDB        int      `json:"redis_db,omitempty"`
+	NoConfig       bool     `json:"no_config,omitempty"`
+	MaxConnections int      `json:"max_connections,omitempty"`
+	Labels         []string `json:"labels,omitempty"`
 }
 
 // SaveLastSettings saves settings to disk
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// CredStoreEnvVar selects where the key that encrypts stored passwords is kept
+const CredStoreEnvVar = "MKDB_CRED_STORE"
+
+// Credential stores selectable with MKDB_CRED_STORE
+const (
+	CredStoreFile    = "file"
+	CredStoreKeyring = "keyring"
+)
+
+// keyringService is the service the key is saved under in the OS keychain
+// The account is the data directory, so separate XDG_DATA_HOME setups don't share a key
+const keyringService = "mkdb"
+
+// KeyringMarkerFileName records that the key was saved to the OS keyring, so the file store only
+// reads the keyring to move the key back, instead of on every run without a key file
+const KeyringMarkerFileName = ".encryption.keyring"
+
+// Store encrypts and decrypts the passwords mkdb saves in its database
+type Store interface {
+	// Name is the MKDB_CRED_STORE value that selects the store
+	Name() string
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// store is the credential store selected by Initialize
+var store Store
+
+var errNoStore = errors.New("credential store not initialized")
+
+// ActiveStore returns the credential store in use, once Initialize has been called
+func ActiveStore() Store {
+	return store
+}
+
+// CanaryFileName holds a known value encrypted with the key, so a replaced key is noticed up front
+// instead of as a decryption error for every stored password
+const CanaryFileName = ".encryption.canary"
+
+// canaryPlaintext is the value encrypted in the canary file
+const canaryPlaintext = "mkdb encryption key canary"
+
+// ErrKeyMismatch wraps decryption errors while the key doesn't match the canary
+var ErrKeyMismatch = errors.New("the encryption key changed since this password was stored (restore the previous key, or set a new password with 'mkdb creds reset')")
+
+// keyMismatch is set by Initialize when the canary doesn't decrypt with the active key
+var keyMismatch bool
+
+// KeyMismatch reports whether the encryption key differs from the one passwords were stored with,
+// such as after the key file was deleted and a new key was generated
+func KeyMismatch() bool {
+	return keyMismatch
+}
+
+// checkCanary reports whether the canary in dataDir decrypts with s, writing one if there is none yet
+func checkCanary(dataDir string, s Store) (bool, error) {
+	path := filepath.Join(dataDir, CanaryFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, writeCanary(path, s)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read key canary: %w", err)
+	}
+
+	plaintext, err := s.Decrypt(strings.TrimSpace(string(data)))
+	return err == nil && plaintext == canaryPlaintext, nil
+}
+
+// writeCanary encrypts the canary value with s and saves it to path
+func writeCanary(path string, s Store) error {
+	encrypted, err := s.Encrypt(canaryPlaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key canary: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(encrypted), 0600); err != nil {
+		return fmt.Errorf("failed to save key canary: %w", err)
+	}
+	return nil
+}
+
+// AcceptKey records the active key as the one passwords are stored with, once none of them need the previous key
+func AcceptKey() error {
+	if store == nil {
+		return errNoStore
+	}
+	if err := writeCanary(filepath.Join(DataDir, CanaryFileName), store); err != nil {
+		return err
+	}
+	keyMismatch = false
+	return nil
+}
+
+// newStore returns the store named by MKDB_CRED_STORE, the key file next to the database by default
+func newStore(dataDir string) (Store, error) {
+	keyPath := filepath.Join(dataDir, KeyFileName)
+
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv(CredStoreEnvVar))); name {
+	case "", CredStoreFile:
+		key, err := loadFileKey(keyPath, dataDir)
+		if err != nil {
+			return nil, err
+		}
+		return &aesStore{name: CredStoreFile, key: key}, nil
+	case CredStoreKeyring:
+		key, err := loadKeyringKey(keyPath, dataDir)
+		if err != nil {
+			return nil, err
+		}
+		return &aesStore{name: CredStoreKeyring, key: key}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q, want %s or %s", CredStoreEnvVar, name, CredStoreFile, CredStoreKeyring)
+	}
+}
+
+// loadFileKey reads the key file, creating it on first run
+// A key that was moved to the keyring is written back, so switching stores doesn't lose passwords
+func loadFileKey(keyPath, account string) ([]byte, error) {
+	key, err := readKeyFile(keyPath)
+	if !errors.Is(err, os.ErrNotExist) {
+		return key, err
+	}
+
+	markerPath := filepath.Join(account, KeyringMarkerFileName)
+	_, err = os.Stat(markerPath)
+	migrating := err == nil
+	if migrating {
+		encoded, err := keyring.Get(keyringService, account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key from the OS keyring to move it back to %s: %w", keyPath, err)
+		}
+		if key, err = hex.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key from the OS keyring: %w", err)
+		}
+	} else if key, err = generateKey(); err != nil {
+		return nil, err
+	}
+
+	// Save key to file with restricted permissions
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to save encryption key: %w", err)
+	}
+	if migrating {
+		if err := os.Remove(markerPath); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", markerPath, err)
+		}
+		Logger.Info("Moved encryption key out of the OS keyring", "to", keyPath)
+	}
+	return key, nil
+}
+
+// loadKeyringKey reads the key from the OS keyring, creating it on first use
+// An existing key file is moved into the keyring, so passwords encrypted with it still decrypt
+func loadKeyringKey(keyPath, account string) ([]byte, error) {
+	encoded, err := keyring.Get(keyringService, account)
+	if err == nil {
+		key, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key from the OS keyring: %w", err)
+		}
+		return key, writeKeyringMarker(account)
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("failed to read encryption key from the OS keyring: %w", err)
+	}
+
+	key, err := readKeyFile(keyPath)
+	migrated := err == nil
+	if errors.Is(err, os.ErrNotExist) {
+		key, err = generateKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := keyring.Set(keyringService, account, hex.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to save encryption key to the OS keyring: %w", err)
+	}
+	if err := writeKeyringMarker(account); err != nil {
+		return nil, err
+	}
+	if migrated {
+		if err := os.Remove(keyPath); err != nil {
+			return nil, fmt.Errorf("encryption key was saved to the OS keyring, but %s couldn't be removed: %w", keyPath, err)
+		}
+		Logger.Info("Moved encryption key to the OS keyring", "from", keyPath)
+	}
+	return key, nil
+}
+
+// writeKeyringMarker records in dataDir that its key is kept in the OS keyring
+func writeKeyringMarker(dataDir string) error {
+	if err := os.WriteFile(filepath.Join(dataDir, KeyringMarkerFileName), nil, 0600); err != nil {
+		return fmt.Errorf("failed to record that the encryption key is in the OS keyring: %w", err)
+	}
+	return nil
+}
+
+// readKeyFile reads a hex encoded key, the error wraps os.ErrNotExist if there is no key file
+func readKeyFile(keyPath string) ([]byte, error) {
+	keyHex, err := os.ReadFile(keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+
+	// A damaged key can't decrypt anything, so there's no point in carrying on with it
+	key, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if err == nil && len(key) != keySize {
+		err = fmt.Errorf("key is %d bytes, want %d", len(key), keySize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key %s, restore it from a backup, or move it aside to generate a new key and set new passwords with 'mkdb creds reset': %w", keyPath, err)
+	}
+	return key, nil
+}
+
+// keySize is the length of an AES-256 key
+const keySize = 32
+
+// generateKey returns a new random AES-256 key
+func generateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// NewAESStore returns a store that encrypts with key, such as one derived from a passphrase
+func NewAESStore(name string, key []byte) Store {
+	return &aesStore{name: name, key: key}
+}
+
+// aesStore encrypts with AES-GCM, the stores differ only in where the key is kept
+type aesStore struct {
+	name string
+	key  []byte
+}
+
+func (s *aesStore) Name() string {
+	return s.name
+}
+
+// Encrypt encrypts plaintext using AES-GCM
+func (s *aesStore) Encrypt(plaintext string) (string, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return hex.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts ciphertext using AES-GCM
+func (s *aesStore) Decrypt(ciphertext string) (string, error) {
+	data, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, encryptedData := data[:nonceSize], data[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, encryptedData, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
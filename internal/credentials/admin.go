@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+// AdminPassword returns the decrypted administrative password for a container
+// Databases without a separate administrative account use the default user's password
+// Returns an empty string if the container runs without authentication
+func AdminPassword(c *database.Container) (string, error) {
+	adapter, err := adapters.GetRegistry().Get(c.Type)
+	if err != nil {
+		return "", err
+	}
+	adminAdapter, ok := adapter.(adapters.AdminPasswordAdapter)
+	if !ok {
+		return defaultUserPassword(c)
+	}
+
+	if c.AdminPasswordHash != "" {
+		password, err := config.Decrypt(c.AdminPasswordHash)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt admin password: %w", err)
+		}
+		return password, nil
+	}
+
+	// Containers created before admin passwords were generated share a fixed one, unless unauthenticated
+	user, err := database.GetDefaultUser(c.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default user: %w", err)
+	}
+	if user.PasswordHash == "" {
+		return "", nil
+	}
+	return adminAdapter.LegacyAdminPassword(), nil
+}
+
+// defaultUserPassword returns the decrypted password of a container's default user
+func defaultUserPassword(c *database.Container) (string, error) {
+	user, err := database.GetDefaultUser(c.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default user: %w", err)
+	}
+	if user.PasswordHash == "" {
+		return "", nil
+	}
+
+	password, err := config.Decrypt(user.PasswordHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	return password, nil
+}
+
+// Kept are the credentials a removed container's volume was initialized with
+type Kept struct {
+	Container         *database.Container // The removed container's record
+	Username          string
+	Password          string
+	AdminPassword     string
+	AdminPasswordHash string
+}
+
+// KeptCredentials returns the credentials of the removed container whose volume a new container named
+// name would mount, or nil if there is none. Databases only read their password settings when they
+// initialize an empty data directory, so a kept volume keeps working with these credentials only
+func KeptCredentials(name, dbType, volumeType, volumePath string) (*Kept, error) {
+	if volumeType == "" || volumeType == "none" {
+		return nil, nil
+	}
+	c, err := database.GetRemovedContainerWithVolume(name, volumeType, volumePath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up removed container: %w", err)
+	}
+	if c.Type != dbType {
+		// Another type of database doesn't read the volume's credentials
+		return nil, nil
+	}
+
+	kept := &Kept{Container: c, AdminPasswordHash: c.AdminPasswordHash}
+	user, err := database.GetDefaultUser(c.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return kept, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default user: %w", err)
+	}
+	kept.Username = user.Username
+	if user.PasswordHash != "" {
+		if kept.Password, err = config.Decrypt(user.PasswordHash); err != nil {
+			return nil, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+	if c.AdminPasswordHash != "" {
+		if kept.AdminPassword, err = config.Decrypt(c.AdminPasswordHash); err != nil {
+			return nil, fmt.Errorf("failed to decrypt admin password: %w", err)
+		}
+	}
+	return kept, nil
+}
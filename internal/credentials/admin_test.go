@@ -0,0 +1,76 @@
+package credentials
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func TestKeptCredentials(t *testing.T) {
+	database.Close()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() error = %v", err)
+	}
+	if err := database.Initialize(); err != nil {
+		t.Fatalf("database.Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	adminHash, err := config.Encrypt("admin-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &database.Container{
+		Name:              "mkdb-shop",
+		DisplayName:       "shop",
+		Type:              "mysql",
+		Version:           "8",
+		Port:              "3306",
+		Status:            "running",
+		CreatedAt:         time.Now(),
+		ExpiresAt:         time.Now().Add(time.Hour),
+		VolumeType:        "named",
+		VolumePath:        "shop",
+		AdminPasswordHash: adminHash,
+	}
+	if err := database.CreateContainer(c); err != nil {
+		t.Fatal(err)
+	}
+	passwordHash, err := config.Encrypt("user-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateUser(&database.User{ContainerID: c.ID, Username: "app", PasswordHash: passwordHash, IsDefault: true, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The volume is still in use
+	if kept, err := KeptCredentials("mkdb-shop", "mysql", "named", "shop"); err != nil || kept != nil {
+		t.Errorf("KeptCredentials() for a running container = %+v, %v, want nil", kept, err)
+	}
+
+	if err := database.MarkContainerRemoved(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := KeptCredentials("mkdb-shop", "mysql", "named", "shop")
+	if err != nil {
+		t.Fatalf("KeptCredentials() error = %v", err)
+	}
+	if kept == nil || kept.Username != "app" || kept.Password != "user-secret" || kept.AdminPassword != "admin-secret" || kept.AdminPasswordHash != adminHash {
+		t.Errorf("KeptCredentials() = %+v, want the removed container's credentials", kept)
+	}
+
+	// Another volume, or another type of database, doesn't use them
+	for _, args := range [][4]string{
+		{"mkdb-shop", "mysql", "docker", "mkdb-shop"},
+		{"mkdb-shop", "postgres", "named", "shop"},
+		{"mkdb-shop", "mysql", "none", ""},
+	} {
+		if kept, err := KeptCredentials(args[0], args[1], args[2], args[3]); err != nil || kept != nil {
+			t.Errorf("KeptCredentials(%v) = %+v, %v, want nil", args, kept, err)
+		}
+	}
+}
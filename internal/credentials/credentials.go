@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"regexp"
 
 	"github.com/pbzona/mkdb/internal/adapters"
 )
@@ -11,6 +12,7 @@ import (
 const (
 	DefaultUsername = "dbuser"
 	DefaultPassword = "$uper$ecret"
+	DefaultEnvKey   = "DB_URL"
 	charset         = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 )
 
@@ -41,7 +43,33 @@ func FormatConnectionString(dbType, username, password, host, port, dbName strin
 	return adapter.FormatConnectionString(username, password, host, port, dbName)
 }
 
+// envKeyPattern matches valid shell environment variable names
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnvKey checks that key can be used as an environment variable name
+func ValidateEnvKey(key string) error {
+	if !envKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name: %q (must contain only letters, digits, and underscores, and not start with a digit)", key)
+	}
+	return nil
+}
+
+// usernamePattern matches usernames that are safe to interpolate into SQL
+var usernamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateUsername checks that username can be used as a database username
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("invalid username: %q (must contain only letters, digits, and underscores, and not start with a digit)", username)
+	}
+	return nil
+}
+
 // FormatEnvVar formats the connection string as an environment variable
-func FormatEnvVar(connectionString string) string {
-	return fmt.Sprintf("DB_URL=%s", connectionString)
+// Uses DefaultEnvKey if key is empty
+func FormatEnvVar(key, connectionString string) string {
+	if key == "" {
+		key = DefaultEnvKey
+	}
+	return fmt.Sprintf("%s=%s", key, connectionString)
 }
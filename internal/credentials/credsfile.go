@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Creds are the credentials of a new database, as written to a credentials file
+type Creds struct {
+	Name             string
+	Type             string
+	Host             string
+	Port             string
+	Username         string // Empty for databases without authentication
+	Password         string
+	ConnectionString string
+	EnvKey           string // Variable the connection string is assigned to, DefaultEnvKey if empty
+}
+
+// WriteCredsFile writes credentials to path in .env format, readable only by the current user
+// An existing file is replaced and its permissions are tightened to match
+func WriteCredsFile(path string, c Creds) error {
+	key := c.EnvKey
+	if key == "" {
+		key = DefaultEnvKey
+	}
+	if err := ValidateEnvKey(key); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Credentials for %s database '%s', written by mkdb\n", c.Type, c.Name)
+	fmt.Fprintf(&b, "%s=%s\n", key, quoteEnvValue(c.ConnectionString))
+	fmt.Fprintf(&b, "DB_HOST=%s\n", quoteEnvValue(c.Host))
+	fmt.Fprintf(&b, "DB_PORT=%s\n", quoteEnvValue(c.Port))
+	if c.Username != "" {
+		fmt.Fprintf(&b, "DB_USERNAME=%s\n", quoteEnvValue(c.Username))
+	}
+	if c.Password != "" {
+		fmt.Fprintf(&b, "DB_PASSWORD=%s\n", quoteEnvValue(c.Password))
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	// OpenFile only applies the mode to new files
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
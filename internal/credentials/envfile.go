@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UpsertEnvVar sets key=value in a .env file, replacing any existing assignment of key
+// Other lines are preserved as-is, and the file is created if it doesn't exist
+func UpsertEnvVar(path, key, value string) error {
+	if err := ValidateEnvKey(key); err != nil {
+		return err
+	}
+
+	var lines []string
+	mode := os.FileMode(0600) // New files hold credentials, so keep them private
+
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		text := strings.TrimSuffix(string(content), "\n")
+		if text != "" {
+			lines = strings.Split(text, "\n")
+		}
+	case os.IsNotExist(err):
+		// Start a new file
+	default:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	assignment := key + "=" + quoteEnvValue(value)
+
+	// Replace the first assignment of key and drop any duplicates after it
+	var updated []string
+	found := false
+	for _, line := range lines {
+		prefix, ok := envLineKey(line, key)
+		if !ok {
+			updated = append(updated, line)
+			continue
+		}
+		if !found {
+			updated = append(updated, prefix+assignment)
+			found = true
+		}
+	}
+	if !found {
+		updated = append(updated, assignment)
+	}
+
+	output := strings.Join(updated, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(output), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// envLineKey reports whether line assigns key, returning any "export " prefix to preserve
+func envLineKey(line, key string) (string, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(trimmed, "#") {
+		return "", false
+	}
+
+	prefix := ""
+	if rest, ok := strings.CutPrefix(trimmed, "export "); ok {
+		prefix = "export "
+		trimmed = strings.TrimLeft(rest, " \t")
+	}
+
+	name, _, ok := strings.Cut(trimmed, "=")
+	if !ok || strings.TrimRight(name, " \t") != key {
+		return "", false
+	}
+	return prefix, true
+}
+
+// quoteEnvValue quotes value if it contains characters that .env parsers treat specially
+func quoteEnvValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r#'\"`$\\") {
+		return value
+	}
+
+	// Single quotes are taken literally by dotenv parsers, so prefer them
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"`", "\\`",
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,56 @@
+package credentials
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultHost is used in connection strings when no other host is configured
+	DefaultHost = "localhost"
+	// HostEnvVar overrides the host used in connection strings
+	HostEnvVar = "MKDB_HOST"
+)
+
+// Host is the host used in connection strings, set by the --host flag
+var Host string
+
+// DockerHost is the endpoint of the Docker daemon in use, set once the client is created
+// It is used instead of DOCKER_HOST, which a Docker context or --docker-host overrides
+var DockerHost string
+
+// ConnectionHost returns the host published ports are reachable on, checking --host,
+// then MKDB_HOST, then the host of a remote Docker endpoint, and falling back to localhost
+func ConnectionHost() string {
+	if Host != "" {
+		return Host
+	}
+	if host := strings.TrimSpace(os.Getenv(HostEnvVar)); host != "" {
+		return host
+	}
+	dockerHost := DockerHost
+	if dockerHost == "" {
+		dockerHost = os.Getenv("DOCKER_HOST")
+	}
+	if host := dockerHostName(dockerHost); host != "" {
+		return host
+	}
+	return DefaultHost
+}
+
+// dockerHostName returns the host name of a tcp or ssh DOCKER_HOST
+// Local sockets and unparseable values return an empty string
+func dockerHostName(dockerHost string) string {
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return ""
+	}
+
+	switch u.Scheme {
+	case "tcp", "ssh", "http", "https":
+		return u.Hostname()
+	default:
+		return ""
+	}
+}
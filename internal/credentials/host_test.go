@@ -0,0 +1,45 @@
+package credentials
+
+import "testing"
+
+func TestConnectionHost(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		env        string
+		dockerHost string
+		want       string
+	}{
+		{"default", "", "", "", "localhost"},
+		{"local socket", "", "", "unix:///var/run/docker.sock", "localhost"},
+		{"remote docker over tcp", "", "", "tcp://192.168.64.2:2376", "192.168.64.2"},
+		{"remote docker over ssh", "", "", "ssh://me@devbox", "devbox"},
+		{"environment", "", "docker.internal", "tcp://192.168.64.2:2376", "docker.internal"},
+		{"flag wins", "10.0.0.5", "docker.internal", "tcp://192.168.64.2:2376", "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(HostEnvVar, tt.env)
+			t.Setenv("DOCKER_HOST", tt.dockerHost)
+			Host = tt.flag
+			defer func() { Host = "" }()
+
+			if got := ConnectionHost(); got != tt.want {
+				t.Errorf("ConnectionHost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionHost_DockerEndpoint(t *testing.T) {
+	t.Setenv(HostEnvVar, "")
+	t.Setenv("DOCKER_HOST", "tcp://192.168.64.2:2376")
+	DockerHost = "tcp://10.1.2.3:2376"
+	defer func() { DockerHost = "" }()
+
+	// The endpoint from a Docker context or --docker-host wins over DOCKER_HOST
+	if got := ConnectionHost(); got != "10.1.2.3" {
+		t.Errorf("ConnectionHost() = %q, want 10.1.2.3", got)
+	}
+}
@@ -0,0 +1,64 @@
+package credentials
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordOptions sets the requirements ValidatePassword checks
+type PasswordOptions struct {
+	MinLength  int // Minimum number of characters
+	MinClasses int // Minimum number of character classes used: lowercase, uppercase, digits, and symbols
+}
+
+// DefaultPasswordOptions are the requirements for passwords supplied with --password
+var DefaultPasswordOptions = PasswordOptions{MinLength: 12, MinClasses: 3}
+
+// unsafePasswordChars can't be used in passwords, either because mkdb interpolates passwords
+// into quoted SQL or because they'd need escaping in connection strings
+const unsafePasswordChars = `'"\@/:?#%`
+
+// ValidatePassword checks that a user-supplied password meets opts and can be used safely
+// in the commands and connection strings mkdb builds
+func ValidatePassword(password string, opts PasswordOptions) error {
+	if i := strings.IndexAny(password, unsafePasswordChars); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(password[i:])
+		return fmt.Errorf("password can't contain %c (these characters aren't allowed: %s)", r, unsafePasswordChars)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsSpace(r) || unicode.IsControl(r):
+			return fmt.Errorf("password can't contain spaces or control characters")
+		case r > unicode.MaxASCII:
+			return fmt.Errorf("password can't contain non-ASCII characters")
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	if length := utf8.RuneCountInString(password); length < opts.MinLength {
+		return fmt.Errorf("password is too short (%d characters, need at least %d)", length, opts.MinLength)
+	}
+
+	classes := 0
+	for _, used := range []bool{lower, upper, digit, symbol} {
+		if used {
+			classes++
+		}
+	}
+	if classes < opts.MinClasses {
+		return fmt.Errorf("password uses %d kind(s) of character, need at least %d of lowercase letters, uppercase letters, digits, and symbols", classes, opts.MinClasses)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package credentials
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{"Three classes", "correctHorse42", ""},
+		{"All classes", "c0rrect-Horse!", ""},
+		{"Too short", "Ab1-", "too short"},
+		{"Two classes", "correcthorsebattery42", "kind(s) of character"},
+		{"Single quote", "it'sMyPassword1", "contain '"},
+		{"Backslash", `Back\slash12345`, `contain \`},
+		{"At sign", "user@Example123", "contain @"},
+		{"Space", "correct Horse 42", "spaces"},
+		{"Non-ASCII", "correctHorsé42", "non-ASCII"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.password, DefaultPasswordOptions)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidatePassword(%q) error = %v", tt.password, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidatePassword(%q) error = %v, want it to mention %s", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratedPasswordsAreValid(t *testing.T) {
+	// Generated passwords are alphanumeric, so they're always safe to use
+	for i := 0; i < 10; i++ {
+		password, err := GeneratePassword(32)
+		if err != nil {
+			t.Fatalf("GeneratePassword() error = %v", err)
+		}
+		if err := ValidatePassword(password, PasswordOptions{MinLength: 32}); err != nil {
+			t.Errorf("ValidatePassword(%q) error = %v", password, err)
+		}
+	}
+}
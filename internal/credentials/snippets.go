@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+)
+
+// SnippetLanguages lists the snippet keys returned by GenerateSnippets, in display order
+var SnippetLanguages = []string{"cli", "go", "python", "node"}
+
+// connParts holds the pieces of a connection string needed by snippets that can't take a URL
+type connParts struct {
+	user     string
+	password string
+	host     string
+	port     string
+	database string
+}
+
+// parseConnectionString splits a connection string produced by FormatConnectionString
+func parseConnectionString(connStr string) (connParts, error) {
+	// MySQL strings use the Go driver's DSN host syntax, user:pass@tcp(host:port)/db
+	if strings.HasPrefix(connStr, "mysql://") {
+		connStr = strings.Replace(connStr, "@tcp(", "@", 1)
+		connStr = strings.Replace(connStr, ")/", "/", 1)
+	}
+
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return connParts{}, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+
+	password, _ := u.User.Password()
+	return connParts{
+		user:     u.User.Username(),
+		password: password,
+		host:     u.Hostname(),
+		port:     u.Port(),
+		database: strings.TrimPrefix(u.Path, "/"),
+	}, nil
+}
+
+// GenerateSnippets returns copy-paste connection examples keyed by SnippetLanguages
+// Returns an empty map for unsupported database types or unparseable connection strings
+func GenerateSnippets(dbType, connStr string) map[string]string {
+	// CockroachDB speaks the Postgres wire protocol, so the same clients work
+	if adapters.IsPostgres(dbType) || dbType == "cockroachdb" {
+		dbType = "postgres"
+	}
+
+	switch dbType {
+	case "postgres":
+		return map[string]string{
+			"cli":    fmt.Sprintf("psql %q", connStr),
+			"go":     fmt.Sprintf("import \"github.com/jackc/pgx/v5\"\n\nconn, err := pgx.Connect(context.Background(), %q)", connStr),
+			"python": fmt.Sprintf("import psycopg\n\nconn = psycopg.connect(%q)", connStr),
+			"node":   fmt.Sprintf("const { Client } = require(\"pg\");\n\nconst client = new Client({ connectionString: %q });\nawait client.connect();", connStr),
+		}
+	case "mysql":
+		parts, err := parseConnectionString(connStr)
+		if err != nil {
+			return map[string]string{}
+		}
+
+		// The mysql client connects over a socket for "localhost" unless told otherwise
+		cli := fmt.Sprintf("mysql --protocol=TCP -h %s -P %s -u %s", parts.host, parts.port, parts.user)
+		if parts.password != "" {
+			// Quoted like the connection strings in the other CLI snippets, -p takes no space before it
+			cli += fmt.Sprintf(" -p%q", parts.password)
+		}
+		cli += " " + parts.database
+
+		dsn := strings.TrimPrefix(connStr, "mysql://")
+		// mysql2 expects a standard URL rather than the Go driver's DSN
+		user := url.User(parts.user)
+		if parts.password != "" {
+			user = url.UserPassword(parts.user, parts.password)
+		}
+		uri := (&url.URL{Scheme: "mysql", User: user, Host: parts.host + ":" + parts.port, Path: "/" + parts.database}).String()
+
+		return map[string]string{
+			"cli":    cli,
+			"go":     fmt.Sprintf("import (\n\t\"database/sql\"\n\n\t_ \"github.com/go-sql-driver/mysql\"\n)\n\ndb, err := sql.Open(\"mysql\", %q)", dsn),
+			"python": fmt.Sprintf("import pymysql\n\nconn = pymysql.connect(host=%q, port=%s, user=%q, password=%q, database=%q)", parts.host, parts.port, parts.user, parts.password, parts.database),
+			"node":   fmt.Sprintf("const mysql = require(\"mysql2/promise\");\n\nconst conn = await mysql.createConnection(%q);", uri),
+		}
+	case "redis":
+		return map[string]string{
+			"cli":    fmt.Sprintf("redis-cli -u %q", connStr),
+			"go":     fmt.Sprintf("import \"github.com/redis/go-redis/v9\"\n\nopt, err := redis.ParseURL(%q)\nrdb := redis.NewClient(opt)", connStr),
+			"python": fmt.Sprintf("import redis\n\nr = redis.from_url(%q)", connStr),
+			"node":   fmt.Sprintf("const { createClient } = require(\"redis\");\n\nconst client = createClient({ url: %q });\nawait client.connect();", connStr),
+		}
+	}
+	return map[string]string{}
+}
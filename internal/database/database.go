@@ -2,7 +2,10 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pbzona/mkdb/internal/config"
@@ -13,37 +16,329 @@ var db *sql.DB
 
 // Container represents a database container
 type Container struct {
-	ID          int
-	Name        string
-	DisplayName string
-	Type        string
-	Version     string
-	ContainerID string
-	Port        string
-	Status      string
-	CreatedAt   time.Time
-	ExpiresAt   time.Time
-	VolumeType  string
-	VolumePath  string
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	DisplayName       string    `json:"display_name"`
+	Type              string    `json:"type"`
+	Version           string    `json:"version"`
+	ContainerID       string    `json:"container_id"`
+	Port              string    `json:"port"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	VolumeType        string    `json:"volume_type"`
+	VolumePath        string    `json:"volume_path"`   // Directory for bind mounts, name for named and Docker volumes
+	VolumeDriver      string    `json:"volume_driver"` // Driver of a Docker volume, empty for Docker's default
+	Persistence       string    `json:"persistence"`
+	CPUShares         int64     `json:"cpu_shares"`
+	VolumeReadOnly    bool      `json:"volume_readonly"`
+	DataTarget        string    `json:"data_target"`
+	RestartPolicy     string    `json:"restart_policy"`
+	ExtraEnv          []string  `json:"extra_env"`
+	AdminPasswordHash string    `json:"admin_password_hash"`
+	BindIP            string    `json:"bind_ip"`
+	ExtraArgs         []string  `json:"extra_args"`
+	Network           string    `json:"network"`
+	RedisDB           int       `json:"redis_db"`
+	NoConfig          bool      `json:"no_config"`
+	Adopted           bool      `json:"adopted"`
+	Pinned            bool      `json:"pinned"`
+	Labels            []string  `json:"labels"`         // KEY=VALUE Docker labels added to mkdb's own
+	ServerVersion     string    `json:"server_version"` // Version the server reported when it was created from the latest tag
+	EnvUnreadable     bool      `json:"-"`              // The stored ExtraEnv couldn't be decrypted, so ExtraEnv is empty
+}
+
+// DisplayVersion returns the version to show for a container, which is the server's own version
+// if it was recorded and the image tag otherwise. Version stays the tag, since images are pulled by it
+func (c *Container) DisplayVersion() string {
+	if c.ServerVersion != "" {
+		return c.ServerVersion
+	}
+	return c.Version
 }
 
 // User represents a database user
 type User struct {
-	ID           int
-	ContainerID  int
-	Username     string
-	PasswordHash string
-	IsDefault    bool
-	CreatedAt    time.Time
+	ID           int       `json:"id"`
+	ContainerID  int       `json:"container_id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	IsDefault    bool      `json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// LogicalDatabase represents a database created inside a container with mkdb db create
+type LogicalDatabase struct {
+	ID          int       `json:"id"`
+	ContainerID int       `json:"container_id"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // Event represents a container event
 type Event struct {
-	ID          int
-	ContainerID int
-	EventType   string
-	Timestamp   time.Time
-	Details     string
+	ID          int       `json:"id"`
+	ContainerID int       `json:"container_id"`
+	EventType   string    `json:"event_type"`
+	Timestamp   time.Time `json:"timestamp"`
+	Details     string    `json:"details"`
+}
+
+// containerColumns is the column list used when selecting containers
+const containerColumns = `id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path, volume_driver, persistence, cpu_shares, volume_readonly, data_target, restart_policy, extra_env, admin_password_hash, bind_ip, extra_args, network, redis_db, no_config, adopted, pinned, labels, server_version`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanContainer scans a row selected with containerColumns into a Container
+func scanContainer(row rowScanner) (*Container, error) {
+	c := &Container{}
+	var extraEnv, extraArgs, labels string
+	err := row.Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath, &c.VolumeDriver, &c.Persistence, &c.CPUShares, &c.VolumeReadOnly, &c.DataTarget, &c.RestartPolicy, &extraEnv, &c.AdminPasswordHash, &c.BindIP, &extraArgs, &c.Network, &c.RedisDB, &c.NoConfig, &c.Adopted, &c.Pinned, &labels, &c.ServerVersion)
+	if err != nil {
+		return nil, err
+	}
+	// An environment that can't be decrypted, e.g. after the key was lost, is left empty rather than
+	// hiding the container, and flagged so it isn't recreated without it
+	c.ExtraEnv, err = decodeEnv(extraEnv)
+	if errors.Is(err, errEnvDecrypt) {
+		c.EnvUnreadable = true
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to decode environment for %s: %w", c.Name, err)
+	}
+	if extraArgs != "" {
+		if err := json.Unmarshal([]byte(extraArgs), &c.ExtraArgs); err != nil {
+			return nil, fmt.Errorf("failed to decode command args for %s: %w", c.Name, err)
+		}
+	}
+	if labels != "" {
+		if err := json.Unmarshal([]byte(labels), &c.Labels); err != nil {
+			return nil, fmt.Errorf("failed to decode labels for %s: %w", c.Name, err)
+		}
+	}
+	return c, nil
+}
+
+// errEnvDecrypt wraps the error when a container's stored environment doesn't decrypt
+var errEnvDecrypt = errors.New("failed to decrypt environment")
+
+// encodeEnv stores extra environment variables as an encrypted JSON array, since they often hold secrets
+// An empty environment is stored as an empty string
+func encodeEnv(values []string) (string, error) {
+	data, err := encodeList(values)
+	if err != nil || data == "" {
+		return data, err
+	}
+	encrypted, err := config.Encrypt(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt environment: %w", err)
+	}
+	return encrypted, nil
+}
+
+// decodeEnv reads an environment stored by encodeEnv, or the plaintext JSON array older versions stored
+func decodeEnv(stored string) ([]string, error) {
+	if stored == "" {
+		return nil, nil
+	}
+	data := stored
+	if !strings.HasPrefix(stored, "[") {
+		decrypted, err := config.Decrypt(stored)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errEnvDecrypt, err)
+		}
+		data = decrypted
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// encodeList stores a list such as environment variables as a JSON array, or an empty string if it's empty
+func encodeList(values []string) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode list: %w", err)
+	}
+	return string(data), nil
+}
+
+// migration adds a column introduced after the initial schema
+type migration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// migrations are applied in order to databases created by older versions
+var migrations = []migration{
+	{"containers", "persistence", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "cpu_shares", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "volume_readonly", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "data_target", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "restart_policy", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "extra_env", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "admin_password_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "bind_ip", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "extra_args", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "network", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "redis_db", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "no_config", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "adopted", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "pinned", "INTEGER NOT NULL DEFAULT 0"},
+	{"containers", "volume_driver", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "labels", "TEXT NOT NULL DEFAULT ''"},
+	{"containers", "server_version", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// migrate adds any missing columns to existing tables
+func migrate() error {
+	for _, m := range migrations {
+		exists, err := columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+	}
+
+	// The default user of a Redis container authenticates as Redis's built-in "default" ACL user,
+	// older versions stored the username that other databases use
+	if _, err := db.Exec(`
+		UPDATE users SET username = 'default'
+		WHERE is_default = 1 AND username != '' AND username != 'default'
+		AND container_id IN (SELECT id FROM containers WHERE type = 'redis')
+	`); err != nil {
+		return fmt.Errorf("failed to migrate redis default users: %w", err)
+	}
+
+	if err := encryptEnv(); err != nil {
+		return err
+	}
+	return normalizeTimestamps()
+}
+
+// encryptEnv encrypts the environments older versions stored as plaintext JSON arrays
+func encryptEnv() error {
+	rows, err := db.Query(`SELECT id, extra_env FROM containers WHERE extra_env LIKE '[%'`)
+	if err != nil {
+		return fmt.Errorf("failed to read containers.extra_env: %w", err)
+	}
+
+	plaintext := make(map[int]string)
+	for rows.Next() {
+		var (
+			id  int
+			env string
+		)
+		if err := rows.Scan(&id, &env); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read containers.extra_env: %w", err)
+		}
+		plaintext[id] = env
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read containers.extra_env: %w", err)
+	}
+
+	for id, env := range plaintext {
+		encrypted, err := config.Encrypt(env)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt environment: %w", err)
+		}
+		if _, err := db.Exec(`UPDATE containers SET extra_env = ? WHERE id = ?`, encrypted, id); err != nil {
+			return fmt.Errorf("failed to migrate containers.extra_env: %w", err)
+		}
+	}
+	return nil
+}
+
+// timestampColumns are the columns compared in queries, which only sort correctly when every value is in UTC
+var timestampColumns = []struct{ table, column string }{
+	{"containers", "created_at"},
+	{"containers", "expires_at"},
+	{"events", "timestamp"},
+}
+
+// utcPattern matches the timestamps stored in UTC. The driver stores times as text in
+// time.Time.String's format, e.g. "2006-01-02 15:04:05.999 +0000 UTC"
+const utcPattern = "% +0000 UTC"
+
+// normalizeTimestamps rewrites timestamps that older versions stored in the local time zone as UTC
+// Times are compared as text, so values with different offsets don't compare correctly.
+// Only values that aren't in UTC yet are read, so this is a no-op once they've been rewritten
+func normalizeTimestamps() error {
+	for _, tc := range timestampColumns {
+		rows, err := db.Query(fmt.Sprintf("SELECT id, %s FROM %s WHERE %s NOT LIKE ?", tc.column, tc.table, tc.column), utcPattern)
+		if err != nil {
+			return fmt.Errorf("failed to read %s.%s: %w", tc.table, tc.column, err)
+		}
+
+		values := make(map[int]time.Time)
+		for rows.Next() {
+			var (
+				id int
+				t  time.Time
+			)
+			if err := rows.Scan(&id, &t); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to read %s.%s: %w", tc.table, tc.column, err)
+			}
+			values[id] = t
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read %s.%s: %w", tc.table, tc.column, err)
+		}
+
+		for id, t := range values {
+			if _, err := db.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", tc.table, tc.column), t.UTC(), id); err != nil {
+				return fmt.Errorf("failed to migrate %s.%s: %w", tc.table, tc.column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// columnExists checks whether a table has the given column
+func columnExists(table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
 }
 
 // Initialize creates the database schema
@@ -82,6 +377,15 @@ func Initialize() error {
 		UNIQUE(container_id, username)
 	);
 
+	CREATE TABLE IF NOT EXISTS databases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		container_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (container_id) REFERENCES containers(id) ON DELETE CASCADE,
+		UNIQUE(container_id, name)
+	);
+
 	CREATE TABLE IF NOT EXISTS events (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		container_id INTEGER NOT NULL,
@@ -100,6 +404,10 @@ func Initialize() error {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	if err := migrate(); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
 	return nil
 }
 
@@ -111,85 +419,141 @@ func Close() error {
 	return nil
 }
 
+// execer is implemented by *sql.DB and *sql.Tx, so inserts can run on their own or in a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 // CreateContainer creates a new container record
+// A removed container with the same name is superseded, so its record is deleted first
 func CreateContainer(c *Container) error {
-	result, err := db.Exec(`
-		INSERT INTO containers (name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, c.Name, c.DisplayName, c.Type, c.Version, c.ContainerID, c.Port, c.Status, c.CreatedAt, c.ExpiresAt, c.VolumeType, c.VolumePath)
+	return CreateContainerRecords(c, nil, nil, nil)
+}
+
+// CreateContainerRecords creates a container record with its users, logical databases and events
+// in one transaction, so a failure doesn't leave a partial record behind. Their ContainerID is set
+// to the new record's ID
+func CreateContainerRecords(c *Container, users []*User, databases []*LogicalDatabase, events []*Event) error {
+	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to create container: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	id, err := result.LastInsertId()
+	id, err := insertContainer(tx, c)
 	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
+		return err
+	}
+	for _, u := range users {
+		u.ContainerID = id
+		if err := insertUser(tx, u); err != nil {
+			return err
+		}
+	}
+	for _, d := range databases {
+		d.ContainerID = id
+		if err := insertLogicalDatabase(tx, d); err != nil {
+			return err
+		}
+	}
+	for _, e := range events {
+		e.ContainerID = id
+		if err := insertEvent(tx, e); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
 	}
 
-	c.ID = int(id)
+	c.ID = id
 	return nil
 }
 
-// GetContainer retrieves a container by name
-func GetContainer(name string) (*Container, error) {
-	c := &Container{}
-	err := db.QueryRow(`
-		SELECT id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path
-		FROM containers WHERE name = ?
-	`, name).Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath)
+// insertContainer inserts a container record and returns its ID, replacing a removed record with the same name
+func insertContainer(tx *sql.Tx, c *Container) (int, error) {
+	extraEnv, err := encodeEnv(c.ExtraEnv)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	extraArgs, err := encodeList(c.ExtraArgs)
+	if err != nil {
+		return 0, err
+	}
+	labels, err := encodeList(c.Labels)
+	if err != nil {
+		return 0, err
 	}
-	return c, nil
-}
 
-// GetContainerByDisplayName retrieves a container by display name
-func GetContainerByDisplayName(displayName string) (*Container, error) {
-	c := &Container{}
-	err := db.QueryRow(`
-		SELECT id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path
-		FROM containers WHERE display_name = ?
-	`, displayName).Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath)
+	// Foreign keys aren't enforced, so the removed record's rows are deleted along with it
+	for _, table := range []string{"users", "databases", "events"} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE container_id IN (SELECT id FROM containers WHERE name = ? AND status = 'removed')`, table)
+		if _, err := tx.Exec(query, c.Name); err != nil {
+			return 0, fmt.Errorf("failed to delete removed container's %s: %w", table, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM containers WHERE name = ? AND status = 'removed'`, c.Name); err != nil {
+		return 0, fmt.Errorf("failed to delete removed container: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO containers (name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path, volume_driver, persistence, cpu_shares, volume_readonly, data_target, restart_policy, extra_env, admin_password_hash, bind_ip, extra_args, network, redis_db, no_config, adopted, pinned, labels, server_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.Name, c.DisplayName, c.Type, c.Version, c.ContainerID, c.Port, c.Status, c.CreatedAt.UTC(), c.ExpiresAt.UTC(), c.VolumeType, c.VolumePath, c.VolumeDriver, c.Persistence, c.CPUShares, c.VolumeReadOnly, c.DataTarget, c.RestartPolicy, extraEnv, c.AdminPasswordHash, c.BindIP, extraArgs, c.Network, c.RedisDB, c.NoConfig, c.Adopted, c.Pinned, labels, c.ServerVersion)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("failed to create container: %w", err)
 	}
-	return c, nil
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return int(id), nil
+}
+
+// GetContainer retrieves a container by name, ignoring removed containers
+func GetContainer(name string) (*Container, error) {
+	row := db.QueryRow(`SELECT `+containerColumns+` FROM containers WHERE name = ? AND status != 'removed'`, name)
+	return scanContainer(row)
+}
+
+// GetContainerByDisplayName retrieves a container by display name, ignoring removed containers
+func GetContainerByDisplayName(displayName string) (*Container, error) {
+	row := db.QueryRow(`SELECT `+containerColumns+` FROM containers WHERE display_name = ? AND status != 'removed'`, displayName)
+	return scanContainer(row)
 }
 
 // GetContainerByID retrieves a container by ID
 func GetContainerByID(id int) (*Container, error) {
-	c := &Container{}
-	err := db.QueryRow(`
-		SELECT id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path
-		FROM containers WHERE id = ?
-	`, id).Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath)
-	if err != nil {
-		return nil, err
-	}
-	return c, nil
+	row := db.QueryRow(`SELECT `+containerColumns+` FROM containers WHERE id = ?`, id)
+	return scanContainer(row)
 }
 
-// ListContainers retrieves all containers (excluding cleaned up expired ones)
+// ListContainers retrieves all containers (excluding cleaned up expired and removed ones)
 func ListContainers() ([]*Container, error) {
-	return listContainersWithStatus(false)
+	return listContainers(`WHERE status != 'expired' AND status != 'removed'`)
 }
 
-// ListAllContainers retrieves all containers including expired ones
+// ListAllContainers retrieves all containers including expired and removed ones
 func ListAllContainers() ([]*Container, error) {
-	return listContainersWithStatus(true)
+	return listContainers(``)
 }
 
-// listContainersWithStatus retrieves containers, optionally including expired
-func listContainersWithStatus(includeExpired bool) ([]*Container, error) {
-	query := `
-		SELECT id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path
-		FROM containers`
+// ListRemovedContainers retrieves the records kept for removed containers
+func ListRemovedContainers() ([]*Container, error) {
+	return listContainers(`WHERE status = 'removed'`)
+}
 
-	if !includeExpired {
-		query += ` WHERE status != 'expired'`
-	}
+// GetRemovedContainerWithVolume retrieves the removed record of a container named name that used the
+// given volume, which is what a new container with that name and volume would mount again
+func GetRemovedContainerWithVolume(name, volumeType, volumePath string) (*Container, error) {
+	row := db.QueryRow(`SELECT `+containerColumns+` FROM containers WHERE name = ? AND status = 'removed' AND volume_type = ? AND volume_path = ? ORDER BY created_at DESC LIMIT 1`, name, volumeType, volumePath)
+	return scanContainer(row)
+}
 
-	query += ` ORDER BY created_at DESC`
+// listContainers retrieves containers matching a WHERE clause, newest first
+func listContainers(where string) ([]*Container, error) {
+	query := `SELECT ` + containerColumns + ` FROM containers ` + where + ` ORDER BY created_at DESC`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -199,8 +563,8 @@ func listContainersWithStatus(includeExpired bool) ([]*Container, error) {
 
 	var containers []*Container
 	for rows.Next() {
-		c := &Container{}
-		if err := rows.Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath); err != nil {
+		c, err := scanContainer(rows)
+		if err != nil {
 			return nil, err
 		}
 		containers = append(containers, c)
@@ -213,24 +577,75 @@ func listContainersWithStatus(includeExpired bool) ([]*Container, error) {
 func UpdateContainer(c *Container) error {
 	_, err := db.Exec(`
 		UPDATE containers
-		SET container_id = ?, status = ?, expires_at = ?
+		SET container_id = ?, version = ?, port = ?, status = ?, expires_at = ?, pinned = ?, server_version = ?
 		WHERE id = ?
-	`, c.ContainerID, c.Status, c.ExpiresAt, c.ID)
+	`, c.ContainerID, c.Version, c.Port, c.Status, c.ExpiresAt.UTC(), c.Pinned, c.ServerVersion, c.ID)
+	return err
+}
+
+// MarkContainerRemoved keeps a removed container's record so its volume can be identified later
+func MarkContainerRemoved(id int) error {
+	_, err := db.Exec(`UPDATE containers SET status = 'removed', container_id = '' WHERE id = ?`, id)
 	return err
 }
 
-// DeleteContainer deletes a container record
+// DeleteContainer permanently deletes a container record
 func DeleteContainer(id int) error {
 	_, err := db.Exec("DELETE FROM containers WHERE id = ?", id)
 	return err
 }
 
+// PurgeRemovedContainers permanently deletes the records of all removed containers,
+// including their users, databases, and events. Returns the number of containers deleted
+func PurgeRemovedContainers() (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"users", "databases", "events"} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE container_id IN (SELECT id FROM containers WHERE status = 'removed')`, table)
+		if _, err := tx.Exec(query); err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+	}
+
+	result, err := tx.Exec(`DELETE FROM containers WHERE status = 'removed'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge containers: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, tx.Commit()
+}
+
 // GetExpiredContainers retrieves containers that have expired
 func GetExpiredContainers() ([]*Container, error) {
-	rows, err := db.Query(`
-		SELECT id, name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path
-		FROM containers WHERE expires_at < ? AND status != 'stopped' AND status != 'expired'
-	`, time.Now())
+	return GetContainersForCleanup(0, false)
+}
+
+// GetExpiredContainersIncludingStopped retrieves expired containers, including
+// ones that were stopped and whose volumes are still on disk
+func GetExpiredContainersIncludingStopped() ([]*Container, error) {
+	return GetContainersForCleanup(0, true)
+}
+
+// GetContainersForCleanup retrieves containers that expired more than grace ago
+// Stopped containers are only included if includeStopped is set, pinned containers never are
+func GetContainersForCleanup(grace time.Duration, includeStopped bool) ([]*Container, error) {
+	excluded := `'stopped', 'expired', 'removed'`
+	if includeStopped {
+		excluded = `'expired', 'removed'`
+	}
+	return queryExpiredContainers(`SELECT `+containerColumns+` FROM containers WHERE expires_at < ? AND pinned = 0 AND status NOT IN (`+excluded+`)`, time.Now().UTC().Add(-grace))
+}
+
+func queryExpiredContainers(query string, cutoff time.Time) ([]*Container, error) {
+	rows, err := db.Query(query, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -238,8 +653,8 @@ func GetExpiredContainers() ([]*Container, error) {
 
 	var containers []*Container
 	for rows.Next() {
-		c := &Container{}
-		if err := rows.Scan(&c.ID, &c.Name, &c.DisplayName, &c.Type, &c.Version, &c.ContainerID, &c.Port, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.VolumeType, &c.VolumePath); err != nil {
+		c, err := scanContainer(rows)
+		if err != nil {
 			return nil, err
 		}
 		containers = append(containers, c)
@@ -250,10 +665,15 @@ func GetExpiredContainers() ([]*Container, error) {
 
 // CreateUser creates a new user record
 func CreateUser(u *User) error {
-	result, err := db.Exec(`
+	return insertUser(db, u)
+}
+
+// insertUser inserts a user record and sets its ID
+func insertUser(ex execer, u *User) error {
+	result, err := ex.Exec(`
 		INSERT INTO users (container_id, username, password_hash, is_default, created_at)
 		VALUES (?, ?, ?, ?, ?)
-	`, u.ContainerID, u.Username, u.PasswordHash, u.IsDefault, u.CreatedAt)
+	`, u.ContainerID, u.Username, u.PasswordHash, u.IsDefault, u.CreatedAt.UTC())
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -317,11 +737,114 @@ func DeleteUser(id int) error {
 	return err
 }
 
+// CreateLogicalDatabase creates a new logical database record
+func CreateLogicalDatabase(d *LogicalDatabase) error {
+	return insertLogicalDatabase(db, d)
+}
+
+// insertLogicalDatabase inserts a logical database record and sets its ID
+func insertLogicalDatabase(ex execer, d *LogicalDatabase) error {
+	result, err := ex.Exec(`
+		INSERT INTO databases (container_id, name, created_at)
+		VALUES (?, ?, ?)
+	`, d.ContainerID, d.Name, d.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	d.ID = int(id)
+	return nil
+}
+
+// ListLogicalDatabases retrieves all logical databases for a container
+func ListLogicalDatabases(containerID int) ([]*LogicalDatabase, error) {
+	rows, err := db.Query(`
+		SELECT id, container_id, name, created_at
+		FROM databases WHERE container_id = ? ORDER BY name
+	`, containerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var databases []*LogicalDatabase
+	for rows.Next() {
+		d := &LogicalDatabase{}
+		if err := rows.Scan(&d.ID, &d.ContainerID, &d.Name, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		databases = append(databases, d)
+	}
+
+	return databases, nil
+}
+
+// DeleteLogicalDatabase deletes a logical database record
+func DeleteLogicalDatabase(id int) error {
+	_, err := db.Exec("DELETE FROM databases WHERE id = ?", id)
+	return err
+}
+
 // CreateEvent creates a new event record
 func CreateEvent(e *Event) error {
-	_, err := db.Exec(`
+	return insertEvent(db, e)
+}
+
+// insertEvent inserts an event record
+func insertEvent(ex execer, e *Event) error {
+	_, err := ex.Exec(`
 		INSERT INTO events (container_id, event_type, timestamp, details)
 		VALUES (?, ?, ?, ?)
-	`, e.ContainerID, e.EventType, e.Timestamp, e.Details)
+	`, e.ContainerID, e.EventType, e.Timestamp.UTC(), e.Details)
 	return err
 }
+
+// ListEvents retrieves the events recorded for a container, oldest first
+func ListEvents(containerID int) ([]*Event, error) {
+	rows, err := db.Query(`
+		SELECT id, container_id, event_type, timestamp, details
+		FROM events WHERE container_id = ? ORDER BY timestamp, id
+	`, containerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.ContainerID, &e.EventType, &e.Timestamp, &e.Details); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// GetLatestEvent retrieves the most recent event of the given type recorded for a container
+func GetLatestEvent(containerID int, eventType string) (*Event, error) {
+	e := &Event{}
+	err := db.QueryRow(`
+		SELECT id, container_id, event_type, timestamp, details
+		FROM events WHERE container_id = ? AND event_type = ? ORDER BY timestamp DESC, id DESC LIMIT 1
+	`, containerID, eventType).Scan(&e.ID, &e.ContainerID, &e.EventType, &e.Timestamp, &e.Details)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// HasEventSince reports whether an event of the given type was recorded for a container at or after since
+func HasEventSince(containerID int, eventType string, since time.Time) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM events WHERE container_id = ? AND event_type = ? AND timestamp >= ?
+	`, containerID, eventType, since.UTC()).Scan(&count)
+	return count > 0, err
+}
@@ -2,9 +2,15 @@ package database
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
 )
 
 func setupTestDB(t *testing.T) string {
@@ -65,6 +71,15 @@ func initTestDatabase(path string) error {
 		UNIQUE(container_id, username)
 	);
 
+	CREATE TABLE IF NOT EXISTS databases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		container_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (container_id) REFERENCES containers(id) ON DELETE CASCADE,
+		UNIQUE(container_id, name)
+	);
+
 	CREATE TABLE IF NOT EXISTS events (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		container_id INTEGER NOT NULL,
@@ -79,8 +94,11 @@ func initTestDatabase(path string) error {
 	CREATE INDEX IF NOT EXISTS idx_events_container_id ON events(container_id);
 	`
 
-	_, err = db.Exec(schema)
-	return err
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	return migrate()
 }
 
 func TestCreateAndGetContainer(t *testing.T) {
@@ -240,6 +258,9 @@ func TestUpdateContainer(t *testing.T) {
 
 	// Update status
 	container.Status = "stopped"
+	container.Port = "5433"
+	container.Version = "latest"
+	container.ServerVersion = "16.4"
 	container.ExpiresAt = time.Now().Add(48 * time.Hour)
 
 	err = UpdateContainer(container)
@@ -256,6 +277,21 @@ func TestUpdateContainer(t *testing.T) {
 	if retrieved.Status != "stopped" {
 		t.Errorf("UpdateContainer() Status = %v, want stopped", retrieved.Status)
 	}
+	if retrieved.Port != "5433" {
+		t.Errorf("UpdateContainer() Port = %v, want 5433", retrieved.Port)
+	}
+	if retrieved.Version != "latest" || retrieved.ServerVersion != "16.4" {
+		t.Errorf("UpdateContainer() Version = %v, ServerVersion = %v, want latest and 16.4", retrieved.Version, retrieved.ServerVersion)
+	}
+	if got := retrieved.DisplayVersion(); got != "16.4" {
+		t.Errorf("DisplayVersion() = %v, want 16.4", got)
+	}
+
+	// Without a recorded server version, the tag is shown
+	retrieved.ServerVersion = ""
+	if got := retrieved.DisplayVersion(); got != "latest" {
+		t.Errorf("DisplayVersion() without a server version = %v, want latest", got)
+	}
 }
 
 func TestDeleteContainer(t *testing.T) {
@@ -291,6 +327,165 @@ func TestDeleteContainer(t *testing.T) {
 	}
 }
 
+func TestMarkContainerRemoved(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	container := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Version:     "15",
+		ContainerID: "abc123",
+		Port:        "5432",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(-time.Hour),
+		VolumeType:  "named",
+		VolumePath:  "testdb",
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	if err := CreateUser(&User{ContainerID: container.ID, Username: "dbuser", IsDefault: true, CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := CreateEvent(&Event{ContainerID: container.ID, EventType: "created", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	if err := MarkContainerRemoved(container.ID); err != nil {
+		t.Fatalf("MarkContainerRemoved() error = %v", err)
+	}
+
+	// Removed containers are hidden from lookups and the active list
+	if _, err := GetContainerByDisplayName("testdb"); err == nil {
+		t.Error("GetContainerByDisplayName() expected error for removed container")
+	}
+	active, err := ListContainers()
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("ListContainers() returned %d containers, want 0", len(active))
+	}
+	expired, err := GetExpiredContainersIncludingStopped()
+	if err != nil {
+		t.Fatalf("GetExpiredContainersIncludingStopped() error = %v", err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("GetExpiredContainersIncludingStopped() returned %d containers, want 0", len(expired))
+	}
+
+	// But the metadata is kept
+	removed, err := ListRemovedContainers()
+	if err != nil {
+		t.Fatalf("ListRemovedContainers() error = %v", err)
+	}
+	if len(removed) != 1 {
+		t.Fatalf("ListRemovedContainers() returned %d containers, want 1", len(removed))
+	}
+	if removed[0].Type != "postgres" || removed[0].Version != "15" || removed[0].VolumePath != "testdb" {
+		t.Errorf("ListRemovedContainers() = %+v, want original metadata", removed[0])
+	}
+	if removed[0].ContainerID != "" {
+		t.Errorf("ListRemovedContainers() ContainerID = %v, want empty", removed[0].ContainerID)
+	}
+
+	// A new container with the same name supersedes the removed record
+	replacement := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "mysql",
+		Version:     "8.0",
+		Port:        "3306",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	if err := CreateContainer(replacement); err != nil {
+		t.Fatalf("CreateContainer() for reused name error = %v", err)
+	}
+	removed, err = ListRemovedContainers()
+	if err != nil {
+		t.Fatalf("ListRemovedContainers() error = %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("ListRemovedContainers() returned %d containers after name reuse, want 0", len(removed))
+	}
+
+	// Along with its users and events
+	if users, err := ListUsers(container.ID); err != nil || len(users) != 0 {
+		t.Errorf("ListUsers() of the superseded record = %v, %v, want none", users, err)
+	}
+	if events, err := ListEvents(container.ID); err != nil || len(events) != 0 {
+		t.Errorf("ListEvents() of the superseded record = %v, %v, want none", events, err)
+	}
+}
+
+func TestPurgeRemovedContainers(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	var ids []int
+	for _, name := range []string{"one", "two", "three"} {
+		container := &Container{
+			Name:        "mkdb-" + name,
+			DisplayName: name,
+			Type:        "redis",
+			Version:     "7",
+			Port:        "6379",
+			Status:      "running",
+			CreatedAt:   time.Now(),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		if err := CreateContainer(container); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+		if err := CreateUser(&User{ContainerID: container.ID, Username: "dbuser", IsDefault: true, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		ids = append(ids, container.ID)
+	}
+
+	for _, id := range ids[:2] {
+		if err := MarkContainerRemoved(id); err != nil {
+			t.Fatalf("MarkContainerRemoved() error = %v", err)
+		}
+	}
+
+	count, err := PurgeRemovedContainers()
+	if err != nil {
+		t.Fatalf("PurgeRemovedContainers() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("PurgeRemovedContainers() = %d, want 2", count)
+	}
+
+	all, err := ListAllContainers()
+	if err != nil {
+		t.Fatalf("ListAllContainers() error = %v", err)
+	}
+	if len(all) != 1 || all[0].DisplayName != "three" {
+		t.Errorf("ListAllContainers() after purge = %v, want only 'three'", all)
+	}
+
+	// Users of purged containers are deleted too
+	for i, id := range ids {
+		users, err := ListUsers(id)
+		if err != nil {
+			t.Fatalf("ListUsers() error = %v", err)
+		}
+		want := 0
+		if i == 2 {
+			want = 1
+		}
+		if len(users) != want {
+			t.Errorf("ListUsers(%d) returned %d users, want %d", id, len(users), want)
+		}
+	}
+}
+
 func TestGetExpiredContainers(t *testing.T) {
 	setupTestDB(t)
 	defer cleanupTestDB(t)
@@ -344,6 +539,152 @@ func TestGetExpiredContainers(t *testing.T) {
 	}
 }
 
+func TestGetExpiredContainersIncludingStopped(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	now := time.Now()
+
+	containers := []*Container{
+		{
+			Name:        "mkdb-expired-running",
+			DisplayName: "expired-running",
+			Status:      "running",
+			ExpiresAt:   now.Add(-1 * time.Hour),
+		},
+		{
+			Name:        "mkdb-expired-stopped",
+			DisplayName: "expired-stopped",
+			Status:      "stopped",
+			ExpiresAt:   now.Add(-48 * time.Hour),
+		},
+		{
+			Name:        "mkdb-active-stopped",
+			DisplayName: "active-stopped",
+			Status:      "stopped",
+			ExpiresAt:   now.Add(24 * time.Hour),
+		},
+	}
+
+	for _, c := range containers {
+		c.Type = "postgres"
+		c.Version = "15"
+		c.Port = "5432"
+		c.CreatedAt = now.Add(-72 * time.Hour)
+		if err := CreateContainer(c); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+
+	names := func(cs []*Container) map[string]bool {
+		m := make(map[string]bool)
+		for _, c := range cs {
+			m[c.DisplayName] = true
+		}
+		return m
+	}
+
+	expired, err := GetExpiredContainers()
+	if err != nil {
+		t.Fatalf("GetExpiredContainers() error = %v", err)
+	}
+	got := names(expired)
+	if len(expired) != 1 || !got["expired-running"] {
+		t.Errorf("GetExpiredContainers() = %v, want only expired-running", got)
+	}
+
+	expired, err = GetExpiredContainersIncludingStopped()
+	if err != nil {
+		t.Fatalf("GetExpiredContainersIncludingStopped() error = %v", err)
+	}
+	got = names(expired)
+	if len(expired) != 2 || !got["expired-running"] || !got["expired-stopped"] {
+		t.Errorf("GetExpiredContainersIncludingStopped() = %v, want expired-running and expired-stopped", got)
+	}
+}
+
+func TestGetContainersForCleanup(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	now := time.Now()
+
+	containers := []*Container{
+		{
+			Name:        "mkdb-recent-running",
+			DisplayName: "recent-running",
+			Status:      "running",
+			ExpiresAt:   now.Add(-1 * time.Hour),
+		},
+		{
+			Name:        "mkdb-old-running",
+			DisplayName: "old-running",
+			Status:      "running",
+			ExpiresAt:   now.Add(-48 * time.Hour),
+		},
+		{
+			Name:        "mkdb-old-stopped",
+			DisplayName: "old-stopped",
+			Status:      "stopped",
+			ExpiresAt:   now.Add(-48 * time.Hour),
+		},
+		{
+			Name:        "mkdb-recent-stopped",
+			DisplayName: "recent-stopped",
+			Status:      "stopped",
+			ExpiresAt:   now.Add(-1 * time.Hour),
+		},
+		{
+			// Pinned containers are never cleaned up
+			Name:        "mkdb-pinned",
+			DisplayName: "pinned",
+			Status:      "running",
+			ExpiresAt:   now.Add(-48 * time.Hour),
+			Pinned:      true,
+		},
+	}
+
+	for _, c := range containers {
+		c.Type = "postgres"
+		c.Version = "15"
+		c.Port = "5432"
+		c.CreatedAt = now.Add(-72 * time.Hour)
+		if err := CreateContainer(c); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		grace          time.Duration
+		includeStopped bool
+		want           []string
+	}{
+		{"No grace", 0, false, []string{"old-running", "recent-running"}},
+		{"No grace with stopped", 0, true, []string{"old-running", "old-stopped", "recent-running", "recent-stopped"}},
+		{"Grace period", 24 * time.Hour, false, []string{"old-running"}},
+		{"Grace period with stopped", 24 * time.Hour, true, []string{"old-running", "old-stopped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetContainersForCleanup(tt.grace, tt.includeStopped)
+			if err != nil {
+				t.Fatalf("GetContainersForCleanup() error = %v", err)
+			}
+
+			var names []string
+			for _, c := range got {
+				names = append(names, c.DisplayName)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("GetContainersForCleanup() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateAndGetUser(t *testing.T) {
 	setupTestDB(t)
 	defer cleanupTestDB(t)
@@ -557,6 +898,65 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
+func TestLogicalDatabases(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	container := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Version:     "15",
+		Port:        "5432",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+	}
+
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	for _, name := range []string{"orders", "analytics"} {
+		d := &LogicalDatabase{ContainerID: container.ID, Name: name, CreatedAt: time.Now()}
+		if err := CreateLogicalDatabase(d); err != nil {
+			t.Fatalf("CreateLogicalDatabase() error = %v", err)
+		}
+		if d.ID == 0 {
+			t.Error("CreateLogicalDatabase() did not set ID")
+		}
+	}
+
+	// Names must be unique per container
+	duplicate := &LogicalDatabase{ContainerID: container.ID, Name: "orders", CreatedAt: time.Now()}
+	if err := CreateLogicalDatabase(duplicate); err == nil {
+		t.Error("CreateLogicalDatabase() expected error for duplicate name")
+	}
+
+	retrieved, err := ListLogicalDatabases(container.ID)
+	if err != nil {
+		t.Fatalf("ListLogicalDatabases() error = %v", err)
+	}
+	if len(retrieved) != 2 {
+		t.Fatalf("ListLogicalDatabases() returned %d databases, want 2", len(retrieved))
+	}
+	if retrieved[0].Name != "analytics" || retrieved[1].Name != "orders" {
+		t.Errorf("ListLogicalDatabases() = [%s %s], want sorted by name", retrieved[0].Name, retrieved[1].Name)
+	}
+
+	if err := DeleteLogicalDatabase(retrieved[0].ID); err != nil {
+		t.Fatalf("DeleteLogicalDatabase() error = %v", err)
+	}
+
+	retrieved, err = ListLogicalDatabases(container.ID)
+	if err != nil {
+		t.Fatalf("ListLogicalDatabases() error = %v", err)
+	}
+	if len(retrieved) != 1 {
+		t.Errorf("ListLogicalDatabases() after delete returned %d databases, want 1", len(retrieved))
+	}
+}
+
 func TestCreateEvent(t *testing.T) {
 	setupTestDB(t)
 	defer cleanupTestDB(t)
@@ -590,4 +990,381 @@ func TestCreateEvent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreateEvent() error = %v", err)
 	}
+
+	events, err := ListEvents(container.ID)
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(events) != 1 || events[0].EventType != "created" || events[0].Details != "Test event" {
+		t.Errorf("ListEvents() = %+v, want the created event", events)
+	}
+}
+
+func TestGetLatestEvent(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	container := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Port:        "5432",
+		Status:      "stopped",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	if _, err := GetLatestEvent(container.ID, "stopped"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLatestEvent() without events error = %v, want sql.ErrNoRows", err)
+	}
+
+	latest := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	for _, e := range []*Event{
+		{ContainerID: container.ID, EventType: "stopped", Timestamp: latest.Add(-time.Hour), Details: "first"},
+		{ContainerID: container.ID, EventType: "stopped", Timestamp: latest, Details: "second"},
+		{ContainerID: container.ID, EventType: "restarted", Timestamp: latest.Add(time.Minute)},
+	} {
+		if err := CreateEvent(e); err != nil {
+			t.Fatalf("CreateEvent() error = %v", err)
+		}
+	}
+
+	event, err := GetLatestEvent(container.ID, "stopped")
+	if err != nil {
+		t.Fatalf("GetLatestEvent() error = %v", err)
+	}
+	if event.Details != "second" || !event.Timestamp.Equal(latest) {
+		t.Errorf("GetLatestEvent() = %+v, want the second stop at %v", event, latest)
+	}
+}
+
+func TestHasEventSince(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	container := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Port:        "5432",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(-time.Hour),
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	recorded := time.Now().Add(-30 * time.Minute)
+	if err := CreateEvent(&Event{ContainerID: container.ID, EventType: "cleanup_skipped", Timestamp: recorded}); err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		eventType string
+		since     time.Time
+		want      bool
+	}{
+		{"Recorded after since", "cleanup_skipped", recorded.Add(-time.Minute), true},
+		{"Recorded before since", "cleanup_skipped", recorded.Add(time.Minute), false},
+		{"Other event type", "cleanup_failed", recorded.Add(-time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HasEventSince(container.ID, tt.eventType, tt.since)
+			if err != nil {
+				t.Fatalf("HasEventSince() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasEventSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// setupTestStore initializes the credential store, which encrypts container environments
+func setupTestStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() error = %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+	setupTestStore(t)
+
+	// Running migrations again should be a no-op
+	if err := migrate(); err != nil {
+		t.Fatalf("migrate() second run error = %v", err)
+	}
+
+	for _, m := range migrations {
+		exists, err := columnExists(m.table, m.column)
+		if err != nil {
+			t.Fatalf("columnExists() error = %v", err)
+		}
+		if !exists {
+			t.Errorf("migrate() did not add column %s.%s", m.table, m.column)
+		}
+	}
+
+	// Migrated columns should round-trip
+	container := &Container{
+		Name:              "mkdb-cache",
+		DisplayName:       "cache",
+		Type:              "redis",
+		Version:           "8",
+		Port:              "6379",
+		Status:            "running",
+		CreatedAt:         time.Now(),
+		ExpiresAt:         time.Now().Add(time.Hour),
+		VolumeType:        "docker",
+		VolumePath:        "mkdb-cache",
+		VolumeDriver:      "local",
+		Persistence:       "aof",
+		CPUShares:         512,
+		VolumeReadOnly:    true,
+		DataTarget:        "/seed",
+		RestartPolicy:     "no",
+		ExtraEnv:          []string{"REDIS_ARGS=--maxmemory 64mb"},
+		AdminPasswordHash: "encrypted",
+		BindIP:            "127.0.0.1",
+		ExtraArgs:         []string{"--maxmemory", "64mb"},
+		Network:           "app",
+		RedisDB:           3,
+		NoConfig:          true,
+		Adopted:           true,
+		Pinned:            true,
+		Labels:            []string{"traefik.enable=true"},
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	retrieved, err := GetContainer("mkdb-cache")
+	if err != nil {
+		t.Fatalf("GetContainer() error = %v", err)
+	}
+	if retrieved.VolumeDriver != "local" {
+		t.Errorf("GetContainer() VolumeDriver = %v, want local", retrieved.VolumeDriver)
+	}
+	if retrieved.Persistence != "aof" {
+		t.Errorf("GetContainer() Persistence = %v, want aof", retrieved.Persistence)
+	}
+	if retrieved.CPUShares != 512 {
+		t.Errorf("GetContainer() CPUShares = %v, want 512", retrieved.CPUShares)
+	}
+	if !retrieved.VolumeReadOnly {
+		t.Errorf("GetContainer() VolumeReadOnly = false, want true")
+	}
+	if retrieved.DataTarget != "/seed" {
+		t.Errorf("GetContainer() DataTarget = %v, want /seed", retrieved.DataTarget)
+	}
+	if retrieved.RestartPolicy != "no" {
+		t.Errorf("GetContainer() RestartPolicy = %v, want no", retrieved.RestartPolicy)
+	}
+	if len(retrieved.ExtraEnv) != 1 || retrieved.ExtraEnv[0] != "REDIS_ARGS=--maxmemory 64mb" {
+		t.Errorf("GetContainer() ExtraEnv = %q, want [REDIS_ARGS=--maxmemory 64mb]", retrieved.ExtraEnv)
+	}
+	if retrieved.AdminPasswordHash != "encrypted" {
+		t.Errorf("GetContainer() AdminPasswordHash = %v, want encrypted", retrieved.AdminPasswordHash)
+	}
+	if retrieved.BindIP != "127.0.0.1" {
+		t.Errorf("GetContainer() BindIP = %v, want 127.0.0.1", retrieved.BindIP)
+	}
+	if !slices.Equal(retrieved.ExtraArgs, []string{"--maxmemory", "64mb"}) {
+		t.Errorf("GetContainer() ExtraArgs = %q, want [--maxmemory 64mb]", retrieved.ExtraArgs)
+	}
+	if retrieved.Network != "app" {
+		t.Errorf("GetContainer() Network = %v, want app", retrieved.Network)
+	}
+	if retrieved.RedisDB != 3 {
+		t.Errorf("GetContainer() RedisDB = %v, want 3", retrieved.RedisDB)
+	}
+	if !retrieved.NoConfig {
+		t.Errorf("GetContainer() NoConfig = false, want true")
+	}
+	if !retrieved.Adopted {
+		t.Errorf("GetContainer() Adopted = false, want true")
+	}
+	if !retrieved.Pinned {
+		t.Errorf("GetContainer() Pinned = false, want true")
+	}
+	if !slices.Equal(retrieved.Labels, []string{"traefik.enable=true"}) {
+		t.Errorf("GetContainer() Labels = %q, want [traefik.enable=true]", retrieved.Labels)
+	}
+
+	// Pinning is changed by UpdateContainer
+	retrieved.Pinned = false
+	if err := UpdateContainer(retrieved); err != nil {
+		t.Fatalf("UpdateContainer() error = %v", err)
+	}
+	if updated, err := GetContainer("mkdb-cache"); err != nil || updated.Pinned {
+		t.Errorf("GetContainer() after unpinning = %+v, %v, want Pinned false", updated, err)
+	}
+}
+
+func TestExtraEnvEncrypted(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+	setupTestStore(t)
+
+	container := &Container{
+		Name:        "mkdb-app",
+		DisplayName: "app",
+		Type:        "postgres",
+		Version:     "16",
+		Port:        "5432",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		ExtraEnv:    []string{"API_TOKEN=secret"},
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT extra_env FROM containers WHERE id = ?`, container.ID).Scan(&stored); err != nil {
+		t.Fatalf("failed to read extra_env: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "secret") {
+		t.Errorf("extra_env = %q, want it encrypted", stored)
+	}
+
+	// Older versions stored the environment as plaintext JSON, which the migration encrypts
+	if _, err := db.Exec(`UPDATE containers SET extra_env = ? WHERE id = ?`, `["LEGACY=plain"]`, container.ID); err != nil {
+		t.Fatalf("failed to store plaintext environment: %v", err)
+	}
+	if got, err := GetContainerByID(container.ID); err != nil || !slices.Equal(got.ExtraEnv, []string{"LEGACY=plain"}) {
+		t.Errorf("GetContainerByID() before migrating = %v, %v, want [LEGACY=plain]", got, err)
+	}
+	if err := migrate(); err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+	if err := db.QueryRow(`SELECT extra_env FROM containers WHERE id = ?`, container.ID).Scan(&stored); err != nil {
+		t.Fatalf("failed to read extra_env: %v", err)
+	}
+	if strings.Contains(stored, "plain") {
+		t.Errorf("extra_env after migrating = %q, want it encrypted", stored)
+	}
+	if got, err := GetContainerByID(container.ID); err != nil || !slices.Equal(got.ExtraEnv, []string{"LEGACY=plain"}) || got.EnvUnreadable {
+		t.Errorf("GetContainerByID() after migrating = %v, %v, want [LEGACY=plain]", got, err)
+	}
+
+	// An environment encrypted with another key still loads the container, flagged as unreadable
+	if _, err := db.Exec(`UPDATE containers SET extra_env = ? WHERE id = ?`, "not-ciphertext", container.ID); err != nil {
+		t.Fatalf("failed to store undecryptable environment: %v", err)
+	}
+	got, err := GetContainerByID(container.ID)
+	if err != nil {
+		t.Fatalf("GetContainerByID() with undecryptable environment error = %v", err)
+	}
+	if got.ExtraEnv != nil || !got.EnvUnreadable {
+		t.Errorf("GetContainerByID() ExtraEnv = %q, EnvUnreadable = %v, want empty and true", got.ExtraEnv, got.EnvUnreadable)
+	}
+}
+
+func TestNormalizeTimestamps(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	// Older versions stored times with the local zone's offset
+	zone := time.FixedZone("PDT", -7*60*60)
+	expires := time.Date(2026, 10, 16, 17, 30, 0, 0, zone)
+	if _, err := db.Exec(`
+		INSERT INTO containers (name, display_name, type, version, container_id, port, status, created_at, expires_at, volume_type, volume_path)
+		VALUES ('mkdb-old', 'old', 'postgres', '16', '', '5432', 'running', ?, ?, 'none', '')
+	`, expires.Add(-24*time.Hour), expires); err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+
+	if err := normalizeTimestamps(); err != nil {
+		t.Fatalf("normalizeTimestamps() error = %v", err)
+	}
+
+	// Concatenating reads the stored text, rather than a time formatted by database/sql
+	var stored string
+	if err := db.QueryRow(`SELECT expires_at || '' FROM containers WHERE name = 'mkdb-old'`).Scan(&stored); err != nil {
+		t.Fatalf("select error = %v", err)
+	}
+	if stored != "2026-10-17 00:30:00 +0000 UTC" {
+		t.Errorf("expires_at = %q, want 2026-10-17 00:30:00 +0000 UTC", stored)
+	}
+
+	// Values written by this version are already in UTC, so nothing is left to rewrite
+	if err := CreateContainer(&Container{Name: "mkdb-new", DisplayName: "new", Type: "redis", Port: "6379", Status: "running", CreatedAt: time.Now(), ExpiresAt: time.Now()}); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	for _, tc := range timestampColumns {
+		var count int
+		query := fmt.Sprintf("SELECT count(*) FROM %s WHERE %s NOT LIKE ?", tc.table, tc.column)
+		if err := db.QueryRow(query, utcPattern).Scan(&count); err != nil {
+			t.Fatalf("select error = %v", err)
+		}
+		if count != 0 {
+			t.Errorf("%d value(s) of %s.%s don't match %q, want all of them in UTC", count, tc.table, tc.column, utcPattern)
+		}
+	}
+
+	retrieved, err := GetContainer("mkdb-old")
+	if err != nil {
+		t.Fatalf("GetContainer() error = %v", err)
+	}
+	if !retrieved.ExpiresAt.Equal(expires) {
+		t.Errorf("GetContainer() ExpiresAt = %v, want %v", retrieved.ExpiresAt, expires)
+	}
+	if retrieved.ExpiresAt.Location() != time.UTC {
+		t.Errorf("GetContainer() ExpiresAt location = %v, want UTC", retrieved.ExpiresAt.Location())
+	}
+}
+
+func TestGetRemovedContainerWithVolume(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB(t)
+
+	container := &Container{
+		Name:        "mkdb-testdb",
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Version:     "16",
+		Port:        "5432",
+		Status:      "running",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		VolumeType:  "named",
+		VolumePath:  "testdb",
+	}
+	if err := CreateContainer(container); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	// Only removed records count, the running container is still using its volume
+	if _, err := GetRemovedContainerWithVolume("mkdb-testdb", "named", "testdb"); err != sql.ErrNoRows {
+		t.Errorf("GetRemovedContainerWithVolume() for a running container error = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := MarkContainerRemoved(container.ID); err != nil {
+		t.Fatalf("MarkContainerRemoved() error = %v", err)
+	}
+	got, err := GetRemovedContainerWithVolume("mkdb-testdb", "named", "testdb")
+	if err != nil {
+		t.Fatalf("GetRemovedContainerWithVolume() error = %v", err)
+	}
+	if got.ID != container.ID {
+		t.Errorf("GetRemovedContainerWithVolume() ID = %d, want %d", got.ID, container.ID)
+	}
+
+	// A different volume wasn't initialized by the removed container
+	if _, err := GetRemovedContainerWithVolume("mkdb-testdb", "docker", "testdb"); err != sql.ErrNoRows {
+		t.Errorf("GetRemovedContainerWithVolume() for another volume error = %v, want sql.ErrNoRows", err)
+	}
 }
@@ -0,0 +1,134 @@
+// Package diff renders line-based differences between two texts as a unified diff
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change, as in diff -u
+const DefaultContext = 3
+
+// edit is one line of the diff, kept (' '), deleted ('-') or inserted ('+')
+// oldLine and newLine are the zero-based positions in each text where the line is, or would be
+type edit struct {
+	op      byte
+	text    string
+	oldLine int
+	newLine int
+}
+
+// Unified returns the unified diff turning oldText into newText, or "" if they are equal
+// oldName and newName label the texts in the --- and +++ header lines
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	if oldText == newText {
+		return ""
+	}
+	edits := lineEdits(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for k := 0; k < len(edits); {
+		if edits[k].op == ' ' {
+			k++
+			continue
+		}
+
+		// Changes separated by at most twice the context share a hunk
+		start := max(k-context, 0)
+		end := k
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		stop := min(end+context, len(edits))
+
+		writeHunk(&b, edits[start:stop])
+		k = stop
+	}
+	return b.String()
+}
+
+// writeHunk writes a hunk header followed by its lines
+func writeHunk(b *strings.Builder, hunk []edit) {
+	oldCount, newCount := 0, 0
+	for _, e := range hunk {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it, as in diff -u
+	oldStart, newStart := hunk[0].oldLine+1, hunk[0].newLine+1
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, e := range hunk {
+		b.WriteByte(e.op)
+		b.WriteString(e.text)
+		b.WriteByte('\n')
+	}
+}
+
+// lineEdits returns the shortest edit script from a to b, from their longest common subsequence
+// Deletions come before insertions where both are possible, so replaced lines read as - then +
+func lineEdits(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j], i, j})
+			j++
+		}
+	}
+	return edits
+}
+
+// splitLines splits text into lines, without a trailing empty line for the final newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -1,11 +1,18 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,8 +21,10 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/pbzona/mkdb/internal/adapters"
 	"github.com/pbzona/mkdb/internal/config"
@@ -26,10 +35,202 @@ const (
 	labelManaged    = "mkdb.managed"
 	labelType       = "mkdb.type"
 	labelName       = "mkdb.name"
+
+	// reservedLabelPrefix starts the labels mkdb manages, which --label can't set
+	reservedLabelPrefix = "mkdb."
 )
 
 var cli *client.Client
 
+// ContainerSummary describes a Docker container carrying the mkdb labels
+type ContainerSummary struct {
+	ID         string
+	Name       string
+	Type       string
+	Image      string
+	State      string
+	Port       string
+	VolumeType string
+	VolumePath string
+	Created    time.Time
+
+	// Managed is false for containers found by InspectContainer that don't carry the mkdb labels
+	Managed bool
+}
+
+// ContainerOptions holds the settings used to create a database container
+type ContainerOptions struct {
+	DBType         string
+	DisplayName    string
+	Username       string
+	Password       string
+	Port           string
+	VolumeType     string
+	VolumePath     string
+	VolumeDriver   string // Driver for a Docker volume, empty for Docker's default
+	Version        string
+	Persistence    string
+	CPUShares      int64 // Relative CPU weight, 0 uses Docker's default of 1024
+	VolumeReadOnly bool
+	DataTarget     string   // Path the volume is mounted at in the container, empty for the adapter's data path
+	RestartPolicy  string   // Docker restart policy, empty for DefaultRestartPolicy
+	ExtraEnv       []string // KEY=VALUE pairs added to the adapter's environment
+	AdminPassword  string   // Administrative password for adapters that implement AdminPasswordAdapter
+	BindIP         string   // Host interface the port is published on, empty for DefaultBindIP
+	ExtraArgs      []string // Server flags appended to the adapter's command
+	Network        string   // User-defined network to attach the container to, empty for Docker's default bridge
+	NoConfig       bool     // Don't mount the config directory, leaving the image's own configuration in place
+	MaxConnections int      // Connection limit written to the config file, 0 leaves the file's limit in place
+	Labels         []string // KEY=VALUE Docker labels added to mkdb's own
+}
+
+// DefaultRestartPolicy is used for containers created without an explicit restart policy
+const DefaultRestartPolicy = "unless-stopped"
+
+// RestartPolicies lists the restart policies accepted by --restart
+var RestartPolicies = []string{"no", "on-failure", "always", "unless-stopped"}
+
+// ValidateRestartPolicy checks that policy is one of RestartPolicies
+func ValidateRestartPolicy(policy string) error {
+	if !slices.Contains(RestartPolicies, policy) {
+		return fmt.Errorf("invalid restart policy '%s' (must be one of: %s)", policy, strings.Join(RestartPolicies, ", "))
+	}
+	return nil
+}
+
+// ResolveRestartPolicy returns policy, or DefaultRestartPolicy if it is empty
+func ResolveRestartPolicy(policy string) string {
+	if policy == "" {
+		return DefaultRestartPolicy
+	}
+	return policy
+}
+
+// DefaultBindIP publishes container ports on every host interface
+const DefaultBindIP = "0.0.0.0"
+
+// ValidateBindIP checks that ip is an IP address Docker can publish a port on
+func ValidateBindIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid bind address '%s' (must be an IP address, e.g. 127.0.0.1)", ip)
+	}
+	return nil
+}
+
+// ResolveBindIP returns ip, or DefaultBindIP if it is empty
+func ResolveBindIP(ip string) string {
+	if ip == "" {
+		return DefaultBindIP
+	}
+	return ip
+}
+
+// ValidateNetworkName rejects Docker's built-in networks that can't be used with a published port
+func ValidateNetworkName(name string) error {
+	switch name {
+	case "host", "none":
+		return fmt.Errorf("network '%s' can't be used, mkdb publishes the database port on the host", name)
+	}
+	if strings.ContainsAny(name, " \t\n/") {
+		return fmt.Errorf("invalid network name '%s'", name)
+	}
+	return nil
+}
+
+// NetworkExists reports whether a Docker network with exactly this name exists
+func NetworkExists(name string) (bool, error) {
+	networks, err := cli.NetworkList(context.Background(), network.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", name)),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	// The name filter also matches networks that contain name
+	for _, n := range networks {
+		if n.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EnsureNetwork creates a bridge network with this name unless one already exists
+func EnsureNetwork(name string) error {
+	exists, err := NetworkExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = cli.NetworkCreate(context.Background(), name, network.CreateOptions{
+		Driver: "bridge",
+		Labels: map[string]string{labelManaged: "true"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+
+	config.Logger.Info("Network created", "name", name)
+	return nil
+}
+
+// volumeNamePattern is what Docker accepts as a volume name
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ValidateVolumeName checks that name can be used for a Docker volume
+func ValidateVolumeName(name string) error {
+	if !volumeNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid Docker volume name '%s' (use letters, digits, '_', '.' and '-')", name)
+	}
+	return nil
+}
+
+// ensureVolume creates a Docker volume unless one with this name already exists
+// Volumes mkdb creates are labeled, so RemoveVolume leaves volumes created outside mkdb alone
+func ensureVolume(ctx context.Context, name, driver string) error {
+	existing, err := cli.VolumeInspect(ctx, name)
+	if err == nil {
+		if driver != "" && existing.Driver != driver {
+			return fmt.Errorf("Docker volume '%s' already exists with driver %s, not %s", name, existing.Driver, driver)
+		}
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect volume: %w", err)
+	}
+
+	_, err = cli.VolumeCreate(ctx, volume.CreateOptions{
+		Name:   name,
+		Driver: driver,
+		Labels: map[string]string{labelManaged: "true"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create volume: %w", err)
+	}
+	config.Logger.Info("Volume created", "name", name, "driver", driver)
+	return nil
+}
+
+// Minimum and maximum CPU shares accepted by Docker
+const (
+	MinCPUShares = 2
+	MaxCPUShares = 262144
+)
+
+// ValidateCPUShares checks that shares is within the range Docker accepts
+func ValidateCPUShares(shares int64) error {
+	if shares < MinCPUShares || shares > MaxCPUShares {
+		return fmt.Errorf("invalid CPU shares %d (must be between %d and %d, default 1024)", shares, MinCPUShares, MaxCPUShares)
+	}
+	return nil
+}
+
+// DefaultStopTimeout is how long a database gets to shut down before it is killed
+const DefaultStopTimeout = 30 * time.Second
+
 // DBConfig represents database-specific configuration
 type DBConfig struct {
 	Image       string
@@ -37,20 +238,40 @@ type DBConfig struct {
 	EnvVars     map[string]string
 }
 
-// Initialize creates a Docker client
+// Initialize creates a Docker client for the endpoint picked by ResolveEndpoint
 func Initialize() error {
-	var err error
-	cli, err = client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	endpoint, err := ResolveEndpoint()
+	if err != nil {
+		return err
+	}
+	activeEndpoint = endpoint
+
+	cli, err = client.NewClientWithOpts(endpoint.clientOpts()...)
 	if err != nil {
-		return fmt.Errorf("failed to create Docker client: %w", err)
+		return fmt.Errorf("failed to create Docker client for %s: %w", endpoint, err)
 	}
 
 	// Test connection
 	ctx := context.Background()
 	if _, err := cli.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to connect to Docker daemon: %w", err)
+		return fmt.Errorf("failed to connect to Docker daemon at %s: %w", endpoint, err)
+	}
+
+	config.Logger.Info("Connected to Docker", "host", endpoint.Host, "source", endpoint.Source)
+	if err := loadExecSettings(); err != nil {
+		config.Logger.Warn("Using the default exec settings", "error", err)
 	}
+	return nil
+}
 
+// Ping checks that the Docker daemon is reachable, for commands that only need it for some flags
+func Ping() error {
+	if cli == nil {
+		return fmt.Errorf("Docker client is not initialized")
+	}
+	if _, err := cli.Ping(context.Background()); err != nil {
+		return fmt.Errorf("failed to connect to Docker daemon: %w", err)
+	}
 	return nil
 }
 
@@ -78,7 +299,8 @@ func GetDBConfig(dbType, version string) *DBConfig {
 }
 
 // IsPortAvailable checks if a port is available on the host
-func IsPortAvailable(port string) (bool, error) {
+// Containers given by name or ID in ignore don't count, such as a container that is about to be replaced
+func IsPortAvailable(port string, ignore ...string) (bool, error) {
 	ctx := context.Background()
 
 	// List all containers
@@ -87,18 +309,240 @@ func IsPortAvailable(port string) (bool, error) {
 		return false, err
 	}
 
-	portNum := uint16(mustAtoi(port))
+	return !portInUse(containers, uint16(mustAtoi(port)), ignore), nil
+}
 
-	// Check if any container is using this port
+// portInUse reports whether any of the containers, other than the ignored ones, publishes port
+func portInUse(containers []container.Summary, port uint16, ignore []string) bool {
 	for _, c := range containers {
+		if isIgnoredContainer(c, ignore) {
+			continue
+		}
 		for _, p := range c.Ports {
-			if p.PublicPort == portNum {
-				return false, nil
+			if p.PublicPort == port {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isIgnoredContainer reports whether c's ID or one of its names is in ignore
+func isIgnoredContainer(c container.Summary, ignore []string) bool {
+	for _, ref := range ignore {
+		if ref == "" {
+			continue
+		}
+		if c.ID == ref {
+			return true
+		}
+		for _, name := range c.Names {
+			if strings.TrimPrefix(name, "/") == ref {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListManagedContainers returns all Docker containers labeled as managed by mkdb
+func ListManagedContainers() ([]ContainerSummary, error) {
+	ctx := context.Background()
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", labelManaged+"=true")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	registry := adapters.GetRegistry()
+
+	summaries := make([]ContainerSummary, 0, len(containers))
+	for _, c := range containers {
+		summary := ContainerSummary{
+			ID:      c.ID,
+			Name:    c.Labels[labelName],
+			Type:    c.Labels[labelType],
+			Image:   c.Image,
+			State:   string(c.State),
+			Created: time.Unix(c.Created, 0),
+			Managed: true,
+		}
+
+		// Fall back to the container name if the name label is missing
+		if summary.Name == "" && len(c.Names) > 0 {
+			summary.Name = strings.TrimPrefix(strings.TrimPrefix(c.Names[0], "/"), containerPrefix)
+		}
+
+		// Use the first published port
+		for _, p := range c.Ports {
+			if p.PublicPort != 0 {
+				summary.Port = strconv.Itoa(int(p.PublicPort))
+				break
+			}
+		}
+
+		// Stopped containers don't report published ports, so read the configured bindings
+		if summary.Port == "" {
+			if info, err := cli.ContainerInspect(ctx, c.ID); err == nil && info.HostConfig != nil {
+				for _, bindings := range info.HostConfig.PortBindings {
+					if len(bindings) > 0 && bindings[0].HostPort != "" {
+						summary.Port = bindings[0].HostPort
+						break
+					}
+				}
+			}
+		}
+
+		summary.VolumeType, summary.VolumePath = dataVolume(registry, summary.Type, c.Mounts)
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// InspectContainer describes any Docker container by name or ID, whether or not it carries the mkdb labels
+// Type is taken from the mkdb.type label, or detected from the image, and is empty if neither matches an adapter
+func InspectContainer(ref string) (*ContainerSummary, error) {
+	info, err := cli.ContainerInspect(context.Background(), ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	summary := &ContainerSummary{
+		ID:   info.ID,
+		Name: strings.TrimPrefix(info.Name, "/"),
+	}
+	if created, err := time.Parse(time.RFC3339Nano, info.Created); err == nil {
+		summary.Created = created
+	}
+	if info.State != nil {
+		summary.State = string(info.State.Status)
+	}
+
+	registry := adapters.GetRegistry()
+	if info.Config != nil {
+		summary.Image = info.Config.Image
+		summary.Managed = info.Config.Labels[labelManaged] == "true"
+		summary.Type = info.Config.Labels[labelType]
+		if summary.Type == "" {
+			summary.Type, _ = registry.DetectType(summary.Image)
+		}
+	}
+
+	// Configured bindings are reported whether or not the container is running,
+	// prefer the database's own port in case others are published too
+	if info.HostConfig != nil {
+		if adapter, err := registry.Get(summary.Type); err == nil {
+			if bindings := info.HostConfig.PortBindings[nat.Port(adapter.GetDefaultPort()+"/tcp")]; len(bindings) > 0 {
+				summary.Port = bindings[0].HostPort
 			}
 		}
+		for _, bindings := range info.HostConfig.PortBindings {
+			if summary.Port != "" {
+				break
+			}
+			if len(bindings) > 0 && bindings[0].HostPort != "" {
+				summary.Port = bindings[0].HostPort
+			}
+		}
+	}
+
+	summary.VolumeType, summary.VolumePath = dataVolume(registry, summary.Type, info.Mounts)
+	return summary, nil
+}
+
+// dataVolume infers a container's data volume from the mount targeting the adapter's data path
+// Returns empty strings if the type is unknown or nothing is mounted there
+func dataVolume(registry *adapters.Registry, dbType string, mounts []container.MountPoint) (string, string) {
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return "", ""
+	}
+	for _, m := range mounts {
+		if m.Destination != adapter.GetDataPath() {
+			continue
+		}
+		if m.Type == mount.TypeVolume {
+			return "docker", m.Name
+		}
+		if filepath.Dir(m.Source) == config.VolumesDir {
+			return "named", filepath.Base(m.Source)
+		}
+		return "bind", m.Source
 	}
+	return "", ""
+}
+
+// ContainerCredentials are the credentials recovered from an existing container's configuration
+type ContainerCredentials struct {
+	Username string
+	Password string
+
+	// AdminPassword is set for databases with a separate administrative account, such as MySQL's root user
+	AdminPassword string
+}
+
+// GetContainerCredentials recovers a container's credentials from its configuration
+// Returns empty credentials if the container was created without authentication
+func GetContainerCredentials(containerID, dbType string) (ContainerCredentials, error) {
+	ctx := context.Background()
 
-	return true, nil
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return ContainerCredentials{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.Config == nil {
+		return ContainerCredentials{}, nil
+	}
+	return credentialsFromConfig(dbType, info.Config.Env, info.Config.Cmd), nil
+}
+
+// credentialsFromConfig reads credentials from a container's environment and command line
+// The admin password is read from the variables the adapter sets with GetAdminEnvVars
+func credentialsFromConfig(dbType string, env, cmd []string) ContainerCredentials {
+	adminKeys := make(map[string]bool)
+	if adapter, err := adapters.GetRegistry().Get(dbType); err == nil {
+		if adminAdapter, ok := adapter.(adapters.AdminPasswordAdapter); ok {
+			for _, v := range adminAdapter.GetAdminEnvVars("") {
+				key, _, _ := strings.Cut(v, "=")
+				adminKeys[key] = true
+			}
+		}
+	}
+
+	var creds ContainerCredentials
+	for _, e := range env {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			continue
+		}
+		if adminKeys[key] {
+			creds.AdminPassword = value
+			continue
+		}
+		if strings.Contains(key, "ROOT") {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(key, "_USER"):
+			creds.Username = value
+		case strings.HasSuffix(key, "_PASSWORD"):
+			creds.Password = value
+		}
+	}
+
+	// Databases like Redis take the password as a command line argument
+	for i, arg := range cmd {
+		if arg == "--requirepass" && i+1 < len(cmd) {
+			creds.Password = cmd[i+1]
+		}
+	}
+
+	return creds
 }
 
 // FindAvailablePort finds the next available port starting from the default port
@@ -107,8 +551,13 @@ func FindAvailablePort(startPort string) (string, error) {
 	basePort := mustAtoi(startPort)
 	maxAttempts := 100 // Check up to 100 ports
 
-	for i := 0; i < maxAttempts; i++ {
-		port := fmt.Sprintf("%d", basePort+i)
+	return FindAvailablePortInRange(basePort, basePort+maxAttempts-1)
+}
+
+// FindAvailablePortInRange finds the first available port between start and end, inclusive
+func FindAvailablePortInRange(start, end int) (string, error) {
+	for p := start; p <= end; p++ {
+		port := strconv.Itoa(p)
 		available, err := IsPortAvailable(port)
 		if err != nil {
 			return "", err
@@ -118,15 +567,64 @@ func FindAvailablePort(startPort string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no available ports found in range %d-%d", basePort, basePort+maxAttempts)
+	return "", fmt.Errorf("no available ports found in range %d-%d", start, end)
 }
 
-// CreateContainer creates and starts a database container
-func CreateContainer(dbType, displayName, username, password, port, volumeType, volumePath, version string) (string, error) {
-	ctx := context.Background()
+// ReclaimPort checks that a container's stored port is still free before the container is recreated
+// If the port has been taken and reassign is true, the next available port is returned instead.
+// Pass the name and ID of the container being replaced as ignore, so its old container doesn't count
+func ReclaimPort(port string, reassign bool, ignore ...string) (string, error) {
+	isAvailable := func(port string) (bool, error) {
+		return IsPortAvailable(port, ignore...)
+	}
+	return reclaimPort(port, reassign, isAvailable, FindAvailablePort)
+}
+
+func reclaimPort(port string, reassign bool, isAvailable func(string) (bool, error), findAvailable func(string) (string, error)) (string, error) {
+	available, err := isAvailable(port)
+	if err != nil {
+		return "", fmt.Errorf("failed to check port %s: %w", port, err)
+	}
+	if available {
+		return port, nil
+	}
+	if !reassign {
+		return "", fmt.Errorf("port %s is now in use by another container, use --reassign-port to pick a new one", port)
+	}
+	return findAvailable(port)
+}
+
+// ParsePortRange parses a port range in the form "start-end"
+func ParsePortRange(s string) (int, int, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid port range %q (expected start-end, e.g. 15432-15499)", s)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: start is not a number", s)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: end is not a number", s)
+	}
+
+	if start < 1 || end > 65535 {
+		return 0, 0, fmt.Errorf("invalid port range %q: ports must be between 1 and 65535", s)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid port range %q: start must not be greater than end", s)
+	}
+
+	return start, end, nil
+}
 
-	dbConfig := GetDBConfig(dbType, version)
-	containerName := containerPrefix + displayName
+// CreateContainer creates and starts a database container
+// Cancelling ctx aborts the image pull and creation, a container that was created anyway is removed
+func CreateContainer(ctx context.Context, opts ContainerOptions) (string, error) {
+	dbConfig := GetDBConfig(opts.DBType, opts.Version)
+	containerName := containerPrefix + opts.DisplayName
 
 	// Pull image if not exists
 	config.Logger.Info("Pulling image", "image", dbConfig.Image)
@@ -139,53 +637,457 @@ func CreateContainer(dbType, displayName, username, password, port, volumeType,
 
 	// Get adapter for this database type
 	registry := adapters.GetRegistry()
-	adapter, err := registry.Get(dbType)
+	adapter, err := registry.Get(opts.DBType)
 	if err != nil {
 		return "", fmt.Errorf("failed to get adapter: %w", err)
 	}
 
+	containerConfig, hostConfig, err := buildContainerConfig(adapter, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// Add the config mount unless the image's own configuration is wanted
+	if !opts.NoConfig {
+		configMount, err := createConfigMount(adapter, opts.DisplayName, opts.MaxConnections)
+		if err != nil {
+			return "", fmt.Errorf("failed to create config mount: %w", err)
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, configMount)
+	}
+	if err := checkMountSources(hostConfig.Mounts); err != nil {
+		return "", err
+	}
+	if opts.VolumeType == "docker" {
+		if err := ensureVolume(ctx, opts.VolumePath, opts.VolumeDriver); err != nil {
+			return "", err
+		}
+	}
+
+	// Create container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostConfig, networkingConfig(opts), nil, containerName)
+	if err != nil {
+		// The daemon may finish creating the container after the request was cancelled
+		if ctx.Err() != nil {
+			cli.ContainerRemove(context.Background(), containerName, container.RemoveOptions{Force: true})
+		}
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	start := func(id string) error {
+		return cli.ContainerStart(ctx, id, container.StartOptions{})
+	}
+	// The removal has to happen even if ctx was cancelled
+	remove := func(id string) error {
+		return cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+	}
+	if err := startOrRemove(resp.ID, start, remove); err != nil {
+		return "", err
+	}
+
+	config.Logger.Info("Container created", "id", resp.ID[:12], "name", opts.DisplayName)
+	return resp.ID, nil
+}
+
+// ErrPortInUse is returned when a container can't start because its host port was bound by
+// someone else after it was checked, such as another process or a concurrent 'mkdb start'
+var ErrPortInUse = errors.New("port is already in use")
+
+// startOrRemove starts a created container, removing it if that fails so the name can be reused
+// A port conflict is reported as ErrPortInUse
+func startOrRemove(id string, start, remove func(string) error) error {
+	err := start(id)
+	if err == nil {
+		return nil
+	}
+	if isPortInUseError(err) {
+		err = fmt.Errorf("failed to start container: %w: %v", ErrPortInUse, err)
+	} else {
+		err = fmt.Errorf("failed to start container: %w", err)
+	}
+
+	if rmErr := remove(id); rmErr != nil {
+		return fmt.Errorf("%w (and the container couldn't be removed: %v)", err, rmErr)
+	}
+	return err
+}
+
+// DefaultPortRetries is how many ports CreateContainerRetryPort tries before giving up
+const DefaultPortRetries = 5
+
+// PortPicker returns a free port to try after the given port turned out to be taken
+type PortPicker func(taken string) (string, error)
+
+// NextPort searches upward from the port after taken, like FindAvailablePort
+func NextPort(taken string) (string, error) {
+	return FindAvailablePort(strconv.Itoa(mustAtoi(taken) + 1))
+}
+
+// NextPortInRange searches from the port after taken up to end, inclusive
+func NextPortInRange(end int) PortPicker {
+	return func(taken string) (string, error) {
+		return FindAvailablePortInRange(mustAtoi(taken)+1, end)
+	}
+}
+
+// CreateContainerRetryPort creates a container like CreateContainer, but retries on a new port from
+// nextPort if opts.Port is bound by someone else first, such as a concurrent 'mkdb start'
+// Returns the container ID and the port it was bound to
+func CreateContainerRetryPort(ctx context.Context, opts ContainerOptions, maxAttempts int, nextPort PortPicker) (string, string, error) {
+	create := func(opts ContainerOptions) (string, error) {
+		return CreateContainer(ctx, opts)
+	}
+	return createWithPortRetry(opts, maxAttempts, create, nextPort)
+}
+
+func createWithPortRetry(opts ContainerOptions, maxAttempts int, create func(ContainerOptions) (string, error), nextPort PortPicker) (string, string, error) {
+	for attempt := 1; ; attempt++ {
+		id, err := create(opts)
+		if err == nil {
+			return id, opts.Port, nil
+		}
+		if !isPortInUseError(err) || attempt >= maxAttempts {
+			return "", "", err
+		}
+
+		port, pickErr := nextPort(opts.Port)
+		if pickErr != nil {
+			return "", "", fmt.Errorf("port %s was taken and no other port is available: %w", opts.Port, pickErr)
+		}
+		opts.Port = port
+	}
+}
+
+// ParseEnvVar splits a KEY=VALUE pair, the value may be empty
+func ParseEnvVar(pair string) (string, string, error) {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid environment variable '%s' (expected KEY=VALUE)", pair)
+	}
+	if strings.ContainsAny(key, " \t\n") {
+		return "", "", fmt.Errorf("invalid environment variable name '%s'", key)
+	}
+	return key, value, nil
+}
+
+// ParseLabel splits a KEY=VALUE Docker label, the value may be empty
+func ParseLabel(pair string) (string, string, error) {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid label '%s' (expected KEY=VALUE)", pair)
+	}
+	if strings.ContainsAny(key, " \t\n") {
+		return "", "", fmt.Errorf("invalid label name '%s'", key)
+	}
+	return key, value, nil
+}
+
+// ValidateLabels checks the format of Docker labels and that none of them use the mkdb. prefix,
+// which mkdb relies on to find its containers
+func ValidateLabels(labels []string) error {
+	for _, pair := range labels {
+		key, _, err := ParseLabel(pair)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(key, reservedLabelPrefix) {
+			return fmt.Errorf("label %s is reserved, labels starting with %s are managed by mkdb", key, reservedLabelPrefix)
+		}
+	}
+	return nil
+}
+
+// ValidateExtraEnv checks the format of extra environment variables and that none of them
+// replace a variable the adapter manages, such as credentials, unless force is set
+func ValidateExtraEnv(dbType string, extra []string, force bool) error {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return err
+	}
+
+	// Adapters set different variables with and without authentication
+	managed := make(map[string]bool)
+	managedEnv := slices.Concat(adapter.GetEnvVars("db", "user", "password"), adapter.GetEnvVars("db", "", ""))
+	if adminAdapter, ok := adapter.(adapters.AdminPasswordAdapter); ok {
+		managedEnv = append(managedEnv, adminAdapter.GetAdminEnvVars("admin")...)
+	}
+	for _, pair := range managedEnv {
+		key, _, _ := strings.Cut(pair, "=")
+		managed[key] = true
+	}
+
+	for _, pair := range extra {
+		key, _, err := ParseEnvVar(pair)
+		if err != nil {
+			return err
+		}
+		if managed[key] && !force {
+			return fmt.Errorf("%s is managed by mkdb for %s (use --env-force to override it)", key, dbType)
+		}
+	}
+	return nil
+}
+
+// HasAdminPassword reports whether a database type uses a separate administrative password
+func HasAdminPassword(dbType string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	_, ok := adapter.(adapters.AdminPasswordAdapter)
+	return ok
+}
+
+// SupportsUsername reports whether users can be created in a container of a database type running version
+func SupportsUsername(dbType, version string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	return adapter.SupportsUsername(version)
+}
+
+// SupportsMultipleDatabases reports whether named databases can be created in a container of a database type
+func SupportsMultipleDatabases(dbType string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	return adapter.SupportsMultipleDatabases()
+}
+
+// UnauthenticatedReason returns why a database type can only run without authentication,
+// or an empty string if authentication is supported
+func UnauthenticatedReason(dbType string) string {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return ""
+	}
+	if unauthenticated, ok := adapter.(adapters.UnauthenticatedAdapter); ok {
+		return unauthenticated.UnauthenticatedReason()
+	}
+	return ""
+}
+
+// PasswordInCommand reports whether a database type passes the default user's password on
+// its command line, so changing the password only lasts until the container is recreated
+func PasswordInCommand(dbType string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	const marker = "mkdb-password"
+	return slices.Contains(adapter.GetCommandArgs(marker), marker)
+}
+
+// UsersInMemory reports whether a database type loses the users created with 'mkdb user create'
+// when its server restarts, so mkdb has to create them again
+func UsersInMemory(dbType string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	inMemory, ok := adapter.(adapters.InMemoryUsersAdapter)
+	return ok && inMemory.UsersInMemory()
+}
+
+// ContainerCommand returns the command a container is started with for the password, persistence
+// mode, and extra server flags. Returns empty slice to keep the image's default command
+// With noConfig the config file isn't mounted, so commands that load it by path, like Redis's, leave it out
+func ContainerCommand(adapter adapters.DatabaseAdapter, password, persistence string, extraArgs []string, noConfig bool) ([]string, error) {
+	var cmd []string
+	if persistence == "" {
+		cmd = adapter.BuildCommand(password, extraArgs)
+	} else {
+		persistenceAdapter, ok := adapter.(adapters.PersistenceAdapter)
+		if !ok {
+			return nil, fmt.Errorf("persistence mode is not supported for %s", adapter.GetName())
+		}
+		cmd = append(persistenceAdapter.BuildCommandArgs(password, persistence), extraArgs...)
+	}
+
+	if noConfig {
+		configFile := path.Join(adapter.GetConfigPath(), adapter.GetConfigFileName())
+		cmd = slices.DeleteFunc(cmd, func(arg string) bool { return arg == configFile })
+	}
+	return cmd, nil
+}
+
+// ContainerEnv returns the adapter's environment variables, including the administrative
+// password for authenticated containers of adapters that have one
+func ContainerEnv(adapter adapters.DatabaseAdapter, dbName, username, password, adminPassword string) ([]string, error) {
+	env := adapter.GetEnvVars(dbName, username, password)
+
+	adminAdapter, ok := adapter.(adapters.AdminPasswordAdapter)
+	if !ok || password == "" {
+		return env, nil
+	}
+	if adminPassword == "" {
+		return nil, fmt.Errorf("an admin password is required for authenticated %s containers", adapter.GetName())
+	}
+	return append(env, adminAdapter.GetAdminEnvVars(adminPassword)...), nil
+}
+
+// MergeEnv appends extra to env, replacing variables in env that extra sets again
+func MergeEnv(env, extra []string) []string {
+	if len(extra) == 0 {
+		return env
+	}
+
+	overridden := make(map[string]bool)
+	for _, pair := range extra {
+		key, _, _ := strings.Cut(pair, "=")
+		overridden[key] = true
+	}
+
+	merged := make([]string, 0, len(env)+len(extra))
+	for _, pair := range env {
+		key, _, _ := strings.Cut(pair, "=")
+		if !overridden[key] {
+			merged = append(merged, pair)
+		}
+	}
+	return append(merged, extra...)
+}
+
+// ContainerPlan describes the container CreateContainer would create, with credentials masked
+type ContainerPlan struct {
+	Image         string
+	PortBinding   string
+	Env           []string
+	Mounts        []string
+	Command       []string
+	RestartPolicy string
+	Network       string
+	Labels        []string
+}
+
+// maskedSecret replaces credentials in a ContainerPlan
+const maskedSecret = "********"
+
+// PlanContainer returns what CreateContainer would do with opts, without pulling the image,
+// creating the config directory, or creating the container
+func PlanContainer(opts ContainerOptions) (ContainerPlan, error) {
+	adapter, err := adapters.GetRegistry().Get(opts.DBType)
+	if err != nil {
+		return ContainerPlan{}, fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	containerConfig, hostConfig, err := buildContainerConfig(adapter, opts)
+	if err != nil {
+		return ContainerPlan{}, err
+	}
+
+	plan := ContainerPlan{
+		Image:         containerConfig.Image,
+		PortBinding:   FormatPortBinding(ResolveBindIP(opts.BindIP), opts.Port, adapter.GetDefaultPort()),
+		Env:           maskSecrets(containerConfig.Env, opts.Password, opts.AdminPassword),
+		Command:       maskSecrets(containerConfig.Cmd, opts.Password, opts.AdminPassword),
+		RestartPolicy: string(hostConfig.RestartPolicy.Name),
+		Network:       opts.Network,
+		Labels:        opts.Labels,
+	}
+	for _, m := range hostConfig.Mounts {
+		volume := m.Source + ":" + m.Target
+		if m.ReadOnly {
+			volume += ":ro"
+		}
+		plan.Mounts = append(plan.Mounts, volume)
+	}
+	if !opts.NoConfig {
+		plan.Mounts = append(plan.Mounts, ConfigDir(opts.DisplayName)+":"+adapter.GetConfigPath())
+	}
+
+	return plan, nil
+}
+
+// FormatPortBinding describes a published port, e.g. "127.0.0.1:5433 -> 5432/tcp"
+func FormatPortBinding(bindIP, hostPort, containerPort string) string {
+	return fmt.Sprintf("%s -> %s/tcp", net.JoinHostPort(bindIP, hostPort), containerPort)
+}
+
+// maskSecrets returns a copy of values with every occurrence of the non-empty secrets masked
+func maskSecrets(values []string, secrets ...string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	// Mask longer secrets first, in case one contains another
+	secrets = slices.Clone(secrets)
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+
+	masked := make([]string, len(values))
+	for i, value := range values {
+		for _, secret := range secrets {
+			if secret != "" {
+				value = strings.ReplaceAll(value, secret, maskedSecret)
+			}
+		}
+		masked[i] = value
+	}
+	return masked
+}
+
+// isPortInUseError reports whether a container failed to start because its host port is already bound
+func isPortInUseError(err error) bool {
+	if errors.Is(err, ErrPortInUse) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "port is already allocated") || strings.Contains(msg, "address already in use")
+}
+
+// buildContainerConfig builds the container and host configuration for a database container
+// The config file mount is added by CreateContainer, since creating it writes to disk
+func buildContainerConfig(adapter adapters.DatabaseAdapter, opts ContainerOptions) (*container.Config, *container.HostConfig, error) {
+	defaultPort := adapter.GetDefaultPort()
+
 	// Prepare environment variables
-	env := adapter.GetEnvVars(displayName, username, password)
+	env, err := ContainerEnv(adapter, opts.DisplayName, opts.Username, opts.Password, opts.AdminPassword)
+	if err != nil {
+		return nil, nil, err
+	}
+	env = MergeEnv(env, opts.ExtraEnv)
 
 	// Prepare port bindings
 	exposedPorts := nat.PortSet{
-		nat.Port(dbConfig.DefaultPort + "/tcp"): struct{}{},
+		nat.Port(defaultPort + "/tcp"): struct{}{},
 	}
 	portBindings := nat.PortMap{
-		nat.Port(dbConfig.DefaultPort + "/tcp"): []nat.PortBinding{
+		nat.Port(defaultPort + "/tcp"): []nat.PortBinding{
 			{
-				HostIP:   "0.0.0.0",
-				HostPort: port,
+				HostIP:   ResolveBindIP(opts.BindIP),
+				HostPort: opts.Port,
 			},
 		},
 	}
 
 	// Prepare volume mounts
 	var mounts []mount.Mount
-	if volumeType != "" && volumePath != "" {
-		mounts = append(mounts, createMount(adapter, volumeType, volumePath))
+	if opts.VolumeType != "" && opts.VolumePath != "" {
+		dataMount, err := createMount(adapter, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		mounts = append(mounts, dataMount)
 	}
 
-	// Always add config mount for all databases
-	configMount, err := createConfigMount(adapter, displayName)
+	// Get custom command args if needed (e.g., for Redis password)
+	cmdArgs, err := ContainerCommand(adapter, opts.Password, opts.Persistence, opts.ExtraArgs, opts.NoConfig)
 	if err != nil {
-		return "", fmt.Errorf("failed to create config mount: %w", err)
+		return nil, nil, err
 	}
-	mounts = append(mounts, configMount)
 
-	// Get custom command args if needed (e.g., for Redis password)
-	cmdArgs := adapter.GetCommandArgs(password)
+	labels, err := containerLabels(opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Create container
 	containerConfig := &container.Config{
-		Image:        dbConfig.Image,
+		Image:        adapter.GetImage(opts.Version),
 		Env:          env,
 		ExposedPorts: exposedPorts,
-		Labels: map[string]string{
-			labelManaged: "true",
-			labelType:    dbType,
-			labelName:    displayName,
-		},
+		Labels:       labels,
 	}
 
 	// Set custom command if provided
@@ -193,44 +1095,178 @@ func CreateContainer(dbType, displayName, username, password, port, volumeType,
 		containerConfig.Cmd = cmdArgs
 	}
 
-	resp, err := cli.ContainerCreate(ctx, containerConfig, &container.HostConfig{
+	hostConfig := &container.HostConfig{
 		PortBindings: portBindings,
 		Mounts:       mounts,
 		RestartPolicy: container.RestartPolicy{
-			Name: "unless-stopped",
+			Name: container.RestartPolicyMode(ResolveRestartPolicy(opts.RestartPolicy)),
+		},
+		Resources: container.Resources{
+			CPUShares: opts.CPUShares,
 		},
-	}, nil, nil, containerName)
-	if err != nil {
-		return "", fmt.Errorf("failed to create container: %w", err)
 	}
+	if opts.Network != "" {
+		hostConfig.NetworkMode = container.NetworkMode(opts.Network)
+	}
+
+	return containerConfig, hostConfig, nil
+}
 
-	// Start container
-	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		return "", fmt.Errorf("failed to start container: %w", err)
+// containerLabels returns the labels from opts.Labels along with mkdb's own
+func containerLabels(opts ContainerOptions) (map[string]string, error) {
+	if err := ValidateLabels(opts.Labels); err != nil {
+		return nil, err
 	}
 
-	config.Logger.Info("Container created", "id", resp.ID[:12], "name", displayName)
-	return resp.ID, nil
+	labels := map[string]string{
+		labelManaged: "true",
+		labelType:    opts.DBType,
+		labelName:    opts.DisplayName,
+	}
+	for _, pair := range opts.Labels {
+		key, value, _ := ParseLabel(pair)
+		labels[key] = value
+	}
+	return labels, nil
 }
 
-// createMount creates a mount configuration
-func createMount(adapter adapters.DatabaseAdapter, volumeType, volumePath string) mount.Mount {
-	target := adapter.GetDataPath()
+// networkingConfig attaches the container to opts.Network, where other containers can reach
+// it by its display name as well as its container name. Returns nil for the default network
+func networkingConfig(opts ContainerOptions) *network.NetworkingConfig {
+	if opts.Network == "" {
+		return nil
+	}
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			opts.Network: {Aliases: []string{opts.DisplayName}},
+		},
+	}
+}
 
-	if volumeType == "bind" {
+// createMount creates the mount for a container's volume
+// Named volumes are directories under config.VolumesDir rather than Docker volumes, so they're
+// bind mounts like custom paths. Docker volumes are mounted by name
+func createMount(adapter adapters.DatabaseAdapter, opts ContainerOptions) (mount.Mount, error) {
+	target := opts.DataTarget
+	if target == "" {
+		target = adapter.GetDataPath()
+	}
+
+	if opts.VolumeType == "docker" {
 		return mount.Mount{
-			Type:   mount.TypeBind,
-			Source: volumePath,
-			Target: target,
-		}
+			Type:     mount.TypeVolume,
+			Source:   opts.VolumePath,
+			Target:   containerMountTarget(target),
+			ReadOnly: opts.VolumeReadOnly,
+		}, nil
+	}
+
+	source, err := hostMountSource(HostVolumePath(opts.VolumeType, opts.VolumePath))
+	if err != nil {
+		return mount.Mount{}, err
 	}
 
-	// Named volume (stored in XDG_DATA_HOME/mkdb/volumes)
 	return mount.Mount{
-		Type:   mount.TypeBind,
-		Source: filepath.Join(config.VolumesDir, volumePath),
-		Target: target,
+		Type:     mount.TypeBind,
+		Source:   source,
+		Target:   containerMountTarget(target),
+		ReadOnly: opts.VolumeReadOnly,
+	}, nil
+}
+
+// windowsVolumePattern matches paths that start with a drive letter, e.g. C:\data or C:/data
+var windowsVolumePattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+
+// hostMountSource returns the absolute form of a host path for a bind mount, since Docker
+// rejects relative sources. Paths with a drive letter are already absolute on a Windows host
+// and are passed through as-is, so they aren't joined to the working directory
+func hostMountSource(p string) (string, error) {
+	if windowsVolumePattern.MatchString(p) {
+		return p, nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve volume path %s: %w", p, err)
+	}
+	return abs, nil
+}
+
+// containerMountTarget cleans a path inside the container, which is always a Linux path
+// Targets are slash-separated literals and must never go through filepath, which would use
+// backslashes when mkdb runs on Windows
+func containerMountTarget(target string) string {
+	return path.Clean(target)
+}
+
+// checkMountSources returns an error if the host directory of a bind mount doesn't exist
+// Docker would otherwise fail to create the container with a less helpful message
+func checkMountSources(mounts []mount.Mount) error {
+	for _, m := range mounts {
+		if m.Type != mount.TypeBind {
+			continue
+		}
+		if _, err := os.Stat(m.Source); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("volume path %s does not exist", m.Source)
+			}
+			return fmt.Errorf("failed to check volume path %s: %w", m.Source, err)
+		}
+	}
+	return nil
+}
+
+// ValidateDataTarget checks that a custom in-container mount target is usable for a database type
+func ValidateDataTarget(dbType, target string) error {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return err
+	}
+
+	if !path.IsAbs(target) {
+		return fmt.Errorf("data target must be an absolute path inside the container, got %q", target)
 	}
+	cleaned := path.Clean(target)
+	if cleaned == "/" {
+		return fmt.Errorf("data target cannot be the container's root directory")
+	}
+	if cleaned == path.Clean(adapter.GetConfigPath()) {
+		return fmt.Errorf("data target %s is already used for the %s config file", cleaned, dbType)
+	}
+	return nil
+}
+
+// DataTargetIsWritable reports whether a database writes to the given mount target,
+// which makes it unsafe to mount read-only. An empty target means the adapter's data path
+func DataTargetIsWritable(dbType, target string) bool {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return false
+	}
+	return target == "" || path.Clean(target) == path.Clean(adapter.GetDataPath())
+}
+
+// HostVolumePath returns the directory on the host that backs a container's volume
+// Named volumes are stored in XDG_DATA_HOME/mkdb/volumes, bind mounts use the path as-is
+// Docker volumes have no such directory, callers check for them first
+func HostVolumePath(volumeType, volumePath string) string {
+	if volumeType == "bind" {
+		return volumePath
+	}
+	return filepath.Join(config.VolumesDir, volumePath)
+}
+
+// DataDirVersion returns the database version that wrote a container's volume,
+// or adapters.UnknownVersion if there is no volume, the volume is managed by Docker, or the version isn't recorded
+func DataDirVersion(dbType, volumeType, volumePath string) (string, error) {
+	if volumeType == "" || volumeType == "none" || volumeType == "docker" || volumePath == "" {
+		return adapters.UnknownVersion, nil
+	}
+
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return "", err
+	}
+	return adapter.DataDirVersion(HostVolumePath(volumeType, volumePath))
 }
 
 // GetConfigFileName returns the main config file name for the database type
@@ -243,10 +1279,86 @@ func GetConfigFileName(dbType string) string {
 	return adapter.GetConfigFileName()
 }
 
+// ValidateConfigFile checks a config file for syntax errors using the database type's adapter
+func ValidateConfigFile(dbType, configFile string) error {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return adapter.ValidateConfig(string(content))
+}
+
+// ValidatePersistence checks that a persistence mode is supported by the database type
+func ValidatePersistence(dbType, persistence string) error {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	persistenceAdapter, ok := adapter.(adapters.PersistenceAdapter)
+	if !ok {
+		return fmt.Errorf("--persistence is not supported for %s", dbType)
+	}
+
+	modes := persistenceAdapter.GetPersistenceModes()
+	if !slices.Contains(modes, persistence) {
+		return fmt.Errorf("invalid persistence mode %q (valid: %s)", persistence, strings.Join(modes, ", "))
+	}
+
+	return nil
+}
+
+// ValidateMaxConnections checks that the database type's connection limit can be set to n in its config file
+func ValidateMaxConnections(dbType string, n int, noConfig bool) error {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	// Adapters that support it add the setting even to an empty file
+	if adapter.SetMaxConnections("", 1) == "" {
+		return fmt.Errorf("--max-connections is not supported for %s", dbType)
+	}
+	if noConfig {
+		return fmt.Errorf("--max-connections is written to the config file and can't be used with --no-config")
+	}
+	if n < 1 {
+		return fmt.Errorf("invalid max connections %d (must be at least 1)", n)
+	}
+	return nil
+}
+
+// MaxRedisDB is the highest database number with Redis' default of 16 databases
+const MaxRedisDB = 15
+
+// ValidateRedisDB checks that db is a Redis database number the server has by default
+func ValidateRedisDB(dbType string, db int) error {
+	if dbType != "redis" {
+		return fmt.Errorf("--redis-db is not supported for %s", dbType)
+	}
+	if db < 0 || db > MaxRedisDB {
+		return fmt.Errorf("invalid Redis database %d (must be between 0 and %d)", db, MaxRedisDB)
+	}
+	return nil
+}
+
+// ConfigDir returns the host directory holding a container's config file, XDG_DATA_HOME/mkdb/configs/<dbname>
+func ConfigDir(displayName string) string {
+	return filepath.Join(config.DataDir, "configs", displayName)
+}
+
 // createConfigMount creates a mount for config files in XDG_DATA_HOME
-func createConfigMount(adapter adapters.DatabaseAdapter, displayName string) (mount.Mount, error) {
-	// Create config directory in XDG_DATA_HOME/mkdb/configs/<dbname>
-	configDir := filepath.Join(config.DataDir, "configs", displayName)
+// A maxConnections above 0 is written to the config file
+func createConfigMount(adapter adapters.DatabaseAdapter, displayName string, maxConnections int) (mount.Mount, error) {
+	configDir := ConfigDir(displayName)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return mount.Mount{}, fmt.Errorf("failed to create config directory: %w", err)
 	}
@@ -258,11 +1370,21 @@ func createConfigMount(adapter adapters.DatabaseAdapter, displayName string) (mo
 			return mount.Mount{}, fmt.Errorf("failed to create default config: %w", err)
 		}
 	}
+	if maxConnections > 0 {
+		if err := setMaxConnections(adapter, configFile, maxConnections); err != nil {
+			return mount.Mount{}, fmt.Errorf("failed to set max connections: %w", err)
+		}
+	}
+
+	source, err := hostMountSource(configDir)
+	if err != nil {
+		return mount.Mount{}, err
+	}
 
 	return mount.Mount{
 		Type:   mount.TypeBind,
-		Source: configDir,
-		Target: adapter.GetConfigPath(),
+		Source: source,
+		Target: containerMountTarget(adapter.GetConfigPath()),
 	}, nil
 }
 
@@ -272,12 +1394,21 @@ func createDefaultConfig(adapter adapters.DatabaseAdapter, configFile string) er
 	return os.WriteFile(configFile, []byte(content), 0644)
 }
 
+// setMaxConnections writes the connection limit n to a config file
+func setMaxConnections(adapter adapters.DatabaseAdapter, configFile string, n int) error {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, []byte(adapter.SetMaxConnections(string(content), n)), 0644)
+}
+
 // StopContainer stops a container gracefully
-func StopContainer(containerID string) error {
+// A timeout of 0 waits indefinitely for the database to shut down
+func StopContainer(containerID string, timeout time.Duration) error {
 	ctx := context.Background()
 
-	timeout := 10
-	if err := cli.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+	if err := cli.ContainerStop(ctx, containerID, stopOptions(timeout)); err != nil {
 		return fmt.Errorf("failed to stop container: %w", err)
 	}
 
@@ -297,12 +1428,33 @@ func RemoveContainer(containerID string) error {
 	return nil
 }
 
+// RecordCreated hands a container that was just created to record, e.g. to save it in mkdb's database
+// If ctx was cancelled in the meantime, such as by Ctrl-C, or record fails, the container is removed
+// rather than left running without a record
+func RecordCreated(ctx context.Context, containerID string, record func() error) error {
+	return recordCreated(ctx, containerID, record, RemoveContainer)
+}
+
+func recordCreated(ctx context.Context, containerID string, record func() error, remove func(string) error) error {
+	err := ctx.Err()
+	if err == nil {
+		if err = record(); err == nil {
+			return nil
+		}
+	}
+
+	if rmErr := remove(containerID); rmErr != nil {
+		return fmt.Errorf("%w (and the container couldn't be removed: %v)", err, rmErr)
+	}
+	return err
+}
+
 // RestartContainer restarts a container
-func RestartContainer(containerID string) error {
+// A timeout of 0 waits indefinitely for the database to shut down
+func RestartContainer(containerID string, timeout time.Duration) error {
 	ctx := context.Background()
 
-	timeout := 10
-	if err := cli.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+	if err := cli.ContainerRestart(ctx, containerID, stopOptions(timeout)); err != nil {
 		return fmt.Errorf("failed to restart container: %w", err)
 	}
 
@@ -310,6 +1462,17 @@ func RestartContainer(containerID string) error {
 	return nil
 }
 
+// stopOptions converts a stop timeout to Docker's representation, where -1 means no timeout
+func stopOptions(timeout time.Duration) container.StopOptions {
+	seconds := int(timeout.Round(time.Second) / time.Second)
+	if timeout <= 0 {
+		seconds = -1
+	} else if seconds == 0 {
+		seconds = 1 // Don't round a short timeout down to "wait forever"
+	}
+	return container.StopOptions{Timeout: &seconds}
+}
+
 // StartContainer starts an existing container
 func StartContainer(containerID string) error {
 	ctx := context.Background()
@@ -318,20 +1481,68 @@ func StartContainer(containerID string) error {
 		return fmt.Errorf("failed to start container: %w", err)
 	}
 
-	config.Logger.Info("Container started", "id", containerID[:12])
-	return nil
+	config.Logger.Info("Container started", "id", containerID[:12])
+	return nil
+}
+
+// GetContainerStatus returns the status of a container
+func GetContainerStatus(containerID string) (string, error) {
+	ctx := context.Background()
+
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+
+	return info.State.Status, nil
+}
+
+// GetContainerUptime returns how long a running container has been up since it was last started,
+// which is shorter than its age if it was stopped and restarted
+func GetContainerUptime(containerID string) (time.Duration, error) {
+	info, err := cli.ContainerInspect(context.Background(), containerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if !info.State.Running {
+		return 0, fmt.Errorf("container is %s", info.State.Status)
+	}
+
+	started, err := parseStateTime(info.State.StartedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read start time: %w", err)
+	}
+	return time.Since(started), nil
 }
 
-// GetContainerStatus returns the status of a container
-func GetContainerStatus(containerID string) (string, error) {
-	ctx := context.Background()
+// GetContainerStoppedAt returns when a container that isn't running last stopped
+func GetContainerStoppedAt(containerID string) (time.Time, error) {
+	info, err := cli.ContainerInspect(context.Background(), containerID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.State.Running {
+		return time.Time{}, fmt.Errorf("container is running")
+	}
 
-	info, err := cli.ContainerInspect(ctx, containerID)
+	finished, err := parseStateTime(info.State.FinishedAt)
 	if err != nil {
-		return "", err
+		return time.Time{}, fmt.Errorf("failed to read stop time: %w", err)
 	}
+	return finished, nil
+}
 
-	return info.State.Status, nil
+// parseStateTime parses a timestamp from a container's state
+// Docker reports the zero time for events that haven't happened, such as a container that never stopped
+func parseStateTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.IsZero() {
+		return time.Time{}, fmt.Errorf("not recorded")
+	}
+	return t, nil
 }
 
 // ContainerExists checks if a container exists
@@ -342,14 +1553,17 @@ func ContainerExists(containerID string) bool {
 	return err == nil
 }
 
-// RemoveVolume removes a volume
+// RemoveVolume removes the Docker volume with this name if mkdb created it
+// Host directories of named volumes and bind mounts are left in place, and so are Docker
+// volumes created outside mkdb, such as an existing volume passed to 'mkdb start'
 func RemoveVolume(volumePath string) error {
 	ctx := context.Background()
 
-	// For bind mounts, we don't remove through Docker
-	// For named volumes, remove the directory
-	filter := filters.NewArgs()
-	filter.Add("name", volumePath)
+	// The name filter matches substrings, so check for the exact name below
+	filter := filters.NewArgs(
+		filters.Arg("name", volumePath),
+		filters.Arg("label", labelManaged+"=true"),
+	)
 
 	volumes, err := cli.VolumeList(ctx, volume.ListOptions{Filters: filter})
 	if err != nil {
@@ -357,6 +1571,9 @@ func RemoveVolume(volumePath string) error {
 	}
 
 	for _, vol := range volumes.Volumes {
+		if vol.Name != volumePath {
+			continue
+		}
 		if err := cli.VolumeRemove(ctx, vol.Name, true); err != nil {
 			return err
 		}
@@ -367,7 +1584,8 @@ func RemoveVolume(volumePath string) error {
 
 // ExecInContainer executes a command in a running container
 func ExecInContainer(containerID string, cmd []string) error {
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), ExecTimeout)
+	defer cancel()
 
 	execConfig := container.ExecOptions{
 		Cmd:          cmd,
@@ -375,42 +1593,38 @@ func ExecInContainer(containerID string, cmd []string) error {
 		AttachStderr: true,
 	}
 
-	execID, err := cli.ContainerExecCreate(ctx, containerID, execConfig)
+	execID, err := createExec(ctx, containerID, execConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create exec: %w", err)
+		return err
 	}
 
-	if err := cli.ContainerExecStart(ctx, execID.ID, container.ExecStartOptions{}); err != nil {
+	if err := cli.ContainerExecStart(ctx, execID, container.ExecStartOptions{}); err != nil {
 		return fmt.Errorf("failed to start exec: %w", err)
 	}
 
 	// Wait for the exec to complete
-	for {
-		inspect, err := cli.ContainerExecInspect(ctx, execID.ID)
-		if err != nil {
-			return err
-		}
-		if !inspect.Running {
-			if inspect.ExitCode != 0 {
-				return fmt.Errorf("command exited with code %d", inspect.ExitCode)
-			}
-			break
-		}
-		time.Sleep(100 * time.Millisecond)
+	inspect, err := waitForExec(ctx, func(ctx context.Context) (container.ExecInspect, error) {
+		return cli.ContainerExecInspect(ctx, execID)
+	}, ExecPollInterval)
+	if err != nil {
+		return err
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("command exited with code %d", inspect.ExitCode)
 	}
 
 	return nil
 }
 
 // CreateUser creates a new user in the database
-func CreateUser(containerID, dbType, username, password, dbName string) error {
+func CreateUser(containerID, dbType, username, password, dbName, adminPassword string) error {
 	registry := adapters.GetRegistry()
 	adapter, err := registry.Get(dbType)
 	if err != nil {
 		return fmt.Errorf("failed to get adapter: %w", err)
 	}
 
-	cmd := adapter.CreateUserCommand(username, password, dbName)
+	cmd := adapter.CreateUserCommand(username, password, dbName, adminPassword)
 	if cmd == nil {
 		return fmt.Errorf("user creation not supported for %s", dbType)
 	}
@@ -419,14 +1633,14 @@ func CreateUser(containerID, dbType, username, password, dbName string) error {
 }
 
 // DeleteUser deletes a user from the database
-func DeleteUser(containerID, dbType, username, dbName string) error {
+func DeleteUser(containerID, dbType, username, dbName, adminPassword string) error {
 	registry := adapters.GetRegistry()
 	adapter, err := registry.Get(dbType)
 	if err != nil {
 		return fmt.Errorf("failed to get adapter: %w", err)
 	}
 
-	cmd := adapter.DeleteUserCommand(username, dbName)
+	cmd := adapter.DeleteUserCommand(username, dbName, adminPassword)
 	if cmd == nil {
 		return fmt.Errorf("user deletion not supported for %s", dbType)
 	}
@@ -434,15 +1648,198 @@ func DeleteUser(containerID, dbType, username, dbName string) error {
 	return ExecInContainer(containerID, cmd)
 }
 
+// CreateDatabase creates a logical database inside the container
+func CreateDatabase(containerID, dbType, dbName, adminPassword string) error {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	cmd := adapter.CreateDatabaseCommand(dbName, adminPassword)
+	if cmd == nil {
+		return fmt.Errorf("multiple databases not supported for %s", dbType)
+	}
+
+	_, err = ExecCommand(containerID, cmd)
+	return err
+}
+
+// ValidateSeedFiles checks that dbType supports seeding and that every path is a readable file
+func ValidateSeedFiles(dbType string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+	if _, ok := adapter.(adapters.SeedAdapter); !ok {
+		return fmt.Errorf("seeding is not supported for %s", dbType)
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("invalid seed file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("invalid seed file: %s is a directory", path)
+		}
+	}
+	return nil
+}
+
+// DefaultReadyTimeout is how long WaitForReady waits for a new database to accept queries
+const DefaultReadyTimeout = 2 * time.Minute
+
+// readyInterval is how often WaitForReady checks the database
+const readyInterval = time.Second
+
+// ErrNoReadyCheck is returned by WaitForReady for database types without a readiness command
+var ErrNoReadyCheck = errors.New("no readiness check for this database type")
+
+// WaitForReady runs the adapter's readiness command inside the container until it succeeds or timeout passes
+// Pass empty strings for username and password for unauthenticated databases
+func WaitForReady(containerID, dbType, username, password, dbName string, timeout time.Duration) error {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	cmd := adapter.GetReadinessCommand(username, password, dbName)
+	if cmd == nil {
+		return ErrNoReadyCheck
+	}
+	return waitForReady(func(ctx context.Context) error {
+		_, err := execCommand(ctx, containerID, cmd, nil)
+		return err
+	}, timeout, readyInterval)
+}
+
+// WaitForPort waits until the container is running and host:port accepts TCP connections, or timeout passes
+// It's a weaker check than WaitForReady, since the port may open before the database accepts queries
+func WaitForPort(containerID, host, port string, timeout time.Duration) error {
+	address := net.JoinHostPort(host, port)
+	return waitForReady(func(ctx context.Context) error {
+		status, err := GetContainerStatus(containerID)
+		if err != nil {
+			return err
+		}
+		if status != "running" {
+			return fmt.Errorf("container is %s", status)
+		}
+
+		conn, err := net.DialTimeout("tcp", address, readyInterval)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}, timeout, readyInterval)
+}
+
+// waitForReady calls ping every interval until it succeeds twice in a row or timeout passes
+// The official images run a temporary server while initializing that can answer a single ping
+// just before it's shut down and replaced. Each ping's context ends at the deadline, so a hung
+// ping can't hold the wait past timeout
+func waitForReady(ping func(ctx context.Context) error, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	successes := 0
+	for {
+		err := ping(ctx)
+		if err == nil {
+			successes++
+			if successes == 2 {
+				return nil
+			}
+		} else {
+			successes = 0
+		}
+
+		if time.Now().After(deadline) {
+			if err == nil {
+				return nil
+			}
+			return fmt.Errorf("database not ready after %s: %w", timeout, err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// SeedDatabase runs the statements read from r against dbName inside the container
+func SeedDatabase(containerID, dbType, dbName, adminPassword string, r io.Reader) error {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	seedAdapter, ok := adapter.(adapters.SeedAdapter)
+	if !ok {
+		return fmt.Errorf("seeding is not supported for %s", dbType)
+	}
+
+	_, err = ExecCommandWithStdin(containerID, seedAdapter.SeedCommand(dbName, adminPassword), r)
+	return err
+}
+
+// ListDatabases lists the user databases inside the container
+func ListDatabases(containerID, dbType, adminPassword string) ([]string, error) {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	cmd := adapter.ListDatabasesCommand(adminPassword)
+	if cmd == nil {
+		return nil, fmt.Errorf("multiple databases not supported for %s", dbType)
+	}
+
+	output, err := ExecCommand(containerID, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// DropDatabase drops a logical database from the container
+func DropDatabase(containerID, dbType, dbName, adminPassword string) error {
+	registry := adapters.GetRegistry()
+	adapter, err := registry.Get(dbType)
+	if err != nil {
+		return fmt.Errorf("failed to get adapter: %w", err)
+	}
+
+	cmd := adapter.DropDatabaseCommand(dbName, adminPassword)
+	if cmd == nil {
+		return fmt.Errorf("multiple databases not supported for %s", dbType)
+	}
+
+	_, err = ExecCommand(containerID, cmd)
+	return err
+}
+
 // RotatePassword rotates a user's password
-func RotatePassword(containerID, dbType, username, newPassword, dbName string) error {
+func RotatePassword(containerID, dbType, username, newPassword, dbName, adminPassword string) error {
 	registry := adapters.GetRegistry()
 	adapter, err := registry.Get(dbType)
 	if err != nil {
 		return fmt.Errorf("failed to get adapter: %w", err)
 	}
 
-	cmd := adapter.RotatePasswordCommand(username, newPassword, dbName)
+	cmd := adapter.RotatePasswordCommand(username, newPassword, dbName, adminPassword)
 	if cmd == nil {
 		return fmt.Errorf("password rotation not supported for %s", dbType)
 	}
@@ -452,47 +1849,95 @@ func RotatePassword(containerID, dbType, username, newPassword, dbName string) e
 
 // ExecCommand executes a command in a container and returns the output
 func ExecCommand(containerName string, cmd []string) (string, error) {
-	ctx := context.Background()
+	return ExecCommandWithStdin(containerName, cmd, nil)
+}
+
+// ExecCommandWithStdin executes a command in a container with stdin read from r and returns the output
+// The command's stdin is closed once r is exhausted. A nil r runs the command without stdin
+func ExecCommandWithStdin(containerName string, cmd []string, r io.Reader) (string, error) {
+	return execCommand(context.Background(), containerName, cmd, r)
+}
+
+// execCommand runs an exec bounded by ExecTimeout or parent's deadline, whichever comes first
+// When parent's deadline is what ends the exec, parent's error is returned instead of the ExecTimeout hint
+func execCommand(parent context.Context, containerName string, cmd []string, r io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(parent, ExecTimeout)
+	defer cancel()
 
+	output, err := runExec(ctx, containerName, cmd, r)
+	if err != nil && parent.Err() != nil {
+		return output, parent.Err()
+	}
+	return output, err
+}
+
+// runExec executes cmd in the container until it finishes or ctx is done
+func runExec(ctx context.Context, containerName string, cmd []string, r io.Reader) (string, error) {
 	execConfig := container.ExecOptions{
 		Cmd:          cmd,
+		AttachStdin:  r != nil,
 		AttachStdout: true,
 		AttachStderr: true,
 	}
 
-	execID, err := cli.ContainerExecCreate(ctx, containerName, execConfig)
+	execID, err := createExec(ctx, containerName, execConfig)
 	if err != nil {
-		return "", fmt.Errorf("failed to create exec: %w", err)
+		return "", err
 	}
 
-	resp, err := cli.ContainerExecAttach(ctx, execID.ID, container.ExecAttachOptions{})
+	resp, err := cli.ContainerExecAttach(ctx, execID, container.ExecAttachOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to attach to exec: %w", err)
 	}
 	defer resp.Close()
 
-	// Read the output
-	output, err := io.ReadAll(resp.Reader)
-	if err != nil {
+	// The attached connection doesn't follow ctx, so give it the same deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		resp.Conn.SetDeadline(deadline)
+	}
+
+	// Write stdin while the output is read, so a command that fills its output buffer doesn't block
+	stdinErr := make(chan error, 1)
+	if r != nil {
+		go func() {
+			_, err := io.Copy(resp.Conn, r)
+			if closeErr := resp.CloseWrite(); err == nil {
+				err = closeErr
+			}
+			stdinErr <- err
+		}()
+	} else {
+		stdinErr <- nil
+	}
+
+	// Read the output, stripping Docker's stream multiplexing headers
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return stdout.String(), execTimeoutError()
+		}
 		return "", fmt.Errorf("failed to read output: %w", err)
 	}
+	output := stdout.String()
+	if err := <-stdinErr; err != nil {
+		return output, fmt.Errorf("failed to write input: %w", err)
+	}
 
 	// Wait for completion and check exit code
-	for {
-		inspect, err := cli.ContainerExecInspect(ctx, execID.ID)
-		if err != nil {
-			return string(output), err
-		}
-		if !inspect.Running {
-			if inspect.ExitCode != 0 {
-				return string(output), fmt.Errorf("command exited with code %d", inspect.ExitCode)
-			}
-			break
+	inspect, err := waitForExec(ctx, func(ctx context.Context) (container.ExecInspect, error) {
+		return cli.ContainerExecInspect(ctx, execID)
+	}, ExecPollInterval)
+	if err != nil {
+		return output, err
+	}
+	if inspect.ExitCode != 0 {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("command exited with code %d: %s", inspect.ExitCode, msg)
 		}
-		time.Sleep(100 * time.Millisecond)
+		return output, fmt.Errorf("command exited with code %d", inspect.ExitCode)
 	}
 
-	return string(output), nil
+	return output, nil
 }
 
 func mustAtoi(s string) int {
@@ -503,6 +1948,51 @@ func mustAtoi(s string) int {
 	return i
 }
 
+// VersionChange describes how moving between two image versions affects existing data
+type VersionChange struct {
+	Comparable  bool // False if either version isn't numeric, such as "latest"
+	Downgrade   bool
+	MajorChange bool
+}
+
+// CompareVersions compares image version tags like "15", "8.0", "16.2-alpine", or "v24.3.0"
+func CompareVersions(from, to string) VersionChange {
+	fromParts, ok := parseVersion(from)
+	if !ok {
+		return VersionChange{}
+	}
+	toParts, ok := parseVersion(to)
+	if !ok {
+		return VersionChange{}
+	}
+
+	change := VersionChange{
+		Comparable:  true,
+		MajorChange: fromParts[0] != toParts[0],
+	}
+	for i := 0; i < len(fromParts) && i < len(toParts); i++ {
+		if fromParts[i] != toParts[i] {
+			change.Downgrade = toParts[i] < fromParts[i]
+			break
+		}
+	}
+	return change
+}
+
+// parseVersion extracts the numeric components of a version tag, ignoring a "v" prefix and suffixes like "-alpine"
+func parseVersion(v string) ([]int, bool) {
+	v, _, _ = strings.Cut(strings.TrimPrefix(strings.TrimSpace(v), "v"), "-")
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
 // GetActualVersion retrieves the actual database version from a running container
 func GetActualVersion(containerID, dbType string) (string, error) {
 	registry := adapters.GetRegistry()
@@ -527,3 +2017,232 @@ func GetActualVersion(containerID, dbType string) (string, error) {
 	version := adapter.ParseVersion(output)
 	return version, nil
 }
+
+// ContainerStats is a point-in-time resource usage sample for a container
+type ContainerStats struct {
+	CPUPercent    float64 `json:"cpu_percent" yaml:"cpu_percent"`
+	MemoryUsage   uint64  `json:"memory_usage" yaml:"memory_usage"`
+	MemoryLimit   uint64  `json:"memory_limit" yaml:"memory_limit"`
+	MemoryPercent float64 `json:"memory_percent" yaml:"memory_percent"`
+	NetworkRx     uint64  `json:"network_rx" yaml:"network_rx"`
+	NetworkTx     uint64  `json:"network_tx" yaml:"network_tx"`
+	BlockRead     uint64  `json:"block_read" yaml:"block_read"`
+	BlockWrite    uint64  `json:"block_write" yaml:"block_write"`
+	PIDs          uint64  `json:"pids" yaml:"pids"`
+}
+
+// GetContainerStats samples resource usage for a running container
+// This blocks for about a second while Docker collects a second CPU sample
+func GetContainerStats(containerID string) (*ContainerStats, error) {
+	ctx := context.Background()
+
+	resp, err := cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	stats := &ContainerStats{
+		CPUPercent:  calculateCPUPercent(raw.CPUStats, raw.PreCPUStats),
+		MemoryUsage: memoryUsage(raw.MemoryStats),
+		MemoryLimit: raw.MemoryStats.Limit,
+		PIDs:        raw.PidsStats.Current,
+	}
+	if stats.MemoryLimit > 0 {
+		stats.MemoryPercent = float64(stats.MemoryUsage) / float64(stats.MemoryLimit) * 100
+	}
+
+	for _, n := range raw.Networks {
+		stats.NetworkRx += n.RxBytes
+		stats.NetworkTx += n.TxBytes
+	}
+
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+
+	return stats, nil
+}
+
+// ContainerResources are the resource limits a container was created with, zero means unlimited
+type ContainerResources struct {
+	CPUShares int64 `json:"cpu_shares" yaml:"cpu_shares"`
+	NanoCPUs  int64 `json:"nano_cpus" yaml:"nano_cpus"`
+	Memory    int64 `json:"memory" yaml:"memory"`
+}
+
+// GetContainerResources returns a container's configured resource limits
+func GetContainerResources(containerID string) (*ContainerResources, error) {
+	ctx := context.Background()
+
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.HostConfig == nil {
+		return &ContainerResources{}, nil
+	}
+
+	return &ContainerResources{
+		CPUShares: info.HostConfig.CPUShares,
+		NanoCPUs:  info.HostConfig.NanoCPUs,
+		Memory:    info.HostConfig.Memory,
+	}, nil
+}
+
+// calculateCPUPercent computes CPU usage the same way 'docker stats' does
+func calculateCPUPercent(current, previous container.CPUStats) float64 {
+	cpuDelta := float64(current.CPUUsage.TotalUsage) - float64(previous.CPUUsage.TotalUsage)
+	systemDelta := float64(current.SystemUsage) - float64(previous.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	onlineCPUs := float64(current.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(current.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// memoryUsage excludes the page cache from usage, matching 'docker stats'
+func memoryUsage(stats container.MemoryStats) uint64 {
+	// cgroup v2 reports inactive_file, cgroup v1 reports total_inactive_file
+	cache, ok := stats.Stats["inactive_file"]
+	if !ok {
+		cache = stats.Stats["total_inactive_file"]
+	}
+	if cache > stats.Usage {
+		return stats.Usage
+	}
+	return stats.Usage - cache
+}
+
+// LogOptions controls which container log lines are returned
+type LogOptions struct {
+	Follow     bool
+	Tail       string    // Number of lines from the end of the logs, or "all"
+	Timestamps bool      // Prefix each line with its RFC3339 timestamp
+	Since      time.Time // Only lines logged at or after this time, if set
+	Grep       *regexp.Regexp
+}
+
+// CompileGrep compiles a log filter pattern, matching regardless of case if ignoreCase is set
+func CompileGrep(pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// StreamLogs writes a container's logs to w until they end or ctx is cancelled
+func StreamLogs(ctx context.Context, containerID string, opts LogOptions, w io.Writer) error {
+	reader, err := cli.ContainerLogs(ctx, containerID, buildLogsOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to get container logs: %w", err)
+	}
+	defer reader.Close()
+
+	if err := copyLogs(w, reader, opts); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+func buildLogsOptions(opts LogOptions) container.LogsOptions {
+	return container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+		Since:      logsSince(opts.Since),
+	}
+}
+
+// logsSince formats a time as the Unix timestamp Docker expects, or "" for the whole log
+func logsSince(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// copyLogs demultiplexes a Docker log stream into w, applying the grep filter if set
+func copyLogs(w io.Writer, r io.Reader, opts LogOptions) error {
+	if opts.Grep == nil {
+		if _, err := stdcopy.StdCopy(w, w, r); err != nil {
+			return fmt.Errorf("failed to read container logs: %w", err)
+		}
+		return nil
+	}
+
+	// Each stream gets its own filter, so a partial line on one isn't joined with a line from the other
+	stdout := &lineFilter{w: w, pattern: opts.Grep, timestamps: opts.Timestamps}
+	stderr := &lineFilter{w: w, pattern: opts.Grep, timestamps: opts.Timestamps}
+	if _, err := stdcopy.StdCopy(stdout, stderr, r); err != nil {
+		return fmt.Errorf("failed to read container logs: %w", err)
+	}
+	if err := stdout.Flush(); err != nil {
+		return err
+	}
+	return stderr.Flush()
+}
+
+// lineFilter writes only the lines that match pattern
+// Timestamps are skipped when matching, so patterns anchored with ^ match the log message
+type lineFilter struct {
+	w          io.Writer
+	pattern    *regexp.Regexp
+	timestamps bool
+	buf        []byte
+}
+
+func (f *lineFilter) Write(p []byte) (int, error) {
+	f.buf = append(f.buf, p...)
+	for {
+		i := bytes.IndexByte(f.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := f.writeLine(f.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		f.buf = f.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line that didn't end in a newline
+func (f *lineFilter) Flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	line := f.buf
+	f.buf = nil
+	return f.writeLine(line)
+}
+
+func (f *lineFilter) writeLine(line []byte) error {
+	message := bytes.TrimRight(line, "\r\n")
+	if f.timestamps {
+		if _, rest, ok := bytes.Cut(message, []byte(" ")); ok {
+			message = rest
+		}
+	}
+	if !f.pattern.Match(message) {
+		return nil
+	}
+	_, err := f.w.Write(line)
+	return err
+}
@@ -0,0 +1,1232 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
+)
+
+func TestBuildContainerConfig_CPUShares(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cpuShares int64
+	}{
+		{"Docker default", 0},
+		{"Low priority", 256},
+		{"High priority", 4096},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContainerOptions{
+				DBType:      "postgres",
+				DisplayName: "mydb",
+				Username:    "dbuser",
+				Password:    "secret",
+				Port:        "5432",
+				Version:     "16",
+				CPUShares:   tt.cpuShares,
+			}
+
+			containerConfig, hostConfig, err := buildContainerConfig(adapter, opts)
+			if err != nil {
+				t.Fatalf("buildContainerConfig() error = %v", err)
+			}
+
+			if hostConfig.Resources.CPUShares != tt.cpuShares {
+				t.Errorf("HostConfig.Resources.CPUShares = %d, want %d", hostConfig.Resources.CPUShares, tt.cpuShares)
+			}
+			if containerConfig.Image != "postgres:16" {
+				t.Errorf("Config.Image = %v, want postgres:16", containerConfig.Image)
+			}
+			if containerConfig.Labels[labelName] != "mydb" {
+				t.Errorf("Config.Labels[%s] = %v, want mydb", labelName, containerConfig.Labels[labelName])
+			}
+		})
+	}
+}
+
+func TestValidateCPUShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		shares  int64
+		wantErr bool
+	}{
+		{"Minimum", MinCPUShares, false},
+		{"Docker default", 1024, false},
+		{"Maximum", MaxCPUShares, false},
+		{"Zero", 0, true},
+		{"Below minimum", 1, true},
+		{"Negative", -512, true},
+		{"Above maximum", MaxCPUShares + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCPUShares(tt.shares)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCPUShares(%d) error = %v, wantErr %v", tt.shares, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRedisDB(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		db      int
+		wantErr bool
+	}{
+		{"Default", "redis", 0, false},
+		{"Maximum", "redis", MaxRedisDB, false},
+		{"Negative", "redis", -1, true},
+		{"Above maximum", "redis", MaxRedisDB + 1, true},
+		{"Not redis", "postgres", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRedisDB(tt.dbType, tt.db)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRedisDB(%q, %d) error = %v, wantErr %v", tt.dbType, tt.db, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMaxConnections(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		n        int
+		noConfig bool
+		wantErr  bool
+	}{
+		{"Postgres", "postgres", 500, false, false},
+		{"MySQL", "mysql", 1, false, false},
+		{"Zero", "postgres", 0, false, true},
+		{"Negative", "mysql", -5, false, true},
+		{"No config", "postgres", 500, true, true},
+		{"Redis", "redis", 500, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMaxConnections(tt.dbType, tt.n, tt.noConfig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMaxConnections(%q, %d, %v) error = %v, wantErr %v", tt.dbType, tt.n, tt.noConfig, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildLogsOptions(t *testing.T) {
+	for _, timestamps := range []bool{false, true} {
+		got := buildLogsOptions(LogOptions{Tail: "50", Timestamps: timestamps})
+		if got.Timestamps != timestamps {
+			t.Errorf("LogsOptions.Timestamps = %v, want %v", got.Timestamps, timestamps)
+		}
+		if !got.ShowStdout || !got.ShowStderr {
+			t.Errorf("LogsOptions should include stdout and stderr, got %+v", got)
+		}
+		if got.Tail != "50" {
+			t.Errorf("LogsOptions.Tail = %q, want 50", got.Tail)
+		}
+		if got.Since != "" {
+			t.Errorf("LogsOptions.Since = %q, want all logs", got.Since)
+		}
+	}
+
+	since := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	if got := buildLogsOptions(LogOptions{Since: since}); got.Since != "1705314600" {
+		t.Errorf("LogsOptions.Since = %q, want 1705314600", got.Since)
+	}
+}
+
+func TestCopyLogs(t *testing.T) {
+	// Build a multiplexed stream the way Docker sends it for non-TTY containers
+	var stream bytes.Buffer
+	stdout := stdcopy.NewStdWriter(&stream, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(&stream, stdcopy.Stderr)
+	stdout.Write([]byte("2024-01-15T10:30:00.000000000Z LOG:  database system is ready\n"))
+	stderr.Write([]byte("2024-01-15T10:30:01.000000000Z ERROR:  relation \"users\" does not exist\n2024-01-15T10:30:02.0"))
+	stderr.Write([]byte("00000000Z ERROR:  syntax error"))
+	raw := stream.Bytes()
+
+	tests := []struct {
+		name       string
+		timestamps bool
+		grep       string
+		ignoreCase bool
+		want       string
+	}{
+		{
+			name:       "No filter",
+			timestamps: true,
+			want: "2024-01-15T10:30:00.000000000Z LOG:  database system is ready\n" +
+				"2024-01-15T10:30:01.000000000Z ERROR:  relation \"users\" does not exist\n" +
+				"2024-01-15T10:30:02.000000000Z ERROR:  syntax error",
+		},
+		{
+			name:       "Grep keeps timestamp prefix",
+			timestamps: true,
+			grep:       "ERROR",
+			want: "2024-01-15T10:30:01.000000000Z ERROR:  relation \"users\" does not exist\n" +
+				"2024-01-15T10:30:02.000000000Z ERROR:  syntax error",
+		},
+		{
+			name:       "Anchored grep skips timestamp",
+			timestamps: true,
+			grep:       "^LOG:",
+			want:       "2024-01-15T10:30:00.000000000Z LOG:  database system is ready\n",
+		},
+		{
+			name:       "Case-insensitive grep",
+			timestamps: true,
+			grep:       "syntax ERROR",
+			ignoreCase: true,
+			want:       "2024-01-15T10:30:02.000000000Z ERROR:  syntax error",
+		},
+		{
+			name:       "Case-sensitive grep",
+			timestamps: true,
+			grep:       "syntax ERROR",
+			want:       "",
+		},
+		{
+			name:       "Anchored grep without timestamps matches whole line",
+			timestamps: false,
+			grep:       "^2024-01-15T10:30:00",
+			want:       "2024-01-15T10:30:00.000000000Z LOG:  database system is ready\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := LogOptions{Timestamps: tt.timestamps}
+			if tt.grep != "" {
+				pattern, err := CompileGrep(tt.grep, tt.ignoreCase)
+				if err != nil {
+					t.Fatalf("CompileGrep() error = %v", err)
+				}
+				opts.Grep = pattern
+			}
+
+			var out bytes.Buffer
+			if err := copyLogs(&out, bytes.NewReader(raw), opts); err != nil {
+				t.Fatalf("copyLogs() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("copyLogs() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyLogs_InterleavedStreams(t *testing.T) {
+	// A stdout line split across frames with a stderr line in between
+	var stream bytes.Buffer
+	stdout := stdcopy.NewStdWriter(&stream, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(&stream, stdcopy.Stderr)
+	stdout.Write([]byte("LOG:  checkpoint "))
+	stderr.Write([]byte("ERROR:  deadlock detected\n"))
+	stdout.Write([]byte("complete\n"))
+
+	pattern, err := CompileGrep("^(LOG|ERROR):", false)
+	if err != nil {
+		t.Fatalf("CompileGrep() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := copyLogs(&out, &stream, LogOptions{Grep: pattern}); err != nil {
+		t.Fatalf("copyLogs() error = %v", err)
+	}
+	want := "ERROR:  deadlock detected\nLOG:  checkpoint complete\n"
+	if out.String() != want {
+		t.Errorf("copyLogs() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestReclaimPort(t *testing.T) {
+	findNext := func(port string) (string, error) {
+		return "5433", nil
+	}
+
+	tests := []struct {
+		name      string
+		available bool
+		checkErr  error
+		reassign  bool
+		want      string
+		wantErr   bool
+	}{
+		{"Port still free", true, nil, false, "5432", false},
+		{"Port still free with reassign", true, nil, true, "5432", false},
+		{"Port taken", false, nil, false, "", true},
+		{"Port taken with reassign", false, nil, true, "5433", false},
+		{"Check fails", false, errors.New("docker unavailable"), true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isAvailable := func(port string) (bool, error) {
+				return tt.available, tt.checkErr
+			}
+
+			got, err := reclaimPort("5432", tt.reassign, isAvailable, findNext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reclaimPort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("reclaimPort() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortInUse(t *testing.T) {
+	containers := []container.Summary{
+		{ID: "abc123", Names: []string{"/mkdb-app"}, Ports: []container.Port{{PrivatePort: 5432, PublicPort: 5432}}},
+		{ID: "def456", Names: []string{"/other"}, Ports: []container.Port{{PrivatePort: 6379, PublicPort: 6380}}},
+	}
+
+	tests := []struct {
+		name   string
+		port   uint16
+		ignore []string
+		want   bool
+	}{
+		{"Published", 5432, nil, true},
+		{"Not published", 3306, nil, false},
+		{"Private port only", 6379, nil, false},
+		{"Ignored by name", 5432, []string{"mkdb-app"}, false},
+		{"Ignored by ID", 5432, []string{"", "abc123"}, false},
+		{"Other container ignored", 5432, []string{"other", "def456"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portInUse(containers, tt.port, tt.ignore); got != tt.want {
+				t.Errorf("portInUse(%d, %v) = %v, want %v", tt.port, tt.ignore, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCreated(t *testing.T) {
+	tests := []struct {
+		name        string
+		cancel      bool // Ctrl-C between creating the container and recording it
+		recordErr   error
+		removeErr   error
+		wantRecord  bool
+		wantRemoved bool
+		wantErr     error
+	}{
+		{"Recorded", false, nil, nil, true, false, nil},
+		{"Interrupted before recording", true, nil, nil, false, true, context.Canceled},
+		{"Record fails", false, errors.New("database is locked"), nil, true, true, nil},
+		{"Interrupted and removal fails", true, nil, errors.New("daemon gone"), false, true, context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			recorded, removed := false, ""
+			record := func() error {
+				recorded = true
+				return tt.recordErr
+			}
+			remove := func(id string) error {
+				removed = id
+				return tt.removeErr
+			}
+
+			err := recordCreated(ctx, "abc123", record, remove)
+			if recorded != tt.wantRecord {
+				t.Errorf("record called = %v, want %v", recorded, tt.wantRecord)
+			}
+			if (removed == "abc123") != tt.wantRemoved {
+				t.Errorf("removed = %q, want removed %v", removed, tt.wantRemoved)
+			}
+			switch {
+			case !tt.wantRemoved && err != nil:
+				t.Errorf("recordCreated() error = %v, want nil", err)
+			case tt.wantRemoved && err == nil:
+				t.Error("recordCreated() error = nil, want error")
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("recordCreated() error = %v, want %v", err, tt.wantErr)
+			case tt.recordErr != nil && !errors.Is(err, tt.recordErr):
+				t.Errorf("recordCreated() error = %v, want %v", err, tt.recordErr)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want VersionChange
+	}{
+		{"15", "16", VersionChange{Comparable: true, MajorChange: true}},
+		{"16", "15", VersionChange{Comparable: true, MajorChange: true, Downgrade: true}},
+		{"16.1", "16.2", VersionChange{Comparable: true}},
+		{"16.2", "16.1", VersionChange{Comparable: true, Downgrade: true}},
+		{"8.0", "8.4", VersionChange{Comparable: true}},
+		{"16", "16.2", VersionChange{Comparable: true}},
+		{"15-alpine", "16-alpine", VersionChange{Comparable: true, MajorChange: true}},
+		{"v24.3.0", "v24.2.1", VersionChange{Comparable: true, Downgrade: true}},
+		{"latest", "16", VersionChange{}},
+		{"16", "latest", VersionChange{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if got := CompareVersions(tt.from, tt.to); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContainerConfig_Mount(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		readOnly     bool
+		dataTarget   string
+		wantTarget   string
+		wantReadOnly bool
+	}{
+		{"Default target", false, "", "/var/lib/postgresql", false},
+		{"Read-only seed directory", true, "/docker-entrypoint-initdb.d", "/docker-entrypoint-initdb.d", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContainerOptions{
+				DBType:         "postgres",
+				DisplayName:    "mydb",
+				Port:           "5432",
+				VolumeType:     "bind",
+				VolumePath:     "/tmp/seed",
+				VolumeReadOnly: tt.readOnly,
+				DataTarget:     tt.dataTarget,
+			}
+
+			_, hostConfig, err := buildContainerConfig(adapter, opts)
+			if err != nil {
+				t.Fatalf("buildContainerConfig() error = %v", err)
+			}
+			if len(hostConfig.Mounts) != 1 {
+				t.Fatalf("HostConfig.Mounts has %d entries, want 1", len(hostConfig.Mounts))
+			}
+
+			m := hostConfig.Mounts[0]
+			if m.Source != "/tmp/seed" {
+				t.Errorf("Mount.Source = %v, want /tmp/seed", m.Source)
+			}
+			if m.Target != tt.wantTarget {
+				t.Errorf("Mount.Target = %v, want %v", m.Target, tt.wantTarget)
+			}
+			if m.ReadOnly != tt.wantReadOnly {
+				t.Errorf("Mount.ReadOnly = %v, want %v", m.ReadOnly, tt.wantReadOnly)
+			}
+		})
+	}
+}
+
+func TestBuildContainerConfig_MountPaths(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	volumesDir := config.VolumesDir
+	t.Cleanup(func() { config.VolumesDir = volumesDir })
+	config.VolumesDir = "/home/me/.local/share/mkdb/volumes"
+
+	tests := []struct {
+		name       string
+		volumeType string
+		volumePath string
+		dataTarget string
+		wantSource string
+		wantTarget string
+	}{
+		{"Named volume", "named", "mydb", "", "/home/me/.local/share/mkdb/volumes/mydb", "/var/lib/postgresql"},
+		{"Relative bind path", "bind", "data/mydb", "", filepath.Join(wd, "data", "mydb"), "/var/lib/postgresql"},
+		{"Windows bind path", "bind", `C:\Users\me\mydb`, "", `C:\Users\me\mydb`, "/var/lib/postgresql"},
+		{"Windows bind path with forward slashes", "bind", "D:/data/mydb", "/docker-entrypoint-initdb.d/", "D:/data/mydb", "/docker-entrypoint-initdb.d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContainerOptions{
+				DBType:      "postgres",
+				DisplayName: "mydb",
+				Port:        "5432",
+				VolumeType:  tt.volumeType,
+				VolumePath:  tt.volumePath,
+				DataTarget:  tt.dataTarget,
+			}
+
+			_, hostConfig, err := buildContainerConfig(adapter, opts)
+			if err != nil {
+				t.Fatalf("buildContainerConfig() error = %v", err)
+			}
+
+			m := hostConfig.Mounts[0]
+			if m.Type != mount.TypeBind {
+				t.Errorf("Mount.Type = %v, want %v", m.Type, mount.TypeBind)
+			}
+			if m.Source != tt.wantSource {
+				t.Errorf("Mount.Source = %v, want %v", m.Source, tt.wantSource)
+			}
+			if m.Target != tt.wantTarget {
+				t.Errorf("Mount.Target = %v, want %v", m.Target, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestBuildContainerConfig_DockerVolume(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	opts := ContainerOptions{
+		DBType:       "postgres",
+		DisplayName:  "mydb",
+		Port:         "5432",
+		VolumeType:   "docker",
+		VolumePath:   "mkdb-mydb",
+		VolumeDriver: "local",
+	}
+	_, hostConfig, err := buildContainerConfig(adapter, opts)
+	if err != nil {
+		t.Fatalf("buildContainerConfig() error = %v", err)
+	}
+
+	m := hostConfig.Mounts[0]
+	if m.Type != mount.TypeVolume || m.Source != "mkdb-mydb" || m.Target != "/var/lib/postgresql" {
+		t.Errorf("Mount = %+v, want volume mkdb-mydb at /var/lib/postgresql", m)
+	}
+	// Docker volumes have no host directory to check
+	if err := checkMountSources(hostConfig.Mounts); err != nil {
+		t.Errorf("checkMountSources() error = %v", err)
+	}
+}
+
+func TestBuildContainerConfig_Labels(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	opts := ContainerOptions{
+		DBType:      "postgres",
+		DisplayName: "mydb",
+		Port:        "5432",
+		Labels:      []string{"traefik.enable=true", "team=payments", "empty="},
+	}
+	containerConfig, _, err := buildContainerConfig(adapter, opts)
+	if err != nil {
+		t.Fatalf("buildContainerConfig() error = %v", err)
+	}
+
+	want := map[string]string{
+		labelManaged:     "true",
+		labelType:        "postgres",
+		labelName:        "mydb",
+		"traefik.enable": "true",
+		"team":           "payments",
+		"empty":          "",
+	}
+	if !maps.Equal(containerConfig.Labels, want) {
+		t.Errorf("Labels = %v, want %v", containerConfig.Labels, want)
+	}
+
+	opts.Labels = []string{"mkdb.managed=false"}
+	if _, _, err := buildContainerConfig(adapter, opts); err == nil {
+		t.Error("buildContainerConfig() with a reserved label error = nil, want error")
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  []string
+		wantErr bool
+	}{
+		{"None", nil, false},
+		{"Valid", []string{"traefik.http.routers.db.rule=Host(`db.local`)", "empty="}, false},
+		{"Missing value", []string{"traefik.enable"}, true},
+		{"Empty key", []string{"=true"}, true},
+		{"Space in key", []string{"my label=x"}, true},
+		{"Reserved", []string{"mkdb.name=other"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabels(tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabels(%q) error = %v, wantErr %v", tt.labels, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVolumeName(t *testing.T) {
+	for _, name := range []string{"mkdb-mydb", "shared_data", "pg.16"} {
+		if err := ValidateVolumeName(name); err != nil {
+			t.Errorf("ValidateVolumeName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a", "-data", "my data", "../data"} {
+		if err := ValidateVolumeName(name); err == nil {
+			t.Errorf("ValidateVolumeName(%q) expected error", name)
+		}
+	}
+}
+
+func TestCheckMountSources(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := checkMountSources([]mount.Mount{{Type: mount.TypeBind, Source: dir, Target: "/data"}}); err != nil {
+		t.Errorf("checkMountSources() error = %v for an existing directory", err)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	err := checkMountSources([]mount.Mount{{Type: mount.TypeBind, Source: missing, Target: "/data"}})
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("checkMountSources() error = %v, want one naming %s", err, missing)
+	}
+}
+
+func TestValidateDataTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		target  string
+		wantErr bool
+	}{
+		{"Seed directory", "postgres", "/docker-entrypoint-initdb.d", false},
+		{"Data path", "mysql", "/var/lib/mysql", false},
+		{"Relative path", "postgres", "seed", true},
+		{"Root", "postgres", "/", true},
+		{"Config path", "redis", "/usr/local/etc/redis/", true},
+		{"Unknown type", "oracle", "/seed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDataTarget(tt.dbType, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDataTarget(%q, %q) error = %v, wantErr %v", tt.dbType, tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDataTargetIsWritable(t *testing.T) {
+	tests := []struct {
+		dbType string
+		target string
+		want   bool
+	}{
+		{"postgres", "", true},
+		{"postgres", "/var/lib/postgresql/", true},
+		{"redis", "/data", true},
+		{"postgres", "/docker-entrypoint-initdb.d", false},
+	}
+
+	for _, tt := range tests {
+		if got := DataTargetIsWritable(tt.dbType, tt.target); got != tt.want {
+			t.Errorf("DataTargetIsWritable(%q, %q) = %v, want %v", tt.dbType, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestCreateWithPortRetry(t *testing.T) {
+	portInUse := errors.New("failed to start container: Bind for 0.0.0.0:5432 failed: port is already allocated")
+	nextPort := func(taken string) (string, error) {
+		return map[string]string{"5432": "5433", "5433": "5434", "5434": "5435"}[taken], nil
+	}
+
+	tests := []struct {
+		name        string
+		taken       map[string]bool
+		otherErr    error
+		maxAttempts int
+		wantPort    string
+		wantCalls   int
+		wantErr     bool
+	}{
+		{"First port free", nil, nil, 3, "5432", 1, false},
+		{"Retries on taken port", map[string]bool{"5432": true, "5433": true}, nil, 3, "5434", 3, false},
+		{"Gives up after max attempts", map[string]bool{"5432": true, "5433": true, "5434": true}, nil, 3, "", 3, true},
+		{"Other errors are not retried", nil, errors.New("failed to pull image"), 3, "", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			create := func(opts ContainerOptions) (string, error) {
+				calls++
+				if tt.otherErr != nil {
+					return "", tt.otherErr
+				}
+				if tt.taken[opts.Port] {
+					return "", portInUse
+				}
+				return "id-" + opts.Port, nil
+			}
+
+			id, port, err := createWithPortRetry(ContainerOptions{Port: "5432"}, tt.maxAttempts, create, nextPort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createWithPortRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if port != tt.wantPort {
+				t.Errorf("createWithPortRetry() port = %q, want %q", port, tt.wantPort)
+			}
+			if !tt.wantErr && id != "id-"+tt.wantPort {
+				t.Errorf("createWithPortRetry() id = %q, want id-%s", id, tt.wantPort)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("create called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestStartOrRemove(t *testing.T) {
+	portInUse := errors.New("driver failed programming external connectivity: Bind for 0.0.0.0:5432 failed: port is already allocated")
+
+	tests := []struct {
+		name        string
+		startErr    error
+		removeErr   error
+		wantRemoved bool
+		wantPortErr bool
+	}{
+		{"Started", nil, nil, false, false},
+		{"Port taken", portInUse, nil, true, true},
+		{"Other failure", errors.New("OCI runtime create failed"), nil, true, false},
+		{"Removal fails", portInUse, errors.New("daemon gone"), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := ""
+			start := func(string) error { return tt.startErr }
+			remove := func(id string) error {
+				removed = id
+				return tt.removeErr
+			}
+
+			err := startOrRemove("abc123", start, remove)
+			if (err != nil) != (tt.startErr != nil) {
+				t.Fatalf("startOrRemove() error = %v, want error %v", err, tt.startErr != nil)
+			}
+			if (removed == "abc123") != tt.wantRemoved {
+				t.Errorf("removed = %q, want removed %v", removed, tt.wantRemoved)
+			}
+			if errors.Is(err, ErrPortInUse) != tt.wantPortErr {
+				t.Errorf("startOrRemove() error = %v, want ErrPortInUse %v", err, tt.wantPortErr)
+			}
+			if tt.removeErr != nil && !strings.Contains(err.Error(), tt.removeErr.Error()) {
+				t.Errorf("startOrRemove() error = %v, want the removal error mentioned", err)
+			}
+		})
+	}
+}
+
+func TestIsPortInUseError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("driver failed programming external connectivity: Bind for 0.0.0.0:5432 failed: port is already allocated"), true},
+		{errors.New("listen tcp4 0.0.0.0:6379: bind: address already in use"), true},
+		{fmt.Errorf("failed to start container: %w", ErrPortInUse), true},
+		{errors.New("failed to pull image"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isPortInUseError(tt.err); got != tt.want {
+			t.Errorf("isPortInUseError(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"Default", "", DefaultRestartPolicy},
+		{"No restart", "no", "no"},
+		{"On failure", "on-failure", "on-failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContainerOptions{
+				DBType:        "postgres",
+				DisplayName:   "mydb",
+				Port:          "5432",
+				RestartPolicy: tt.policy,
+			}
+
+			_, hostConfig, err := buildContainerConfig(adapter, opts)
+			if err != nil {
+				t.Fatalf("buildContainerConfig() error = %v", err)
+			}
+			if string(hostConfig.RestartPolicy.Name) != tt.want {
+				t.Errorf("RestartPolicy.Name = %v, want %v", hostConfig.RestartPolicy.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRestartPolicy(t *testing.T) {
+	for _, policy := range RestartPolicies {
+		if err := ValidateRestartPolicy(policy); err != nil {
+			t.Errorf("ValidateRestartPolicy(%q) error = %v", policy, err)
+		}
+	}
+	for _, policy := range []string{"", "never", "on-failure:3"} {
+		if err := ValidateRestartPolicy(policy); err == nil {
+			t.Errorf("ValidateRestartPolicy(%q) expected error", policy)
+		}
+	}
+}
+
+func TestBindIP(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		bindIP      string
+		want        string
+		wantBinding string
+	}{
+		{"Default", "", DefaultBindIP, "0.0.0.0:5433 -> 5432/tcp"},
+		{"Loopback", "127.0.0.1", "127.0.0.1", "127.0.0.1:5433 -> 5432/tcp"},
+		{"IPv6 loopback", "::1", "::1", "[::1]:5433 -> 5432/tcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContainerOptions{
+				DBType:      "postgres",
+				DisplayName: "mydb",
+				Port:        "5433",
+				BindIP:      tt.bindIP,
+			}
+
+			_, hostConfig, err := buildContainerConfig(adapter, opts)
+			if err != nil {
+				t.Fatalf("buildContainerConfig() error = %v", err)
+			}
+			bindings := hostConfig.PortBindings["5432/tcp"]
+			if len(bindings) != 1 || bindings[0].HostIP != tt.want {
+				t.Errorf("PortBindings = %v, want HostIP %v", bindings, tt.want)
+			}
+
+			plan, err := PlanContainer(opts)
+			if err != nil {
+				t.Fatalf("PlanContainer() error = %v", err)
+			}
+			if plan.PortBinding != tt.wantBinding {
+				t.Errorf("PortBinding = %v, want %v", plan.PortBinding, tt.wantBinding)
+			}
+		})
+	}
+}
+
+func TestValidateBindIP(t *testing.T) {
+	for _, ip := range []string{"0.0.0.0", "127.0.0.1", "192.168.1.10", "::1"} {
+		if err := ValidateBindIP(ip); err != nil {
+			t.Errorf("ValidateBindIP(%q) error = %v", ip, err)
+		}
+	}
+	for _, ip := range []string{"", "localhost", "127.0.0.1:5432", "300.0.0.1"} {
+		if err := ValidateBindIP(ip); err == nil {
+			t.Errorf("ValidateBindIP(%q) expected error", ip)
+		}
+	}
+}
+
+func TestValidateNetworkName(t *testing.T) {
+	for _, name := range []string{"app", "myapp_default", "bridge"} {
+		if err := ValidateNetworkName(name); err != nil {
+			t.Errorf("ValidateNetworkName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"host", "none", "my app"} {
+		if err := ValidateNetworkName(name); err == nil {
+			t.Errorf("ValidateNetworkName(%q) expected error", name)
+		}
+	}
+}
+
+func TestBuildContainerConfig_Network(t *testing.T) {
+	adapter, err := adapters.GetRegistry().Get("redis")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	opts := ContainerOptions{DBType: "redis", DisplayName: "cache", Port: "6379", Network: "app"}
+	_, hostConfig, err := buildContainerConfig(adapter, opts)
+	if err != nil {
+		t.Fatalf("buildContainerConfig() error = %v", err)
+	}
+	if hostConfig.NetworkMode != "app" {
+		t.Errorf("HostConfig.NetworkMode = %v, want app", hostConfig.NetworkMode)
+	}
+
+	endpoint := networkingConfig(opts).EndpointsConfig["app"]
+	if endpoint == nil || !slices.Equal(endpoint.Aliases, []string{"cache"}) {
+		t.Errorf("networkingConfig() endpoint = %+v, want alias cache", endpoint)
+	}
+
+	opts.Network = ""
+	if got := networkingConfig(opts); got != nil {
+		t.Errorf("networkingConfig() without a network = %+v, want nil", got)
+	}
+}
+
+func TestParseEnvVar(t *testing.T) {
+	tests := []struct {
+		pair      string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{"POSTGRES_INITDB_ARGS=--data-checksums", "POSTGRES_INITDB_ARGS", "--data-checksums", false},
+		{"TZ=", "TZ", "", false},
+		{"A=b=c", "A", "b=c", false},
+		{"NOVALUE", "", "", true},
+		{"=value", "", "", true},
+		{"BAD KEY=value", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			key, value, err := ParseEnvVar(tt.pair)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEnvVar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("ParseEnvVar() = %q, %q, want %q, %q", key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestValidateExtraEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		extra   []string
+		force   bool
+		wantErr bool
+	}{
+		{"Unmanaged variable", "postgres", []string{"POSTGRES_INITDB_ARGS=--data-checksums"}, false, false},
+		{"Credential variable", "postgres", []string{"POSTGRES_PASSWORD=hunter2"}, false, true},
+		{"Credential variable forced", "postgres", []string{"POSTGRES_PASSWORD=hunter2"}, true, false},
+		{"No-auth variable", "mysql", []string{"MYSQL_ALLOW_EMPTY_PASSWORD=no"}, false, true},
+		{"Invalid format", "redis", []string{"oops"}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExtraEnv(tt.dbType, tt.extra, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExtraEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	env := []string{"POSTGRES_DB=mydb", "POSTGRES_PASSWORD=secret"}
+	extra := []string{"POSTGRES_PASSWORD=override", "TZ=UTC"}
+
+	got := MergeEnv(env, extra)
+	want := []string{"POSTGRES_DB=mydb", "POSTGRES_PASSWORD=override", "TZ=UTC"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeEnv() = %q, want %q", got, want)
+	}
+}
+
+func TestContainerCommand(t *testing.T) {
+	postgres, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	redis, err := adapters.GetRegistry().Get("redis")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	got, err := ContainerCommand(postgres, "secret", "", []string{"-c", "work_mem=64MB"}, false)
+	if err != nil {
+		t.Fatalf("ContainerCommand() error = %v", err)
+	}
+	if !slices.Equal(got, []string{"postgres", "-c", "work_mem=64MB"}) {
+		t.Errorf("ContainerCommand() = %q, want postgres with the extra args", got)
+	}
+
+	// Extra args come after the persistence flags so they can override them
+	got, err = ContainerCommand(redis, "secret", "aof", []string{"--appendfsync", "always"}, false)
+	if err != nil {
+		t.Fatalf("ContainerCommand() error = %v", err)
+	}
+	if !slices.Contains(got, "--appendonly") || !slices.Equal(got[len(got)-2:], []string{"--appendfsync", "always"}) {
+		t.Errorf("ContainerCommand() = %q, want the persistence flags followed by the extra args", got)
+	}
+
+	if _, err := ContainerCommand(postgres, "secret", "aof", nil, false); err == nil {
+		t.Error("ContainerCommand() expected error for persistence on postgres")
+	}
+
+	// Without the config mount Redis is started without its config file
+	got, err = ContainerCommand(redis, "secret", "", nil, true)
+	if err != nil {
+		t.Fatalf("ContainerCommand() error = %v", err)
+	}
+	if !slices.Equal(got, []string{"redis-server", "--requirepass", "secret"}) {
+		t.Errorf("ContainerCommand() with noConfig = %q, want redis-server without the config file", got)
+	}
+}
+
+func TestContainerEnv(t *testing.T) {
+	mysql, err := adapters.GetRegistry().Get("mysql")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	postgres, err := adapters.GetRegistry().Get("postgres")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	env, err := ContainerEnv(mysql, "shop", "dbuser", "secret", "rootsecret")
+	if err != nil {
+		t.Fatalf("ContainerEnv() error = %v", err)
+	}
+	if !slices.Contains(env, "MYSQL_ROOT_PASSWORD=rootsecret") {
+		t.Errorf("ContainerEnv() = %q, want MYSQL_ROOT_PASSWORD=rootsecret", env)
+	}
+
+	if _, err := ContainerEnv(mysql, "shop", "dbuser", "secret", ""); err == nil {
+		t.Error("ContainerEnv() expected error for missing admin password")
+	}
+
+	// Unauthenticated containers and databases without an admin account don't need one
+	if _, err := ContainerEnv(mysql, "shop", "", "", ""); err != nil {
+		t.Errorf("ContainerEnv() unauthenticated error = %v", err)
+	}
+	if _, err := ContainerEnv(postgres, "mydb", "dbuser", "secret", ""); err != nil {
+		t.Errorf("ContainerEnv() postgres error = %v", err)
+	}
+}
+
+func TestPlanContainer(t *testing.T) {
+	plan, err := PlanContainer(ContainerOptions{
+		DBType:         "mysql",
+		DisplayName:    "shop",
+		Username:       "dbuser",
+		Password:       "secret",
+		AdminPassword:  "rootsecret",
+		Port:           "13306",
+		Version:        "8.4",
+		VolumeType:     "bind",
+		VolumePath:     "/srv/shop",
+		VolumeReadOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("PlanContainer() error = %v", err)
+	}
+
+	if plan.Image != "mysql:8.4" {
+		t.Errorf("Image = %v, want mysql:8.4", plan.Image)
+	}
+	if plan.PortBinding != "0.0.0.0:13306 -> 3306/tcp" {
+		t.Errorf("PortBinding = %v, want 0.0.0.0:13306 -> 3306/tcp", plan.PortBinding)
+	}
+	for _, pair := range plan.Env {
+		if strings.Contains(pair, "secret") {
+			t.Errorf("Env contains an unmasked secret: %q", pair)
+		}
+	}
+	if !slices.Contains(plan.Env, "MYSQL_ROOT_PASSWORD="+maskedSecret) {
+		t.Errorf("Env = %q, want a masked MYSQL_ROOT_PASSWORD", plan.Env)
+	}
+	if len(plan.Mounts) != 2 || plan.Mounts[0] != "/srv/shop:/var/lib/mysql:ro" {
+		t.Errorf("Mounts = %q, want the read-only volume and the config directory", plan.Mounts)
+	}
+	if plan.RestartPolicy != DefaultRestartPolicy {
+		t.Errorf("RestartPolicy = %v, want %v", plan.RestartPolicy, DefaultRestartPolicy)
+	}
+
+	plan, err = PlanContainer(ContainerOptions{DBType: "redis", DisplayName: "cache", Password: "secret", Port: "6379"})
+	if err != nil {
+		t.Fatalf("PlanContainer() error = %v", err)
+	}
+	if slices.Contains(plan.Command, "secret") || !slices.Contains(plan.Command, maskedSecret) {
+		t.Errorf("Command = %q, want the password masked", plan.Command)
+	}
+}
+
+func TestCredentialsFromConfig(t *testing.T) {
+	mysqlEnv := []string{"MYSQL_DATABASE=orders", "MYSQL_USER=dbuser", "MYSQL_PASSWORD=user-secret", "MYSQL_ROOT_PASSWORD=root-secret"}
+	got := credentialsFromConfig("mysql", mysqlEnv, nil)
+	want := ContainerCredentials{Username: "dbuser", Password: "user-secret", AdminPassword: "root-secret"}
+	if got != want {
+		t.Errorf("credentialsFromConfig(mysql) = %+v, want %+v", got, want)
+	}
+
+	got = credentialsFromConfig("redis", nil, []string{"redis-server", "--requirepass", "redis-secret"})
+	if got != (ContainerCredentials{Password: "redis-secret"}) {
+		t.Errorf("credentialsFromConfig(redis) = %+v, want only the --requirepass password", got)
+	}
+
+	// Postgres has no separate admin account, so nothing is taken as its admin password
+	got = credentialsFromConfig("postgres", []string{"POSTGRES_USER=dbuser", "POSTGRES_PASSWORD=pg-secret"}, nil)
+	if got != (ContainerCredentials{Username: "dbuser", Password: "pg-secret"}) {
+		t.Errorf("credentialsFromConfig(postgres) = %+v", got)
+	}
+}
+
+func TestPasswordInCommand(t *testing.T) {
+	if !PasswordInCommand("redis") {
+		t.Error("PasswordInCommand(redis) = false, want true")
+	}
+	if PasswordInCommand("postgres") {
+		t.Error("PasswordInCommand(postgres) = true, want false")
+	}
+}
+
+func TestUsersInMemory(t *testing.T) {
+	if !UsersInMemory("redis") {
+		t.Error("UsersInMemory(redis) = false, want true")
+	}
+	if UsersInMemory("postgres") {
+		t.Error("UsersInMemory(postgres) = true, want false")
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	// A single success followed by a failure is the temporary init server going away
+	results := []error{nil, errors.New("connection refused"), nil, nil}
+	calls := 0
+	ping := func(context.Context) error {
+		err := results[calls]
+		calls++
+		return err
+	}
+	if err := waitForReady(ping, time.Second, time.Millisecond); err != nil {
+		t.Fatalf("waitForReady() error = %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("waitForReady() pinged %d times, want 4", calls)
+	}
+
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	if err := waitForReady(failing, 5*time.Millisecond, time.Millisecond); err == nil {
+		t.Error("waitForReady() expected error for a database that never answers")
+	}
+
+	// A ping that hangs must be cut off at the wait's deadline
+	hung := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	start := time.Now()
+	if err := waitForReady(hung, 20*time.Millisecond, time.Millisecond); err == nil {
+		t.Error("waitForReady() expected error for a hung ping")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitForReady() took %s with a hung ping, want about the 20ms timeout", elapsed)
+	}
+}
+
+func TestValidateSeedFiles(t *testing.T) {
+	dir := t.TempDir()
+	seed := filepath.Join(dir, "schema.sql")
+	if err := os.WriteFile(seed, []byte("CREATE TABLE t (id int);"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := ValidateSeedFiles("postgres", []string{seed}); err != nil {
+		t.Errorf("ValidateSeedFiles() error = %v", err)
+	}
+	if err := ValidateSeedFiles("postgres", []string{filepath.Join(dir, "missing.sql")}); err == nil {
+		t.Error("ValidateSeedFiles() expected error for a missing file")
+	}
+	if err := ValidateSeedFiles("mysql", []string{dir}); err == nil {
+		t.Error("ValidateSeedFiles() expected error for a directory")
+	}
+}
+
+func TestParseStateTime(t *testing.T) {
+	got, err := parseStateTime("2024-05-01T10:30:00.123456789Z")
+	if err != nil {
+		t.Fatalf("parseStateTime() error = %v", err)
+	}
+	if want := time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.UTC); !got.Equal(want) {
+		t.Errorf("parseStateTime() = %v, want %v", got, want)
+	}
+
+	for _, value := range []string{"0001-01-01T00:00:00Z", "", "yesterday"} {
+		if _, err := parseStateTime(value); err == nil {
+			t.Errorf("parseStateTime(%q) error = nil, want error", value)
+		}
+	}
+}
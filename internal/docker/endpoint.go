@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// ContextEnvVar selects a Docker context when --docker-context isn't given
+const ContextEnvVar = "MKDB_DOCKER_CONTEXT"
+
+// Host and ContextName are set by the --docker-host and --docker-context flags
+var (
+	Host        string
+	ContextName string
+)
+
+// Endpoint is the Docker daemon mkdb talks to and where it was configured
+type Endpoint struct {
+	Host   string
+	Source string
+
+	// TLS files from a Docker context, empty if it doesn't use TLS
+	CACert string
+	Cert   string
+	Key    string
+}
+
+// String describes the endpoint for messages, e.g. "unix:///var/run/docker.sock (DOCKER_HOST)"
+func (e Endpoint) String() string {
+	if e.Source == "" {
+		return e.Host
+	}
+	return fmt.Sprintf("%s (%s)", e.Host, e.Source)
+}
+
+// activeEndpoint is the endpoint the client was created for
+var activeEndpoint Endpoint
+
+// ActiveEndpoint returns the Docker endpoint mkdb is connected to, once Initialize has been called
+func ActiveEndpoint() Endpoint {
+	return activeEndpoint
+}
+
+// ResolveEndpoint picks the Docker endpoint from --docker-host, then --docker-context or
+// MKDB_DOCKER_CONTEXT, then DOCKER_HOST, and falls back to the default local socket
+func ResolveEndpoint() (Endpoint, error) {
+	if Host != "" && ContextName != "" {
+		return Endpoint{}, fmt.Errorf("--docker-host and --docker-context cannot be used together")
+	}
+	if Host != "" {
+		return Endpoint{Host: Host, Source: "--docker-host"}, nil
+	}
+
+	name, source := ContextName, "--docker-context"
+	if name == "" {
+		name, source = strings.TrimSpace(os.Getenv(ContextEnvVar)), ContextEnvVar
+	}
+	if name != "" {
+		endpoint, err := contextEndpoint(dockerConfigDir(), name)
+		if err != nil {
+			return Endpoint{}, err
+		}
+		endpoint.Source = fmt.Sprintf("context %s from %s", name, source)
+		return endpoint, nil
+	}
+
+	if host := os.Getenv(client.EnvOverrideHost); host != "" {
+		return Endpoint{Host: host, Source: client.EnvOverrideHost}, nil
+	}
+	return Endpoint{Host: client.DefaultDockerHost, Source: "default"}, nil
+}
+
+// clientOpts returns the client options that connect to endpoint
+func (e Endpoint) clientOpts() []client.Opt {
+	opts := []client.Opt{client.FromEnv, client.WithHost(e.Host), client.WithAPIVersionNegotiation()}
+	if e.CACert != "" || e.Cert != "" {
+		opts = append(opts, client.WithTLSClientConfig(e.CACert, e.Cert, e.Key))
+	}
+	return opts
+}
+
+// dockerConfigDir returns the Docker CLI's config directory, $DOCKER_CONFIG or ~/.docker
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// contextMeta is the part of a Docker context's meta.json mkdb uses
+type contextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints map[string]struct {
+		Host string `json:"Host"`
+	} `json:"Endpoints"`
+}
+
+// contextEndpoint reads a Docker context created with 'docker context create' from configDir
+// Contexts are stored under contexts/meta/<sha256 of the name>, with TLS files under contexts/tls
+func contextEndpoint(configDir, name string) (Endpoint, error) {
+	// The default context is DOCKER_HOST or the local socket, and has no meta.json
+	if name == "default" {
+		if host := os.Getenv(client.EnvOverrideHost); host != "" {
+			return Endpoint{Host: host}, nil
+		}
+		return Endpoint{Host: client.DefaultDockerHost}, nil
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+
+	data, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", id, "meta.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return Endpoint{}, fmt.Errorf("Docker context '%s' not found (see 'docker context ls')", name)
+	}
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("failed to read Docker context '%s': %w", name, err)
+	}
+
+	var meta contextMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Endpoint{}, fmt.Errorf("failed to parse Docker context '%s': %w", name, err)
+	}
+	docker, ok := meta.Endpoints["docker"]
+	if !ok || docker.Host == "" {
+		return Endpoint{}, fmt.Errorf("Docker context '%s' has no Docker endpoint", name)
+	}
+
+	endpoint := Endpoint{Host: docker.Host}
+	tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker")
+	for file, field := range map[string]*string{"ca.pem": &endpoint.CACert, "cert.pem": &endpoint.Cert, "key.pem": &endpoint.Key} {
+		path := filepath.Join(tlsDir, file)
+		if _, err := os.Stat(path); err == nil {
+			*field = path
+		}
+	}
+	return endpoint, nil
+}
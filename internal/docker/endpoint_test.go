@@ -0,0 +1,114 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+// writeContext creates a Docker context the way 'docker context create' stores it
+func writeContext(t *testing.T, configDir, name, meta string, tlsFiles ...string) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+
+	metaDir := filepath.Join(configDir, "contexts", "meta", id)
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker")
+	for _, file := range tlsFiles {
+		if err := os.MkdirAll(tlsDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tlsDir, file), []byte("pem"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestContextEndpoint(t *testing.T) {
+	configDir := t.TempDir()
+	writeContext(t, configDir, "devbox", `{"Name":"devbox","Metadata":{},"Endpoints":{"docker":{"Host":"tcp://devbox:2376","SkipTLSVerify":false}}}`, "ca.pem", "cert.pem", "key.pem")
+	writeContext(t, configDir, "desktop", `{"Name":"desktop","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///Users/me/.docker/run/docker.sock"}}}`)
+	writeContext(t, configDir, "empty", `{"Name":"empty","Metadata":{},"Endpoints":{}}`)
+
+	endpoint, err := contextEndpoint(configDir, "devbox")
+	if err != nil {
+		t.Fatalf("contextEndpoint(devbox) error = %v", err)
+	}
+	if endpoint.Host != "tcp://devbox:2376" {
+		t.Errorf("contextEndpoint(devbox) Host = %q, want tcp://devbox:2376", endpoint.Host)
+	}
+	if filepath.Base(endpoint.CACert) != "ca.pem" || filepath.Base(endpoint.Cert) != "cert.pem" || filepath.Base(endpoint.Key) != "key.pem" {
+		t.Errorf("contextEndpoint(devbox) TLS files = %q, %q, %q", endpoint.CACert, endpoint.Cert, endpoint.Key)
+	}
+
+	endpoint, err = contextEndpoint(configDir, "desktop")
+	if err != nil {
+		t.Fatalf("contextEndpoint(desktop) error = %v", err)
+	}
+	if endpoint.Host != "unix:///Users/me/.docker/run/docker.sock" {
+		t.Errorf("contextEndpoint(desktop) Host = %q", endpoint.Host)
+	}
+	if endpoint.CACert != "" || endpoint.Cert != "" || endpoint.Key != "" {
+		t.Errorf("contextEndpoint(desktop) has TLS files without a tls directory")
+	}
+
+	if _, err := contextEndpoint(configDir, "missing"); err == nil {
+		t.Error("contextEndpoint(missing) error = nil, want error")
+	}
+	if _, err := contextEndpoint(configDir, "empty"); err == nil {
+		t.Error("contextEndpoint(empty) error = nil, want error")
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	configDir := t.TempDir()
+	writeContext(t, configDir, "devbox", `{"Name":"devbox","Endpoints":{"docker":{"Host":"tcp://devbox:2376"}}}`)
+	t.Setenv("DOCKER_CONFIG", configDir)
+
+	tests := []struct {
+		name       string
+		host       string
+		context    string
+		envContext string
+		dockerHost string
+		want       string
+		wantErr    bool
+	}{
+		{"Default", "", "", "", "", client.DefaultDockerHost, false},
+		{"DOCKER_HOST", "", "", "", "tcp://remote:2375", "tcp://remote:2375", false},
+		{"Context from environment", "", "", "devbox", "tcp://remote:2375", "tcp://devbox:2376", false},
+		{"Context flag", "", "devbox", "missing", "", "tcp://devbox:2376", false},
+		{"Default context", "", "default", "", "tcp://remote:2375", "tcp://remote:2375", false},
+		{"Host flag", "tcp://flag:2375", "", "devbox", "tcp://remote:2375", "tcp://flag:2375", false},
+		{"Missing context", "", "missing", "", "", "", true},
+		{"Host and context", "tcp://flag:2375", "devbox", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ContextEnvVar, tt.envContext)
+			t.Setenv(client.EnvOverrideHost, tt.dockerHost)
+			Host, ContextName = tt.host, tt.context
+			defer func() { Host, ContextName = "", "" }()
+
+			endpoint, err := ResolveEndpoint()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if endpoint.Host != tt.want {
+				t.Errorf("ResolveEndpoint() Host = %q, want %q", endpoint.Host, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Environment variables that override how long mkdb waits on commands run inside containers
+const (
+	ExecTimeoutEnvVar      = "MKDB_EXEC_TIMEOUT"
+	ExecPollIntervalEnvVar = "MKDB_EXEC_POLL_INTERVAL"
+)
+
+// ExecTimeout bounds a whole exec, including reading its output. It's generous because seeding
+// a database from a large file runs as a single exec
+var ExecTimeout = 10 * time.Minute
+
+// ExecPollInterval is how often a finished exec is checked for its exit code
+var ExecPollInterval = 100 * time.Millisecond
+
+// execCreateAttempts and execCreateBackoff retry creating an exec while a container that was just
+// started is still coming up
+const (
+	execCreateAttempts = 4
+	execCreateBackoff  = 250 * time.Millisecond
+)
+
+// errExecTimeout is returned when an exec doesn't finish within ExecTimeout
+var errExecTimeout = errors.New("command timed out")
+
+// loadExecSettings applies MKDB_EXEC_TIMEOUT and MKDB_EXEC_POLL_INTERVAL
+// Invalid values are reported and the defaults kept
+func loadExecSettings() error {
+	var errs []error
+	for envVar, setting := range map[string]*time.Duration{
+		ExecTimeoutEnvVar:      &ExecTimeout,
+		ExecPollIntervalEnvVar: &ExecPollInterval,
+	} {
+		value := strings.TrimSpace(os.Getenv(envVar))
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %q, want a positive duration such as 30s", envVar, value))
+			continue
+		}
+		*setting = d
+	}
+	return errors.Join(errs...)
+}
+
+// createExec creates an exec, retrying with backoff if the container isn't running yet
+func createExec(ctx context.Context, containerID string, opts container.ExecOptions) (string, error) {
+	return retryExecCreate(ctx, func() (string, error) {
+		resp, err := cli.ContainerExecCreate(ctx, containerID, opts)
+		return resp.ID, err
+	}, execCreateAttempts, execCreateBackoff)
+}
+
+// retryExecCreate calls create until it succeeds, fails with an error that isn't transient, or attempts run out
+// The wait doubles after each attempt
+func retryExecCreate(ctx context.Context, create func() (string, error), attempts int, backoff time.Duration) (string, error) {
+	for attempt := 1; ; attempt++ {
+		id, err := create()
+		if err == nil {
+			return id, nil
+		}
+		if !isTransientExecError(err) || attempt >= attempts {
+			return "", fmt.Errorf("failed to create exec: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed to create exec: %w", err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientExecError reports whether creating an exec failed because the container is still starting
+func isTransientExecError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "is not running") || strings.Contains(msg, "is restarting")
+}
+
+// waitForExec calls inspect every interval until the exec has finished, or ctx is done
+func waitForExec(ctx context.Context, inspect func(context.Context) (container.ExecInspect, error), interval time.Duration) (container.ExecInspect, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := inspect(ctx)
+		if ctx.Err() != nil {
+			return result, execTimeoutError()
+		}
+		if err != nil {
+			return result, err
+		}
+		if !result.Running {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, execTimeoutError()
+		case <-ticker.C:
+		}
+	}
+}
+
+// execTimeoutError describes an exec that ran longer than ExecTimeout
+func execTimeoutError() error {
+	return fmt.Errorf("%w after %s (set %s to wait longer)", errExecTimeout, ExecTimeout, ExecTimeoutEnvVar)
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestRetryExecCreate(t *testing.T) {
+	notRunning := errors.New("Error response from daemon: container 3f2a is not running")
+
+	tests := []struct {
+		name      string
+		results   []error
+		wantErr   bool
+		wantCalls int
+	}{
+		{"First attempt succeeds", []error{nil}, false, 1},
+		{"Container still starting", []error{notRunning, notRunning, nil}, false, 3},
+		{"Attempts run out", []error{notRunning, notRunning, notRunning}, true, 3},
+		{"Other errors aren't retried", []error{errors.New("no such container"), nil}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			create := func() (string, error) {
+				err := tt.results[calls]
+				calls++
+				if err != nil {
+					return "", err
+				}
+				return "exec-id", nil
+			}
+
+			id, err := retryExecCreate(context.Background(), create, 3, time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retryExecCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && id != "exec-id" {
+				t.Errorf("retryExecCreate() = %q, want exec-id", id)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("retryExecCreate() called create %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWaitForExec(t *testing.T) {
+	polls := 0
+	inspect := func(ctx context.Context) (container.ExecInspect, error) {
+		polls++
+		return container.ExecInspect{Running: polls < 3, ExitCode: 2}, nil
+	}
+
+	result, err := waitForExec(context.Background(), inspect, time.Millisecond)
+	if err != nil {
+		t.Fatalf("waitForExec() error = %v", err)
+	}
+	if result.ExitCode != 2 || polls != 3 {
+		t.Errorf("waitForExec() = exit code %d after %d polls, want 2 after 3", result.ExitCode, polls)
+	}
+
+	// A command that never finishes gives up once the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	hung := func(ctx context.Context) (container.ExecInspect, error) {
+		return container.ExecInspect{Running: true}, nil
+	}
+	if _, err := waitForExec(ctx, hung, time.Millisecond); !errors.Is(err, errExecTimeout) {
+		t.Errorf("waitForExec() error = %v, want errExecTimeout", err)
+	}
+}
+
+func TestLoadExecSettings(t *testing.T) {
+	defer func(timeout, interval time.Duration) { ExecTimeout, ExecPollInterval = timeout, interval }(ExecTimeout, ExecPollInterval)
+
+	t.Setenv(ExecTimeoutEnvVar, "30s")
+	t.Setenv(ExecPollIntervalEnvVar, "not a duration")
+	if err := loadExecSettings(); err == nil {
+		t.Error("loadExecSettings() error = nil, want an error for the invalid poll interval")
+	}
+
+	if ExecTimeout != 30*time.Second {
+		t.Errorf("ExecTimeout = %s, want 30s", ExecTimeout)
+	}
+	if ExecPollInterval != 100*time.Millisecond {
+		t.Errorf("ExecPollInterval = %s, want the 100ms default", ExecPollInterval)
+	}
+}
@@ -0,0 +1,227 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/types"
+)
+
+// Predicate reports whether a container matches a filter
+type Predicate func(c *database.Container) bool
+
+// Supported operators, ordered so two-character operators are matched first
+var operators = []string{"!=", "=", "<", ">"}
+
+// now returns the current time and can be overridden in tests
+var now = time.Now
+
+// Parse parses a comma-separated filter expression into a single predicate
+// that matches only containers satisfying every condition
+//
+// Example: "type=postgres,status=running,expires<2h"
+//
+// Supported fields:
+//   - type, status, name, port, version: compared with = and !=
+//   - expires: time remaining before expiration, compared with < and >
+func Parse(expr string) (Predicate, error) {
+	var predicates []Predicate
+
+	for _, cond := range strings.Split(expr, ",") {
+		cond = strings.TrimSpace(cond)
+		if cond == "" {
+			continue
+		}
+
+		p, err := parseCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, p)
+	}
+
+	if len(predicates) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	return func(c *database.Container) bool {
+		for _, p := range predicates {
+			if !p(c) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// parseCondition parses a single "field<op>value" condition
+func parseCondition(cond string) (Predicate, error) {
+	field, op, value, found := splitCondition(cond)
+	if !found {
+		return nil, fmt.Errorf("invalid filter condition: %q (expected field=value, field!=value, or expires<duration)", cond)
+	}
+	if field == "" || value == "" {
+		return nil, fmt.Errorf("invalid filter condition: %q", cond)
+	}
+
+	if field == "expires" {
+		return parseExpires(op, value)
+	}
+	return parseEquality(field, op, value)
+}
+
+// splitCondition splits a condition at its operator, found is false if it has none
+// The field is lowercased, both sides are trimmed
+func splitCondition(cond string) (field, op, value string, found bool) {
+	for _, op := range operators {
+		idx := strings.Index(cond, op)
+		if idx == -1 {
+			continue
+		}
+		return strings.ToLower(strings.TrimSpace(cond[:idx])), op, strings.TrimSpace(cond[idx+len(op):]), true
+	}
+	return "", "", "", false
+}
+
+// HasStatus reports whether expr has a status=<status> condition, so callers can tell when removed
+// containers are asked for without matching them against the predicate
+func HasStatus(expr, status string) bool {
+	for _, cond := range strings.Split(expr, ",") {
+		field, op, value, found := splitCondition(strings.TrimSpace(cond))
+		if found && field == "status" && op == "=" && strings.EqualFold(value, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEquality builds a predicate comparing a string field with = or !=
+func parseEquality(field, op, value string) (Predicate, error) {
+	if op != "=" && op != "!=" {
+		return nil, fmt.Errorf("operator %s is not supported for %s (use = or !=)", op, field)
+	}
+
+	var get func(c *database.Container) string
+	switch field {
+	case "type":
+		normalized, err := types.NormalizeDBType(value)
+		if err != nil {
+			return nil, err
+		}
+		value = normalized
+		get = func(c *database.Container) string {
+			if normalized, err := types.NormalizeDBType(c.Type); err == nil {
+				return normalized
+			}
+			return c.Type
+		}
+	case "status":
+		if strings.ToLower(value) == "removed" {
+			value = "removed"
+		} else {
+			normalized, err := types.NormalizeStatus(value)
+			if err != nil {
+				return nil, err
+			}
+			value = normalized
+		}
+		get = effectiveStatus
+	case "name":
+		get = func(c *database.Container) string { return c.DisplayName }
+	case "port":
+		get = func(c *database.Container) string { return c.Port }
+	case "version":
+		get = func(c *database.Container) string { return c.Version }
+	default:
+		return nil, fmt.Errorf("unknown filter field: %s (valid fields: type, status, name, port, version, expires)", field)
+	}
+
+	if op == "!=" {
+		return func(c *database.Container) bool { return get(c) != value }, nil
+	}
+	return func(c *database.Container) bool { return get(c) == value }, nil
+}
+
+// parseExpires builds a predicate comparing the time remaining before expiration
+func parseExpires(op, value string) (Predicate, error) {
+	if op != "<" && op != ">" {
+		return nil, fmt.Errorf("operator %s is not supported for expires (use < or >)", op)
+	}
+
+	d, err := parseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "<" {
+		return func(c *database.Container) bool { return c.ExpiresAt.Sub(now()) < d }, nil
+	}
+	return func(c *database.Container) bool { return c.ExpiresAt.Sub(now()) > d }, nil
+}
+
+// parseDuration parses a Go duration, additionally accepting a "d" suffix for days
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return d, nil
+}
+
+// ParseTime parses a point in time given either as a duration before now, such as 24h or 7d,
+// or as an RFC3339 timestamp or 2006-01-02 date in local time
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	d, err := parseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (expected a duration like 24h or 7d, or a date like 2006-01-02)", value)
+	}
+	if d < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q (duration must not be negative)", value)
+	}
+	return now().Add(-d), nil
+}
+
+// CreatedBetween matches containers created at or after since and before until
+// A zero since or until leaves that end of the range open
+func CreatedBetween(since, until time.Time) Predicate {
+	return func(c *database.Container) bool {
+		if !since.IsZero() && c.CreatedAt.Before(since) {
+			return false
+		}
+		if !until.IsZero() && !c.CreatedAt.Before(until) {
+			return false
+		}
+		return true
+	}
+}
+
+// effectiveStatus returns the container's status, treating past-TTL containers as expired
+func effectiveStatus(c *database.Container) string {
+	if c.Status == "removed" || c.Status == types.StatusStopped {
+		return c.Status
+	}
+	if now().After(c.ExpiresAt) {
+		return types.StatusExpired
+	}
+	return c.Status
+}
@@ -0,0 +1,199 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+func testContainers(base time.Time) []*database.Container {
+	return []*database.Container{
+		{DisplayName: "pg-soon", Type: "postgres", Version: "16", Port: "5432", Status: "running", ExpiresAt: base.Add(1 * time.Hour)},
+		{DisplayName: "pg-later", Type: "postgres", Version: "18", Port: "5433", Status: "running", ExpiresAt: base.Add(48 * time.Hour)},
+		{DisplayName: "cache", Type: "redis", Version: "8", Port: "6379", Status: "stopped", ExpiresAt: base.Add(3 * time.Hour)},
+		{DisplayName: "old", Type: "mysql", Version: "latest", Port: "3306", Status: "running", ExpiresAt: base.Add(-1 * time.Hour)},
+	}
+}
+
+func matchNames(t *testing.T, expr string, containers []*database.Container) []string {
+	t.Helper()
+
+	p, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", expr, err)
+	}
+
+	var names []string
+	for _, c := range containers {
+		if p(c) {
+			names = append(names, c.DisplayName)
+		}
+	}
+	return names
+}
+
+func TestParse(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return base }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{"type equals", "type=postgres", []string{"pg-soon", "pg-later"}},
+		{"type alias", "type=pg", []string{"pg-soon", "pg-later"}},
+		{"type not equals", "type!=postgres", []string{"cache", "old"}},
+		{"status equals", "status=running", []string{"pg-soon", "pg-later"}},
+		{"status alias", "status=down", []string{"cache"}},
+		{"status expired", "status=expired", []string{"old"}},
+		{"status not equals", "status!=running", []string{"cache", "old"}},
+		{"name equals", "name=cache", []string{"cache"}},
+		{"port equals", "port=5433", []string{"pg-later"}},
+		{"version not equals", "version!=latest", []string{"pg-soon", "pg-later", "cache"}},
+		{"expires less than", "expires<2h", []string{"pg-soon", "old"}},
+		{"expires greater than", "expires>2h", []string{"pg-later", "cache"}},
+		{"expires in days", "expires>1d", []string{"pg-later"}},
+		{"combined", "type=postgres,status=running,expires<2h", []string{"pg-soon"}},
+		{"combined with spaces", " type = postgres , expires > 2h ", []string{"pg-later"}},
+		{"combined no matches", "type=redis,status=running", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchNames(t, tt.expr, testContainers(base))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse(%q) matched %v, want %v", tt.expr, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Parse(%q) matched %v, want %v", tt.expr, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"only commas", ",,"},
+		{"no operator", "type"},
+		{"missing value", "type="},
+		{"missing field", "=postgres"},
+		{"unknown field", "owner=me"},
+		{"invalid type", "type=oracle"},
+		{"invalid status", "status=sleeping"},
+		{"ordering on string field", "type<postgres"},
+		{"equality on expires", "expires=2h"},
+		{"invalid duration", "expires<soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) expected error, got nil", tt.expr)
+			}
+		})
+	}
+}
+
+func TestHasStatus(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status=removed", true},
+		{"type=postgres, STATUS = Removed", true},
+		{"status!=removed", false},
+		{"name=removed", false},
+		{"name=removed-db,status=running", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := HasStatus(tt.expr, "removed"); got != tt.want {
+			t.Errorf("HasStatus(%q, removed) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return base }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"hours", "24h", base.Add(-24 * time.Hour)},
+		{"days", "7d", base.Add(-7 * 24 * time.Hour)},
+		{"RFC3339", "2025-01-05T08:30:00Z", time.Date(2025, 1, 5, 8, 30, 0, 0, time.UTC)},
+		{"date", "2025-01-05", time.Date(2025, 1, 5, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value)
+			if err != nil {
+				t.Fatalf("ParseTime(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "yesterday", "-2h", "2025-13-01"} {
+		if _, err := ParseTime(value); err == nil {
+			t.Errorf("ParseTime(%q) expected error, got nil", value)
+		}
+	}
+}
+
+func TestCreatedBetween(t *testing.T) {
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	containers := []*database.Container{
+		{DisplayName: "old", CreatedAt: base.Add(-72 * time.Hour)},
+		{DisplayName: "yesterday", CreatedAt: base.Add(-24 * time.Hour)},
+		{DisplayName: "recent", CreatedAt: base.Add(-1 * time.Hour)},
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		until time.Time
+		want  []string
+	}{
+		{"unbounded", time.Time{}, time.Time{}, []string{"old", "yesterday", "recent"}},
+		{"since", base.Add(-48 * time.Hour), time.Time{}, []string{"yesterday", "recent"}},
+		{"until", time.Time{}, base.Add(-12 * time.Hour), []string{"old", "yesterday"}},
+		{"since is inclusive", base.Add(-24 * time.Hour), time.Time{}, []string{"yesterday", "recent"}},
+		{"until is exclusive", time.Time{}, base.Add(-24 * time.Hour), []string{"old"}},
+		{"range", base.Add(-48 * time.Hour), base.Add(-12 * time.Hour), []string{"yesterday"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := CreatedBetween(tt.since, tt.until)
+			var got []string
+			for _, c := range containers {
+				if p(c) {
+					got = append(got, c.DisplayName)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CreatedBetween() matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
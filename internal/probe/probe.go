@@ -0,0 +1,65 @@
+package probe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+)
+
+// Result is the outcome of a connectivity check against a database container
+type Result struct {
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	OK          bool               `json:"ok"`
+	LatencyMS   int64              `json:"latency_ms"`
+	Detail      string             `json:"detail"`
+	Connections *adapters.ConnInfo `json:"connections,omitempty"`
+}
+
+// Command returns the command that runs a trivial query inside the container
+// Pass empty strings for username and password for unauthenticated databases
+func Command(dbType, dbName, username, password string) ([]string, error) {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return nil, err
+	}
+	return adapter.GetPingCommand(username, password, dbName), nil
+}
+
+// ConnectionInfo runs the adapter's connection report through exec and parses its output
+// exec runs a command inside the container and returns its output
+func ConnectionInfo(dbType, adminPassword string, exec func(cmd []string) (string, error)) (adapters.ConnInfo, error) {
+	adapter, err := adapters.GetRegistry().Get(dbType)
+	if err != nil {
+		return adapters.ConnInfo{}, err
+	}
+
+	cmd := adapter.GetConnectionInfoCommand(adminPassword)
+	if cmd == nil {
+		return adapters.ConnInfo{}, fmt.Errorf("connection reporting is not supported for %s", dbType)
+	}
+
+	output, err := exec(cmd)
+	if err != nil {
+		return adapters.ConnInfo{}, err
+	}
+	return adapter.ParseConnectionInfo(output), nil
+}
+
+// NewResult assembles a Result from a probe's output, duration, and error
+// On failure, Detail carries the error instead of the command output
+func NewResult(name, dbType string, latency time.Duration, output string, err error) Result {
+	result := Result{
+		Name:      name,
+		Type:      dbType,
+		OK:        err == nil,
+		LatencyMS: latency.Milliseconds(),
+		Detail:    strings.TrimSpace(output),
+	}
+	if err != nil {
+		result.Detail = err.Error()
+	}
+	return result
+}
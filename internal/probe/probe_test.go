@@ -0,0 +1,148 @@
+package probe
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		latency time.Duration
+		output  string
+		err     error
+		want    Result
+	}{
+		{
+			name:    "Success",
+			latency: 42 * time.Millisecond,
+			output:  "PONG\n",
+			want:    Result{Name: "cache", Type: "redis", OK: true, LatencyMS: 42, Detail: "PONG"},
+		},
+		{
+			name:    "Failure carries the error",
+			latency: 1500 * time.Millisecond,
+			output:  "partial output",
+			err:     errors.New("command exited with code 2"),
+			want:    Result{Name: "cache", Type: "redis", OK: false, LatencyMS: 1500, Detail: "command exited with code 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewResult("cache", "redis", tt.latency, tt.output, tt.err)
+			if got != tt.want {
+				t.Errorf("NewResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	result := NewResult("mydb", "postgres", 12*time.Millisecond, "", errors.New("connection refused"))
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{"name":"mydb","type":"postgres","ok":false,"latency_ms":12,"detail":"connection refused"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		username string
+		password string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "Postgres",
+			dbType:   "postgres",
+			username: "dbuser",
+			password: "secret",
+			want: []string{"sh", "-c", `PGPASSWORD="$1" exec psql -h "$(hostname -i | cut -d ' ' -f 1)" -U "$0" -d "$2" -c "$3"`,
+				"dbuser", "secret", "mydb", "SELECT 1 as status, current_user, current_database();"},
+		},
+		{
+			name:   "Postgres unauthenticated",
+			dbType: "postgres",
+			want:   []string{"psql", "-U", "postgres", "-d", "mydb", "-c", "SELECT 1 as status, current_user, current_database();"},
+		},
+		{
+			name:     "MySQL",
+			dbType:   "mysql",
+			username: "dbuser",
+			password: "secret",
+			want:     []string{"mysql", "-u", "dbuser", "-psecret", "mydb", "-e", "SELECT 1 as status, USER() as user, DATABASE() as db;"},
+		},
+		{
+			name:   "MySQL unauthenticated",
+			dbType: "mysql",
+			want:   []string{"mysql", "-u", "root", "mydb", "-e", "SELECT 1 as status, USER() as user, DATABASE() as db;"},
+		},
+		{
+			name:     "Redis with password",
+			dbType:   "redis",
+			password: "secret",
+			want:     []string{"redis-cli", "-e", "--no-auth-warning", "-a", "secret", "PING"},
+		},
+		{
+			name:   "Redis unauthenticated",
+			dbType: "redis",
+			want:   []string{"redis-cli", "-e", "PING"},
+		},
+		{
+			name:    "Unsupported type",
+			dbType:  "oracle",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Command(tt.dbType, "mydb", tt.username, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Command() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionInfo(t *testing.T) {
+	var ran []string
+	exec := func(cmd []string) (string, error) {
+		ran = cmd
+		return "# Clients\r\nconnected_clients:2\r\nmaxclients:10000\r\n", nil
+	}
+
+	info, err := ConnectionInfo("redis", "secret", exec)
+	if err != nil {
+		t.Fatalf("ConnectionInfo() error = %v", err)
+	}
+	if info.Current != 2 || info.Max != 10000 {
+		t.Errorf("ConnectionInfo() = %+v, want 2 of 10000", info)
+	}
+	if !slices.Equal(ran, []string{"redis-cli", "-e", "--no-auth-warning", "-a", "secret", "INFO", "clients"}) {
+		t.Errorf("ConnectionInfo() ran %q, want INFO clients", ran)
+	}
+
+	failing := func(cmd []string) (string, error) { return "", errors.New("exec failed") }
+	if _, err := ConnectionInfo("postgres", "", failing); err == nil {
+		t.Error("ConnectionInfo() expected error when the command fails")
+	}
+	if _, err := ConnectionInfo("mongo", "", exec); err == nil {
+		t.Error("ConnectionInfo() expected error for an unknown type")
+	}
+}
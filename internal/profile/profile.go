@@ -0,0 +1,149 @@
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StartSpec describes one database to create, with the same meaning as the matching 'mkdb start' flags
+type StartSpec struct {
+	Type     string   `yaml:"type"`
+	Name     string   `yaml:"name"`
+	Version  string   `yaml:"version,omitempty"`
+	Port     string   `yaml:"port,omitempty"`
+	TTLHours int      `yaml:"ttl,omitempty"`
+	Volume   string   `yaml:"volume,omitempty"`
+	Env      []string `yaml:"env,omitempty"`
+	Args     []string `yaml:"args,omitempty"`
+	Username string   `yaml:"username,omitempty"`
+	NoAuth   bool     `yaml:"no_auth,omitempty"`
+	Seed     []string `yaml:"seed,omitempty"`
+
+	// DependsOn names databases in the same profile that have to be ready before this one is created
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+// file is the layout of a profile document
+type file struct {
+	Databases []StartSpec `yaml:"databases"`
+}
+
+// Load reads a YAML or JSON profile and returns its databases in the order they should be created
+func Load(path string) ([]StartSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	specs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return specs, nil
+}
+
+// Parse decodes and validates a profile, JSON is accepted since it is valid YAML
+// Databases are returned in the order they are listed, except that each comes after the ones it depends on
+func Parse(data []byte) ([]StartSpec, error) {
+	var f file
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	if len(f.Databases) == 0 {
+		return nil, fmt.Errorf("profile has no databases")
+	}
+
+	seen := make(map[string]bool)
+	for i, spec := range f.Databases {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("database %d has no name", i+1)
+		}
+		if spec.Type == "" {
+			return nil, fmt.Errorf("database '%s' has no type", spec.Name)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("database '%s' is listed more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+		if spec.TTLHours < 0 {
+			return nil, fmt.Errorf("database '%s' has a negative ttl", spec.Name)
+		}
+	}
+	for _, spec := range f.Databases {
+		for _, dep := range spec.DependsOn {
+			if !seen[dep] {
+				return nil, fmt.Errorf("database '%s' depends on '%s', which isn't in the profile", spec.Name, dep)
+			}
+		}
+	}
+
+	return Order(f.Databases)
+}
+
+// Order sorts specs so that every database comes after the ones it depends on, keeping the listed
+// order otherwise. Returns an error naming the databases involved if the dependencies form a cycle
+func Order(specs []StartSpec) ([]StartSpec, error) {
+	byName := make(map[string]StartSpec, len(specs))
+	for _, spec := range specs {
+		byName[spec.Name] = spec
+	}
+
+	ordered := make([]StartSpec, 0, len(specs))
+	done := make(map[string]bool, len(specs))
+	for len(ordered) < len(specs) {
+		// Take the first listed database whose dependencies are all placed
+		next := -1
+		for i, spec := range specs {
+			if !done[spec.Name] && !slices.ContainsFunc(spec.DependsOn, func(dep string) bool { return !done[dep] }) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("databases depend on each other in a cycle: %s", strings.Join(findCycle(specs, byName, done), " -> "))
+		}
+		done[specs[next].Name] = true
+		ordered = append(ordered, specs[next])
+	}
+	return ordered, nil
+}
+
+// findCycle follows unplaced dependencies from the first unplaced database until a name repeats,
+// returning the cycle with its first name repeated at the end
+func findCycle(specs []StartSpec, byName map[string]StartSpec, done map[string]bool) []string {
+	var start string
+	for _, spec := range specs {
+		if !done[spec.Name] {
+			start = spec.Name
+			break
+		}
+	}
+
+	var path []string
+	visited := make(map[string]int)
+	for name := start; ; {
+		if i, ok := visited[name]; ok {
+			return append(path[i:], name)
+		}
+		visited[name] = len(path)
+		path = append(path, name)
+
+		// Every unplaced database has an unplaced dependency, otherwise Order would have placed it
+		for _, dep := range byName[name].DependsOn {
+			if !done[dep] {
+				name = dep
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	data := []byte(`
+databases:
+  - type: postgres
+    name: app
+    version: "16"
+    ttl: 8
+    volume: named
+    env:
+      - POSTGRES_INITDB_ARGS=--data-checksums
+  - type: redis
+    name: cache
+    no_auth: true
+`)
+
+	specs, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []StartSpec{
+		{Type: "postgres", Name: "app", Version: "16", TTLHours: 8, Volume: "named", Env: []string{"POSTGRES_INITDB_ARGS=--data-checksums"}},
+		{Type: "redis", Name: "cache", NoAuth: true},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("Parse() = %+v, want %+v", specs, want)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := []byte(`{"databases": [{"type": "mysql", "name": "shop", "port": "3307", "seed": ["schema.sql"]}]}`)
+
+	specs, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []StartSpec{{Type: "mysql", Name: "shop", Port: "3307", Seed: []string{"schema.sql"}}}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("Parse() = %+v, want %+v", specs, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "no databases"},
+		{"no databases", "databases: []", "no databases"},
+		{"missing name", "databases:\n  - type: postgres", "has no name"},
+		{"missing type", "databases:\n  - name: app", "has no type"},
+		{"duplicate", "databases:\n  - {type: postgres, name: app}\n  - {type: redis, name: app}", "more than once"},
+		{"negative ttl", "databases:\n  - {type: postgres, name: app, ttl: -1}", "negative ttl"},
+		{"unknown field", "databases:\n  - {type: postgres, name: app, colour: red}", "colour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	if err := os.WriteFile(path, []byte("databases:\n  - {type: postgres, name: app}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	specs, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(specs) != 1 || specs[0].Name != "app" {
+		t.Errorf("Load() = %+v, want one database named app", specs)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file should fail")
+	}
+}
+
+func TestParseDependsOn(t *testing.T) {
+	data := []byte(`
+databases:
+  - {type: postgres, name: reports, depends_on: [app, cache]}
+  - {type: redis, name: cache}
+  - {type: postgres, name: app, depends_on: [cache]}
+  - {type: mysql, name: shop}
+`)
+
+	specs, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	if want := []string{"cache", "app", "reports", "shop"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Parse() order = %v, want %v", names, want)
+	}
+}
+
+func TestParseDependsOnErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown", "databases:\n  - {type: postgres, name: app, depends_on: [cache]}", "depends on 'cache', which isn't in the profile"},
+		{"self", "databases:\n  - {type: postgres, name: app, depends_on: [app]}", "cycle: app -> app"},
+		{"cycle", "databases:\n  - {type: postgres, name: shop}\n  - {type: postgres, name: a, depends_on: [b]}\n  - {type: postgres, name: b, depends_on: [c]}\n  - {type: postgres, name: c, depends_on: [a, shop]}", "cycle: a -> b -> c -> a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
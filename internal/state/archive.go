@@ -0,0 +1,200 @@
+package state
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pbzona/mkdb/internal/config"
+)
+
+// writeArchive writes doc and the named volume directories as a gzipped tar
+// Volumes without a directory are left out, the names of the ones written are returned
+func writeArchive(w io.Writer, doc *document, volumeNames []string) ([]string, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+	hdr := &tar.Header{Name: stateFileName, Mode: 0600, Size: int64(len(data)), ModTime: doc.CreatedAt}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	var written []string
+	for _, name := range volumeNames {
+		dir := filepath.Join(config.VolumesDir, name)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := addVolume(tw, dir, path.Join(strings.TrimSuffix(volumesPrefix, "/"), name)); err != nil {
+			return nil, fmt.Errorf("failed to archive volume %s: %w", name, err)
+		}
+		written = append(written, name)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return written, nil
+}
+
+// addVolume adds the files, directories and symlinks under dir to tw, named under prefix
+func addVolume(tw *tar.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files written by the database often belong to its user in the container
+			return fmt.Errorf("%w (files owned by the database's user may need to be readable by you)", err)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil // Sockets and the like are recreated by the database
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// extractVolumes writes the archived volumes named in extract into volumesDir, skipping the rest
+// Each volume is written through an os.Root, so entries can't reach outside their volume's
+// directory, whether by path or through a symlink extracted earlier
+func extractVolumes(tr *tar.Reader, volumesDir string, extract map[string]bool) ([]string, error) {
+	roots := make(map[string]*os.Root)
+	defer func() {
+		for _, root := range roots {
+			root.Close()
+		}
+	}()
+	var extracted []string
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return extracted, nil
+		}
+		if err != nil {
+			return extracted, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		rest, ok := strings.CutPrefix(hdr.Name, volumesPrefix)
+		if !ok {
+			continue
+		}
+		name, rel, _ := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+		if !extract[name] {
+			continue
+		}
+		if rel == "" {
+			rel = "."
+		}
+		if !filepath.IsLocal(name) || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return extracted, fmt.Errorf("archive entry %s is outside its volume", hdr.Name)
+		}
+
+		root, ok := roots[name]
+		if !ok {
+			dir := filepath.Join(volumesDir, name)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return extracted, fmt.Errorf("failed to extract volume %s: %w", name, err)
+			}
+			if root, err = os.OpenRoot(dir); err != nil {
+				return extracted, fmt.Errorf("failed to extract volume %s: %w", name, err)
+			}
+			roots[name] = root
+			extracted = append(extracted, name)
+		}
+
+		if err := extractEntry(root, tr, hdr, filepath.FromSlash(rel)); err != nil {
+			return extracted, fmt.Errorf("failed to extract archive entry %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// extractEntry writes a single archive entry to target, relative to root
+// Existing symlinks at target are never followed, an entry can't write through one
+func extractEntry(root *os.Root, tr *tar.Reader, hdr *tar.Header, target string) error {
+	mode := hdr.FileInfo().Mode().Perm()
+
+	if target != "." {
+		if info, err := root.Lstat(target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", target)
+		}
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := root.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		return root.Chmod(target, mode)
+	case tar.TypeReg:
+		if err := root.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		f, err := root.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	case tar.TypeSymlink:
+		if err := root.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return root.Symlink(hdr.Linkname, target)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,363 @@
+package state
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+// FormatVersion is written to every archive, archives from a newer mkdb are refused
+const FormatVersion = 1
+
+// stateFileName is the first entry of an archive, volume directories follow under volumesPrefix
+const (
+	stateFileName = "state.json"
+	volumesPrefix = "volumes/"
+)
+
+// keyIterations is the PBKDF2 work factor for the passphrase, following OWASP's advice for SHA-256
+const keyIterations = 600_000
+
+// checkValue is encrypted into every archive, so a wrong passphrase is caught before anything is imported
+const checkValue = "mkdb"
+
+// document is the contents of state.json
+// Password hashes and environment variables in it are encrypted with the passphrase key instead of the machine's key
+type document struct {
+	Version      int                  `json:"version"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Salt         []byte               `json:"salt"`
+	Iterations   int                  `json:"iterations"`
+	Check        string               `json:"check"`
+	WithVolumes  bool                 `json:"with_volumes"`
+	Containers   []containerState     `json:"containers"`
+	LastSettings *config.LastSettings `json:"last_settings,omitempty"`
+}
+
+// containerState is a container record with everything that belongs to it
+type containerState struct {
+	Container *database.Container         `json:"container"`
+	Users     []*database.User            `json:"users"`
+	Databases []*database.LogicalDatabase `json:"databases,omitempty"`
+	Events    []*database.Event           `json:"events,omitempty"`
+}
+
+// ExportSummary describes what an export wrote
+type ExportSummary struct {
+	Containers []string
+	Volumes    []string
+	Skipped    []string // Containers that aren't exported, with the reason
+}
+
+// ImportSummary describes what an import restored
+type ImportSummary struct {
+	Containers []string
+	Volumes    []string
+	Skipped    []string // Containers and volumes that were left alone, with the reason
+}
+
+// Export writes containers, their users, logical databases and events, and the last used settings
+// to an archive at path. Passwords are re-encrypted with a key derived from passphrase.
+// With withVolumes, the directories of named volumes are included too
+func Export(path, passphrase string, withVolumes bool) (*ExportSummary, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	sealer, err := passphraseStore(passphrase, salt, keyIterations)
+	if err != nil {
+		return nil, err
+	}
+	check, err := sealer.Encrypt(checkValue)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{
+		Version:     FormatVersion,
+		CreatedAt:   time.Now().UTC(),
+		Salt:        salt,
+		Iterations:  keyIterations,
+		Check:       check,
+		WithVolumes: withVolumes,
+	}
+	summary := &ExportSummary{}
+
+	containers, err := database.ListAllContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	var volumeNames []string
+	for _, c := range containers {
+		switch {
+		case c.Status == "removed":
+			continue
+		case c.Adopted:
+			// mkdb didn't create the container, so it couldn't be recreated from its record
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s (adopted)", c.DisplayName))
+			continue
+		}
+
+		state, err := exportContainer(c, sealer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export '%s': %w", c.DisplayName, err)
+		}
+		doc.Containers = append(doc.Containers, *state)
+		summary.Containers = append(summary.Containers, c.DisplayName)
+
+		if !withVolumes {
+			continue
+		}
+		if c.VolumeType == "named" && c.VolumePath != "" {
+			volumeNames = append(volumeNames, c.VolumePath)
+		} else if c.VolumeType == "bind" {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s volume (bind mount %s)", c.DisplayName, c.VolumePath))
+		} else if c.VolumeType == "docker" {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s volume (Docker volume %s)", c.DisplayName, c.VolumePath))
+		}
+	}
+
+	doc.LastSettings, err = config.LoadLastSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	// Write next to the destination and rename, so a failed export doesn't leave a partial archive
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".mkdb-state-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	volumes, err := writeArchive(tmp, doc, volumeNames)
+	if err != nil {
+		return nil, err
+	}
+	summary.Volumes = volumes
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return summary, nil
+}
+
+// exportContainer collects a container's records, re-encrypting its passwords with sealer
+// Environment variables often hold secrets too, so each is encrypted with sealer
+func exportContainer(c *database.Container, sealer config.Store) (*containerState, error) {
+	var err error
+	exported := *c
+	if c.AdminPasswordHash != "" {
+		if exported.AdminPasswordHash, err = reseal(c.AdminPasswordHash, config.ActiveStore(), sealer); err != nil {
+			return nil, fmt.Errorf("failed to encrypt admin password: %w", err)
+		}
+	}
+	exported.ExtraEnv = make([]string, len(c.ExtraEnv))
+	for i, env := range c.ExtraEnv {
+		if exported.ExtraEnv[i], err = sealer.Encrypt(env); err != nil {
+			return nil, fmt.Errorf("failed to encrypt environment: %w", err)
+		}
+	}
+	state := &containerState{Container: &exported}
+
+	users, err := database.ListUsers(c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, u := range users {
+		if u.PasswordHash != "" {
+			if u.PasswordHash, err = reseal(u.PasswordHash, config.ActiveStore(), sealer); err != nil {
+				return nil, fmt.Errorf("failed to encrypt password of user '%s': %w", u.Username, err)
+			}
+		}
+		state.Users = append(state.Users, u)
+	}
+
+	if state.Databases, err = database.ListLogicalDatabases(c.ID); err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	if state.Events, err = database.ListEvents(c.ID); err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	return state, nil
+}
+
+// Import restores the containers in the archive at path that don't exist yet, and their volumes if
+// the archive has them and the volume directory doesn't exist. The containers are recorded as stopped,
+// so 'mkdb restart' or 'mkdb start --from-stopped' creates them in Docker
+func Import(path, passphrase string) (*ImportSummary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an mkdb state archive: %w", path, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	doc, err := readDocument(tr)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an mkdb state archive: %w", path, err)
+	}
+	if doc.Version > FormatVersion {
+		return nil, fmt.Errorf("archive format %d is newer than this mkdb supports (%d), upgrade mkdb first", doc.Version, FormatVersion)
+	}
+	sealer, err := passphraseStore(passphrase, doc.Salt, doc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if check, err := sealer.Decrypt(doc.Check); err != nil || check != checkValue {
+		return nil, fmt.Errorf("wrong passphrase")
+	}
+
+	summary := &ImportSummary{}
+	extract := make(map[string]bool)
+	for _, state := range doc.Containers {
+		c := state.Container
+		if _, err := database.GetContainerByDisplayName(c.DisplayName); err == nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s (already exists)", c.DisplayName))
+			continue
+		}
+		if err := importContainer(state, sealer); err != nil {
+			return summary, fmt.Errorf("failed to import '%s': %w", c.DisplayName, err)
+		}
+		summary.Containers = append(summary.Containers, c.DisplayName)
+
+		if !doc.WithVolumes || c.VolumeType != "named" || c.VolumePath == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(config.VolumesDir, c.VolumePath)); err == nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s volume (directory already exists)", c.DisplayName))
+			continue
+		}
+		extract[c.VolumePath] = true
+	}
+
+	if doc.LastSettings != nil {
+		if existing, _ := config.LoadLastSettings(); existing == nil {
+			if err := config.SaveLastSettings(doc.LastSettings); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	volumes, err := extractVolumes(tr, config.VolumesDir, extract)
+	summary.Volumes = volumes
+	if err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// importContainer records a container from an archive with its users, databases and events in one
+// transaction. Passwords are decrypted with sealer and encrypted again with this machine's key
+func importContainer(state containerState, sealer config.Store) error {
+	var err error
+	c := *state.Container
+	if c.AdminPasswordHash != "" {
+		if c.AdminPasswordHash, err = reseal(c.AdminPasswordHash, sealer, config.ActiveStore()); err != nil {
+			return fmt.Errorf("failed to decrypt admin password: %w", err)
+		}
+	}
+	c.ExtraEnv = make([]string, len(state.Container.ExtraEnv))
+	for i, env := range state.Container.ExtraEnv {
+		if c.ExtraEnv[i], err = sealer.Decrypt(env); err != nil {
+			return fmt.Errorf("failed to decrypt environment: %w", err)
+		}
+	}
+	for _, u := range state.Users {
+		if u.PasswordHash != "" {
+			if u.PasswordHash, err = reseal(u.PasswordHash, sealer, config.ActiveStore()); err != nil {
+				return fmt.Errorf("failed to decrypt password of user '%s': %w", u.Username, err)
+			}
+		}
+	}
+
+	// The Docker container doesn't exist on this machine, so it's recreated on the next restart
+	c.ID = 0
+	c.ContainerID = ""
+	if c.Status != "expired" {
+		c.Status = "stopped"
+	}
+	for _, u := range state.Users {
+		u.ID = 0
+	}
+	for _, d := range state.Databases {
+		d.ID = 0
+	}
+	for _, e := range state.Events {
+		e.ID = 0
+	}
+	events := append(state.Events, &database.Event{
+		EventType: "imported",
+		Timestamp: time.Now(),
+		Details:   "Imported from a state archive",
+	})
+	return database.CreateContainerRecords(&c, state.Users, state.Databases, events)
+}
+
+// passphraseStore derives an AES-256 key from passphrase
+func passphraseStore(passphrase string, salt []byte, iterations int) (config.Store, error) {
+	if len(salt) == 0 || iterations <= 0 {
+		return nil, fmt.Errorf("archive has no key parameters")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return config.NewAESStore("passphrase", key), nil
+}
+
+// reseal decrypts ciphertext with one store and encrypts it with another
+func reseal(ciphertext string, from, to config.Store) (string, error) {
+	if from == nil || to == nil {
+		return "", errors.New("credential store not initialized")
+	}
+	plaintext, err := from.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return to.Encrypt(plaintext)
+}
+
+// readDocument reads state.json, which has to be the archive's first entry
+func readDocument(tr *tar.Reader) (*document, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if hdr.Name != stateFileName {
+		return nil, fmt.Errorf("first entry is %s, want %s", hdr.Name, stateFileName)
+	}
+
+	var doc document
+	if err := json.NewDecoder(io.LimitReader(tr, hdr.Size)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", stateFileName, err)
+	}
+	return &doc, nil
+}
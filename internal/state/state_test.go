@@ -0,0 +1,294 @@
+package state
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+	"github.com/pbzona/mkdb/internal/database"
+)
+
+// setupMachine initializes config and an empty database in a fresh data directory, like a new machine
+func setupMachine(t *testing.T) {
+	t.Helper()
+	database.Close()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() error = %v", err)
+	}
+	if err := database.Initialize(); err != nil {
+		t.Fatalf("database.Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+}
+
+// seedContainer records a running postgres container with a user, a logical database, an event and a volume
+func seedContainer(t *testing.T, name string) {
+	t.Helper()
+	adminHash, err := config.Encrypt("admin-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &database.Container{
+		Name:              "mkdb-" + name,
+		DisplayName:       name,
+		Type:              "postgres",
+		Version:           "16",
+		ContainerID:       "abc123def456",
+		Port:              "5432",
+		Status:            "running",
+		CreatedAt:         time.Now(),
+		ExpiresAt:         time.Now().Add(24 * time.Hour),
+		VolumeType:        "named",
+		VolumePath:        name,
+		AdminPasswordHash: adminHash,
+		ExtraEnv:          []string{"API_TOKEN=env-secret"},
+	}
+	if err := database.CreateContainer(c); err != nil {
+		t.Fatal(err)
+	}
+
+	passwordHash, err := config.Encrypt("user-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateUser(&database.User{ContainerID: c.ID, Username: "app", PasswordHash: passwordHash, IsDefault: true, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateLogicalDatabase(&database.LogicalDatabase{ContainerID: c.ID, Name: "reports", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.CreateEvent(&database.Event{ContainerID: c.ID, EventType: "created", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	volume := filepath.Join(config.VolumesDir, name, "data")
+	if err := os.MkdirAll(volume, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(volume, "PG_VERSION"), []byte("16\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	setupMachine(t)
+	seedContainer(t, "mydb")
+	archive := filepath.Join(t.TempDir(), "state.tar.gz")
+
+	exported, err := Export(archive, "correct horse", true)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(exported.Containers) != 1 || len(exported.Volumes) != 1 {
+		t.Errorf("Export() = %+v, want one container and its volume", exported)
+	}
+	if info, err := os.Stat(archive); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("archive mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	if data := readArchive(t, archive); strings.Contains(data, "env-secret") || strings.Contains(data, "user-secret") {
+		t.Error("archive contains a secret in plaintext")
+	}
+
+	// Import on a machine with a different encryption key
+	setupMachine(t)
+	if _, err := Import(archive, "wrong"); err == nil {
+		t.Fatal("Import() with the wrong passphrase error = nil, want error")
+	}
+	imported, err := Import(archive, "correct horse")
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(imported.Containers) != 1 || len(imported.Volumes) != 1 {
+		t.Errorf("Import() = %+v, want one container and its volume", imported)
+	}
+
+	c, err := database.GetContainerByDisplayName("mydb")
+	if err != nil {
+		t.Fatalf("imported container not found: %v", err)
+	}
+	if c.Status != "stopped" || c.ContainerID != "" {
+		t.Errorf("imported container status = %q, id = %q, want stopped with no Docker ID", c.Status, c.ContainerID)
+	}
+	if admin, err := config.Decrypt(c.AdminPasswordHash); err != nil || admin != "admin-secret" {
+		t.Errorf("admin password = %q, %v, want admin-secret", admin, err)
+	}
+	if len(c.ExtraEnv) != 1 || c.ExtraEnv[0] != "API_TOKEN=env-secret" {
+		t.Errorf("imported environment = %v, want API_TOKEN=env-secret", c.ExtraEnv)
+	}
+
+	user, err := database.GetDefaultUser(c.ID)
+	if err != nil {
+		t.Fatalf("GetDefaultUser() error = %v", err)
+	}
+	if password, err := config.Decrypt(user.PasswordHash); err != nil || password != "user-secret" {
+		t.Errorf("user password = %q, %v, want user-secret", password, err)
+	}
+	if dbs, err := database.ListLogicalDatabases(c.ID); err != nil || len(dbs) != 1 || dbs[0].Name != "reports" {
+		t.Errorf("ListLogicalDatabases() = %v, %v, want reports", dbs, err)
+	}
+	events, err := database.ListEvents(c.ID)
+	if err != nil || len(events) != 2 || events[1].EventType != "imported" {
+		t.Errorf("ListEvents() = %v, %v, want created and imported", events, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.VolumesDir, "mydb", "data", "PG_VERSION"))
+	if err != nil || string(data) != "16\n" {
+		t.Errorf("restored volume file = %q, %v", data, err)
+	}
+
+	// Importing again leaves the existing container alone
+	again, err := Import(archive, "correct horse")
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if len(again.Containers) != 0 || len(again.Skipped) != 1 {
+		t.Errorf("second Import() = %+v, want the container skipped", again)
+	}
+}
+
+func TestExportWithoutVolumes(t *testing.T) {
+	setupMachine(t)
+	seedContainer(t, "mydb")
+	archive := filepath.Join(t.TempDir(), "state.tar.gz")
+
+	if _, err := Export(archive, "passphrase", false); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	setupMachine(t)
+	imported, err := Import(archive, "passphrase")
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(imported.Volumes) != 0 {
+		t.Errorf("Import() volumes = %v, want none", imported.Volumes)
+	}
+	if _, err := os.Stat(filepath.Join(config.VolumesDir, "mydb")); !os.IsNotExist(err) {
+		t.Errorf("volume directory exists after importing without volumes, Stat() error = %v", err)
+	}
+}
+
+func TestExtractVolumes_Unsafe(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{"Parent directory", []tar.Header{{Name: "volumes/mydb/../../escape", Typeflag: tar.TypeReg}}},
+		{"Through a symlink", []tar.Header{
+			{Name: "volumes/mydb/link", Typeflag: tar.TypeSymlink, Linkname: "/tmp"},
+			{Name: "volumes/mydb/link/escape", Typeflag: tar.TypeReg},
+		}},
+		{"Directory over a symlink", []tar.Header{
+			{Name: "volumes/mydb/link", Typeflag: tar.TypeSymlink, Linkname: "/tmp"},
+			{Name: "volumes/mydb/link/", Typeflag: tar.TypeDir},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			for _, hdr := range tt.entries {
+				hdr.Mode = 0644
+				if err := tw.WriteHeader(&hdr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tw.Close()
+			gz.Close()
+
+			gr, err := gzip.NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = extractVolumes(tar.NewReader(gr), t.TempDir(), map[string]bool{"mydb": true})
+			if err == nil || !strings.Contains(err.Error(), "volumes/mydb/") {
+				t.Errorf("extractVolumes() error = %v, want the unsafe entry refused", err)
+			}
+		})
+	}
+}
+
+func TestExtractVolumes_FileOverSymlink(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "victim")
+	if err := os.WriteFile(outside, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("overwritten")
+	for _, hdr := range []*tar.Header{
+		{Name: "volumes/mydb/link", Typeflag: tar.TypeSymlink, Linkname: outside, Mode: 0777},
+		{Name: "volumes/mydb/link", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			tw.Write(content)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractVolumes(tar.NewReader(gr), t.TempDir(), map[string]bool{"mydb": true}); err == nil {
+		t.Error("extractVolumes() error = nil, want the file over a symlink refused")
+	}
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "original" {
+		t.Errorf("file outside the volume = %q, %v, want it untouched", data, err)
+	}
+}
+
+// readArchive returns the uncompressed contents of an archive
+func readArchive(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestImportRollsBack(t *testing.T) {
+	setupMachine(t)
+	sealer, err := passphraseStore("passphrase", []byte("salt"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := containerState{
+		Container: &database.Container{Name: "mkdb-mydb", DisplayName: "mydb", Type: "postgres", Port: "5432", CreatedAt: time.Now(), ExpiresAt: time.Now()},
+		// The second user breaks the unique constraint on usernames
+		Users: []*database.User{{Username: "app", CreatedAt: time.Now()}, {Username: "app", CreatedAt: time.Now()}},
+	}
+
+	if err := importContainer(state, sealer); err == nil {
+		t.Fatal("importContainer() error = nil, want the duplicate user refused")
+	}
+	if all, err := database.ListAllContainers(); err != nil || len(all) != 0 {
+		t.Errorf("ListAllContainers() after a failed import = %v, %v, want nothing recorded", all, err)
+	}
+}
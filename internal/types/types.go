@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/pbzona/mkdb/internal/adapters"
@@ -20,6 +21,7 @@ const (
 	VolumeTypeNamed  = "named"
 	VolumeTypeBind   = "bind"
 	VolumeTypeCustom = "custom path"
+	VolumeTypeDocker = "docker" // A volume managed by Docker, rather than a directory on the host
 )
 
 // Container statuses
@@ -27,8 +29,16 @@ const (
 	StatusRunning = "running"
 	StatusStopped = "stopped"
 	StatusExpired = "expired"
+	StatusRemoved = "removed"
 )
 
+// MaxDBNameLength keeps names within Postgres' 63 byte identifier limit, the shortest of the engines
+const MaxDBNameLength = 63
+
+// dbNamePattern is used for container names, volume directories and the initial database,
+// so it sticks to characters that are safe in all of them
+var dbNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
 var (
 	// ValidVolumeTypes is a list of all valid volume types
 	ValidVolumeTypes = []string{VolumeTypeNone, VolumeTypeNamed, VolumeTypeCustom}
@@ -68,6 +78,20 @@ func NormalizeDBType(dbType string) (string, error) {
 	return canonical, nil
 }
 
+// ValidateDBName checks that name can be used for a database's container, volume and initial database
+func ValidateDBName(name string) error {
+	if name == "" {
+		return fmt.Errorf("database name cannot be empty")
+	}
+	if len(name) > MaxDBNameLength {
+		return fmt.Errorf("invalid database name '%s' (must be at most %d characters)", name, MaxDBNameLength)
+	}
+	if !dbNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid database name '%s' (must start with a letter and contain only letters, digits, hyphens and underscores)", name)
+	}
+	return nil
+}
+
 // NormalizeStatus normalizes a status string to canonical form
 func NormalizeStatus(status string) (string, error) {
 	normalized := strings.ToLower(strings.TrimSpace(status))
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDBName(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbName  string
+		wantErr bool
+	}{
+		{"Simple", "mydb", false},
+		{"Hyphen and underscore", "my-app_db", false},
+		{"Digits after first letter", "app2", false},
+		{"Maximum length", "a" + strings.Repeat("b", MaxDBNameLength-1), false},
+		{"Empty", "", true},
+		{"Leading digit", "2fast", true},
+		{"Leading hyphen", "-db", true},
+		{"Space", "my db", true},
+		{"Emoji", "db🚀", true},
+		{"Dot", "my.db", true},
+		{"Slash", "../db", true},
+		{"Too long", "a" + strings.Repeat("b", MaxDBNameLength), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDBName(tt.dbName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDBName(%q) error = %v, wantErr %v", tt.dbName, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package ui
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/manifoldco/promptui"
+	"github.com/muesli/termenv"
+	"github.com/pbzona/mkdb/internal/adapters"
+	"github.com/pbzona/mkdb/internal/config"
 	"github.com/pbzona/mkdb/internal/database"
+	"github.com/pbzona/mkdb/internal/docker"
 	"github.com/pbzona/mkdb/internal/types"
+	"github.com/pbzona/mkdb/internal/volumes"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -24,41 +33,190 @@ var (
 			Padding(1, 2)
 )
 
+// Quiet suppresses decorative output so that stdout only carries essential values
+// Errors and warnings are still shown, on stderr
+var Quiet bool
+
+// AssumeYes answers every confirmation prompt with yes, for scripts that can't respond to prompts
+var AssumeYes bool
+
+// TimeZoneEnvVar selects the time zone times are displayed in when --tz isn't given
+const TimeZoneEnvVar = "MKDB_TZ"
+
+// TimeLayout is the layout for displayed times, which always include the zone
+const TimeLayout = "2006-01-02 15:04:05 MST"
+
+// location is the time zone times are displayed in
+var location = time.Local
+
+// SetTimeZone sets the time zone times are displayed in from an IANA name such as "Europe/Berlin"
+// An empty name falls back to MKDB_TZ, then the local time zone
+func SetTimeZone(name string) error {
+	source := "--tz"
+	if name == "" {
+		name, source = strings.TrimSpace(os.Getenv(TimeZoneEnvVar)), TimeZoneEnvVar
+	}
+	if name == "" {
+		location = time.Local
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid time zone %q from %s: %w", name, source, err)
+	}
+	location = loc
+	return nil
+}
+
+// Location returns the time zone times are displayed in
+func Location() *time.Location {
+	return location
+}
+
+// Output formats for commands that can print structured data
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// ValidateOutputFormat checks that format is a supported --output value
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputText, OutputJSON, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q (valid formats: %s, %s, %s)", format, OutputText, OutputJSON, OutputYAML)
+}
+
+// PrintStructured prints v to stdout as JSON or YAML, using the field names from its json and yaml tags
+// It prints even in quiet mode, since the output is what the command was run for
+func PrintStructured(format string, v any) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case OutputJSON:
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	case OutputYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err = encoder.Encode(v)
+		if err == nil {
+			err = encoder.Close()
+		}
+		data = buf.Bytes()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", format, err)
+	}
+
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// FormatTime formats a time for display in the selected time zone, e.g. "2026-10-16 17:30:00 PDT"
+func FormatTime(t time.Time) string {
+	return t.In(location).Format(TimeLayout)
+}
+
 // Success prints a success message
 func Success(message string) {
+	if Quiet {
+		return
+	}
 	fmt.Println(successStyle.Render("✓ " + message))
 }
 
 // Error prints an error message
 func Error(message string) {
+	if Quiet {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("✗ "+message))
+		return
+	}
 	fmt.Println(errorStyle.Render("✗ " + message))
 }
 
 // Warning prints a warning message
 func Warning(message string) {
+	if Quiet {
+		fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ "+message))
+		return
+	}
 	fmt.Println(warningStyle.Render("⚠ " + message))
 }
 
+// WarningStderr prints a warning to stderr, for warnings that come before a command's output,
+// which may be JSON or shell code that stdout has to hold on its own
+func WarningStderr(message string) {
+	fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ "+message))
+}
+
 // Info prints an info message
 func Info(message string) {
+	if Quiet {
+		return
+	}
 	fmt.Println(infoStyle.Render("ℹ " + message))
 }
 
 // Header prints a header
 func Header(message string) {
+	if Quiet {
+		return
+	}
 	fmt.Println(headerStyle.Render(message))
 }
 
-// Box prints text in a box
+// Box prints text in a box, or as plain text in quiet mode
 func Box(content string) {
+	if Quiet {
+		fmt.Println(content)
+		return
+	}
 	fmt.Println(boxStyle.Render(content))
 }
 
+// Newline prints a blank spacer line
+func Newline() {
+	if Quiet {
+		return
+	}
+	fmt.Println()
+}
+
+// SetColorMode forces colors on or off, or leaves terminal detection in place for auto
+func SetColorMode(mode string) {
+	switch mode {
+	case config.ColorAlways:
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	case config.ColorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
 // SelectDBType prompts the user to select a database type
-func SelectDBType() (string, error) {
+// The cursor starts on defaultType if it is a valid type
+func SelectDBType(defaultType string) (string, error) {
+	items := types.ValidDBTypes()
+	cursor := 0
+	for i, t := range items {
+		if t == defaultType {
+			cursor = i
+			break
+		}
+	}
+
 	prompt := promptui.Select{
-		Label: "Select database type",
-		Items: types.ValidDBTypes(),
+		Label:     "Select database type",
+		Items:     items,
+		CursorPos: cursor,
 		Templates: &promptui.SelectTemplates{
 			Label:    "{{ . }}",
 			Active:   "▸ {{ . | cyan }}",
@@ -143,6 +301,73 @@ func SelectUser(users []*database.User, label string) (*database.User, error) {
 	return users[idx], nil
 }
 
+// SelectDatabase prompts the user to select a logical database
+func SelectDatabase(databases []*database.LogicalDatabase, label string) (*database.LogicalDatabase, error) {
+	if len(databases) == 0 {
+		return nil, fmt.Errorf("no databases found")
+	}
+
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ . }}",
+		Active:   "▸ {{ .Name | cyan }}",
+		Inactive: "  {{ .Name }}",
+		Selected: "{{ .Name | green }}",
+	}
+
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     databases,
+		Templates: templates,
+		Keys: &promptui.SelectKeys{
+			Prev:     promptui.Key{Code: promptui.KeyPrev, Display: "↑"},
+			Next:     promptui.Key{Code: promptui.KeyNext, Display: "↓"},
+			PageUp:   promptui.Key{Code: 'k'},
+			PageDown: promptui.Key{Code: 'j'},
+		},
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return databases[idx], nil
+}
+
+// SelectSnapshot prompts the user to select a volume snapshot
+func SelectSnapshot(snapshots []*volumes.Snapshot, label string) (*volumes.Snapshot, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots found")
+	}
+
+	// Snapshot IDs are their creation time, so they're readable as-is
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ . }}",
+		Active:   "▸ {{ .ID | cyan }}",
+		Inactive: "  {{ .ID }}",
+		Selected: "{{ .ID | green }}",
+	}
+
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     snapshots,
+		Templates: templates,
+		Keys: &promptui.SelectKeys{
+			Prev:     promptui.Key{Code: promptui.KeyPrev, Display: "↑"},
+			Next:     promptui.Key{Code: promptui.KeyNext, Display: "↓"},
+			PageUp:   promptui.Key{Code: 'k'},
+			PageDown: promptui.Key{Code: 'j'},
+		},
+	}
+
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots[idx], nil
+}
+
 // PromptString prompts the user for a string input
 func PromptString(label string, defaultValue string) (string, error) {
 	prompt := promptui.Prompt{
@@ -153,8 +378,24 @@ func PromptString(label string, defaultValue string) (string, error) {
 	return prompt.Run()
 }
 
+// PromptSecret prompts for a value without echoing it, such as a passphrase
+func PromptSecret(label string) (string, error) {
+	prompt := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+	}
+
+	return prompt.Run()
+}
+
 // PromptConfirm prompts the user for confirmation
+// Returns true without prompting if AssumeYes is set
 func PromptConfirm(label string) (bool, error) {
+	if AssumeYes {
+		config.Logger.Info("Confirmed by --yes", "prompt", label)
+		return true, nil
+	}
+
 	prompt := promptui.Prompt{
 		Label:     label,
 		IsConfirm: true,
@@ -214,33 +455,104 @@ func FormatDuration(d time.Duration) string {
 
 // PrintContainerInfo prints detailed container information
 func PrintContainerInfo(c *database.Container) {
-	timeRemaining := time.Until(c.ExpiresAt)
+	Box(containerInfo(c))
+}
+
+// ResourceInfo is a container's formatted resource limits and, if it's running, current usage
+// Uptime is set for running containers, StoppedSince for stopped ones
+type ResourceInfo struct {
+	CPULimit     string
+	MemoryLimit  string
+	CPUUsage     string
+	MemoryUsage  string
+	Uptime       string
+	StoppedSince string
+}
+
+// PrintContainerInfoDetailed prints a container's information followed by its resources
+// Uptime and usage lines are left out when they're empty, such as for stopped containers, and the
+// limits when there's no Docker container to read them from
+func PrintContainerInfoDetailed(c *database.Container, r ResourceInfo) {
+	info := containerInfo(c)
+	if r.Uptime != "" {
+		info += "\nUptime:      " + r.Uptime
+	}
+	if r.StoppedSince != "" {
+		info += "\nStopped:     since " + r.StoppedSince
+	}
+	if r.CPULimit != "" {
+		info += fmt.Sprintf(`
+CPU limit:   %s
+Mem limit:   %s`, r.CPULimit, r.MemoryLimit)
+	}
+	if r.CPUUsage != "" {
+		info += "\nCPU usage:   " + r.CPUUsage
+	}
+	if r.MemoryUsage != "" {
+		info += "\nMem usage:   " + r.MemoryUsage
+	}
 
+	Box(info)
+}
+
+func containerInfo(c *database.Container) string {
 	info := fmt.Sprintf(`Name:        %s
 Type:        %s
 Version:     %s
 Status:      %s
 Port:        %s
 Created:     %s
-Expires:     %s (%s remaining)
-Volume:      %s`,
+Expires:     %s
+Volume:      %s
+Network:     %s`,
 		c.DisplayName,
 		c.Type,
-		c.Version,
+		c.DisplayVersion(),
 		c.Status,
-		c.Port,
-		c.CreatedAt.Format("2006-01-02 15:04:05"),
-		c.ExpiresAt.Format("2006-01-02 15:04:05"),
-		FormatDuration(timeRemaining),
+		formatPortMapping(c),
+		FormatTime(c.CreatedAt),
+		formatExpiry(c),
 		formatVolumeInfo(c),
+		formatNetwork(c),
 	)
+	if len(c.Labels) > 0 {
+		info += "\nLabels:      " + strings.Join(c.Labels, ", ")
+	}
+	return info
+}
 
-	Box(info)
+// formatExpiry returns when a container expires and the time remaining, or that it's pinned
+func formatExpiry(c *database.Container) string {
+	if c.Pinned {
+		return "never (pinned)"
+	}
+	return fmt.Sprintf("%s (%s remaining)", FormatTime(c.ExpiresAt), FormatDuration(time.Until(c.ExpiresAt)))
+}
+
+// formatNetwork returns the container's Docker network, or "default" for the default bridge
+func formatNetwork(c *database.Container) string {
+	if c.Network == "" {
+		return "default"
+	}
+	return c.Network
+}
+
+// formatPortMapping describes the published port, e.g. "127.0.0.1:5433 -> 5432/tcp"
+// Falls back to the host port alone if the database type is unknown
+func formatPortMapping(c *database.Container) string {
+	adapter, err := adapters.GetRegistry().Get(c.Type)
+	if err != nil {
+		return c.Port
+	}
+	return docker.FormatPortBinding(docker.ResolveBindIP(c.BindIP), c.Port, adapter.GetDefaultPort())
 }
 
 func formatVolumeInfo(c *database.Container) string {
 	if c.VolumeType == "" {
 		return "none"
 	}
+	if c.VolumeDriver != "" {
+		return fmt.Sprintf("%s (%s, driver %s)", c.VolumePath, c.VolumeType, c.VolumeDriver)
+	}
 	return fmt.Sprintf("%s (%s)", c.VolumePath, c.VolumeType)
 }
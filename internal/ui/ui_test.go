@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"io"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -111,6 +113,86 @@ func TestPrintContainerInfoNoVolume(t *testing.T) {
 	PrintContainerInfo(container)
 }
 
+func TestPrintContainerInfoDetailed(t *testing.T) {
+	now := time.Now()
+	container := &database.Container{
+		DisplayName: "testdb",
+		Type:        "postgres",
+		Version:     "16",
+		Port:        "5432",
+		Status:      "stopped",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	stdout, _ := captureOutput(t, func() {
+		PrintContainerInfoDetailed(container, ResourceInfo{CPULimit: "unlimited", MemoryLimit: "512.0 MB"})
+	})
+	if !strings.Contains(stdout, "CPU limit:   unlimited") || !strings.Contains(stdout, "Mem limit:   512.0 MB") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want the resource limits", stdout)
+	}
+	if strings.Contains(stdout, "usage") || strings.Contains(stdout, "Uptime") || strings.Contains(stdout, "Stopped") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want no usage or uptime without them", stdout)
+	}
+
+	stdout, _ = captureOutput(t, func() {
+		PrintContainerInfoDetailed(container, ResourceInfo{CPULimit: "unlimited", MemoryLimit: "unlimited", StoppedSince: "Jan 2 15:04 (2h 0m ago)"})
+	})
+	if !strings.Contains(stdout, "Stopped:     since Jan 2 15:04 (2h 0m ago)") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want when it stopped", stdout)
+	}
+
+	// A removed Docker container has no limits to show
+	stdout, _ = captureOutput(t, func() {
+		PrintContainerInfoDetailed(container, ResourceInfo{StoppedSince: "Jan 2 15:04 (2h 0m ago)"})
+	})
+	if !strings.Contains(stdout, "Stopped:     since") || strings.Contains(stdout, "limit") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want when it stopped and no limits", stdout)
+	}
+
+	stdout, _ = captureOutput(t, func() {
+		PrintContainerInfoDetailed(container, ResourceInfo{CPULimit: "unlimited", MemoryLimit: "unlimited", CPUUsage: "1.5%", MemoryUsage: "40.0 MB", Uptime: "3h 12m"})
+	})
+	if !strings.Contains(stdout, "CPU usage:   1.5%") || !strings.Contains(stdout, "Mem usage:   40.0 MB") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want the resource usage", stdout)
+	}
+	if !strings.Contains(stdout, "Uptime:      3h 12m") {
+		t.Errorf("PrintContainerInfoDetailed() output = %q, want the uptime", stdout)
+	}
+}
+
+func TestFormatPortMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		container *database.Container
+		want      string
+	}{
+		{
+			name:      "Default bind address",
+			container: &database.Container{Type: "postgres", Port: "5433"},
+			want:      "0.0.0.0:5433 -> 5432/tcp",
+		},
+		{
+			name:      "Loopback",
+			container: &database.Container{Type: "redis", Port: "16379", BindIP: "127.0.0.1"},
+			want:      "127.0.0.1:16379 -> 6379/tcp",
+		},
+		{
+			name:      "Unknown type",
+			container: &database.Container{Type: "mongo", Port: "27017"},
+			want:      "27017",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPortMapping(tt.container); got != tt.want {
+				t.Errorf("formatPortMapping() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFormatVolumeInfo(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -234,3 +316,154 @@ func TestSelectUserError(t *testing.T) {
 		t.Errorf("SelectUser() error = %v, want error containing %q", err, expectedMsg)
 	}
 }
+
+func TestSelectDatabaseError(t *testing.T) {
+	// Test with empty database list
+	_, err := SelectDatabase([]*database.LogicalDatabase{}, "Select database")
+	if err == nil {
+		t.Error("SelectDatabase() with empty list should return error")
+	}
+
+	expectedMsg := "no databases found"
+	if !strings.Contains(err.Error(), expectedMsg) {
+		t.Errorf("SelectDatabase() error = %v, want error containing %q", err, expectedMsg)
+	}
+}
+
+// captureOutput returns what fn writes to stdout and stderr
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	fn()
+	outW.Close()
+	errW.Close()
+
+	out, _ := io.ReadAll(outR)
+	errOut, _ := io.ReadAll(errR)
+	return string(out), string(errOut)
+}
+
+func TestQuiet(t *testing.T) {
+	Quiet = true
+	defer func() { Quiet = false }()
+
+	stdout, stderr := captureOutput(t, func() {
+		Success("created")
+		Info("info")
+		Header("header")
+		Newline()
+		Warning("careful")
+		Error("failed")
+	})
+
+	if stdout != "" {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+	if !strings.Contains(stderr, "careful") || !strings.Contains(stderr, "failed") {
+		t.Errorf("stderr = %q, want the warning and error", stderr)
+	}
+}
+
+func TestSetTimeZone(t *testing.T) {
+	defer func() { location = time.Local }()
+	expires := time.Date(2026, 10, 17, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"Flag", "America/Los_Angeles", "", "2026-10-16 17:30:00 PDT", false},
+		{"Environment", "", "Europe/Berlin", "2026-10-17 02:30:00 CEST", false},
+		{"Flag overrides environment", "UTC", "Europe/Berlin", "2026-10-17 00:30:00 UTC", false},
+		{"Unknown zone", "Mars/Olympus_Mons", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TimeZoneEnvVar, tt.env)
+			err := SetTimeZone(tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetTimeZone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := FormatTime(expires); got != tt.want {
+				t.Errorf("FormatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintStructured(t *testing.T) {
+	value := struct {
+		Name  string `json:"name" yaml:"name"`
+		Ports []int  `json:"ports" yaml:"ports"`
+	}{"mydb", []int{5432}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{OutputJSON, "{\n  \"name\": \"mydb\",\n  \"ports\": [\n    5432\n  ]\n}\n"},
+		{OutputYAML, "name: mydb\nports:\n  - 5432\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			stdout, _ := captureOutput(t, func() {
+				if err := PrintStructured(tt.format, value); err != nil {
+					t.Errorf("PrintStructured() error = %v", err)
+				}
+			})
+			if stdout != tt.want {
+				t.Errorf("PrintStructured() printed %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+
+	if err := ValidateOutputFormat("xml"); err == nil {
+		t.Error("ValidateOutputFormat(xml) error = nil, want error")
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	pinned := &database.Container{ExpiresAt: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), Pinned: true}
+	if got := formatExpiry(pinned); got != "never (pinned)" {
+		t.Errorf("formatExpiry() = %q, want never (pinned)", got)
+	}
+
+	expiring := &database.Container{ExpiresAt: time.Now().Add(90 * time.Minute)}
+	if got := formatExpiry(expiring); !strings.HasSuffix(got, "remaining)") {
+		t.Errorf("formatExpiry() = %q, want the time remaining", got)
+	}
+}
+
+func TestWarningStderr(t *testing.T) {
+	stdout, stderr := captureOutput(t, func() {
+		WarningStderr("careful")
+	})
+
+	if stdout != "" {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+	if !strings.Contains(stderr, "careful") {
+		t.Errorf("stderr = %q, want the warning", stderr)
+	}
+}
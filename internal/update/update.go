@@ -0,0 +1,161 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LatestReleaseURL is the GitHub API endpoint for mkdb's latest release
+	LatestReleaseURL = "https://api.github.com/repos/pbzona/mkdb/releases/latest"
+	// DisableEnvVar turns off update checks when set to a non-empty value
+	DisableEnvVar = "MKDB_NO_UPDATE_CHECK"
+	// DefaultTimeout is how long an update check waits for GitHub
+	DefaultTimeout = 5 * time.Second
+)
+
+// HTTPClient sends requests, and is satisfied by *http.Client
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Checker looks up the latest mkdb release
+type Checker struct {
+	Client HTTPClient
+	URL    string
+}
+
+// Result is the outcome of an update check
+type Result struct {
+	Current         string
+	Latest          string
+	URL             string
+	UpdateAvailable bool
+}
+
+// release is the part of the GitHub releases API response mkdb uses
+type release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// NewChecker returns a Checker for mkdb's GitHub releases
+func NewChecker() *Checker {
+	return &Checker{
+		Client: &http.Client{Timeout: DefaultTimeout},
+		URL:    LatestReleaseURL,
+	}
+}
+
+// Disabled reports whether update checks are turned off with MKDB_NO_UPDATE_CHECK
+func Disabled() bool {
+	return strings.TrimSpace(os.Getenv(DisableEnvVar)) != ""
+}
+
+// Check fetches the latest release and compares it with current
+// A current version that isn't a release, such as "dev", never has an update available
+func (c *Checker) Check(ctx context.Context, current string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch latest release: %s", resp.Status)
+	}
+
+	var latest release
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, fmt.Errorf("failed to decode latest release: %w", err)
+	}
+	if latest.TagName == "" {
+		return nil, fmt.Errorf("latest release has no tag")
+	}
+
+	result := &Result{
+		Current: current,
+		Latest:  latest.TagName,
+		URL:     latest.HTMLURL,
+	}
+	if IsRelease(current) {
+		result.UpdateAvailable = Compare(latest.TagName, current) > 0
+	}
+	return result, nil
+}
+
+// IsRelease reports whether version is a release version such as v1.2.3, rather than a development build
+func IsRelease(version string) bool {
+	_, ok := parseVersion(version)
+	return ok
+}
+
+// Compare compares two release versions, returning -1 if a is older than b, 1 if it is newer, and 0 otherwise
+// A pre-release such as v1.2.0-rc.1 is older than v1.2.0, and versions that can't be parsed compare as equal
+func Compare(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0
+	}
+
+	for i := range va.parts {
+		if va.parts[i] != vb.parts[i] {
+			if va.parts[i] < vb.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0
+	case va.prerelease == "":
+		return 1
+	case vb.prerelease == "":
+		return -1
+	}
+	return strings.Compare(va.prerelease, vb.prerelease)
+}
+
+// version is a parsed major.minor.patch version
+type version struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseVersion parses a version like v1.2.3 or 1.2.3-rc.1, a missing minor or patch version is taken as 0
+func parseVersion(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	// Build metadata doesn't affect ordering
+	s, _, _ = strings.Cut(s, "+")
+	s, v.prerelease, _ = strings.Cut(s, "-")
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return version{}, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,145 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakeClient returns a canned response instead of calling GitHub
+type fakeClient struct {
+	status int
+	body   string
+	err    error
+	req    *http.Request
+}
+
+func (f *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	f.req = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &http.Response{
+		StatusCode: f.status,
+		Status:     http.StatusText(f.status),
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+	}, nil
+}
+
+func TestCheck(t *testing.T) {
+	body := `{"tag_name": "v1.3.0", "html_url": "https://github.com/pbzona/mkdb/releases/tag/v1.3.0"}`
+
+	tests := []struct {
+		name       string
+		current    string
+		wantUpdate bool
+	}{
+		{"Older", "v1.2.4", true},
+		{"Same", "v1.3.0", false},
+		{"Newer", "v1.4.0", false},
+		{"Development build", "dev", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{status: http.StatusOK, body: body}
+			checker := &Checker{Client: client, URL: LatestReleaseURL}
+
+			result, err := checker.Check(context.Background(), tt.current)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if result.Latest != "v1.3.0" {
+				t.Errorf("Check() Latest = %q, want v1.3.0", result.Latest)
+			}
+			if result.URL != "https://github.com/pbzona/mkdb/releases/tag/v1.3.0" {
+				t.Errorf("Check() URL = %q", result.URL)
+			}
+			if result.UpdateAvailable != tt.wantUpdate {
+				t.Errorf("Check() UpdateAvailable = %v, want %v", result.UpdateAvailable, tt.wantUpdate)
+			}
+			if client.req.URL.String() != LatestReleaseURL {
+				t.Errorf("Check() requested %s, want %s", client.req.URL, LatestReleaseURL)
+			}
+		})
+	}
+}
+
+func TestCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *fakeClient
+	}{
+		{"Request fails", &fakeClient{err: errors.New("network down")}},
+		{"Not found", &fakeClient{status: http.StatusNotFound, body: `{"message": "Not Found"}`}},
+		{"Invalid JSON", &fakeClient{status: http.StatusOK, body: `not json`}},
+		{"Missing tag", &fakeClient{status: http.StatusOK, body: `{}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &Checker{Client: tt.client, URL: LatestReleaseURL}
+			if _, err := checker.Check(context.Background(), "v1.0.0"); err == nil {
+				t.Error("Check() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.2.4", "v1.2.3", 1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.2.3", "v1.3.0", -1},
+		{"v1.2", "v1.2.0", 0},
+		{"v1.2.0-rc.1", "v1.2.0", -1},
+		{"v1.2.0", "v1.2.0-rc.1", 1},
+		{"v1.2.0-rc.2", "v1.2.0-rc.1", 1},
+		{"v1.2.0+build.5", "v1.2.0", 0},
+		{"dev", "v1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	tests := map[string]bool{
+		"v1.2.3":      true,
+		"1.2.3":       true,
+		"v1.2.0-rc.1": true,
+		"dev":         false,
+		"":            false,
+		"v1.2.3.4":    false,
+	}
+
+	for version, want := range tests {
+		if got := IsRelease(version); got != want {
+			t.Errorf("IsRelease(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	t.Setenv(DisableEnvVar, "")
+	if Disabled() {
+		t.Error("Disabled() = true with MKDB_NO_UPDATE_CHECK unset")
+	}
+
+	t.Setenv(DisableEnvVar, "1")
+	if !Disabled() {
+		t.Error("Disabled() = false with MKDB_NO_UPDATE_CHECK=1")
+	}
+}
@@ -0,0 +1,59 @@
+package volumes
+
+import (
+	"path/filepath"
+
+	"github.com/pbzona/mkdb/internal/adapters"
+)
+
+// typeMarker is a file or directory that a database leaves in its volume
+type typeMarker struct {
+	dbType     string
+	found      func(volumePath string) bool
+	confidence float64
+}
+
+// glob matches a pattern relative to the volume, as the directory is mounted into the container
+func glob(pattern string) func(string) bool {
+	return func(volumePath string) bool {
+		matches, err := filepath.Glob(filepath.Join(volumePath, pattern))
+		return err == nil && len(matches) > 0
+	}
+}
+
+// postgresData matches the data directory layout of any Postgres image version
+func postgresData(volumePath string) bool {
+	path, err := adapters.PostgresVersionFile(volumePath)
+	return err == nil && path != ""
+}
+
+// typeMarkers are checked in order. Postgres variants share the postgres layout, so they're
+// reported as postgres
+var typeMarkers = []typeMarker{
+	{"postgres", postgresData, 0.9},
+	{"mysql", glob("mysql_upgrade_info"), 0.9},
+	{"mysql", glob("ibdata1"), 0.7},
+	{"mysql", glob("mysql"), 0.5},
+	{"redis", glob("dump.rdb"), 0.8},
+	{"redis", glob("appendonlydir"), 0.8},
+	{"redis", glob("appendonly.aof"), 0.8},
+}
+
+// DetectType guesses which database wrote the volume at volumePath from the files it left behind
+// It returns the type and a confidence between 0 and 1, or "" and 0 if nothing matched.
+// When markers for one type are found together, e.g. ibdata1 and mysql/, the confidence adds up
+func DetectType(volumePath string) (string, float64) {
+	scores := make(map[string]float64)
+	best := ""
+	for _, marker := range typeMarkers {
+		if !marker.found(volumePath) {
+			continue
+		}
+
+		scores[marker.dbType] = min(1, scores[marker.dbType]+marker.confidence)
+		if best == "" || scores[marker.dbType] > scores[best] {
+			best = marker.dbType
+		}
+	}
+	return best, scores[best]
+}
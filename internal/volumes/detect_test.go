@@ -0,0 +1,58 @@
+package volumes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectType(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          []string // Paths ending in / are created as directories
+		want           string
+		wantConfidence float64
+	}{
+		{"Postgres", []string{"data/PG_VERSION", "data/base/"}, "postgres", 0.9},
+		{"Postgres 18 layout", []string{"18/docker/PG_VERSION"}, "postgres", 0.9},
+		{"MySQL", []string{"ibdata1", "mysql/", "mysql_upgrade_info"}, "mysql", 1},
+		{"MySQL system schema only", []string{"mysql/"}, "mysql", 0.5},
+		{"MySQL tablespace and schema", []string{"ibdata1", "mysql/"}, "mysql", 1},
+		{"Redis snapshot", []string{"dump.rdb"}, "redis", 0.8},
+		{"Redis append-only", []string{"appendonlydir/"}, "redis", 0.8},
+		{"Empty", nil, "", 0},
+		{"Unrelated files", []string{"notes.txt", "data/"}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(dir, file)
+				if file[len(file)-1] == '/' {
+					if err := os.MkdirAll(path, 0755); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, confidence := DetectType(dir)
+			if got != tt.want || confidence != tt.wantConfidence {
+				t.Errorf("DetectType() = %q, %v, want %q, %v", got, confidence, tt.want, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectType_MissingVolume(t *testing.T) {
+	if got, confidence := DetectType(filepath.Join(t.TempDir(), "missing")); got != "" || confidence != 0 {
+		t.Errorf("DetectType() = %q, %v, want no guess", got, confidence)
+	}
+}
@@ -0,0 +1,234 @@
+package volumes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/pbzona/mkdb/internal/config"
+)
+
+// snapshotTimeFormat names snapshot directories so they sort by creation time
+const snapshotTimeFormat = "20060102-150405"
+
+// Snapshot is a copy of a container's volume directory
+type Snapshot struct {
+	ID        string // Directory name, the creation time
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// SnapshotsDir returns the directory holding a container's snapshots
+func SnapshotsDir(name string) string {
+	return filepath.Join(config.DataDir, "snapshots", name)
+}
+
+// CreateSnapshot copies volumeDir to a new snapshot of the named container
+func CreateSnapshot(name, volumeDir string) (*Snapshot, error) {
+	if info, err := os.Stat(volumeDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("volume directory %s does not exist", volumeDir)
+	}
+
+	now := time.Now()
+	id := now.Format(snapshotTimeFormat)
+	path := filepath.Join(SnapshotsDir(name), id)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("snapshot %s already exists, try again in a second", id)
+	}
+
+	if err := CopyVolume(volumeDir, path); err != nil {
+		// Don't leave a partial snapshot that could be rolled back to
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to copy volume: %w", err)
+	}
+
+	size, err := getDirSize(path)
+	if err != nil {
+		config.Logger.Warn("Failed to calculate snapshot size", "snapshot", path, "error", err)
+	}
+
+	return &Snapshot{ID: id, Path: path, Size: size, CreatedAt: now}, nil
+}
+
+// ListSnapshots returns a container's snapshots, newest first
+func ListSnapshots(name string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(SnapshotsDir(name))
+	if os.IsNotExist(err) {
+		return []*Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(snapshotTimeFormat, entry.Name(), time.Local)
+		if err != nil {
+			// Not a snapshot created by mkdb
+			continue
+		}
+
+		path := filepath.Join(SnapshotsDir(name), entry.Name())
+		size, err := getDirSize(path)
+		if err != nil {
+			config.Logger.Warn("Failed to calculate snapshot size", "snapshot", path, "error", err)
+		}
+		snapshots = append(snapshots, &Snapshot{ID: entry.Name(), Path: path, Size: size, CreatedAt: createdAt})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// RemoveSnapshots deletes all snapshots of the named container
+func RemoveSnapshots(name string) error {
+	return os.RemoveAll(SnapshotsDir(name))
+}
+
+// RestoreSnapshot replaces the contents of volumeDir with a snapshot
+// The snapshot is copied next to volumeDir first, so a failed copy leaves the volume untouched
+func RestoreSnapshot(snapshot *Snapshot, volumeDir string) error {
+	staging := volumeDir + ".mkdb-restore"
+	old := volumeDir + ".mkdb-old"
+	os.RemoveAll(staging)
+	os.RemoveAll(old)
+
+	if err := CopyVolume(snapshot.Path, staging); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+
+	if err := os.Rename(volumeDir, old); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move volume aside: %w", err)
+	}
+	if err := os.Rename(staging, volumeDir); err != nil {
+		// Put the original volume back
+		os.Rename(old, volumeDir)
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	if err := os.RemoveAll(old); err != nil {
+		config.Logger.Warn("Failed to remove replaced volume", "path", old, "error", err)
+	}
+	return nil
+}
+
+// CopyVolume recursively copies the directory src to dst, which must not exist
+// Permissions, file modification times and symlinks are preserved, and so is ownership when running as
+// root, since database servers check the owner of their data directory
+func CopyVolume(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// Directories are created writable so their contents can be copied, and get their mode afterwards
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var dirs []dirMode
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+		case info.IsDir():
+			if err := os.Mkdir(target, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{target, info.Mode().Perm()})
+		case info.Mode().IsRegular():
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			// Sockets and pipes are recreated by the server
+			return nil
+		}
+
+		preserveOwner(target, info)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deepest first, so a read-only parent doesn't stop its children from being updated
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preserveOwner gives target the owner of the file described by info, which only works as root
+func preserveOwner(target string, info os.FileInfo) {
+	if os.Geteuid() != 0 {
+		return
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		os.Lchown(target, int(stat.Uid), int(stat.Gid))
+	}
+}
+
+// copyFile copies a regular file's contents and modification time to a new file with the given mode
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// The mode given to OpenFile is reduced by the umask
+	if err := os.Chmod(dst, mode); err != nil {
+		return err
+	}
+	if info, err := in.Stat(); err == nil {
+		os.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package volumes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pbzona/mkdb/internal/config"
+)
+
+// writeTestVolume creates a small volume directory with a nested file, a private file and a symlink
+func writeTestVolume(t *testing.T, dir, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "base"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "base", "data"), []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "PG_VERSION"), []byte("16\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("base/data", filepath.Join(dir, "current")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyVolume(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	writeTestVolume(t, src, "hello")
+	dst := filepath.Join(t.TempDir(), "nested", "dst")
+
+	if err := CopyVolume(src, dst); err != nil {
+		t.Fatalf("CopyVolume() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "base", "data"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("copied file = %q, %v, want hello", data, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "base", "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("copied file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	dirInfo, err := os.Stat(filepath.Join(dst, "base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirInfo.Mode().Perm() != 0700 {
+		t.Errorf("copied directory mode = %v, want 0700", dirInfo.Mode().Perm())
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "current"))
+	if err != nil || link != "base/data" {
+		t.Errorf("copied symlink = %q, %v, want base/data", link, err)
+	}
+
+	if err := CopyVolume(src, dst); err == nil {
+		t.Error("CopyVolume() to an existing directory should fail")
+	}
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	config.DataDir = t.TempDir()
+	volumeDir := filepath.Join(t.TempDir(), "mydb")
+	writeTestVolume(t, volumeDir, "before")
+
+	snapshot, err := CreateSnapshot("mydb", volumeDir)
+	if err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	if filepath.Dir(snapshot.Path) != SnapshotsDir("mydb") {
+		t.Errorf("CreateSnapshot() path = %v, want it in %v", snapshot.Path, SnapshotsDir("mydb"))
+	}
+
+	// A directory that isn't named like a snapshot is ignored
+	if err := os.Mkdir(filepath.Join(SnapshotsDir("mydb"), "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := ListSnapshots("mydb")
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != snapshot.ID || snapshots[0].Size == 0 {
+		t.Fatalf("ListSnapshots() = %+v, want the snapshot that was created", snapshots)
+	}
+
+	// Change the volume, then roll back
+	if err := os.WriteFile(filepath.Join(volumeDir, "base", "data"), []byte("after"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(volumeDir, "new"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreSnapshot(snapshots[0], volumeDir); err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(volumeDir, "base", "data"))
+	if err != nil || string(data) != "before" {
+		t.Errorf("restored file = %q, %v, want before", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(volumeDir, "new")); !os.IsNotExist(err) {
+		t.Errorf("file created after the snapshot still exists after restoring")
+	}
+	if _, err := os.Stat(volumeDir + ".mkdb-old"); !os.IsNotExist(err) {
+		t.Errorf("replaced volume was left behind")
+	}
+
+	// The snapshot itself is kept so it can be restored again
+	if _, err := os.Stat(snapshot.Path); err != nil {
+		t.Errorf("snapshot was removed by restoring it: %v", err)
+	}
+}
+
+func TestListSnapshots_None(t *testing.T) {
+	config.DataDir = t.TempDir()
+
+	snapshots, err := ListSnapshots("missing")
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Errorf("ListSnapshots() = %+v, want none", snapshots)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pbzona/mkdb/internal/config"
@@ -19,6 +20,30 @@ type OrphanedVolume struct {
 	Container *database.Container // Original container info if available
 }
 
+// ResolveBindPath converts a bind mount path to an absolute path, resolving
+// relative paths against the current working directory
+func ResolveBindPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("volume path cannot be empty")
+	}
+	if strings.ContainsRune(path, 0) {
+		return "", fmt.Errorf("volume path %q contains invalid characters", path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve volume path %q: %w", path, err)
+	}
+
+	// Docker can only bind mount a directory here
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		return "", fmt.Errorf("volume path %s is not a directory", absPath)
+	}
+
+	return absPath, nil
+}
+
 // ScanOrphaned finds volumes on disk that don't have an active container
 func ScanOrphaned() ([]*OrphanedVolume, error) {
 	volumesDir := config.VolumesDir
@@ -34,6 +34,55 @@ func TestFormatSize(t *testing.T) {
 	}
 }
 
+func TestResolveBindPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	// Resolve tmpDir the same way filepath.Abs will see the working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+
+	filePath := filepath.Join(cwd, "file.txt")
+	if err := os.WriteFile(filePath, []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"relative path", "./data", filepath.Join(cwd, "data"), false},
+		{"bare relative path", "data/pg", filepath.Join(cwd, "data", "pg"), false},
+		{"parent path", "../data", filepath.Join(filepath.Dir(cwd), "data"), false},
+		{"absolute path", "/var/lib/mkdb", "/var/lib/mkdb", false},
+		{"unclean absolute path", "/var/lib/../lib/mkdb/", "/var/lib/mkdb", false},
+		{"surrounding whitespace", "  ./data  ", filepath.Join(cwd, "data"), false},
+		{"empty path", "", "", true},
+		{"whitespace path", "   ", "", true},
+		{"nul byte", "data\x00pg", "", true},
+		{"existing file", "file.txt", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveBindPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveBindPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveBindPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+			if !tt.wantErr && !filepath.IsAbs(got) {
+				t.Errorf("ResolveBindPath(%q) = %v, want absolute path", tt.path, got)
+			}
+		})
+	}
+}
+
 func TestScanOrphaned(t *testing.T) {
 	// Initialize config and database for testing
 	if err := config.Initialize(); err != nil {
@@ -249,3 +298,70 @@ func TestOrphanedVolumeWithOriginalContainer(t *testing.T) {
 		}
 	}
 }
+
+func TestOrphanedVolumeWithRemovedContainer(t *testing.T) {
+	// Initialize config and database
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if err := database.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	// Create a test volume
+	testVolumeName := "test-orphaned-after-rm"
+	testVolumePath := filepath.Join(config.VolumesDir, testVolumeName)
+	os.RemoveAll(testVolumePath)
+
+	if err := os.MkdirAll(testVolumePath, 0755); err != nil {
+		t.Fatalf("Failed to create test volume: %v", err)
+	}
+	defer os.RemoveAll(testVolumePath)
+
+	// Remove the container the way 'mkdb rm' and cleanup do, which keeps its record
+	container := &database.Container{
+		Name:        "mkdb-" + testVolumeName,
+		DisplayName: testVolumeName,
+		Type:        "postgres",
+		Version:     "16",
+		Status:      "running",
+		Port:        "5432",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+		VolumeType:  "named",
+		VolumePath:  testVolumeName,
+	}
+
+	if err := database.CreateContainer(container); err != nil {
+		t.Fatalf("Failed to create test container: %v", err)
+	}
+	defer database.DeleteContainer(container.ID)
+
+	if err := database.MarkContainerRemoved(container.ID); err != nil {
+		t.Fatalf("Failed to mark container removed: %v", err)
+	}
+
+	orphaned, err := ScanOrphaned()
+	if err != nil {
+		t.Fatalf("ScanOrphaned() error: %v", err)
+	}
+
+	var testVol *OrphanedVolume
+	for _, vol := range orphaned {
+		if vol.Name == testVolumeName {
+			testVol = vol
+			break
+		}
+	}
+
+	if testVol == nil {
+		t.Fatalf("Volume of removed container %s should be orphaned", testVolumeName)
+	}
+	if testVol.Container == nil {
+		t.Fatal("Expected Container metadata from the removed record")
+	}
+	if testVol.Container.Type != "postgres" || testVol.Container.Version != "16" {
+		t.Errorf("Container = %s %s, want postgres 16", testVol.Container.Type, testVol.Container.Version)
+	}
+}